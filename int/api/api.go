@@ -19,6 +19,8 @@ import (
 	metricsPkg "github.com/massalabs/node-manager-plugin/int/node-api/metrics"
 	nodeDirManager "github.com/massalabs/node-manager-plugin/int/node-bin-dir-manager"
 	nodeDriverPkg "github.com/massalabs/node-manager-plugin/int/node-driver"
+	priceProviderPkg "github.com/massalabs/node-manager-plugin/int/price-provider"
+	eventBusPkg "github.com/massalabs/node-manager-plugin/pkg/event-bus"
 	"github.com/massalabs/station/pkg/logger"
 	pluginKit "github.com/massalabs/station/plugin-kit"
 )
@@ -37,6 +39,7 @@ type API struct {
 	stakingManager   stakingManagerPkg.StakingManager
 	db               db.DB
 	historyMgr       *historymanager.HistoryManager
+	eventBus         eventBusPkg.EventBus
 }
 
 // NewAPI creates a new API with the provided plugin directory
@@ -65,7 +68,16 @@ func NewAPI(config *config.PluginConfig) *API {
 		logger.Fatalf("could not create a database instance, got : %s", err)
 	}
 
+	eventBus := eventBusPkg.NewEventBus(10)
+
 	historyMgr := historymanager.NewHistoryManager(db, int64(config.TotValueDelAfter), int64(config.TotValueRegisterInterval))
+	historyMgr.RecordNodeStatus(statusDispatcher)
+
+	priceProvider, err := priceProviderPkg.NewPriceProvider(config, db)
+	if err != nil {
+		logger.Fatalf("could not create the price provider, got : %s", err)
+	}
+	historyMgr.SetPriceProvider(priceProvider)
 
 	stakingManager := stakingManagerPkg.NewStakingManager(
 		nodeAPI,
@@ -74,8 +86,9 @@ func NewAPI(config *config.PluginConfig) *API {
 		nodeDirManager,
 		uint64(config.StakingAddressDataPollInterval),
 		uint64(config.ClientTimeout),
-		stakingManagerPkg.NewMassaWalletManager(),
+		stakingManagerPkg.NewMassaWalletManager(config.KeystorePath),
 		config,
+		eventBus,
 	)
 
 	// create the node manager instance
@@ -84,6 +97,7 @@ func NewAPI(config *config.PluginConfig) *API {
 		nodeMonitor,
 		nodeDriver,
 		statusDispatcher,
+		stakingManager,
 	)
 	if err != nil {
 		logger.Fatalf("could not create a node manager instance, got : %s", err)
@@ -99,6 +113,7 @@ func NewAPI(config *config.PluginConfig) *API {
 		stakingManager:   stakingManager,
 		db:               db,
 		historyMgr:       historyMgr,
+		eventBus:         eventBus,
 	}
 }
 
@@ -162,8 +177,36 @@ func (a API) registerHandlers() {
 	a.api.AddStakingAddressHandler = operations.AddStakingAddressHandlerFunc(handlers.HandlePostStakingAddresses(a.stakingManager))
 	a.api.UpdateStakingAddressHandler = operations.UpdateStakingAddressHandlerFunc(handlers.HandlePutStakingAddresses(a.stakingManager))
 	a.api.RemoveStakingAddressHandler = operations.RemoveStakingAddressHandlerFunc(handlers.HandleDeleteStakingAddresses(a.stakingManager))
+	a.api.ImportStakingAddressesHandler = operations.ImportStakingAddressesHandlerFunc(handlers.HandleImportStakingAddresses(a.stakingManager))
+	a.api.AddStakingKeyHandler = operations.AddStakingKeyHandlerFunc(handlers.HandleAddStakingKey(a.stakingManager))
+	a.api.GetWalletAccountsHandler = operations.GetWalletAccountsHandlerFunc(handlers.HandleGetWalletAccounts(a.stakingManager))
+	a.api.GetDecommissionsHandler = operations.GetDecommissionsHandlerFunc(handlers.HandleGetDecommissions(a.stakingManager))
 	a.api.GetRollOpHistoryHandler = operations.GetRollOpHistoryHandlerFunc(handlers.HandleGetRollOpHistory(a.db))
+	a.api.GetAutomationPausesHandler = operations.GetAutomationPausesHandlerFunc(handlers.HandleGetAutomationPauses(a.stakingManager))
+	a.api.SetAutomationPauseHandler = operations.SetAutomationPauseHandlerFunc(handlers.HandleSetAutomationPause(a.stakingManager))
+	a.api.GetWatchOnlyAddressesHandler = operations.GetWatchOnlyAddressesHandlerFunc(handlers.HandleGetWatchOnlyAddresses(a.stakingManager))
+	a.api.AddWatchOnlyAddressHandler = operations.AddWatchOnlyAddressHandlerFunc(handlers.HandleAddWatchOnlyAddress(a.stakingManager))
+	a.api.RemoveWatchOnlyAddressHandler = operations.RemoveWatchOnlyAddressHandlerFunc(handlers.HandleRemoveWatchOnlyAddress(a.stakingManager))
+	a.api.GetAddressLabelsHandler = operations.GetAddressLabelsHandlerFunc(handlers.HandleGetAddressLabels(a.stakingManager))
+	a.api.SetAddressLabelHandler = operations.SetAddressLabelHandlerFunc(handlers.HandleSetAddressLabel(a.stakingManager))
+	a.api.DeleteAddressLabelHandler = operations.DeleteAddressLabelHandlerFunc(handlers.HandleDeleteAddressLabel(a.stakingManager))
+	a.api.GetAddressGroupsHandler = operations.GetAddressGroupsHandlerFunc(handlers.HandleGetAddressGroups(a.stakingManager))
+	a.api.GetBlockedRollOpsHandler = operations.GetBlockedRollOpsHandlerFunc(handlers.HandleGetBlockedRollOps(a.db))
+	a.api.GetRollSchedulesHandler = operations.GetRollSchedulesHandlerFunc(handlers.HandleGetRollSchedules(a.stakingManager))
+	a.api.ScheduleRollChangeHandler = operations.ScheduleRollChangeHandlerFunc(handlers.HandleScheduleRollChange(a.stakingManager))
+	a.api.UpdateRollScheduleHandler = operations.UpdateRollScheduleHandlerFunc(handlers.HandleUpdateRollSchedule(a.stakingManager))
+	a.api.CancelRollScheduleHandler = operations.CancelRollScheduleHandlerFunc(handlers.HandleCancelRollSchedule(a.stakingManager))
+	a.api.GetSweepRulesHandler = operations.GetSweepRulesHandlerFunc(handlers.HandleGetSweepRules(a.stakingManager))
+	a.api.SetSweepRuleHandler = operations.SetSweepRuleHandlerFunc(handlers.HandleSetSweepRule(a.stakingManager))
+	a.api.DeleteSweepRuleHandler = operations.DeleteSweepRuleHandlerFunc(handlers.HandleDeleteSweepRule(a.stakingManager))
+	a.api.GetSweepHistoryHandler = operations.GetSweepHistoryHandlerFunc(handlers.HandleGetSweepHistory(a.db))
 	a.api.GetValueHistoryHandler = operations.GetValueHistoryHandlerFunc(handlers.HandleGetValueHistory(a.db, a.historyMgr, a.config))
+	a.api.GetPerformanceHandler = operations.GetPerformanceHandlerFunc(handlers.HandleGetPerformance(a.historyMgr))
+	a.api.GetLedgerHandler = operations.GetLedgerHandlerFunc(handlers.HandleGetLedger(a.db, a.historyMgr))
+	a.api.ExportAccountingHandler = operations.ExportAccountingHandlerFunc(handlers.HandleExportAccounting(a.historyMgr))
+	a.api.GetBlockProductionHistoryHandler = operations.GetBlockProductionHistoryHandlerFunc(handlers.HandleGetBlockProductionHistory(a.db))
+	a.api.GetNextDrawsHandler = operations.GetNextDrawsHandlerFunc(handlers.HandleGetNextDraws(a.stakingManager))
+	a.api.GetStakingEventsHandler = operations.GetStakingEventsHandlerFunc(handlers.HandleStakingEventsFeeder(a.eventBus))
 }
 
 func (a *API) Cleanup() {
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/massalabs/node-manager-plugin/api/models"
+	"github.com/massalabs/node-manager-plugin/api/restapi/operations"
+	historymanager "github.com/massalabs/node-manager-plugin/int/core/history-manager"
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+func HandleExportAccounting(historyMgr *historymanager.HistoryManager) func(params operations.ExportAccountingParams) middleware.Responder {
+	return func(params operations.ExportAccountingParams) middleware.Responder {
+		options := historymanager.ExportOptions{
+			Consolidated: params.Consolidated != nil && *params.Consolidated,
+			Period:       historymanager.ExportPeriodMonth,
+			Version:      historymanager.AccountingExportVersion,
+		}
+		if params.Version != nil {
+			options.Version = int(*params.Version)
+		}
+		if options.Version < historymanager.MinAccountingExportVersion || options.Version > historymanager.AccountingExportVersion {
+			return createErrorResponse(400, fmt.Sprintf("Unsupported export version %d, the supported versions are %d to %d",
+				options.Version, historymanager.MinAccountingExportVersion, historymanager.AccountingExportVersion))
+		}
+		if params.Currency != nil {
+			options.Currency = *params.Currency
+		}
+		if options.Currency != "" && options.Version < 2 {
+			return createErrorResponse(400, "Fiat values require the version 2 of the export")
+		}
+
+		since := time.Time(params.Since)
+		until := time.Now()
+		if params.Until != nil {
+			until = time.Time(*params.Until)
+		}
+		if !since.Before(until) {
+			return createErrorResponse(400, "Since must be before until")
+		}
+
+		csvFormat := params.Format != nil && *params.Format == "csv"
+		var section historymanager.ExportSection
+		if params.Section != nil {
+			section = historymanager.ExportSection(*params.Section)
+		}
+		if csvFormat && section == "" {
+			return createErrorResponse(400, "A section must be set to export in csv format")
+		}
+
+		if params.Period != nil {
+			options.Period = historymanager.ExportPeriod(*params.Period)
+		}
+		if params.Address != nil {
+			options.Address = *params.Address
+		}
+
+		network := utils.NetworkBuildnet
+		if params.IsMainnet {
+			network = utils.NetworkMainnet
+		}
+
+		export, err := historyMgr.ExportAccounting(since, until, network, options)
+		if err != nil {
+			if nodeManagerError.Is(err, nodeManagerError.ErrPriceSourceNotConfigured) {
+				return createErrorResponse(400, err.Error())
+			}
+			return operations.NewExportAccountingInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		return middleware.ResponderFunc(func(w http.ResponseWriter, _ runtime.Producer) {
+			w.Header().Set("X-Export-Version", strconv.Itoa(export.Version))
+
+			if !csvFormat {
+				w.Header().Set("Content-Type", "application/json")
+				if err := json.NewEncoder(w).Encode(export); err != nil {
+					logger.Errorf("failed to write accounting export: %v", err)
+				}
+				return
+			}
+
+			filename := fmt.Sprintf("staking_%s_v%d_%s_%s_%s.csv", section, export.Version, export.Network, since.UTC().Format("20060102"), until.UTC().Format("20060102"))
+			w.Header().Set("Content-Type", "text/csv")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+			if err := export.WriteCSV(w, section); err != nil {
+				logger.Errorf("failed to write accounting export: %v", err)
+			}
+		})
+	}
+}
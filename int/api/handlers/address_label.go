@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/massalabs/node-manager-plugin/api/models"
+	"github.com/massalabs/node-manager-plugin/api/restapi/operations"
+	stakingManagerPkg "github.com/massalabs/node-manager-plugin/int/core/staking-manager"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+)
+
+func HandleGetAddressLabels(stakingManager stakingManagerPkg.StakingManager) func(operations.GetAddressLabelsParams) middleware.Responder {
+	return func(params operations.GetAddressLabelsParams) middleware.Responder {
+		labels, err := stakingManager.GetAddressLabels()
+		if err != nil {
+			return operations.NewGetAddressLabelsInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		labelModels := make([]*models.AddressLabel, len(labels))
+		for i, label := range labels {
+			address := label.Address
+			labelModels[i] = &models.AddressLabel{
+				Address: &address,
+				Label:   label.Label,
+				Tags:    label.Tags,
+				Group:   label.Group,
+			}
+		}
+
+		return operations.NewGetAddressLabelsOK().WithPayload(&models.AddressLabelsResponse{Labels: labelModels})
+	}
+}
+
+func HandleSetAddressLabel(stakingManager stakingManagerPkg.StakingManager) func(operations.SetAddressLabelParams) middleware.Responder {
+	return func(params operations.SetAddressLabelParams) middleware.Responder {
+		err := stakingManager.SetAddressLabel(dbPkg.AddressLabel{
+			Address: *params.Body.Address,
+			Label:   params.Body.Label,
+			Tags:    params.Body.Tags,
+			Group:   params.Body.Group,
+		})
+		if err != nil {
+			return operations.NewSetAddressLabelInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		return operations.NewSetAddressLabelNoContent()
+	}
+}
+
+func HandleDeleteAddressLabel(stakingManager stakingManagerPkg.StakingManager) func(operations.DeleteAddressLabelParams) middleware.Responder {
+	return func(params operations.DeleteAddressLabelParams) middleware.Responder {
+		if err := stakingManager.DeleteAddressLabel(*params.Body.Address); err != nil {
+			return operations.NewDeleteAddressLabelInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		return operations.NewDeleteAddressLabelNoContent()
+	}
+}
+
+func HandleGetAddressGroups(stakingManager stakingManagerPkg.StakingManager) func(operations.GetAddressGroupsParams) middleware.Responder {
+	return func(params operations.GetAddressGroupsParams) middleware.Responder {
+		groups, err := stakingManager.GetAddressGroups()
+		if err != nil {
+			return operations.NewGetAddressGroupsInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		groupModels := make([]*models.AddressGroup, len(groups))
+		for i, group := range groups {
+			name := group.Name
+			finalRolls := int64(group.FinalRolls)
+			candidateRolls := int64(group.CandidateRolls)
+			activeRolls := int64(group.ActiveRolls)
+			finalBalance := group.FinalBalance
+			candidateBalance := group.CandidateBalance
+			deferredCredits := group.DeferredCredits
+			totalValue := group.TotalValue
+			groupModels[i] = &models.AddressGroup{
+				Name:               &name,
+				Addresses:          group.Addresses,
+				FinalRollCount:     &finalRolls,
+				CandidateRollCount: &candidateRolls,
+				ActiveRollCount:    &activeRolls,
+				FinalBalance:       &finalBalance,
+				CandidateBalance:   &candidateBalance,
+				DeferredCredits:    &deferredCredits,
+				TotalValue:         &totalValue,
+			}
+		}
+
+		return operations.NewGetAddressGroupsOK().WithPayload(&models.AddressGroupsResponse{Groups: groupModels})
+	}
+}
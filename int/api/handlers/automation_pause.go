@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/massalabs/node-manager-plugin/api/models"
+	"github.com/massalabs/node-manager-plugin/api/restapi/operations"
+	stakingManagerPkg "github.com/massalabs/node-manager-plugin/int/core/staking-manager"
+)
+
+func HandleGetAutomationPauses(stakingManager stakingManagerPkg.StakingManager) func(operations.GetAutomationPausesParams) middleware.Responder {
+	return func(params operations.GetAutomationPausesParams) middleware.Responder {
+		pauses := stakingManager.GetAutomationPauses()
+
+		return operations.NewGetAutomationPausesOK().WithPayload(&models.AutomationPauses{
+			Global:    &pauses.Global,
+			Addresses: pauses.Addresses,
+		})
+	}
+}
+
+func HandleSetAutomationPause(stakingManager stakingManagerPkg.StakingManager) func(operations.SetAutomationPauseParams) middleware.Responder {
+	return func(params operations.SetAutomationPauseParams) middleware.Responder {
+		if err := stakingManager.SetAutomationPaused(params.Body.Address, *params.Body.Paused); err != nil {
+			return operations.NewSetAutomationPauseInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		return operations.NewSetAutomationPauseNoContent()
+	}
+}
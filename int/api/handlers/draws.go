@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/massalabs/node-manager-plugin/api/models"
+	"github.com/massalabs/node-manager-plugin/api/restapi/operations"
+	stakingManagerPkg "github.com/massalabs/node-manager-plugin/int/core/staking-manager"
+)
+
+func HandleGetNextDraws(stakingManager stakingManagerPkg.StakingManager) func(operations.GetNextDrawsParams) middleware.Responder {
+	return func(params operations.GetNextDrawsParams) middleware.Responder {
+		addressesDraws, err := stakingManager.GetNextDraws()
+		if err != nil {
+			return operations.NewGetNextDrawsInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		addresses := make([]*models.AddressDraws, len(addressesDraws))
+		for i, addressDraws := range addressesDraws {
+			draws := make([]*models.Draw, len(addressDraws.Draws))
+			for j, draw := range addressDraws.Draws {
+				drawType := string(draw.Type)
+				period := int64(draw.Slot.Period)
+				thread := int64(draw.Slot.Thread)
+				drawTime := strfmt.DateTime(convertUTCToLocal(draw.Time))
+
+				var index *int64
+				if draw.Index != nil {
+					idx := int64(*draw.Index)
+					index = &idx
+				}
+
+				draws[j] = &models.Draw{
+					Type:   &drawType,
+					Period: &period,
+					Thread: &thread,
+					Index:  index,
+					Time:   &drawTime,
+				}
+			}
+
+			address := addressDraws.Address
+			addresses[i] = &models.AddressDraws{
+				Address: &address,
+				Draws:   draws,
+			}
+		}
+
+		return operations.NewGetNextDrawsOK().WithPayload(&models.NextDrawsResponse{
+			Addresses: addresses,
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/massalabs/node-manager-plugin/int/config"
 	historymanager "github.com/massalabs/node-manager-plugin/int/core/history-manager"
 	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
 	"github.com/massalabs/node-manager-plugin/int/utils"
 )
 
@@ -52,8 +53,43 @@ func HandleGetValueHistory(db dbPkg.DB, historyMgr *historymanager.HistoryManage
 			return createErrorResponse(400, "SampleNum is too large")
 		}
 
-		result, err := historyMgr.SampleValueHistory(since, int64(params.SampleNum), params.IsMainnet, interval)
+		filter := historymanager.ValueHistoryFilter{
+			WatchOnly: params.WatchOnly != nil && *params.WatchOnly,
+			Breakdown: params.Breakdown != nil && *params.Breakdown,
+		}
+		if params.Address != nil {
+			filter.Address = *params.Address
+		}
+		if params.Group != nil {
+			filter.Group = *params.Group
+		}
+		if params.Aggregation != nil {
+			filter.Aggregation = historymanager.Aggregation(*params.Aggregation)
+		}
+		if params.Currency != nil {
+			filter.Currency = *params.Currency
+		}
+
+		selectors := 0
+		for _, set := range []bool{filter.WatchOnly, filter.Address != "", filter.Group != ""} {
+			if set {
+				selectors++
+			}
+		}
+		if selectors > 1 {
+			return createErrorResponse(400, "Only one of watchOnly, address and group can be set")
+		}
+
+		network := utils.NetworkBuildnet
+		if params.IsMainnet {
+			network = utils.NetworkMainnet
+		}
+
+		result, err := historyMgr.SampleValueHistory(since, int64(params.SampleNum), network, interval, filter)
 		if err != nil {
+			if nodeManagerError.Is(err, nodeManagerError.ErrPriceSourceNotConfigured) {
+				return createErrorResponse(400, err.Error())
+			}
 			return createErrorResponse(500, err.Error())
 		}
 
@@ -70,15 +106,71 @@ func HandleGetValueHistory(db dbPkg.DB, historyMgr *historymanager.HistoryManage
 			} else {
 				emptyDataPointNum++
 			}
+			if r.Breakdown != nil {
+				samples[i].Balance = &r.Breakdown.Balance
+				samples[i].RollValue = &r.Breakdown.RollValue
+				samples[i].Deferred = &r.Breakdown.Deferred
+			}
+			samples[i].Open = r.Open
+			samples[i].Min = r.Min
+			samples[i].Max = r.Max
+			samples[i].FiatValue = r.FiatValue
 		}
+		annotations, err := historyMgr.GetValueHistoryAnnotations(since, network, filter)
+		if err != nil {
+			return createErrorResponse(500, err.Error())
+		}
+
 		response := &models.ValueHistorySamplesResponse{
 			Samples:           samples,
 			EmptyDataPointNum: &emptyDataPointNum,
+			Annotations:       annotationsToModel(annotations),
 		}
 		return operations.NewGetValueHistoryOK().WithPayload(response)
 	}
 }
 
+func annotationsToModel(annotations historymanager.ValueHistoryAnnotations) *models.ValueHistoryAnnotations {
+	result := &models.ValueHistoryAnnotations{
+		Downtimes:     make([]*models.ValueHistoryAnnotationsDowntimesItems0, len(annotations.Downtimes)),
+		RollOps:       make([]*models.ValueHistoryAnnotationsRollOpsItems0, len(annotations.RollOps)),
+		AddressEvents: make([]*models.ValueHistoryAnnotationsAddressEventsItems0, len(annotations.AddressEvents)),
+	}
+
+	for i, downtime := range annotations.Downtimes {
+		start := strfmt.DateTime(convertUTCToLocal(downtime.Start))
+		result.Downtimes[i] = &models.ValueHistoryAnnotationsDowntimesItems0{Start: &start}
+		if downtime.End != nil {
+			end := strfmt.DateTime(convertUTCToLocal(*downtime.End))
+			result.Downtimes[i].End = &end
+		}
+	}
+
+	for i, rollOp := range annotations.RollOps {
+		timestamp := strfmt.DateTime(convertUTCToLocal(rollOp.Timestamp))
+		result.RollOps[i] = &models.ValueHistoryAnnotationsRollOpsItems0{
+			Address:   &rollOp.Address,
+			Op:        &rollOp.Op,
+			Amount:    &rollOp.Amount,
+			OpID:      &rollOp.OpId,
+			Timestamp: &timestamp,
+		}
+	}
+
+	for i, event := range annotations.AddressEvents {
+		timestamp := strfmt.DateTime(convertUTCToLocal(event.Timestamp))
+		kind := string(event.Event)
+		result.AddressEvents[i] = &models.ValueHistoryAnnotationsAddressEventsItems0{
+			Address:   &event.Address,
+			Event:     &kind,
+			WatchOnly: &event.WatchOnly,
+			Timestamp: &timestamp,
+		}
+	}
+
+	return result
+}
+
 func HandleGetRollOpHistory(db dbPkg.DB) func(operations.GetRollOpHistoryParams) middleware.Responder {
 	return func(params operations.GetRollOpHistoryParams) middleware.Responder {
 		network := utils.NetworkBuildnet
@@ -111,3 +203,67 @@ func HandleGetRollOpHistory(db dbPkg.DB) func(operations.GetRollOpHistoryParams)
 		})
 	}
 }
+
+func HandleGetBlockedRollOps(db dbPkg.DB) func(operations.GetBlockedRollOpsParams) middleware.Responder {
+	return func(params operations.GetBlockedRollOpsParams) middleware.Responder {
+		network := utils.NetworkBuildnet
+		if params.IsMainnet {
+			network = utils.NetworkMainnet
+		}
+
+		blockedOps, err := db.GetBlockedRollOps(params.Address, network)
+		if err != nil {
+			return operations.NewGetBlockedRollOpsInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		operationsList := make([]*models.BlockedRollOp, len(blockedOps))
+		for i, blocked := range blockedOps {
+			op := string(blocked.Op)
+			// Convert UTC timestamp to local timezone for frontend display
+			timestamp := strfmt.DateTime(convertUTCToLocal(blocked.Timestamp))
+			operationsList[i] = &models.BlockedRollOp{
+				Op:        &op,
+				Amount:    &blocked.Amount,
+				Reason:    &blocked.Reason,
+				Timestamp: &timestamp,
+			}
+		}
+
+		return operations.NewGetBlockedRollOpsOK().WithPayload(&models.BlockedRollOpsResponse{
+			Operations: operationsList,
+		})
+	}
+}
+
+func HandleGetBlockProductionHistory(db dbPkg.DB) func(operations.GetBlockProductionHistoryParams) middleware.Responder {
+	return func(params operations.GetBlockProductionHistoryParams) middleware.Responder {
+		network := utils.NetworkBuildnet
+		if params.IsMainnet {
+			network = utils.NetworkMainnet
+		}
+
+		stats, err := db.GetCycleStats(params.Address, network)
+		if err != nil {
+			return operations.NewGetBlockProductionHistoryInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		cycles := make([]*models.CycleInfo, len(stats))
+		for i, s := range stats {
+			cycles[i] = &models.CycleInfo{
+				Cycle:       int64(s.Cycle),
+				IsFinal:     s.IsFinal,
+				OkCount:     int64(s.OkCount),
+				NokCount:    int64(s.NokCount),
+				ActiveRolls: int64(s.ActiveRolls),
+			}
+		}
+
+		return operations.NewGetBlockProductionHistoryOK().WithPayload(&models.BlockProductionHistoryResponse{
+			Cycles: cycles,
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/massalabs/node-manager-plugin/api/models"
+	"github.com/massalabs/node-manager-plugin/api/restapi/operations"
+	configPkg "github.com/massalabs/node-manager-plugin/int/config"
+	stakingManagerPkg "github.com/massalabs/node-manager-plugin/int/core/staking-manager"
+)
+
+func HandleImportStakingAddresses(stakingManager stakingManagerPkg.StakingManager) func(operations.ImportStakingAddressesParams) middleware.Responder {
+	return func(params operations.ImportStakingAddressesParams) middleware.Responder {
+		accounts := make([]stakingManagerPkg.AccountCredentials, 0, len(params.Body.Accounts))
+		for _, account := range params.Body.Accounts {
+			if account == nil {
+				continue
+			}
+			accounts = append(accounts, stakingManagerPkg.AccountCredentials{
+				Nickname: account.Nickname,
+				Password: account.Password,
+			})
+		}
+
+		if len(accounts) == 0 {
+			return createErrorResponse(400, "No account to import")
+		}
+
+		importResults, err := stakingManager.AddStakingAddresses(configPkg.GlobalPluginInfo.GetPwd(), accounts)
+		if err != nil {
+			return operations.NewImportStakingAddressesInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		results := make([]*models.ImportResult, len(importResults))
+		for i, importResult := range importResults {
+			nickname := importResult.Nickname
+			success := importResult.Success
+			results[i] = &models.ImportResult{
+				Nickname: &nickname,
+				Address:  importResult.Address,
+				Success:  &success,
+				Error:    importResult.Error,
+			}
+		}
+
+		return operations.NewImportStakingAddressesOK().WithPayload(&models.ImportStakingAddressesResponse{
+			Results: results,
+		})
+	}
+}
+
+func HandleGetWalletAccounts(stakingManager stakingManagerPkg.StakingManager) func(operations.GetWalletAccountsParams) middleware.Responder {
+	return func(params operations.GetWalletAccountsParams) middleware.Responder {
+		walletAccounts, err := stakingManager.ListWalletAccounts()
+		if err != nil {
+			return operations.NewGetWalletAccountsInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		accounts := make([]*models.WalletAccount, len(walletAccounts))
+		for i, walletAccount := range walletAccounts {
+			nickname := walletAccount.Nickname
+			address := walletAccount.Address
+			isStaking := walletAccount.IsStaking
+			accounts[i] = &models.WalletAccount{
+				Nickname:  &nickname,
+				Address:   &address,
+				IsStaking: &isStaking,
+			}
+		}
+
+		return operations.NewGetWalletAccountsOK().WithPayload(&models.WalletAccountsResponse{
+			Accounts: accounts,
+		})
+	}
+}
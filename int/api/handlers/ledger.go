@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/massalabs/node-manager-plugin/api/models"
+	"github.com/massalabs/node-manager-plugin/api/restapi/operations"
+	historymanager "github.com/massalabs/node-manager-plugin/int/core/history-manager"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+)
+
+func HandleGetLedger(db dbPkg.DB, historyMgr *historymanager.HistoryManager) func(operations.GetLedgerParams) middleware.Responder {
+	return func(params operations.GetLedgerParams) middleware.Responder {
+		network := utils.NetworkBuildnet
+		if params.IsMainnet {
+			network = utils.NetworkMainnet
+		}
+
+		address := ""
+		if params.Address != nil {
+			address = *params.Address
+		}
+
+		currency := ""
+		if params.Currency != nil {
+			currency = *params.Currency
+		}
+		converter, err := historyMgr.NewConverter(currency)
+		if err != nil {
+			return createErrorResponse(400, err.Error())
+		}
+
+		entries, err := db.GetLedgerEntries(address, time.Time(params.Since), network)
+		if err != nil {
+			return operations.NewGetLedgerInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		payload := make([]*models.LedgerEntry, len(entries))
+		for i, entry := range entries {
+			// Convert UTC timestamp to local timezone for frontend display
+			timestamp := strfmt.DateTime(convertUTCToLocal(entry.Timestamp))
+			kind := string(entry.Kind)
+			payload[i] = &models.LedgerEntry{
+				Timestamp:  &timestamp,
+				Address:    &entry.Address,
+				Cycle:      &entry.Cycle,
+				Kind:       &kind,
+				Amount:     &entry.Amount,
+				Rolls:      &entry.Rolls,
+				OpID:       entry.OpId,
+				FiatAmount: converter.Convert(entry.Timestamp, entry.Amount.MAS()),
+			}
+		}
+
+		return operations.NewGetLedgerOK().WithPayload(&models.LedgerResponse{
+			Entries: payload,
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/massalabs/node-manager-plugin/api/models"
+	"github.com/massalabs/node-manager-plugin/api/restapi/operations"
+	historymanager "github.com/massalabs/node-manager-plugin/int/core/history-manager"
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+)
+
+func HandleGetPerformance(historyMgr *historymanager.HistoryManager) func(params operations.GetPerformanceParams) middleware.Responder {
+	return func(params operations.GetPerformanceParams) middleware.Responder {
+		since := time.Time(params.Since)
+		until := time.Now()
+		if params.Until != nil {
+			until = time.Time(*params.Until)
+		}
+		if !since.Before(until) {
+			return createErrorResponse(400, "Since must be before until")
+		}
+
+		filter := historymanager.ValueHistoryFilter{
+			WatchOnly: params.WatchOnly != nil && *params.WatchOnly,
+		}
+		if params.Address != nil {
+			filter.Address = *params.Address
+		}
+		if params.Group != nil {
+			filter.Group = *params.Group
+		}
+		if params.Currency != nil {
+			filter.Currency = *params.Currency
+		}
+
+		selectors := 0
+		for _, set := range []bool{filter.WatchOnly, filter.Address != "", filter.Group != ""} {
+			if set {
+				selectors++
+			}
+		}
+		if selectors > 1 {
+			return createErrorResponse(400, "Only one of watchOnly, address and group can be set")
+		}
+
+		network := utils.NetworkBuildnet
+		if params.IsMainnet {
+			network = utils.NetworkMainnet
+		}
+
+		report, err := historyMgr.GetPerformance(since, until, network, filter)
+		if err != nil {
+			if nodeManagerError.Is(err, nodeManagerError.ErrPriceSourceNotConfigured) {
+				return createErrorResponse(400, err.Error())
+			}
+			return operations.NewGetPerformanceInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		addresses := make([]*models.Performance, len(report.Addresses))
+		for i, performance := range report.Addresses {
+			addresses[i] = performanceToModel(performance)
+		}
+
+		return operations.NewGetPerformanceOK().WithPayload(&models.PerformanceReport{
+			Total:     performanceToModel(report.Total),
+			Addresses: addresses,
+		})
+	}
+}
+
+func performanceToModel(performance historymanager.Performance) *models.Performance {
+	since := strfmt.DateTime(convertUTCToLocal(performance.Since))
+	until := strfmt.DateTime(convertUTCToLocal(performance.Until))
+
+	flows := make([]*models.CapitalFlow, len(performance.Flows))
+	for i, flow := range performance.Flows {
+		timestamp := strfmt.DateTime(convertUTCToLocal(flow.Timestamp))
+		kind := string(flow.Kind)
+		flows[i] = &models.CapitalFlow{
+			Timestamp: &timestamp,
+			Address:   &flow.Address,
+			Amount:    &flow.Amount,
+			Kind:      &kind,
+		}
+	}
+
+	return &models.Performance{
+		Address:             performance.Address,
+		Since:               &since,
+		Until:               &until,
+		StartValue:          &performance.StartValue,
+		EndValue:            &performance.EndValue,
+		Deposits:            &performance.Deposits,
+		Withdrawals:         &performance.Withdrawals,
+		Rewards:             &performance.Rewards,
+		TimeWeightedReturn:  &performance.TimeWeightedReturn,
+		MoneyWeightedReturn: performance.MoneyWeightedReturn,
+		AnnualizedYield:     performance.AnnualizedYield,
+		AverageRolls:        &performance.AverageRolls,
+		RewardsPerRoll:      performance.RewardsPerRoll,
+		Flows:               flows,
+		StartValueFiat:      performance.StartValueFiat,
+		EndValueFiat:        performance.EndValueFiat,
+		RewardsFiat:         performance.RewardsFiat,
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/massalabs/node-manager-plugin/api/models"
+	"github.com/massalabs/node-manager-plugin/api/restapi/operations"
+	stakingManagerPkg "github.com/massalabs/node-manager-plugin/int/core/staking-manager"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+)
+
+func HandleGetRollSchedules(stakingManager stakingManagerPkg.StakingManager) func(operations.GetRollSchedulesParams) middleware.Responder {
+	return func(params operations.GetRollSchedulesParams) middleware.Responder {
+		schedules, err := stakingManager.GetRollSchedules()
+		if err != nil {
+			return operations.NewGetRollSchedulesInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		rollSchedules := make([]*models.RollSchedule, len(schedules))
+		for i, schedule := range schedules {
+			rollSchedules[i] = toRollScheduleModel(schedule)
+		}
+
+		return operations.NewGetRollSchedulesOK().WithPayload(&models.RollSchedulesResponse{
+			Schedules: rollSchedules,
+		})
+	}
+}
+
+func HandleScheduleRollChange(stakingManager stakingManagerPkg.StakingManager) func(operations.ScheduleRollChangeParams) middleware.Responder {
+	return func(params operations.ScheduleRollChangeParams) middleware.Responder {
+		schedule, err := stakingManager.ScheduleRollChange(dbPkg.RollSchedule{
+			Address: *params.Body.Address,
+			Action:  dbPkg.RollScheduleAction(*params.Body.Action),
+			Amount:  *params.Body.Amount,
+			RunAt:   toTime(params.Body.RunAt),
+			Cycle:   params.Body.Cycle,
+		})
+		if err != nil {
+			return operations.NewScheduleRollChangeInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		return operations.NewScheduleRollChangeOK().WithPayload(toRollScheduleModel(schedule))
+	}
+}
+
+func HandleUpdateRollSchedule(stakingManager stakingManagerPkg.StakingManager) func(operations.UpdateRollScheduleParams) middleware.Responder {
+	return func(params operations.UpdateRollScheduleParams) middleware.Responder {
+		err := stakingManager.UpdateRollSchedule(dbPkg.RollSchedule{
+			ID:     *params.Body.ID,
+			Action: dbPkg.RollScheduleAction(*params.Body.Action),
+			Amount: *params.Body.Amount,
+			RunAt:  toTime(params.Body.RunAt),
+			Cycle:  params.Body.Cycle,
+		})
+		if err != nil {
+			return operations.NewUpdateRollScheduleInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		return operations.NewUpdateRollScheduleNoContent()
+	}
+}
+
+func HandleCancelRollSchedule(stakingManager stakingManagerPkg.StakingManager) func(operations.CancelRollScheduleParams) middleware.Responder {
+	return func(params operations.CancelRollScheduleParams) middleware.Responder {
+		if err := stakingManager.CancelRollSchedule(*params.Body.ID); err != nil {
+			return operations.NewCancelRollScheduleInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		return operations.NewCancelRollScheduleNoContent()
+	}
+}
+
+func toRollScheduleModel(schedule dbPkg.RollSchedule) *models.RollSchedule {
+	action := string(schedule.Action)
+	status := string(schedule.Status)
+	createdAt := strfmt.DateTime(convertUTCToLocal(schedule.CreatedAt))
+
+	return &models.RollSchedule{
+		ID:         &schedule.ID,
+		Address:    &schedule.Address,
+		Action:     &action,
+		Amount:     &schedule.Amount,
+		RunAt:      toLocalDateTime(schedule.RunAt),
+		Cycle:      schedule.Cycle,
+		Status:     &status,
+		Error:      schedule.Error,
+		CreatedAt:  &createdAt,
+		ExecutedAt: toLocalDateTime(schedule.ExecutedAt),
+	}
+}
+
+func toTime(dateTime *strfmt.DateTime) *time.Time {
+	if dateTime == nil {
+		return nil
+	}
+	t := time.Time(*dateTime)
+	return &t
+}
+
+func toLocalDateTime(t *time.Time) *strfmt.DateTime {
+	if t == nil {
+		return nil
+	}
+	dateTime := strfmt.DateTime(convertUTCToLocal(*t))
+	return &dateTime
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
 	"github.com/massalabs/node-manager-plugin/api/models"
 	"github.com/massalabs/node-manager-plugin/api/restapi/operations"
 	configPkg "github.com/massalabs/node-manager-plugin/int/config"
@@ -17,29 +18,83 @@ func HandlePostStakingAddresses(stakingManager stakingManagerPkg.StakingManager)
 				Message: err.Error(),
 			})
 		}
-		targetRolls := int64(stakingAddress.TargetRolls)
-		finalRolls := int64(stakingAddress.FinalRolls)
-		activeRolls := int64(stakingAddress.ActiveRolls)
-		candidateRolls := int64(stakingAddress.CandidateRolls)
-		thread := int64(stakingAddress.Thread)
-		deferredCredits := make([]*models.DeferredCredit, len(stakingAddress.DeferredCredits))
-		for i, deferredCredit := range stakingAddress.DeferredCredits {
-			deferredCredits[i] = &models.DeferredCredit{
-				Slot:   &models.DeferredCreditSlot{Period: int64(deferredCredit.Slot.Period), Thread: int64(deferredCredit.Slot.Thread)},
-				Amount: deferredCredit.Amount,
-			}
+		return operations.NewAddStakingAddressOK().WithPayload(stakingAddressToModel(stakingAddress))
+	}
+}
+
+func HandleAddStakingKey(stakingManager stakingManagerPkg.StakingManager) func(operations.AddStakingKeyParams) middleware.Responder {
+	return func(params operations.AddStakingKeyParams) middleware.Responder {
+		source := stakingManagerPkg.KeySource{
+			Type:       stakingManagerPkg.KeySourceType(*params.Body.Source),
+			WalletFile: []byte(params.Body.WalletFile),
+			Password:   params.Body.Password,
+			SecretKey:  params.Body.SecretKey,
+			Nickname:   params.Body.Nickname,
 		}
-		return operations.NewAddStakingAddressOK().WithPayload(&models.StakingAddress{
-			Address:            &stakingAddress.Address,
-			TargetRolls:        &targetRolls,
-			FinalRollCount:     &finalRolls,
-			ActiveRollCount:    &activeRolls,
-			FinalBalance:       &stakingAddress.FinalBalance,
-			CandidateRollCount: &candidateRolls,
-			CandidateBalance:   &stakingAddress.CandidateBalance,
-			DeferredCredits:    deferredCredits,
-			Thread:             &thread,
-		})
+
+		stakingAddress, err := stakingManager.AddStakingAddressFromKey(configPkg.GlobalPluginInfo.GetPwd(), source)
+		if err != nil {
+			return operations.NewAddStakingKeyInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+		return operations.NewAddStakingKeyOK().WithPayload(stakingAddressToModel(stakingAddress))
+	}
+}
+
+// stakingAddressToModel converts a staking address of the staking manager to its api model
+func stakingAddressToModel(stakingAddress stakingManagerPkg.StakingAddress) *models.StakingAddress {
+	targetRolls := int64(stakingAddress.TargetRolls)
+	finalRolls := int64(stakingAddress.FinalRolls)
+	activeRolls := int64(stakingAddress.ActiveRolls)
+	candidateRolls := int64(stakingAddress.CandidateRolls)
+	thread := int64(stakingAddress.Thread)
+	deferredCredits := make([]*models.DeferredCredit, len(stakingAddress.DeferredCredits))
+	for i, deferredCredit := range stakingAddress.DeferredCredits {
+		deferredCredits[i] = &models.DeferredCredit{
+			Slot:   &models.DeferredCreditSlot{Period: int64(deferredCredit.Slot.Period), Thread: int64(deferredCredit.Slot.Thread)},
+			Amount: deferredCredit.Amount,
+		}
+		if deferredCredit.UnlockTime != nil {
+			unlockTime := strfmt.DateTime(convertUTCToLocal(*deferredCredit.UnlockTime))
+			deferredCredits[i].UnlockTime = &unlockTime
+		}
+	}
+	cycleInfos := make([]*models.CycleInfo, len(stakingAddress.CycleInfos))
+	for i, cycleInfo := range stakingAddress.CycleInfos {
+		cycleInfos[i] = &models.CycleInfo{
+			Cycle:       int64(cycleInfo.Cycle),
+			IsFinal:     cycleInfo.IsFinal,
+			OkCount:     int64(cycleInfo.OkCount),
+			NokCount:    int64(cycleInfo.NokCount),
+			ActiveRolls: int64(cycleInfo.ActiveRolls),
+		}
+	}
+	var pendingOperation *models.PendingOperation
+	if stakingAddress.PendingOperation != nil {
+		pendingOperation = &models.PendingOperation{ID: &stakingAddress.PendingOperation.ID}
+		if stakingAddress.PendingOperation.ExpirePeriod != nil {
+			expirePeriod := int64(*stakingAddress.PendingOperation.ExpirePeriod)
+			pendingOperation.ExpirePeriod = &expirePeriod
+		}
+		if stakingAddress.PendingOperation.ExpireTime != nil {
+			expireTime := strfmt.DateTime(convertUTCToLocal(*stakingAddress.PendingOperation.ExpireTime))
+			pendingOperation.ExpireTime = &expireTime
+		}
+	}
+	return &models.StakingAddress{
+		Address:            &stakingAddress.Address,
+		TargetRolls:        &targetRolls,
+		FinalRollCount:     &finalRolls,
+		ActiveRollCount:    &activeRolls,
+		FinalBalance:       &stakingAddress.FinalBalance,
+		CandidateRollCount: &candidateRolls,
+		CandidateBalance:   &stakingAddress.CandidateBalance,
+		DeferredCredits:    deferredCredits,
+		CycleInfos:         cycleInfos,
+		PendingOperation:   pendingOperation,
+		Thread:             &thread,
+		WatchOnly:          stakingAddress.WatchOnly,
 	}
 }
 
@@ -57,7 +112,12 @@ func HandlePutStakingAddresses(stakingManager stakingManagerPkg.StakingManager)
 
 func HandleDeleteStakingAddresses(stakingManager stakingManagerPkg.StakingManager) func(operations.RemoveStakingAddressParams) middleware.Responder {
 	return func(params operations.RemoveStakingAddressParams) middleware.Responder {
-		err := stakingManager.RemoveStakingAddress(configPkg.GlobalPluginInfo.GetPwd(), params.Body.Address)
+		var err error
+		if params.Body.Force {
+			err = stakingManager.RemoveStakingAddress(configPkg.GlobalPluginInfo.GetPwd(), params.Body.Address)
+		} else {
+			err = stakingManager.DecommissionStakingAddress(params.Body.Address)
+		}
 		if err != nil {
 			return operations.NewRemoveStakingAddressInternalServerError().WithPayload(&models.Error{
 				Message: err.Error(),
@@ -66,3 +126,42 @@ func HandleDeleteStakingAddresses(stakingManager stakingManagerPkg.StakingManage
 		return operations.NewRemoveStakingAddressNoContent()
 	}
 }
+
+func HandleGetDecommissions(stakingManager stakingManagerPkg.StakingManager) func(operations.GetDecommissionsParams) middleware.Responder {
+	return func(params operations.GetDecommissionsParams) middleware.Responder {
+		statuses, err := stakingManager.GetDecommissions()
+		if err != nil {
+			return operations.NewGetDecommissionsInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		decommissions := make([]*models.Decommission, len(statuses))
+		for i, status := range statuses {
+			address := status.Address
+			step := string(status.Step)
+			stepIndex := int64(status.StepIndex)
+			stepCount := int64(status.StepCount)
+			startedAt := strfmt.DateTime(convertUTCToLocal(status.StartedAt))
+			updatedAt := strfmt.DateTime(convertUTCToLocal(status.UpdatedAt))
+
+			decommissions[i] = &models.Decommission{
+				Address:   &address,
+				Step:      &step,
+				StepIndex: &stepIndex,
+				StepCount: &stepCount,
+				StartedAt: &startedAt,
+				UpdatedAt: &updatedAt,
+			}
+
+			if status.ETA != nil {
+				eta := strfmt.DateTime(convertUTCToLocal(*status.ETA))
+				decommissions[i].Eta = &eta
+			}
+		}
+
+		return operations.NewGetDecommissionsOK().WithPayload(&models.DecommissionsResponse{
+			Decommissions: decommissions,
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/massalabs/node-manager-plugin/api/restapi/operations"
+	stakingManagerPkg "github.com/massalabs/node-manager-plugin/int/core/staking-manager"
+	eventBusPkg "github.com/massalabs/node-manager-plugin/pkg/event-bus"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+type stakingEvent struct {
+	Type eventBusPkg.EventType `json:"type"`
+	Data interface{}           `json:"data"`
+}
+
+func HandleStakingEventsFeeder(eventBus eventBusPkg.EventBus) func(operations.GetStakingEventsParams) middleware.Responder {
+	return func(params operations.GetStakingEventsParams) middleware.Responder {
+		return middleware.ResponderFunc(
+			func(w http.ResponseWriter, _ runtime.Producer) {
+				logger.Info("Call GET api/stakingEvents")
+				flusher, ok := w.(http.Flusher)
+				if !ok {
+					logger.Error("ResponseWriter does not implement http.Flusher, cannot handle SSE")
+					http.Error(w, "ResponseWriter does not implement http.Flusher, cannot handle SSE", http.StatusInternalServerError)
+					return
+				}
+
+				// Set SSE headers
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.Header().Set("Cache-Control", "no-cache")
+				w.Header().Set("Connection", "keep-alive")
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+
+				ctx := params.HTTPRequest.Context()
+
+				// The event bus provides one channel per event type, merge them into a single one
+				events := make(chan eventBusPkg.Event, 10)
+				for _, eventType := range stakingManagerPkg.StakingEventTypes {
+					eventChan := eventBus.Subscribe(eventType, "staking-events-Server-Side-Event-feeder")
+					defer eventBus.Unsubscribe(eventType, eventChan) // Ensure cleanup
+					go forwardEvents(ctx, eventChan, events)
+				}
+
+				// Flush headers so that the client knows the stream is open
+				flusher.Flush()
+
+				for {
+					select {
+					case <-ctx.Done():
+						logger.Debug("SSE connection closed")
+						return
+					case event := <-events:
+						logger.Infof("Sending staking event: %s", event.Type)
+						flushEvent(w, flusher, event)
+					}
+				}
+			},
+		)
+	}
+}
+
+// forwardEvents forwards the events received on eventChan to out until eventChan is closed or ctx is done
+func forwardEvents(ctx context.Context, eventChan chan eventBusPkg.Event, out chan<- eventBusPkg.Event) {
+	for event := range eventChan {
+		select {
+		case out <- event:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func flushEvent(w http.ResponseWriter, flusher http.Flusher, event eventBusPkg.Event) {
+	eventJSON, err := json.Marshal(stakingEvent{Type: event.Type, Data: event.Data})
+	if err != nil {
+		logger.Errorf("Failed to marshal staking event %s to JSON: %v", event.Type, err)
+		return
+	}
+
+	_, err = fmt.Fprintf(w, "data: %s\n\n", string(eventJSON))
+	if err != nil {
+		logger.Errorf("Failed to flush staking event %s, got error: %v", event.Type, err)
+		return
+	}
+	flusher.Flush()
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
 	"github.com/massalabs/node-manager-plugin/api/models"
 	"github.com/massalabs/node-manager-plugin/api/restapi/operations"
 	nodeStatusPkg "github.com/massalabs/node-manager-plugin/int/core/NodeStatus"
@@ -18,11 +19,30 @@ func HandleStopNode(nodeManager NodeManagerPkg.INodeManager, statusDispatcher no
 
 		logger.Infof("Current node status is %s", statusDispatcher.GetCurrentStatus())
 
-		if err := nodeManager.StopNode(); err != nil {
+		// Stop the node immediately if forced, otherwise wait for a gap between the draws of the staking addresses
+		if params.Body != nil && params.Body.Force {
+			if err := nodeManager.StopNode(); err != nil {
+				return operations.NewStopNodeInternalServerError().WithPayload(&models.Error{
+					Message: err.Error(),
+				})
+			}
+			return operations.NewStopNodeNoContent()
+		}
+
+		scheduledAt, err := nodeManager.StopNodeWhenSafe()
+		if err != nil {
 			return operations.NewStopNodeInternalServerError().WithPayload(&models.Error{
 				Message: err.Error(),
 			})
 		}
+
+		if !scheduledAt.IsZero() {
+			stopTime := strfmt.DateTime(convertUTCToLocal(scheduledAt))
+			return operations.NewStopNodeAccepted().WithPayload(&models.ScheduledStop{
+				ScheduledAt: &stopTime,
+			})
+		}
+
 		return operations.NewStopNodeNoContent()
 	}
 }
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/massalabs/node-manager-plugin/api/models"
+	"github.com/massalabs/node-manager-plugin/api/restapi/operations"
+	stakingManagerPkg "github.com/massalabs/node-manager-plugin/int/core/staking-manager"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+)
+
+func HandleGetSweepRules(stakingManager stakingManagerPkg.StakingManager) func(operations.GetSweepRulesParams) middleware.Responder {
+	return func(params operations.GetSweepRulesParams) middleware.Responder {
+		rules, err := stakingManager.GetSweepRules()
+		if err != nil {
+			return operations.NewGetSweepRulesInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		sweepRules := make([]*models.SweepRule, len(rules))
+		for i, rule := range rules {
+			sweepRules[i] = &models.SweepRule{
+				Address:     &rule.Address,
+				ColdAddress: &rule.ColdAddress,
+				Threshold:   &rule.Threshold,
+				Reserve:     &rule.Reserve,
+				DryRun:      &rule.DryRun,
+			}
+		}
+
+		return operations.NewGetSweepRulesOK().WithPayload(&models.SweepRulesResponse{
+			Rules: sweepRules,
+		})
+	}
+}
+
+func HandleSetSweepRule(stakingManager stakingManagerPkg.StakingManager) func(operations.SetSweepRuleParams) middleware.Responder {
+	return func(params operations.SetSweepRuleParams) middleware.Responder {
+		err := stakingManager.SetSweepRule(dbPkg.SweepRule{
+			Address:     *params.Body.Address,
+			ColdAddress: *params.Body.ColdAddress,
+			Threshold:   *params.Body.Threshold,
+			Reserve:     *params.Body.Reserve,
+			DryRun:      *params.Body.DryRun,
+		})
+		if err != nil {
+			return operations.NewSetSweepRuleInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		return operations.NewSetSweepRuleNoContent()
+	}
+}
+
+func HandleDeleteSweepRule(stakingManager stakingManagerPkg.StakingManager) func(operations.DeleteSweepRuleParams) middleware.Responder {
+	return func(params operations.DeleteSweepRuleParams) middleware.Responder {
+		if err := stakingManager.DeleteSweepRule(*params.Body.Address); err != nil {
+			return operations.NewDeleteSweepRuleInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		return operations.NewDeleteSweepRuleNoContent()
+	}
+}
+
+func HandleGetSweepHistory(db dbPkg.DB) func(operations.GetSweepHistoryParams) middleware.Responder {
+	return func(params operations.GetSweepHistoryParams) middleware.Responder {
+		network := utils.NetworkBuildnet
+		if params.IsMainnet {
+			network = utils.NetworkMainnet
+		}
+
+		histories, err := db.GetSweepHistory(params.Address, network)
+		if err != nil {
+			return operations.NewGetSweepHistoryInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		sweeps := make([]*models.SweepHistory, len(histories))
+		for i, history := range histories {
+			// Convert UTC timestamp to local timezone for frontend display
+			timestamp := strfmt.DateTime(convertUTCToLocal(history.Timestamp))
+			sweeps[i] = &models.SweepHistory{
+				ColdAddress: &history.ColdAddress,
+				Amount:      &history.Amount,
+				Fee:         &history.Fee,
+				OpID:        history.OpId,
+				DryRun:      &history.DryRun,
+				Timestamp:   &timestamp,
+			}
+		}
+
+		return operations.NewGetSweepHistoryOK().WithPayload(&models.SweepHistoryResponse{
+			Sweeps: sweeps,
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/massalabs/node-manager-plugin/api/models"
+	"github.com/massalabs/node-manager-plugin/api/restapi/operations"
+	stakingManagerPkg "github.com/massalabs/node-manager-plugin/int/core/staking-manager"
+)
+
+func HandleGetWatchOnlyAddresses(stakingManager stakingManagerPkg.StakingManager) func(operations.GetWatchOnlyAddressesParams) middleware.Responder {
+	return func(params operations.GetWatchOnlyAddressesParams) middleware.Responder {
+		watchOnlyAddresses := stakingManager.GetWatchOnlyAddresses()
+
+		addresses := make([]*models.StakingAddress, len(watchOnlyAddresses))
+		for i, address := range watchOnlyAddresses {
+			addresses[i] = stakingAddressToModel(address)
+		}
+
+		return operations.NewGetWatchOnlyAddressesOK().WithPayload(&models.StakingAddresses{Addresses: addresses})
+	}
+}
+
+func HandleAddWatchOnlyAddress(stakingManager stakingManagerPkg.StakingManager) func(operations.AddWatchOnlyAddressParams) middleware.Responder {
+	return func(params operations.AddWatchOnlyAddressParams) middleware.Responder {
+		address, err := stakingManager.AddWatchOnlyAddress(*params.Body.Address)
+		if err != nil {
+			return operations.NewAddWatchOnlyAddressInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		return operations.NewAddWatchOnlyAddressOK().WithPayload(stakingAddressToModel(address))
+	}
+}
+
+func HandleRemoveWatchOnlyAddress(stakingManager stakingManagerPkg.StakingManager) func(operations.RemoveWatchOnlyAddressParams) middleware.Responder {
+	return func(params operations.RemoveWatchOnlyAddressParams) middleware.Responder {
+		if err := stakingManager.RemoveWatchOnlyAddress(*params.Body.Address); err != nil {
+			return operations.NewRemoveWatchOnlyAddressInternalServerError().WithPayload(&models.Error{
+				Message: err.Error(),
+			})
+		}
+
+		return operations.NewRemoveWatchOnlyAddressNoContent()
+	}
+}
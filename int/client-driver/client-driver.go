@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"time"
 
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
 	nodeDirManagerPkg "github.com/massalabs/node-manager-plugin/int/node-bin-dir-manager"
 )
 
@@ -30,9 +31,11 @@ type WalletInfo struct {
 type ClientDriver interface {
 	GetStakingAddresses() ([]string, error)
 	AddStakingAddress(pwd string, secKey, address string) error
+	AddStakingAddresses(pwd string, secKeys, addresses []string) error
 	RemoveStakingAddress(pwd string, address string) error
-	BuyRolls(pwd string, address string, amount uint64, fee float32) (string, error)
-	SellRolls(pwd string, address string, amount uint64, fee float32) (string, error)
+	BuyRolls(pwd string, address string, amount uint64, fee massaAmount.Amount) (string, error)
+	SellRolls(pwd string, address string, amount uint64, fee massaAmount.Amount) (string, error)
+	SendTransaction(pwd string, from, to string, amount, fee massaAmount.Amount) (string, error)
 	WalletInfo(pwd string) (map[string]WalletInfo, error)
 	WalletInfoWithoutNode(pwd string) (string, error)
 }
@@ -113,6 +116,25 @@ func (cd *clientDriver) AddStakingAddress(pwd string, secKey, address string) er
 	return nil
 }
 
+// AddStakingAddresses adds several staking addresses with a single wallet_add_secret_keys and a single node_start_staking call
+func (cd *clientDriver) AddStakingAddresses(pwd string, secKeys, addresses []string) error {
+	if len(secKeys) == 0 {
+		return nil
+	}
+
+	_, err := cd.executeCommand(append([]string{"wallet_add_secret_keys", "-p", pwd}, secKeys...)...)
+	if err != nil {
+		return fmt.Errorf("failed to add %d addresses to massa client: %v", len(addresses), err)
+	}
+
+	_, err = cd.executeCommand(append([]string{"node_start_staking", "-p", pwd}, addresses...)...)
+	if err != nil {
+		return fmt.Errorf("failed to add %d staking addresses to massa node: %v", len(addresses), err)
+	}
+
+	return nil
+}
+
 // RemoveStakingAddress removes a staking address
 func (cd *clientDriver) RemoveStakingAddress(pwd string, address string) error {
 	_, err := cd.executeCommand("node_stop_staking", "-p", pwd, address)
@@ -129,10 +151,10 @@ func (cd *clientDriver) RemoveStakingAddress(pwd string, address string) error {
 }
 
 // BuyRolls buys rolls for a specific address
-func (cd *clientDriver) BuyRolls(pwd string, address string, amount uint64, fee float32) (string, error) {
-	output, err := cd.executeCommand("buy_rolls", "-p", pwd, "-j", address, fmt.Sprintf("%d", amount), fmt.Sprintf("%f", fee))
+func (cd *clientDriver) BuyRolls(pwd string, address string, amount uint64, fee massaAmount.Amount) (string, error) {
+	output, err := cd.executeCommand("buy_rolls", "-p", pwd, "-j", address, fmt.Sprintf("%d", amount), fee.String())
 	if err != nil {
-		return "", fmt.Errorf("failed to buy %d rolls for address %s with fee %f MAS, got error: %v", amount, address, fee, err)
+		return "", fmt.Errorf("failed to buy %d rolls for address %s with fee %s MAS, got error: %v", amount, address, fee, err)
 	}
 
 	// retrieve operation id from response
@@ -149,10 +171,10 @@ func (cd *clientDriver) BuyRolls(pwd string, address string, amount uint64, fee
 }
 
 // SellRolls sells rolls for a specific address
-func (cd *clientDriver) SellRolls(pwd string, address string, amount uint64, fee float32) (string, error) {
-	output, err := cd.executeCommand("sell_rolls", "-p", pwd, "-j", address, fmt.Sprintf("%d", amount), fmt.Sprintf("%f", fee))
+func (cd *clientDriver) SellRolls(pwd string, address string, amount uint64, fee massaAmount.Amount) (string, error) {
+	output, err := cd.executeCommand("sell_rolls", "-p", pwd, "-j", address, fmt.Sprintf("%d", amount), fee.String())
 	if err != nil {
-		return "", fmt.Errorf("failed to sell %d rolls for address %s with fee %f MAS, got error: %v", amount, address, fee, err)
+		return "", fmt.Errorf("failed to sell %d rolls for address %s with fee %s MAS, got error: %v", amount, address, fee, err)
 	}
 
 	// retrieve operation id from response
@@ -168,6 +190,26 @@ func (cd *clientDriver) SellRolls(pwd string, address string, amount uint64, fee
 	return res[0], nil
 }
 
+// SendTransaction sends amount MAS from an address of the wallet to another address
+func (cd *clientDriver) SendTransaction(pwd string, from, to string, amount, fee massaAmount.Amount) (string, error) {
+	output, err := cd.executeCommand("send_transaction", "-p", pwd, "-j", from, to, amount.String(), fee.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to send %s MAS from %s to %s with fee %s MAS, got error: %v", amount, from, to, fee, err)
+	}
+
+	// retrieve operation id from response
+	var res []string
+	err = json.Unmarshal(output, &res)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal send transaction response: %v", err)
+	}
+	if len(res) == 0 {
+		return "", fmt.Errorf("no operation id response from send transaction")
+	}
+
+	return res[0], nil
+}
+
 // WalletInfo retrieves wallet information for all addresses
 func (cd *clientDriver) WalletInfo(pwd string) (map[string]WalletInfo, error) {
 	output, err := cd.executeCommand("wallet_info", "-j", "-p", pwd)
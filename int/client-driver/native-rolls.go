@@ -0,0 +1,212 @@
+package clientDriver
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/awnumar/memguard"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	massaKeys "github.com/massalabs/node-manager-plugin/int/massa-keys"
+	massaOperation "github.com/massalabs/node-manager-plugin/int/massa-operation"
+)
+
+const (
+	PublicAPIURL = "http://localhost:33035"
+
+	// operationValidityPeriods is the number of periods after the last slot during which an operation can be included
+	operationValidityPeriods = 10
+)
+
+/*
+nativeRollsClientDriver builds, signs and sends roll buy, roll sell and transaction operations itself
+through the node public API send_operations method, instead of spawning massa-client.
+The staking key is read from the massa-client wallet and only kept decrypted in a memguard enclave.
+All other calls are delegated to the wrapped ClientDriver.
+*/
+type nativeRollsClientDriver struct {
+	ClientDriver
+	rpc            *jsonRPCClient
+	walletDir      string
+	defaultChainID uint64
+}
+
+type nodeStatus struct {
+	LastSlot *Slot  `json:"last_slot"`
+	ChainID  uint64 `json:"chain_id"`
+}
+
+type sendOperationInput struct {
+	SerializedContent []int  `json:"serialized_content"`
+	CreatorPublicKey  string `json:"creator_public_key"`
+	Signature         string `json:"signature"`
+}
+
+// NewNativeRollsClientDriver wraps a ClientDriver so that roll operations and transactions are signed and sent natively
+func NewNativeRollsClientDriver(isMainnet bool, walletDir string, driver ClientDriver, timeout time.Duration) ClientDriver {
+	chainID := massaOperation.BuildnetChainID
+	if isMainnet {
+		chainID = massaOperation.MainnetChainID
+	}
+
+	return newNativeRollsClientDriver(PublicAPIURL, walletDir, chainID, driver, timeout)
+}
+
+func newNativeRollsClientDriver(url, walletDir string, chainID uint64, driver ClientDriver, timeout time.Duration) *nativeRollsClientDriver {
+	return &nativeRollsClientDriver{
+		ClientDriver:   driver,
+		rpc:            newJSONRPCClient(url, "node public api", timeout),
+		walletDir:      walletDir,
+		defaultChainID: chainID,
+	}
+}
+
+// BuyRolls buys rolls for a specific address and returns the operation id
+func (nd *nativeRollsClientDriver) BuyRolls(pwd string, address string, amount uint64, fee massaAmount.Amount) (string, error) {
+	return nd.sendRollOperation(pwd, address, amount, fee, massaOperation.NewRollBuy)
+}
+
+// SellRolls sells rolls for a specific address and returns the operation id
+func (nd *nativeRollsClientDriver) SellRolls(pwd string, address string, amount uint64, fee massaAmount.Amount) (string, error) {
+	return nd.sendRollOperation(pwd, address, amount, fee, massaOperation.NewRollSell)
+}
+
+// SendTransaction sends amount MAS from an address of the wallet to another address and returns the operation id
+func (nd *nativeRollsClientDriver) SendTransaction(pwd string, from, to string, amount, fee massaAmount.Amount) (string, error) {
+	nanoAmount, err := amount.NanoMAS()
+	if err != nil {
+		return "", fmt.Errorf("invalid amount: %w", err)
+	}
+	if nanoAmount == 0 {
+		return "", fmt.Errorf("transaction amount must be greater than 0")
+	}
+
+	return nd.sendOperation(pwd, from, fee, func(nanoFee, expirePeriod uint64) massaOperation.Operation {
+		return massaOperation.NewTransaction(nanoFee, expirePeriod, to, nanoAmount)
+	})
+}
+
+func (nd *nativeRollsClientDriver) sendRollOperation(
+	pwd string,
+	address string,
+	amount uint64,
+	fee massaAmount.Amount,
+	newOperation func(fee, expirePeriod, rollCount uint64) massaOperation.Operation,
+) (string, error) {
+	if amount == 0 {
+		return "", fmt.Errorf("roll amount must be greater than 0")
+	}
+
+	return nd.sendOperation(pwd, address, fee, func(nanoFee, expirePeriod uint64) massaOperation.Operation {
+		return newOperation(nanoFee, expirePeriod, amount)
+	})
+}
+
+// sendOperation signs the operation built by newOperation with the key of address and sends it to the node
+func (nd *nativeRollsClientDriver) sendOperation(
+	pwd string,
+	address string,
+	fee massaAmount.Amount,
+	newOperation func(fee, expirePeriod uint64) massaOperation.Operation,
+) (string, error) {
+	nanoFee, err := fee.NanoMAS()
+	if err != nil {
+		return "", fmt.Errorf("invalid fee: %w", err)
+	}
+
+	signer, err := nd.loadSigner(pwd, address)
+	if err != nil {
+		return "", fmt.Errorf("failed to load key of %s: %w", address, err)
+	}
+
+	var status nodeStatus
+	if err := nd.rpc.call("get_status", &status); err != nil {
+		return "", fmt.Errorf("failed to get node status: %w", err)
+	}
+	if status.LastSlot == nil {
+		return "", fmt.Errorf("node has no last slot yet")
+	}
+
+	chainID := status.ChainID
+	if chainID == 0 {
+		chainID = nd.defaultChainID
+	}
+
+	signed, err := massaOperation.Sign(newOperation(nanoFee, status.LastSlot.Period+operationValidityPeriods), signer, chainID)
+	if err != nil {
+		return "", err
+	}
+
+	content := make([]int, len(signed.SerializedContent))
+	for i, b := range signed.SerializedContent {
+		content[i] = int(b)
+	}
+
+	var operationIDs []string
+	if err := nd.rpc.call("send_operations", &operationIDs, []sendOperationInput{{
+		SerializedContent: content,
+		CreatorPublicKey:  signed.CreatorPublicKey,
+		Signature:         signed.Signature,
+	}}); err != nil {
+		return "", fmt.Errorf("failed to send operation for %s: %w", address, err)
+	}
+
+	if len(operationIDs) != 1 || operationIDs[0] != signed.ID {
+		return "", fmt.Errorf("node returned operation ids %v, expected %s", operationIDs, signed.ID)
+	}
+
+	return signed.ID, nil
+}
+
+// loadSigner decrypts the key of address from the massa-client wallet directly into a memguard enclave
+func (nd *nativeRollsClientDriver) loadSigner(pwd, address string) (*massaOperation.Signer, error) {
+	accountFile, err := nd.findAccountFile(address)
+	if err != nil {
+		return nil, err
+	}
+
+	seed, err := accountFile.DecryptSeed(pwd)
+	if err != nil {
+		return nil, err
+	}
+
+	// NewEnclave wipes the seed
+	signer, err := massaOperation.NewSigner(memguard.NewEnclave(seed))
+	if err != nil {
+		return nil, err
+	}
+
+	if signer.Address() != address {
+		return nil, fmt.Errorf("wallet key does not match address %s", address)
+	}
+
+	return signer, nil
+}
+
+// findAccountFile returns the wallet file of address, looking first at wallet_<address>.yaml then at every other wallet file
+func (nd *nativeRollsClientDriver) findAccountFile(address string) (*massaKeys.AccountFile, error) {
+	content, err := os.ReadFile(filepath.Join(nd.walletDir, fmt.Sprintf("wallet_%s.yaml", address)))
+	if err == nil {
+		return massaKeys.ParseAccountFile(content)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(nd.walletDir, "*.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallet files in %s: %w", nd.walletDir, err)
+	}
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		accountFile, err := massaKeys.ParseAccountFile(content)
+		if err == nil && accountFile.Address == address {
+			return accountFile, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no wallet file found for %s in %s", address, nd.walletDir)
+}
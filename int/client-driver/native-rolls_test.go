@@ -0,0 +1,217 @@
+package clientDriver
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	massaKeys "github.com/massalabs/node-manager-plugin/int/massa-keys"
+	massaOperation "github.com/massalabs/node-manager-plugin/int/massa-operation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"lukechampine.com/blake3"
+)
+
+// fakePublicAPI is a stand-in for the node public JSON-RPC API checking the operations it receives
+type fakePublicAPI struct {
+	t          *testing.T
+	mu         sync.Mutex
+	period     uint64
+	chainID    uint64
+	hideChain  bool // older nodes do not return their chain id in get_status
+	operations [][]byte
+	failWith   *rpcError
+}
+
+func (f *fakePublicAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var req struct {
+		ID     uint64                 `json:"id"`
+		Method string                 `json:"method"`
+		Params [][]sendOperationInput `json:"params"`
+	}
+	require.NoError(f.t, json.NewDecoder(r.Body).Decode(&req))
+
+	respond := func(result interface{}, rpcErr *rpcError) {
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if rpcErr != nil {
+			resp["error"] = rpcErr
+		} else {
+			resp["result"] = result
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+
+	switch req.Method {
+	case "get_status":
+		status := map[string]interface{}{"last_slot": Slot{Period: f.period, Thread: 3}}
+		if !f.hideChain {
+			status["chain_id"] = f.chainID
+		}
+		respond(status, nil)
+	case "send_operations":
+		if f.failWith != nil {
+			respond(nil, f.failWith)
+			return
+		}
+
+		ids := []string{}
+		for _, op := range req.Params[0] {
+			id, err := f.verify(op)
+			if err != nil {
+				respond(nil, &rpcError{Code: -32000, Message: err.Error()})
+				return
+			}
+			ids = append(ids, id)
+		}
+		respond(ids, nil)
+	default:
+		respond(nil, &rpcError{Code: -32601, Message: "Method not found"})
+	}
+}
+
+// verify checks the signature of an operation like the node would and returns its id
+func (f *fakePublicAPI) verify(op sendOperationInput) (string, error) {
+	content := make([]byte, len(op.SerializedContent))
+	for i, b := range op.SerializedContent {
+		content[i] = byte(b)
+	}
+	f.operations = append(f.operations, content)
+
+	keyPair, err := massaKeys.ParseSecretKey(testSecretKey)
+	if err != nil {
+		return "", err
+	}
+	if op.CreatorPublicKey != keyPair.PublicKey() {
+		return "", fmt.Errorf("unexpected creator %s", op.CreatorPublicKey)
+	}
+
+	signedData := binary.BigEndian.AppendUint64(nil, f.chainID)
+	signedData = append(signedData, keyPair.PublicKeyBytes()...)
+	hash := blake3.Sum256(append(signedData, content...))
+
+	// ed25519 signatures are deterministic, so a valid signature is the one made with the key
+	if op.Signature != massaKeys.SignatureText(ed25519.Sign(ed25519.NewKeyFromSeed(keyPair.Seed()), hash[:])) {
+		return "", fmt.Errorf("invalid signature")
+	}
+
+	return "O" + massaKeys.EncodeBase58Check(append([]byte{0}, hash[:]...)), nil
+}
+
+func newTestNativeRollsDriver(t *testing.T, massaClient ClientDriver) (*nativeRollsClientDriver, *fakePublicAPI) {
+	fake := &fakePublicAPI{t: t, period: 5000, chainID: massaOperation.MainnetChainID}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	walletDir := t.TempDir()
+	keyPair, err := massaKeys.ParseSecretKey(testSecretKey)
+	require.NoError(t, err)
+	accountFile, err := massaKeys.EncryptAccount(testAddress, "client_password", keyPair)
+	require.NoError(t, err)
+	content, err := accountFile.Marshal()
+	require.NoError(t, err)
+	// massa-client names wallet files after the nickname, the driver must find it by address anyway
+	require.NoError(t, os.WriteFile(filepath.Join(walletDir, "wallet_staking.yaml"), content, 0o600))
+
+	return newNativeRollsClientDriver(server.URL, walletDir, massaOperation.BuildnetChainID, massaClient, 5*time.Second), fake
+}
+
+func TestNativeRollsBuyAndSell(t *testing.T) {
+	driver, fake := newTestNativeRollsDriver(t, nil)
+
+	buyID, err := driver.BuyRolls("client_password", testAddress, 5, massaAmount.FromMAS(0.01))
+	require.NoError(t, err)
+	assert.Regexp(t, "^O1", buyID)
+
+	sellID, err := driver.SellRolls("client_password", testAddress, 2, 0)
+	require.NoError(t, err)
+	assert.NotEqual(t, buyID, sellID)
+
+	// fee 10000000 nanoMAS, expire period 5010, type, roll count
+	buy, err := massaOperation.NewRollBuy(10_000_000, 5010, 5).Serialize()
+	require.NoError(t, err)
+	sell, err := massaOperation.NewRollSell(0, 5010, 2).Serialize()
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{buy, sell}, fake.operations)
+}
+
+func TestNativeSendTransaction(t *testing.T) {
+	driver, fake := newTestNativeRollsDriver(t, nil)
+
+	opID, err := driver.SendTransaction("client_password", testAddress, testAddress, massaAmount.FromMAS(12.5), massaAmount.FromMAS(0.01))
+	require.NoError(t, err)
+	assert.Regexp(t, "^O1", opID)
+
+	transaction, err := massaOperation.NewTransaction(10_000_000, 5010, testAddress, 12_500_000_000).Serialize()
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{transaction}, fake.operations)
+
+	_, err = driver.SendTransaction("client_password", testAddress, "AU12invalid", massaAmount.MAS, massaAmount.FromMAS(0.01))
+	assert.ErrorContains(t, err, "invalid transaction recipient")
+
+	_, err = driver.SendTransaction("client_password", testAddress, testAddress, 0, massaAmount.FromMAS(0.01))
+	assert.ErrorContains(t, err, "transaction amount must be greater than 0")
+}
+
+func TestNativeRollsErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		pwd           string
+		address       string
+		amount        uint64
+		failWith      *rpcError
+		expectedError string
+	}{
+		{name: "zero rolls", pwd: "client_password", address: testAddress, amount: 0, expectedError: "roll amount must be greater than 0"},
+		{name: "wrong password", pwd: "wrong", address: testAddress, amount: 1, expectedError: "failed to load key of " + testAddress},
+		{name: "unknown address", pwd: "client_password", address: "AU12unknown", amount: 1, expectedError: "no wallet file found for AU12unknown"},
+		{name: "node rejects operation", pwd: "client_password", address: testAddress, amount: 1, failWith: &rpcError{Code: -32000, Message: "insufficient balance"}, expectedError: "send_operations failed with code -32000: insufficient balance"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, fake := newTestNativeRollsDriver(t, nil)
+			fake.failWith = tt.failWith
+
+			_, err := driver.BuyRolls(tt.pwd, tt.address, tt.amount, massaAmount.FromMAS(0.01))
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestNativeRollsChainIDFallback(t *testing.T) {
+	driver, fake := newTestNativeRollsDriver(t, nil)
+	// without a chain id from the node, the driver signs for the chain of its network
+	fake.hideChain = true
+	fake.chainID = massaOperation.BuildnetChainID
+
+	_, err := driver.BuyRolls("client_password", testAddress, 1, 0)
+	assert.NoError(t, err)
+
+	driver.defaultChainID = massaOperation.MainnetChainID
+	_, err = driver.BuyRolls("client_password", testAddress, 1, 0)
+	assert.ErrorContains(t, err, "invalid signature")
+}
+
+func TestNativeRollsDelegatesToClientDriver(t *testing.T) {
+	massaClient := NewMockClientDriver(t)
+	massaClient.On("GetStakingAddresses").Return([]string{testAddress}, nil).Once()
+
+	driver, fake := newTestNativeRollsDriver(t, massaClient)
+
+	addresses, err := driver.GetStakingAddresses()
+	require.NoError(t, err)
+	assert.Equal(t, []string{testAddress}, addresses)
+	assert.Empty(t, fake.operations)
+}
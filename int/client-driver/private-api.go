@@ -0,0 +1,234 @@
+package clientDriver
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	massaKeys "github.com/massalabs/node-manager-plugin/int/massa-keys"
+	nodeDirManagerPkg "github.com/massalabs/node-manager-plugin/int/node-bin-dir-manager"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+const (
+	PrivateAPIURL = "http://localhost:33034"
+)
+
+// PeerManager handles the peers whitelist and bans of the node
+type PeerManager interface {
+	GetPeersWhitelist() ([]string, error)
+	AddToPeersWhitelist(ips []string) error
+	RemoveFromPeersWhitelist(ips []string) error
+	BanByIP(ips []string) error
+	UnbanByIP(ips []string) error
+}
+
+// PrivateAPIClientDriver is a ClientDriver calling the node private JSON-RPC API instead of spawning massa-client
+type PrivateAPIClientDriver interface {
+	ClientDriver
+	PeerManager
+}
+
+/*
+privateAPIClientDriver manages the staking keys of the node through its private API.
+The keys are also written, encrypted with the password, in the massa-client wallet so that
+the operations the private API does not provide (rolls, transactions and wallet info) can still be done by massa-client.
+*/
+type privateAPIClientDriver struct {
+	rpc         *jsonRPCClient
+	walletDir   string
+	massaClient ClientDriver
+}
+
+// NewPrivateAPIClientDriver creates a PrivateAPIClientDriver, rolls, transactions and wallet info operations still go through massa-client
+func NewPrivateAPIClientDriver(
+	isMainnet bool,
+	nodeDirManager nodeDirManagerPkg.NodeDirManager,
+	timeout time.Duration,
+) (PrivateAPIClientDriver, error) {
+	massaClient, err := NewClientDriver(isMainnet, nodeDirManager, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPrivateAPIClientDriver(PrivateAPIURL, nodeDirManager.GetClientWalletDir(isMainnet), massaClient, timeout), nil
+}
+
+func newPrivateAPIClientDriver(url, walletDir string, massaClient ClientDriver, timeout time.Duration) *privateAPIClientDriver {
+	return &privateAPIClientDriver{
+		rpc:         newJSONRPCClient(url, "node private api", timeout),
+		walletDir:   walletDir,
+		massaClient: massaClient,
+	}
+}
+
+// GetStakingAddresses retrieves all staking addresses
+func (pd *privateAPIClientDriver) GetStakingAddresses() ([]string, error) {
+	var addresses []string
+	if err := pd.rpc.call("node_get_staking_addresses", &addresses); err != nil {
+		return nil, fmt.Errorf("failed to get staking addresses list: %w", err)
+	}
+
+	return addresses, nil
+}
+
+// AddStakingAddress adds a new staking address
+func (pd *privateAPIClientDriver) AddStakingAddress(pwd string, secKey, address string) error {
+	return pd.AddStakingAddresses(pwd, []string{secKey}, []string{address})
+}
+
+/*
+AddStakingAddresses adds several staking addresses with a single node_add_staking_secret_keys call.
+The wallet files created by the call are removed if it fails, so that massa-client doesn't list addresses the node doesn't stake.
+*/
+func (pd *privateAPIClientDriver) AddStakingAddresses(pwd string, secKeys, addresses []string) (err error) {
+	if len(secKeys) == 0 {
+		return nil
+	}
+
+	if len(secKeys) != len(addresses) {
+		return fmt.Errorf("got %d secret keys for %d addresses", len(secKeys), len(addresses))
+	}
+
+	var created []string
+	defer func() {
+		if err != nil {
+			for _, path := range created {
+				if rmErr := os.Remove(path); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) {
+					logger.Errorf("Failed to remove wallet file %s: %v", path, rmErr)
+				}
+			}
+		}
+	}()
+
+	for i, secKey := range secKeys {
+		path := pd.walletFilePath(addresses[i])
+		if _, statErr := os.Stat(path); errors.Is(statErr, os.ErrNotExist) {
+			created = append(created, path)
+		}
+
+		if err = pd.writeWalletFile(pwd, secKey, addresses[i]); err != nil {
+			return fmt.Errorf("failed to add address %s to massa client wallet: %w", addresses[i], err)
+		}
+	}
+
+	if err = pd.rpc.call("node_add_staking_secret_keys", nil, secKeys); err != nil {
+		return fmt.Errorf("failed to add %d staking addresses to massa node: %w", len(addresses), err)
+	}
+
+	return nil
+}
+
+// RemoveStakingAddress removes a staking address
+func (pd *privateAPIClientDriver) RemoveStakingAddress(pwd string, address string) error {
+	if err := pd.rpc.call("node_remove_staking_addresses", nil, []string{address}); err != nil {
+		return fmt.Errorf("failed to remove staking address %s from massa node: %w", address, err)
+	}
+
+	if err := os.Remove(pd.walletFilePath(address)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove address %s from massa client: %w", address, err)
+	}
+
+	return nil
+}
+
+// BuyRolls buys rolls for a specific address, through massa-client
+func (pd *privateAPIClientDriver) BuyRolls(pwd string, address string, amount uint64, fee massaAmount.Amount) (string, error) {
+	return pd.massaClient.BuyRolls(pwd, address, amount, fee)
+}
+
+// SellRolls sells rolls for a specific address, through massa-client
+func (pd *privateAPIClientDriver) SellRolls(pwd string, address string, amount uint64, fee massaAmount.Amount) (string, error) {
+	return pd.massaClient.SellRolls(pwd, address, amount, fee)
+}
+
+// SendTransaction sends MAS from a staking address to another address, through massa-client
+func (pd *privateAPIClientDriver) SendTransaction(pwd string, from, to string, amount, fee massaAmount.Amount) (string, error) {
+	return pd.massaClient.SendTransaction(pwd, from, to, amount, fee)
+}
+
+// WalletInfo retrieves wallet information for all addresses, through massa-client
+func (pd *privateAPIClientDriver) WalletInfo(pwd string) (map[string]WalletInfo, error) {
+	return pd.massaClient.WalletInfo(pwd)
+}
+
+func (pd *privateAPIClientDriver) WalletInfoWithoutNode(pwd string) (string, error) {
+	return pd.massaClient.WalletInfoWithoutNode(pwd)
+}
+
+// GetPeersWhitelist returns the ips of the peers whitelist
+func (pd *privateAPIClientDriver) GetPeersWhitelist() ([]string, error) {
+	var ips []string
+	if err := pd.rpc.call("node_peers_whitelist", &ips); err != nil {
+		return nil, fmt.Errorf("failed to get peers whitelist: %w", err)
+	}
+
+	return ips, nil
+}
+
+// AddToPeersWhitelist adds ips to the peers whitelist
+func (pd *privateAPIClientDriver) AddToPeersWhitelist(ips []string) error {
+	if err := pd.rpc.call("node_add_to_peers_whitelist", nil, ips); err != nil {
+		return fmt.Errorf("failed to add %v to peers whitelist: %w", ips, err)
+	}
+	return nil
+}
+
+// RemoveFromPeersWhitelist removes ips from the peers whitelist
+func (pd *privateAPIClientDriver) RemoveFromPeersWhitelist(ips []string) error {
+	if err := pd.rpc.call("node_remove_from_peers_whitelist", nil, ips); err != nil {
+		return fmt.Errorf("failed to remove %v from peers whitelist: %w", ips, err)
+	}
+	return nil
+}
+
+// BanByIP bans the peers with the given ips
+func (pd *privateAPIClientDriver) BanByIP(ips []string) error {
+	if err := pd.rpc.call("node_ban_by_ip", nil, ips); err != nil {
+		return fmt.Errorf("failed to ban %v: %w", ips, err)
+	}
+	return nil
+}
+
+// UnbanByIP unbans the peers with the given ips
+func (pd *privateAPIClientDriver) UnbanByIP(ips []string) error {
+	if err := pd.rpc.call("node_unban_by_ip", nil, ips); err != nil {
+		return fmt.Errorf("failed to unban %v: %w", ips, err)
+	}
+	return nil
+}
+
+// writeWalletFile stores the secret key in the massa-client wallet, encrypted with the password
+func (pd *privateAPIClientDriver) writeWalletFile(pwd, secKey, address string) error {
+	keyPair, err := massaKeys.ParseSecretKey(secKey)
+	if err != nil {
+		return err
+	}
+
+	if keyPair.Address() != address {
+		return fmt.Errorf("secret key does not match address %s", address)
+	}
+
+	accountFile, err := massaKeys.EncryptAccount(address, pwd, keyPair)
+	if err != nil {
+		return err
+	}
+
+	content, err := accountFile.Marshal()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(pd.walletDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create wallet directory %s: %w", pd.walletDir, err)
+	}
+
+	return os.WriteFile(pd.walletFilePath(address), content, 0o600)
+}
+
+func (pd *privateAPIClientDriver) walletFilePath(address string) string {
+	return filepath.Join(pd.walletDir, fmt.Sprintf("wallet_%s.yaml", address))
+}
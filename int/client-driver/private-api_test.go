@@ -0,0 +1,252 @@
+package clientDriver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	massaKeys "github.com/massalabs/node-manager-plugin/int/massa-keys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSecretKey = "S1Ka3NKcnfs8d67EZYU5mbTCVY7Znnd2YQAYjbBfb4XmeYfTma5"
+	testAddress   = "AU1KBGdEiu8zYtJ79ouX1Jv7HsWMgXRKK6yWgt93joD2HLQ9euFp"
+)
+
+// fakePrivateAPI is a stand-in for the node private JSON-RPC API keeping its state in memory
+type fakePrivateAPI struct {
+	mu         sync.Mutex
+	stakingKey []string
+	whitelist  []string
+	banned     []string
+	failWith   *rpcError
+	requests   []rpcRequest
+}
+
+func (f *fakePrivateAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var req struct {
+		JSONRPC string            `json:"jsonrpc"`
+		ID      uint64            `json:"id"`
+		Method  string            `json:"method"`
+		Params  []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.JSONRPC != "2.0" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	f.requests = append(f.requests, rpcRequest{JSONRPC: req.JSONRPC, ID: req.ID, Method: req.Method})
+
+	respond := func(result interface{}, rpcErr *rpcError) {
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if rpcErr != nil {
+			resp["error"] = rpcErr
+		} else {
+			resp["result"] = result
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+
+	if f.failWith != nil {
+		respond(nil, f.failWith)
+		return
+	}
+
+	var list []string
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params[0], &list); err != nil {
+			respond(nil, &rpcError{Code: -32602, Message: "invalid params"})
+			return
+		}
+	}
+
+	switch req.Method {
+	case "node_get_staking_addresses":
+		addresses := []string{}
+		for _, secretKey := range f.stakingKey {
+			keyPair, err := massaKeys.ParseSecretKey(secretKey)
+			if err != nil {
+				respond(nil, &rpcError{Code: -32000, Message: err.Error()})
+				return
+			}
+			addresses = append(addresses, keyPair.Address())
+		}
+		respond(addresses, nil)
+	case "node_add_staking_secret_keys":
+		f.stakingKey = append(f.stakingKey, list...)
+		respond(nil, nil)
+	case "node_remove_staking_addresses":
+		f.stakingKey = slices.DeleteFunc(f.stakingKey, func(secretKey string) bool {
+			keyPair, err := massaKeys.ParseSecretKey(secretKey)
+			return err == nil && slices.Contains(list, keyPair.Address())
+		})
+		respond(nil, nil)
+	case "node_peers_whitelist":
+		respond(f.whitelist, nil)
+	case "node_add_to_peers_whitelist":
+		f.whitelist = append(f.whitelist, list...)
+		respond(nil, nil)
+	case "node_remove_from_peers_whitelist":
+		f.whitelist = slices.DeleteFunc(f.whitelist, func(ip string) bool { return slices.Contains(list, ip) })
+		respond(nil, nil)
+	case "node_ban_by_ip":
+		f.banned = append(f.banned, list...)
+		respond(nil, nil)
+	case "node_unban_by_ip":
+		f.banned = slices.DeleteFunc(f.banned, func(ip string) bool { return slices.Contains(list, ip) })
+		respond(nil, nil)
+	default:
+		respond(nil, &rpcError{Code: -32601, Message: "Method not found"})
+	}
+}
+
+func newTestPrivateAPIDriver(t *testing.T, massaClient ClientDriver) (*privateAPIClientDriver, *fakePrivateAPI) {
+	fake := &fakePrivateAPI{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return newPrivateAPIClientDriver(server.URL, filepath.Join(t.TempDir(), "wallets"), massaClient, 5*time.Second), fake
+}
+
+func TestPrivateAPIStakingAddresses(t *testing.T) {
+	driver, fake := newTestPrivateAPIDriver(t, nil)
+
+	addresses, err := driver.GetStakingAddresses()
+	require.NoError(t, err)
+	assert.Empty(t, addresses)
+
+	require.NoError(t, driver.AddStakingAddress("client_password", testSecretKey, testAddress))
+
+	addresses, err = driver.GetStakingAddresses()
+	require.NoError(t, err)
+	assert.Equal(t, []string{testAddress}, addresses)
+
+	// the key is stored encrypted in the massa-client wallet for the operations still done by massa-client
+	content, err := os.ReadFile(filepath.Join(driver.walletDir, "wallet_"+testAddress+".yaml"))
+	require.NoError(t, err)
+	accountFile, err := massaKeys.ParseAccountFile(content)
+	require.NoError(t, err)
+	keyPair, err := accountFile.Decrypt("client_password")
+	require.NoError(t, err)
+	assert.Equal(t, testSecretKey, keyPair.SecretKey())
+
+	require.NoError(t, driver.RemoveStakingAddress("client_password", testAddress))
+
+	addresses, err = driver.GetStakingAddresses()
+	require.NoError(t, err)
+	assert.Empty(t, addresses)
+	assert.NoFileExists(t, filepath.Join(driver.walletDir, "wallet_"+testAddress+".yaml"))
+
+	methods := make([]string, len(fake.requests))
+	for i, req := range fake.requests {
+		methods[i] = req.Method
+	}
+	assert.Equal(t, []string{
+		"node_get_staking_addresses",
+		"node_add_staking_secret_keys",
+		"node_get_staking_addresses",
+		"node_remove_staking_addresses",
+		"node_get_staking_addresses",
+	}, methods)
+}
+
+func TestPrivateAPIAddStakingAddressesErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		secKeys       []string
+		addresses     []string
+		failWith      *rpcError
+		expectedError string
+	}{
+		{name: "mismatched lists", secKeys: []string{testSecretKey}, addresses: []string{}, expectedError: "got 1 secret keys for 0 addresses"},
+		{name: "invalid secret key", secKeys: []string{"not_a_key"}, addresses: []string{testAddress}, expectedError: "invalid secret key"},
+		{name: "key of another address", secKeys: []string{testSecretKey}, addresses: []string{"AU12other"}, expectedError: "secret key does not match address AU12other"},
+		{name: "second secret key invalid", secKeys: []string{testSecretKey, "not_a_key"}, addresses: []string{testAddress, "AU12other"}, expectedError: "invalid secret key"},
+		{name: "node error", secKeys: []string{testSecretKey}, addresses: []string{testAddress}, failWith: &rpcError{Code: -32000, Message: "node is stopping"}, expectedError: "node_add_staking_secret_keys failed with code -32000: node is stopping"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, fake := newTestPrivateAPIDriver(t, nil)
+			fake.failWith = tt.failWith
+
+			err := driver.AddStakingAddresses("client_password", tt.secKeys, tt.addresses)
+			assert.ErrorContains(t, err, tt.expectedError)
+
+			// the wallet files written before the failure are removed
+			assert.NoFileExists(t, filepath.Join(driver.walletDir, "wallet_"+testAddress+".yaml"))
+		})
+	}
+}
+
+func TestPrivateAPIAddStakingAddressesKeepsExistingWalletFile(t *testing.T) {
+	driver, fake := newTestPrivateAPIDriver(t, nil)
+	require.NoError(t, driver.AddStakingAddress("client_password", testSecretKey, testAddress))
+
+	// the wallet file of an address already in the wallet is not removed when the node fails
+	fake.failWith = &rpcError{Code: -32000, Message: "node is stopping"}
+	assert.Error(t, driver.AddStakingAddress("client_password", testSecretKey, testAddress))
+	assert.FileExists(t, filepath.Join(driver.walletDir, "wallet_"+testAddress+".yaml"))
+}
+
+func TestPrivateAPIPeers(t *testing.T) {
+	driver, fake := newTestPrivateAPIDriver(t, nil)
+
+	require.NoError(t, driver.AddToPeersWhitelist([]string{"1.2.3.4", "5.6.7.8"}))
+	require.NoError(t, driver.RemoveFromPeersWhitelist([]string{"1.2.3.4"}))
+
+	whitelist, err := driver.GetPeersWhitelist()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"5.6.7.8"}, whitelist)
+
+	require.NoError(t, driver.BanByIP([]string{"9.9.9.9", "8.8.8.8"}))
+	require.NoError(t, driver.UnbanByIP([]string{"9.9.9.9"}))
+	assert.Equal(t, []string{"8.8.8.8"}, fake.banned)
+}
+
+func TestPrivateAPIUnreachable(t *testing.T) {
+	driver := newPrivateAPIClientDriver("http://127.0.0.1:1", t.TempDir(), nil, time.Second)
+
+	_, err := driver.GetStakingAddresses()
+	assert.ErrorContains(t, err, "failed to call node_get_staking_addresses on node private api")
+}
+
+func TestPrivateAPIDelegatesToMassaClient(t *testing.T) {
+	massaClient := NewMockClientDriver(t)
+	fee := massaAmount.FromMAS(0.01)
+	massaClient.On("BuyRolls", "pwd", testAddress, uint64(2), fee).Return("op_buy", nil).Once()
+	massaClient.On("SellRolls", "pwd", testAddress, uint64(1), fee).Return("op_sell", nil).Once()
+	massaClient.On("SendTransaction", "pwd", testAddress, "AU12cold", massaAmount.FromMAS(12.5), fee).Return("op_transaction", nil).Once()
+	massaClient.On("WalletInfo", "pwd").Return(map[string]WalletInfo{testAddress: {AddressInfo: AddressInfo{ActiveRolls: 3}}}, nil).Once()
+
+	driver, fake := newTestPrivateAPIDriver(t, massaClient)
+
+	opID, err := driver.BuyRolls("pwd", testAddress, 2, fee)
+	require.NoError(t, err)
+	assert.Equal(t, "op_buy", opID)
+
+	opID, err = driver.SellRolls("pwd", testAddress, 1, fee)
+	require.NoError(t, err)
+	assert.Equal(t, "op_sell", opID)
+
+	opID, err = driver.SendTransaction("pwd", testAddress, "AU12cold", massaAmount.FromMAS(12.5), fee)
+	require.NoError(t, err)
+	assert.Equal(t, "op_transaction", opID)
+
+	walletInfo, err := driver.WalletInfo("pwd")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), walletInfo[testAddress].AddressInfo.ActiveRolls)
+
+	assert.Empty(t, fake.requests)
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"reflect"
 
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
 	"gopkg.in/yaml.v2"
 )
 
@@ -14,20 +15,47 @@ const (
 	configFileName = "node_manager_config.yaml"
 	nodeLogPath    = "nodeLogs"
 	dbName         = "db.sqlite"
+	keystoreDir    = "keystore"
+
+	// values of the client_driver config field
+	ClientDriverPrivateAPI  = "private_api"  // staking keys are managed through the node private api, opt-in
+	ClientDriverMassaClient = "massa_client" // every staking call spawns massa-client, the default
+
+	// values of the roll_operations config field
+	RollOperationsMassaClient = "massa_client" // roll buys and sells are sent by massa-client
+	RollOperationsNative      = "native"       // roll buys and sells are signed by the plugin and sent through the node public api
+
+	// values of the price_source config field
+	PriceSourceNone = "none" // values are only given in MAS
+	PriceSourceFile = "file" // prices are read from the price_file CSV or JSON file
+	PriceSourceHTTP = "http" // prices are fetched from the price_url endpoint
 )
 
 type PluginConfig struct {
-	NodeLogPath                    string `yaml:"node_log_path"`
-	NodeLogMaxSize                 int    `yaml:"log_max_size"`
-	MaxLogBackups                  int    `yaml:"max_log_backups"`
-	ClientTimeout                  int    `yaml:"client_timeout"`
-	BootstrapCheckInterval         int    `yaml:"bootstrap_check_interval"`
-	DesyncCheckInterval            int    `yaml:"desync_check_interval"`
-	RestartCooldown                int    `yaml:"restart_cooldown"`
-	StakingAddressDataPollInterval int    `yaml:"staking_address_data_poll_interval"`
-	DBPath                         string `yaml:"db_path"`
-	TotValueRegisterInterval       int    `yaml:"tot_value_register_interval"`
-	TotValueDelAfter               int    `yaml:"tot_value_del_after"`
+	NodeLogPath                    string             `yaml:"node_log_path"`
+	NodeLogMaxSize                 int                `yaml:"log_max_size"`
+	MaxLogBackups                  int                `yaml:"max_log_backups"`
+	ClientTimeout                  int                `yaml:"client_timeout"`
+	BootstrapCheckInterval         int                `yaml:"bootstrap_check_interval"`
+	DesyncCheckInterval            int                `yaml:"desync_check_interval"`
+	RestartCooldown                int                `yaml:"restart_cooldown"`
+	StakingAddressDataPollInterval int                `yaml:"staking_address_data_poll_interval"`
+	DBPath                         string             `yaml:"db_path"`
+	TotValueRegisterInterval       int                `yaml:"tot_value_register_interval"`
+	TotValueDelAfter               int                `yaml:"tot_value_del_after"`
+	MissRateAlertThreshold         int                `yaml:"miss_rate_alert_threshold"`
+	MaintenanceGap                 int                `yaml:"maintenance_gap"`
+	MaxMaintenanceDelay            int                `yaml:"max_maintenance_delay"`
+	KeystorePath                   string             `yaml:"keystore_path"`
+	ClientDriver                   string             `yaml:"client_driver"`
+	RollOperations                 string             `yaml:"roll_operations"`
+	MaxRollSpendPerDay             massaAmount.Amount `yaml:"max_roll_spend_per_day"`
+	MaxRollsPerOperation           int                `yaml:"max_rolls_per_operation"`
+	MinRollOperationInterval       int                `yaml:"min_roll_operation_interval"`
+	RollOperationsKillSwitch       bool               `yaml:"roll_operations_kill_switch"`
+	PriceSource                    string             `yaml:"price_source"`
+	PriceFile                      string             `yaml:"price_file"`
+	PriceURL                       string             `yaml:"price_url"`
 }
 
 func defaultPluginConfig() (PluginConfig, error) {
@@ -48,6 +76,19 @@ func defaultPluginConfig() (PluginConfig, error) {
 		DBPath:                         filepath.Join(execDir, dbName),
 		TotValueRegisterInterval:       180,      // 3 minutes
 		TotValueDelAfter:               31536000, // 1 year
+		MissRateAlertThreshold:         10,       // percentage of missed blocks in a cycle above which an alert is raised, -1 to disable the alert
+		MaintenanceGap:                 300,      // minimum time without draws required to stop or restart the node
+		MaxMaintenanceDelay:            1800,     // maximum time a non-urgent stop or restart can be delayed to wait for a gap between draws, -1 to never delay it
+		KeystorePath:                   filepath.Join(execDir, keystoreDir),
+		ClientDriver:                   ClientDriverMassaClient,
+		RollOperations:                 RollOperationsMassaClient,
+		MaxRollSpendPerDay:             0,     // maximum MAS spent on rolls by all the addresses over the last 24 hours, 0 for no limit
+		MaxRollsPerOperation:           0,     // maximum rolls bought or sold by a single operation, 0 for no limit
+		MinRollOperationInterval:       0,     // minimum time between two roll operations of an address, 0 for no minimum
+		RollOperationsKillSwitch:       false, // if true, no roll is bought or sold automatically
+		PriceSource:                    PriceSourceNone,
+		PriceFile:                      "", // path of the price file, used by the file price source
+		PriceURL:                       "", // endpoint queried with the date and currency parameters, used by the http price source
 	}, nil
 }
 
@@ -108,7 +149,8 @@ func RetrieveConfig() (*PluginConfig, error) {
 
 /*
 FillDefaultValues takes a PluginConfig instance and replaces any zero values with the corresponding
-default values from defaultPluginConfig(). Fields that already have non-zero values are kept unchanged.
+default values from defaultPluginConfig(). Fields that already have non-zero values are kept unchanged,
+so a feature enabled by default is disabled with -1 rather than 0.
 */
 func FillDefaultValues(config PluginConfig) (PluginConfig, bool, error) {
 	defaultConfig, err := defaultPluginConfig()
@@ -0,0 +1,482 @@
+package historymanager
+
+import (
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/massalabs/node-manager-plugin/int/db"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	priceProviderPkg "github.com/massalabs/node-manager-plugin/int/price-provider"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+)
+
+/*
+AccountingExportVersion is the latest version of the layout of the accounting export. The columns of a version never change:
+a column can only be added, removed or changed by a new version. Every version since MinAccountingExportVersion can still be exported.
+
+  - 1: amounts in MAS
+  - 2: the currency and the fiat values of the totals and amounts are appended to the columns of version 1
+*/
+const (
+	AccountingExportVersion    = 2
+	MinAccountingExportVersion = 1
+)
+
+// ConsolidatedAddress is the address of the rows summing every address in a consolidated export
+const ConsolidatedAddress = "ALL"
+
+// ExportPeriod is the length of the periods the balances of an accounting export are given for
+type ExportPeriod string
+
+const (
+	ExportPeriodDay   ExportPeriod = "DAY"
+	ExportPeriodWeek  ExportPeriod = "WEEK" // weeks start on monday
+	ExportPeriodMonth ExportPeriod = "MONTH"
+)
+
+// start returns the start of the period t is in, in UTC
+func (p ExportPeriod) start(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch p {
+	case ExportPeriodWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case ExportPeriodMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// next returns the start of the period following the one starting at start
+func (p ExportPeriod) next(start time.Time) time.Time {
+	switch p {
+	case ExportPeriodWeek:
+		return start.AddDate(0, 0, 7)
+	case ExportPeriodMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// ExportSection is a table of the accounting export, exported alone in CSV
+type ExportSection string
+
+const (
+	ExportSectionBalances               ExportSection = "balances"
+	ExportSectionRollOperations         ExportSection = "roll_operations"
+	ExportSectionRewards                ExportSection = "rewards"
+	ExportSectionDeferredCreditReleases ExportSection = "deferred_credit_releases"
+)
+
+// ExportOptions selects the content and the layout of an accounting export
+type ExportOptions struct {
+	Address      string       // export a single address instead of the staking addresses
+	Consolidated bool         // sum the balances and rewards of every address
+	Period       ExportPeriod // length of the periods of the balances
+	Version      int          // version of the layout, defaults to AccountingExportVersion
+	Currency     string       // also value the amounts in this fiat currency, requires version 2 and a price provider
+}
+
+// BalanceRow is the value of an address, split by part, at the first and last records of a period
+type BalanceRow struct {
+	PeriodStart    time.Time          `json:"period_start"`
+	PeriodEnd      time.Time          `json:"period_end"`
+	Address        string             `json:"address"`
+	StartBalance   massaAmount.Amount `json:"start_balance"`
+	StartRollValue massaAmount.Amount `json:"start_roll_value"`
+	StartDeferred  massaAmount.Amount `json:"start_deferred_credits"`
+	StartTotal     massaAmount.Amount `json:"start_total"`
+	EndBalance     massaAmount.Amount `json:"end_balance"`
+	EndRollValue   massaAmount.Amount `json:"end_roll_value"`
+	EndDeferred    massaAmount.Amount `json:"end_deferred_credits"`
+	EndTotal       massaAmount.Amount `json:"end_total"`
+	StartTotalFiat *float64           `json:"start_total_fiat,omitempty"` // valued at the price of the day of the first record
+	EndTotalFiat   *float64           `json:"end_total_fiat,omitempty"`   // valued at the price of the day of the last record
+}
+
+// RollOperationRow is a roll buy or sell that reached the final balance of an address, with its fee
+type RollOperationRow struct {
+	Timestamp     time.Time          `json:"timestamp"`
+	Cycle         uint64             `json:"cycle"`
+	Address       string             `json:"address"`
+	Operation     string             `json:"operation"` // BUY or SELL
+	Rolls         uint64             `json:"rolls"`
+	BalanceChange massaAmount.Amount `json:"balance_change"` // price of the rolls bought, 0 for sold rolls which are credited later
+	Fee           massaAmount.Amount `json:"fee"`            // 0 if the operation has not been sent by the plugin
+	OpId          string             `json:"op_id"`
+
+	BalanceChangeFiat *float64 `json:"balance_change_fiat,omitempty"`
+	FeeFiat           *float64 `json:"fee_fiat,omitempty"`
+}
+
+// RewardRow is the staking rewards of an address during a cycle
+type RewardRow struct {
+	Cycle              uint64             `json:"cycle"`
+	Address            string             `json:"address"`
+	BlockRewards       massaAmount.Amount `json:"block_rewards"`
+	EndorsementRewards massaAmount.Amount `json:"endorsement_rewards"`
+	Total              massaAmount.Amount `json:"total"`
+	TotalFiat          *float64           `json:"total_fiat,omitempty"` // sum of the rewards valued at the price of their day
+}
+
+// DeferredCreditReleaseRow is the release of the deferred credits of an address, the MAS of its sold rolls
+type DeferredCreditReleaseRow struct {
+	Timestamp  time.Time          `json:"timestamp"`
+	Cycle      uint64             `json:"cycle"`
+	Address    string             `json:"address"`
+	Amount     massaAmount.Amount `json:"amount"`
+	AmountFiat *float64           `json:"amount_fiat,omitempty"`
+}
+
+/*
+AccountingExport is the staking activity of the addresses over a date range, built from the ledger and the address value history.
+In a consolidated export, the balances and rewards of every address are summed under ConsolidatedAddress,
+the roll operations and deferred credit releases are still listed by address.
+The fiat values are only set if a currency is requested, and left nil when the price of their day is unknown.
+*/
+type AccountingExport struct {
+	Version                int                        `json:"version"`
+	Network                utils.Network              `json:"network"`
+	Since                  time.Time                  `json:"since"`
+	Until                  time.Time                  `json:"until"`
+	Period                 ExportPeriod               `json:"period"`
+	Consolidated           bool                       `json:"consolidated"`
+	Currency               string                     `json:"currency,omitempty"`
+	Balances               []BalanceRow               `json:"balances"`
+	RollOperations         []RollOperationRow         `json:"roll_operations"`
+	Rewards                []RewardRow                `json:"rewards"`
+	DeferredCreditReleases []DeferredCreditReleaseRow `json:"deferred_credit_releases"`
+}
+
+/*
+ExportAccounting builds the accounting export of the staking addresses, or of a single address if set in options,
+between since and until. The balances are given for each period of the range, clipped to the range.
+*/
+func (mgr *HistoryManager) ExportAccounting(since, until time.Time, net utils.Network, options ExportOptions) (AccountingExport, error) {
+	version := options.Version
+	if version == 0 {
+		version = AccountingExportVersion
+	}
+	if version < MinAccountingExportVersion || version > AccountingExportVersion {
+		return AccountingExport{}, fmt.Errorf("unsupported accounting export version %d", version)
+	}
+	if options.Currency != "" && version < 2 {
+		return AccountingExport{}, fmt.Errorf("fiat values require the version 2 of the accounting export")
+	}
+
+	converter, err := mgr.NewConverter(options.Currency)
+	if err != nil {
+		return AccountingExport{}, err
+	}
+
+	address, consolidated := options.Address, options.Consolidated
+	export := AccountingExport{
+		Version:                version,
+		Network:                net,
+		Since:                  since,
+		Until:                  until,
+		Period:                 options.Period,
+		Consolidated:           consolidated,
+		Currency:               converter.Currency(),
+		Balances:               []BalanceRow{},
+		RollOperations:         []RollOperationRow{},
+		Rewards:                []RewardRow{},
+		DeferredCreditReleases: []DeferredCreditReleaseRow{},
+	}
+
+	addressFilter := db.AddressHistoryFilter{}
+	if address != "" {
+		addressFilter.Addresses = []string{address}
+	}
+	histories, err := mgr.db.GetAddressValueHistory(addressFilter, since, until, net)
+	if err != nil {
+		return AccountingExport{}, err
+	}
+	export.Balances = balanceRows(histories, since, until, options.Period, consolidated, converter)
+
+	entries, err := mgr.db.GetLedgerEntries(address, since, net)
+	if err != nil {
+		return AccountingExport{}, err
+	}
+	entries = slices.DeleteFunc(entries, func(entry db.LedgerEntry) bool {
+		return entry.Timestamp.After(until)
+	})
+
+	type opKey struct{ address, opId string }
+	fees := make(map[opKey]massaAmount.Amount)
+	for _, entry := range entries {
+		if entry.Kind == db.LedgerFee && entry.OpId != "" {
+			fees[opKey{entry.Address, entry.OpId}] -= entry.Amount
+		}
+	}
+
+	type rewardKey struct {
+		cycle   uint64
+		address string
+	}
+	rewards := make(map[rewardKey]*RewardRow)
+	for _, entry := range entries {
+		switch entry.Kind {
+		case db.LedgerRollBuy, db.LedgerRollSell:
+			operation, rolls := string(db.RollOpBuy), entry.Rolls
+			if entry.Kind == db.LedgerRollSell {
+				operation, rolls = string(db.RollOpSell), -rolls
+			}
+			row := RollOperationRow{
+				Timestamp:     entry.Timestamp,
+				Cycle:         entry.Cycle,
+				Address:       entry.Address,
+				Operation:     operation,
+				Rolls:         uint64(rolls),
+				BalanceChange: entry.Amount,
+				OpId:          entry.OpId,
+			}
+			if entry.OpId != "" {
+				row.Fee = fees[opKey{entry.Address, entry.OpId}]
+			}
+			row.BalanceChangeFiat = converter.Convert(entry.Timestamp, row.BalanceChange.MAS())
+			row.FeeFiat = converter.Convert(entry.Timestamp, row.Fee.MAS())
+			export.RollOperations = append(export.RollOperations, row)
+
+		case db.LedgerBlockReward, db.LedgerEndorsementReward:
+			key := rewardKey{entry.Cycle, entry.Address}
+			if consolidated {
+				key.address = ConsolidatedAddress
+			}
+			row, ok := rewards[key]
+			if !ok {
+				row = &RewardRow{Cycle: key.cycle, Address: key.address}
+				if converter != nil {
+					row.TotalFiat = new(float64)
+				}
+				rewards[key] = row
+			}
+			row.TotalFiat = addFiat(row.TotalFiat, converter.Convert(entry.Timestamp, entry.Amount.MAS()))
+			if entry.Kind == db.LedgerBlockReward {
+				row.BlockRewards += entry.Amount
+			} else {
+				row.EndorsementRewards += entry.Amount
+			}
+			row.Total += entry.Amount
+
+		case db.LedgerDeferredCreditRelease:
+			export.DeferredCreditReleases = append(export.DeferredCreditReleases, DeferredCreditReleaseRow{
+				Timestamp:  entry.Timestamp,
+				Cycle:      entry.Cycle,
+				Address:    entry.Address,
+				Amount:     entry.Amount,
+				AmountFiat: converter.Convert(entry.Timestamp, entry.Amount.MAS()),
+			})
+		}
+	}
+
+	for _, row := range rewards {
+		export.Rewards = append(export.Rewards, *row)
+	}
+	slices.SortFunc(export.Rewards, func(a, b RewardRow) int {
+		if c := cmp.Compare(a.Cycle, b.Cycle); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Address, b.Address)
+	})
+
+	return export, nil
+}
+
+// addFiat sums two fiat values, nil if any of them is unknown
+func addFiat(a, b *float64) *float64 {
+	if a == nil || b == nil {
+		return nil
+	}
+	sum := *a + *b
+	return &sum
+}
+
+/*
+balanceRows gives the first and last values of each address in each period of the range.
+The histories are ordered by address then chronologically, as returned by the database.
+*/
+func balanceRows(histories []db.AddressValueHistory, since, until time.Time, period ExportPeriod, consolidated bool, converter *priceProviderPkg.Converter) []BalanceRow {
+	rows := []BalanceRow{}
+	for i := 0; i < len(histories); {
+		periodStart := period.start(histories[i].Timestamp)
+		periodEnd := period.next(periodStart)
+
+		first := histories[i]
+		last := first
+		for i++; i < len(histories) && histories[i].Address == first.Address && histories[i].Timestamp.Before(periodEnd); i++ {
+			last = histories[i]
+		}
+
+		if periodStart.Before(since) {
+			periodStart = since
+		}
+		if periodEnd.After(until) {
+			periodEnd = until
+		}
+		startTotal := first.Balance + first.RollValue + first.Deferred
+		endTotal := last.Balance + last.RollValue + last.Deferred
+		rows = append(rows, BalanceRow{
+			PeriodStart:    periodStart,
+			PeriodEnd:      periodEnd,
+			Address:        first.Address,
+			StartBalance:   first.Balance,
+			StartRollValue: first.RollValue,
+			StartDeferred:  first.Deferred,
+			StartTotal:     startTotal,
+			EndBalance:     last.Balance,
+			EndRollValue:   last.RollValue,
+			EndDeferred:    last.Deferred,
+			EndTotal:       endTotal,
+			StartTotalFiat: converter.Convert(first.Timestamp, startTotal.MAS()),
+			EndTotalFiat:   converter.Convert(last.Timestamp, endTotal.MAS()),
+		})
+	}
+
+	if consolidated {
+		var totals []BalanceRow
+		for _, row := range rows {
+			index := slices.IndexFunc(totals, func(total BalanceRow) bool { return total.PeriodStart.Equal(row.PeriodStart) })
+			if index < 0 {
+				totals = append(totals, BalanceRow{PeriodStart: row.PeriodStart, PeriodEnd: row.PeriodEnd, Address: ConsolidatedAddress})
+				index = len(totals) - 1
+				if converter != nil {
+					totals[index].StartTotalFiat = new(float64)
+					totals[index].EndTotalFiat = new(float64)
+				}
+			}
+			total := &totals[index]
+			total.StartBalance += row.StartBalance
+			total.StartRollValue += row.StartRollValue
+			total.StartDeferred += row.StartDeferred
+			total.StartTotal += row.StartTotal
+			total.EndBalance += row.EndBalance
+			total.EndRollValue += row.EndRollValue
+			total.EndDeferred += row.EndDeferred
+			total.EndTotal += row.EndTotal
+			total.StartTotalFiat = addFiat(total.StartTotalFiat, row.StartTotalFiat)
+			total.EndTotalFiat = addFiat(total.EndTotalFiat, row.EndTotalFiat)
+		}
+		rows = append([]BalanceRow{}, totals...)
+	}
+
+	slices.SortStableFunc(rows, func(a, b BalanceRow) int {
+		if c := a.PeriodStart.Compare(b.PeriodStart); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Address, b.Address)
+	})
+	return rows
+}
+
+// columns of each section of the version 1 of the CSV export, their order is part of the version
+var (
+	balanceColumns = []string{
+		"period_start", "period_end", "address",
+		"start_balance", "start_roll_value", "start_deferred_credits", "start_total",
+		"end_balance", "end_roll_value", "end_deferred_credits", "end_total",
+	}
+	rollOperationColumns         = []string{"timestamp", "cycle", "address", "operation", "rolls", "balance_change", "fee", "op_id"}
+	rewardColumns                = []string{"cycle", "address", "block_rewards", "endorsement_rewards", "total"}
+	deferredCreditReleaseColumns = []string{"timestamp", "cycle", "address", "amount"}
+)
+
+// columns appended to each section by the version 2 of the CSV export
+var (
+	balanceFiatColumns               = []string{"currency", "start_total_fiat", "end_total_fiat"}
+	rollOperationFiatColumns         = []string{"currency", "balance_change_fiat", "fee_fiat"}
+	rewardFiatColumns                = []string{"currency", "total_fiat"}
+	deferredCreditReleaseFiatColumns = []string{"currency", "amount_fiat"}
+)
+
+// formatAmount writes an amount in MAS with the 9 decimals of the nanoMAS
+func formatAmount(amount massaAmount.Amount) string {
+	return amount.Fixed()
+}
+
+// formatFiat writes a fiat value with 6 decimals, empty if it is unknown
+func formatFiat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', 6, 64)
+}
+
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+/*
+WriteCSV writes a section of the export as CSV, with a header line naming the columns.
+The columns are the ones of the version of the export.
+*/
+func (e AccountingExport) WriteCSV(w io.Writer, section ExportSection) error {
+	withFiat := e.Version >= 2
+	var records [][]string
+	// addRecord adds a row with the fiat cells of the version 2 if any
+	addRecord := func(cells []string, fiat ...*float64) {
+		if withFiat {
+			cells = append(cells, e.Currency)
+			for _, value := range fiat {
+				cells = append(cells, formatFiat(value))
+			}
+		}
+		records = append(records, cells)
+	}
+	addHeader := func(columns, fiatColumns []string) {
+		if withFiat {
+			columns = slices.Concat(columns, fiatColumns)
+		}
+		records = append(records, columns)
+	}
+
+	switch section {
+	case ExportSectionBalances:
+		addHeader(balanceColumns, balanceFiatColumns)
+		for _, row := range e.Balances {
+			addRecord([]string{
+				formatTimestamp(row.PeriodStart), formatTimestamp(row.PeriodEnd), row.Address,
+				formatAmount(row.StartBalance), formatAmount(row.StartRollValue), formatAmount(row.StartDeferred), formatAmount(row.StartTotal),
+				formatAmount(row.EndBalance), formatAmount(row.EndRollValue), formatAmount(row.EndDeferred), formatAmount(row.EndTotal),
+			}, row.StartTotalFiat, row.EndTotalFiat)
+		}
+	case ExportSectionRollOperations:
+		addHeader(rollOperationColumns, rollOperationFiatColumns)
+		for _, row := range e.RollOperations {
+			addRecord([]string{
+				formatTimestamp(row.Timestamp), strconv.FormatUint(row.Cycle, 10), row.Address, row.Operation,
+				strconv.FormatUint(row.Rolls, 10), formatAmount(row.BalanceChange), formatAmount(row.Fee), row.OpId,
+			}, row.BalanceChangeFiat, row.FeeFiat)
+		}
+	case ExportSectionRewards:
+		addHeader(rewardColumns, rewardFiatColumns)
+		for _, row := range e.Rewards {
+			addRecord([]string{
+				strconv.FormatUint(row.Cycle, 10), row.Address,
+				formatAmount(row.BlockRewards), formatAmount(row.EndorsementRewards), formatAmount(row.Total),
+			}, row.TotalFiat)
+		}
+	case ExportSectionDeferredCreditReleases:
+		addHeader(deferredCreditReleaseColumns, deferredCreditReleaseFiatColumns)
+		for _, row := range e.DeferredCreditReleases {
+			addRecord([]string{
+				formatTimestamp(row.Timestamp), strconv.FormatUint(row.Cycle, 10), row.Address, formatAmount(row.Amount),
+			}, row.AmountFiat)
+		}
+	default:
+		return fmt.Errorf("unknown export section %q", section)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write %s CSV: %w", section, err)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package historymanager
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/massalabs/node-manager-plugin/int/db"
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportPeriod(t *testing.T) {
+	// wednesday
+	timestamp := time.Date(2025, 1, 15, 13, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		period        ExportPeriod
+		expectedStart time.Time
+		expectedNext  time.Time
+	}{
+		{ExportPeriodDay, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{ExportPeriodWeek, time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)},
+		{ExportPeriodMonth, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.period), func(t *testing.T) {
+			start := tt.period.start(timestamp)
+			assert.Equal(t, tt.expectedStart, start)
+			assert.Equal(t, tt.expectedNext, tt.period.next(start))
+		})
+	}
+}
+
+func exportFixture() ([]db.AddressValueHistory, []db.LedgerEntry) {
+	histories := []db.AddressValueHistory{
+		{Timestamp: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), Address: "address_a", Balance: 110 * massaAmount.MAS, RollValue: 100 * massaAmount.MAS},
+		{Timestamp: time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), Address: "address_a", Balance: 12 * massaAmount.MAS, RollValue: 200 * massaAmount.MAS},
+		{Timestamp: time.Date(2025, 2, 20, 0, 0, 0, 0, time.UTC), Address: "address_a", Balance: 13 * massaAmount.MAS, RollValue: 200 * massaAmount.MAS},
+		{Timestamp: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), Address: "address_b", Balance: 5 * massaAmount.MAS, RollValue: 200 * massaAmount.MAS},
+		{Timestamp: time.Date(2025, 2, 27, 0, 0, 0, 0, time.UTC), Address: "address_b", Balance: massaAmount.FromMAS(4.99), Deferred: 200 * massaAmount.MAS},
+	}
+
+	entries := []db.LedgerEntry{
+		{Timestamp: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC), Address: "address_a", Cycle: 10, Kind: db.LedgerRollBuy, Amount: -100 * massaAmount.MAS, Rolls: 1, OpId: "op1"},
+		{Timestamp: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC), Address: "address_a", Cycle: 10, Kind: db.LedgerFee, Amount: -massaAmount.FromMAS(0.01), OpId: "op1"},
+		{Timestamp: time.Date(2025, 1, 21, 0, 0, 0, 0, time.UTC), Address: "address_a", Cycle: 10, Kind: db.LedgerBlockReward, Amount: massaAmount.MAS},
+		{Timestamp: time.Date(2025, 1, 22, 0, 0, 0, 0, time.UTC), Address: "address_a", Cycle: 10, Kind: db.LedgerEndorsementReward, Amount: massaAmount.FromMAS(0.5)},
+		{Timestamp: time.Date(2025, 1, 22, 0, 0, 0, 0, time.UTC), Address: "address_b", Cycle: 10, Kind: db.LedgerEndorsementReward, Amount: massaAmount.FromMAS(0.25)},
+		{Timestamp: time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC), Address: "address_a", Cycle: 11, Kind: db.LedgerBlockReward, Amount: massaAmount.MAS},
+		{Timestamp: time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC), Address: "address_b", Cycle: 11, Kind: db.LedgerRollSell, Rolls: -2, OpId: "op2"},
+		{Timestamp: time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC), Address: "address_b", Cycle: 11, Kind: db.LedgerFee, Amount: -massaAmount.FromMAS(0.01), OpId: "op2"},
+		{Timestamp: time.Date(2025, 2, 4, 0, 0, 0, 0, time.UTC), Address: "address_b", Cycle: 11, Kind: db.LedgerExternalTransfer, Amount: 3 * massaAmount.MAS},
+		{Timestamp: time.Date(2025, 2, 25, 0, 0, 0, 0, time.UTC), Address: "address_b", Cycle: 12, Kind: db.LedgerDeferredCreditRelease, Amount: 200 * massaAmount.MAS},
+		// after the end of the range
+		{Timestamp: time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), Address: "address_a", Cycle: 13, Kind: db.LedgerBlockReward, Amount: massaAmount.MAS},
+	}
+
+	return histories, entries
+}
+
+func TestExportAccounting(t *testing.T) {
+	since := time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	histories, entries := exportFixture()
+
+	mockDB := db.NewMockDB(t)
+	mockDB.On("GetAddressValueHistory", db.AddressHistoryFilter{}, since, until, utils.NetworkMainnet).Return(histories, nil).Once()
+	mockDB.On("GetLedgerEntries", "", since, utils.NetworkMainnet).Return(entries, nil).Once()
+
+	mgr := NewHistoryManager(mockDB, 3600, 60)
+	export, err := mgr.ExportAccounting(since, until, utils.NetworkMainnet, ExportOptions{Period: ExportPeriodMonth})
+	require.NoError(t, err)
+
+	assert.Equal(t, AccountingExportVersion, export.Version)
+	assert.Equal(t, utils.NetworkMainnet, export.Network)
+
+	february := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []BalanceRow{
+		{PeriodStart: since, PeriodEnd: february, Address: "address_a", StartBalance: 110 * massaAmount.MAS, StartRollValue: 100 * massaAmount.MAS, StartTotal: 210 * massaAmount.MAS, EndBalance: 12 * massaAmount.MAS, EndRollValue: 200 * massaAmount.MAS, EndTotal: 212 * massaAmount.MAS},
+		{PeriodStart: february, PeriodEnd: until, Address: "address_a", StartBalance: 13 * massaAmount.MAS, StartRollValue: 200 * massaAmount.MAS, StartTotal: 213 * massaAmount.MAS, EndBalance: 13 * massaAmount.MAS, EndRollValue: 200 * massaAmount.MAS, EndTotal: 213 * massaAmount.MAS},
+		{PeriodStart: february, PeriodEnd: until, Address: "address_b", StartBalance: 5 * massaAmount.MAS, StartRollValue: 200 * massaAmount.MAS, StartTotal: 205 * massaAmount.MAS, EndBalance: massaAmount.FromMAS(4.99), EndDeferred: 200 * massaAmount.MAS, EndTotal: massaAmount.FromMAS(204.99)},
+	}, export.Balances)
+
+	assert.Equal(t, []RollOperationRow{
+		{Timestamp: entries[0].Timestamp, Cycle: 10, Address: "address_a", Operation: "BUY", Rolls: 1, BalanceChange: -100 * massaAmount.MAS, Fee: massaAmount.FromMAS(0.01), OpId: "op1"},
+		{Timestamp: entries[6].Timestamp, Cycle: 11, Address: "address_b", Operation: "SELL", Rolls: 2, BalanceChange: 0, Fee: massaAmount.FromMAS(0.01), OpId: "op2"},
+	}, export.RollOperations)
+
+	assert.Equal(t, []RewardRow{
+		{Cycle: 10, Address: "address_a", BlockRewards: massaAmount.MAS, EndorsementRewards: massaAmount.FromMAS(0.5), Total: massaAmount.FromMAS(1.5)},
+		{Cycle: 10, Address: "address_b", EndorsementRewards: massaAmount.FromMAS(0.25), Total: massaAmount.FromMAS(0.25)},
+		{Cycle: 11, Address: "address_a", BlockRewards: massaAmount.MAS, Total: massaAmount.MAS},
+	}, export.Rewards)
+
+	assert.Equal(t, []DeferredCreditReleaseRow{
+		{Timestamp: entries[9].Timestamp, Cycle: 12, Address: "address_b", Amount: 200 * massaAmount.MAS},
+	}, export.DeferredCreditReleases)
+}
+
+func TestExportAccountingConsolidated(t *testing.T) {
+	since := time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	histories, entries := exportFixture()
+
+	mockDB := db.NewMockDB(t)
+	mockDB.On("GetAddressValueHistory", db.AddressHistoryFilter{}, since, until, utils.NetworkBuildnet).Return(histories, nil).Once()
+	mockDB.On("GetLedgerEntries", "", since, utils.NetworkBuildnet).Return(entries, nil).Once()
+
+	mgr := NewHistoryManager(mockDB, 3600, 60)
+	export, err := mgr.ExportAccounting(since, until, utils.NetworkBuildnet, ExportOptions{Consolidated: true, Period: ExportPeriodMonth, Version: 1})
+	require.NoError(t, err)
+
+	var balances strings.Builder
+	require.NoError(t, export.WriteCSV(&balances, ExportSectionBalances))
+	assert.Equal(t, `period_start,period_end,address,start_balance,start_roll_value,start_deferred_credits,start_total,end_balance,end_roll_value,end_deferred_credits,end_total
+2025-01-05T00:00:00Z,2025-02-01T00:00:00Z,ALL,110.000000000,100.000000000,0.000000000,210.000000000,12.000000000,200.000000000,0.000000000,212.000000000
+2025-02-01T00:00:00Z,2025-03-01T00:00:00Z,ALL,18.000000000,400.000000000,0.000000000,418.000000000,17.990000000,200.000000000,200.000000000,417.990000000
+`, balances.String())
+
+	var rewards strings.Builder
+	require.NoError(t, export.WriteCSV(&rewards, ExportSectionRewards))
+	assert.Equal(t, `cycle,address,block_rewards,endorsement_rewards,total
+10,ALL,1.000000000,0.750000000,1.750000000
+11,ALL,1.000000000,0.000000000,1.000000000
+`, rewards.String())
+
+	// the roll operations are still listed by address
+	var rollOperations strings.Builder
+	require.NoError(t, export.WriteCSV(&rollOperations, ExportSectionRollOperations))
+	assert.Equal(t, `timestamp,cycle,address,operation,rolls,balance_change,fee,op_id
+2025-01-20T00:00:00Z,10,address_a,BUY,1,-100.000000000,0.010000000,op1
+2025-02-03T00:00:00Z,11,address_b,SELL,2,0.000000000,0.010000000,op2
+`, rollOperations.String())
+
+	var releases strings.Builder
+	require.NoError(t, export.WriteCSV(&releases, ExportSectionDeferredCreditReleases))
+	assert.Equal(t, `timestamp,cycle,address,amount
+2025-02-25T00:00:00Z,12,address_b,200.000000000
+`, releases.String())
+
+	assert.Error(t, export.WriteCSV(&strings.Builder{}, ExportSection("unknown")))
+}
+
+func TestExportAccountingSingleAddress(t *testing.T) {
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	mockDB := db.NewMockDB(t)
+	mockDB.On("GetAddressValueHistory", db.AddressHistoryFilter{Addresses: []string{"address_a"}}, since, until, utils.NetworkMainnet).Return(nil, nil).Once()
+	mockDB.On("GetLedgerEntries", "address_a", since, utils.NetworkMainnet).Return(nil, nil).Once()
+
+	mgr := NewHistoryManager(mockDB, 3600, 60)
+	export, err := mgr.ExportAccounting(since, until, utils.NetworkMainnet, ExportOptions{Address: "address_a", Period: ExportPeriodDay})
+	require.NoError(t, err)
+
+	// empty tables are exported as empty lists
+	assert.Empty(t, export.Balances)
+	assert.NotNil(t, export.Balances)
+	assert.NotNil(t, export.RollOperations)
+	assert.NotNil(t, export.Rewards)
+	assert.NotNil(t, export.DeferredCreditReleases)
+}
+
+// stubPriceProvider gives the price of the days it holds, in any currency
+type stubPriceProvider map[string]float64
+
+func (p stubPriceProvider) GetPrice(day time.Time, currency string) (float64, error) {
+	price, ok := p[day.UTC().Format("2006-01-02")]
+	if !ok {
+		return 0, nodeManagerError.New(nodeManagerError.ErrPriceNotFound, "no price")
+	}
+	return price, nil
+}
+
+func TestExportAccountingFiat(t *testing.T) {
+	since := time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	histories, entries := exportFixture()
+
+	mockDB := db.NewMockDB(t)
+	mockDB.On("GetAddressValueHistory", db.AddressHistoryFilter{}, since, until, utils.NetworkMainnet).Return(histories, nil).Once()
+	mockDB.On("GetLedgerEntries", "", since, utils.NetworkMainnet).Return(entries, nil).Once()
+
+	mgr := NewHistoryManager(mockDB, 3600, 60)
+
+	// no price source is configured
+	_, err := mgr.ExportAccounting(since, until, utils.NetworkMainnet, ExportOptions{Period: ExportPeriodMonth, Currency: "EUR"})
+	assert.True(t, nodeManagerError.Is(err, nodeManagerError.ErrPriceSourceNotConfigured))
+
+	mgr.SetPriceProvider(stubPriceProvider{"2025-01-10": 2, "2025-01-31": 3, "2025-01-20": 2, "2025-01-21": 1.5, "2025-01-22": 2})
+
+	// the version 1 has no fiat columns
+	_, err = mgr.ExportAccounting(since, until, utils.NetworkMainnet, ExportOptions{Period: ExportPeriodMonth, Version: 1, Currency: "EUR"})
+	assert.Error(t, err)
+
+	export, err := mgr.ExportAccounting(since, until, utils.NetworkMainnet, ExportOptions{Consolidated: true, Period: ExportPeriodMonth, Currency: "eur"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, export.Version)
+	assert.Equal(t, "EUR", export.Currency)
+
+	var balances strings.Builder
+	require.NoError(t, export.WriteCSV(&balances, ExportSectionBalances))
+	assert.Equal(t, `period_start,period_end,address,start_balance,start_roll_value,start_deferred_credits,start_total,end_balance,end_roll_value,end_deferred_credits,end_total,currency,start_total_fiat,end_total_fiat
+2025-01-05T00:00:00Z,2025-02-01T00:00:00Z,ALL,110.000000000,100.000000000,0.000000000,210.000000000,12.000000000,200.000000000,0.000000000,212.000000000,EUR,420.000000,636.000000
+2025-02-01T00:00:00Z,2025-03-01T00:00:00Z,ALL,18.000000000,400.000000000,0.000000000,418.000000000,17.990000000,200.000000000,200.000000000,417.990000000,EUR,,
+`, balances.String())
+
+	var rewards strings.Builder
+	require.NoError(t, export.WriteCSV(&rewards, ExportSectionRewards))
+	assert.Equal(t, `cycle,address,block_rewards,endorsement_rewards,total,currency,total_fiat
+10,ALL,1.000000000,0.750000000,1.750000000,EUR,3.000000
+11,ALL,1.000000000,0.000000000,1.000000000,EUR,
+`, rewards.String())
+
+	var rollOperations strings.Builder
+	require.NoError(t, export.WriteCSV(&rollOperations, ExportSectionRollOperations))
+	assert.Equal(t, `timestamp,cycle,address,operation,rolls,balance_change,fee,op_id,currency,balance_change_fiat,fee_fiat
+2025-01-20T00:00:00Z,10,address_a,BUY,1,-100.000000000,0.010000000,op1,EUR,-200.000000,0.020000
+2025-02-03T00:00:00Z,11,address_b,SELL,2,0.000000000,0.010000000,op2,EUR,,
+`, rollOperations.String())
+}
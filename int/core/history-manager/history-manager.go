@@ -1,21 +1,110 @@
 package historymanager
 
 import (
+	"math"
+	"slices"
+	"sync"
 	"time"
 
+	"github.com/massalabs/node-manager-plugin/int/config"
+	nodeStatusPkg "github.com/massalabs/node-manager-plugin/int/core/NodeStatus"
 	"github.com/massalabs/node-manager-plugin/int/db"
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	priceProviderPkg "github.com/massalabs/node-manager-plugin/int/price-provider"
 	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/massalabs/station/pkg/logger"
 )
 
 type ValueHistorySample struct {
-	Timestamp time.Time `json:"timestamp"`
-	Value     *float64  `json:"value"`
+	Timestamp time.Time       `json:"timestamp"`
+	Value     *float64        `json:"value"`
+	Breakdown *ValueBreakdown `json:"breakdown,omitempty"`  // only set in breakdown mode, nil if Value is nil
+	Open      *float64        `json:"open,omitempty"`       // only set in ohlc aggregation mode, nil if Value is nil
+	Min       *float64        `json:"min,omitempty"`        // only set in minmax and ohlc aggregation modes, nil if Value is nil
+	Max       *float64        `json:"max,omitempty"`        // only set in minmax and ohlc aggregation modes, nil if Value is nil
+	FiatValue *float64        `json:"fiat_value,omitempty"` // Value in the currency of the filter, nil if Value or the price of the day is unknown
+}
+
+// Aggregation is the way the entries falling between two samples are aggregated into the latter
+type Aggregation string
+
+const (
+	AggregationLast   Aggregation = "last"   // value of the newest entry
+	AggregationAvg    Aggregation = "avg"    // average value of the entries
+	AggregationMinMax Aggregation = "minmax" // value of the newest entry, with the lowest and highest values of the entries
+	AggregationOHLC   Aggregation = "ohlc"   // values of the oldest and newest entries, with the lowest and highest values of the entries
+)
+
+const (
+	hourlyRollupMinInterval = time.Hour      // sampling intervals from which the hourly rollups are used instead of the raw entries
+	dailyRollupMinInterval  = 24 * time.Hour // sampling intervals from which the daily rollups are used
+)
+
+// ValueBreakdown splits a value between the final balances, the final rolls value and the deferred credits
+type ValueBreakdown struct {
+	Balance   float64 `json:"balance"`
+	RollValue float64 `json:"roll_value"`
+	Deferred  float64 `json:"deferred"`
+}
+
+/*
+ValueHistoryFilter selects the value history to sample and how it is aggregated. With no filter, the last total value
+of the staking addresses is sampled. Only one of Address, Group and WatchOnly should be set.
+*/
+type ValueHistoryFilter struct {
+	Address     string      // sample the value of a single address
+	Group       string      // sample the value of the addresses currently in the group
+	WatchOnly   bool        // sample the value of the watch-only addresses instead of the staking addresses
+	Breakdown   bool        // split each sample between balance, roll value and deferred credits
+	Aggregation Aggregation // defaults to AggregationLast
+	Currency    string      // also value the samples in this fiat currency, requires a price provider
+}
+
+/*
+valueEntry summarizes the values recorded at a timestamp: a single raw value, or the values of a rollup bucket.
+The breakdown is set if the entry has been retrieved from the address value history.
+*/
+type valueEntry struct {
+	timestamp time.Time
+	open      float64
+	close     float64
+	min       float64
+	max       float64
+	sum       float64
+	count     uint64
+	breakdown *ValueBreakdown
+}
+
+func newValueEntry(timestamp time.Time, value float64) valueEntry {
+	return valueEntry{timestamp: timestamp, open: value, close: value, min: value, max: value, sum: value, count: 1}
+}
+
+// Downtime is a range during which the node was not running on the network of the value history, End is nil if it is still down
+type Downtime struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end"`
+}
+
+/*
+ValueHistoryAnnotations explain the gaps and the jumps of a value history: the node downtimes,
+the roll operations and the addresses added to or removed from the sampled addresses
+*/
+type ValueHistoryAnnotations struct {
+	Downtimes     []Downtime                `json:"downtimes"`
+	RollOps       []db.AddressRollOpHistory `json:"roll_ops"`
+	AddressEvents []db.AddressEvent         `json:"address_events"`
 }
 
 type HistoryManager struct {
 	db                   db.DB
 	delAfter             int64 // seconds
 	totValuePostInterval int64 // seconds
+
+	statusMu          sync.Mutex
+	lastStatus        nodeStatusPkg.NodeStatus // last node status recorded in the node status history
+	lastStatusNetwork utils.Network
+
+	priceProvider priceProviderPkg.PriceProvider // nil if no price source is configured
 }
 
 var globalManager *HistoryManager
@@ -26,6 +115,22 @@ func NewHistoryManager(database db.DB, delAfter int64, totValuePostInterval int6
 	return mgr
 }
 
+// SetPriceProvider sets the provider of the prices used to value the histories in fiat currencies
+func (mgr *HistoryManager) SetPriceProvider(provider priceProviderPkg.PriceProvider) {
+	mgr.priceProvider = provider
+}
+
+// NewConverter returns a converter to the currency, nil if currency is empty. It fails if no price source is configured.
+func (mgr *HistoryManager) NewConverter(currency string) (*priceProviderPkg.Converter, error) {
+	if currency == "" {
+		return nil, nil
+	}
+	if mgr.priceProvider == nil {
+		return nil, nodeManagerError.New(nodeManagerError.ErrPriceSourceNotConfigured, "no price source is configured to value in "+currency)
+	}
+	return priceProviderPkg.NewConverter(mgr.priceProvider, currency), nil
+}
+
 func init() {
 	if globalManager != nil {
 		cutoff := time.Now().Add(-time.Duration(globalManager.delAfter) * time.Second)
@@ -34,51 +139,378 @@ func init() {
 }
 
 /*
-SampleValueHistory returns sampleNum samples between since and now
-Each sample takes the value of the newest entry with timestamp <= sample timestamp if the value of this entry
-has not already been taken by a previous sample.
-If no such entry exists, the sample value is nil.
+SampleValueHistory returns sampleNum samples between since and now of the value history selected by filter.
+Each sample aggregates the entries with timestamp <= sample timestamp that have not already been taken by a previous sample,
+according to the aggregation mode of the filter. If no such entry exists, the sample value is nil.
+If the filter has a currency, each sample is also valued in it at the price of the day of the sample.
+
+The value histories are read from the hourly or daily rollups when the interval between samples is long enough,
+a rollup bucket being taken by the first sample whose timestamp is not before the bucket start.
 
 The returned samples are sorted by timestamp.
 */
-func (mgr *HistoryManager) SampleValueHistory(since time.Time, sampleNum int64, isMainnet bool, interval time.Duration) ([]ValueHistorySample, error) {
-	net := utils.NetworkBuildnet
-	if isMainnet {
-		net = utils.NetworkMainnet
+func (mgr *HistoryManager) SampleValueHistory(since time.Time, sampleNum int64, net utils.Network, interval time.Duration, filter ValueHistoryFilter) ([]ValueHistorySample, error) {
+	converter, err := mgr.NewConverter(filter.Currency)
+	if err != nil {
+		return nil, err
 	}
 
 	// Retrieve values from since - totValuePostInterval to ensure that if an entry has timestamp "since", it is included
 	retrieveSince := since.Add(-time.Duration(mgr.totValuePostInterval) * time.Second)
-	dbEntries, err := mgr.db.GetHistory(retrieveSince, net)
+
+	var entries []valueEntry
+	resolution, useRollups := rollupResolution(interval)
+	addressHistory := filter.Address != "" || filter.Group != "" || filter.Breakdown
+	switch {
+	case addressHistory && useRollups:
+		entries, err = mgr.getAddressRollupEntries(retrieveSince, net, filter, resolution)
+	case addressHistory:
+		entries, err = mgr.getAddressEntries(retrieveSince, net, filter)
+	case useRollups:
+		entries, err = mgr.getRollupEntries(retrieveSince, net, filter.WatchOnly, resolution)
+	default:
+		entries, err = mgr.getTotalEntries(retrieveSince, net, filter.WatchOnly)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	lenDbEntries := len(dbEntries)
-	if lenDbEntries == 0 {
-		return nil, nil
+	samples := sampleEntries(entries, since, sampleNum, interval, filter.Aggregation)
+	for i := range samples {
+		if samples[i].Value != nil {
+			samples[i].FiatValue = converter.Convert(samples[i].Timestamp, *samples[i].Value)
+		}
+	}
+	return samples, nil
+}
+
+/*
+GetValueHistoryAnnotations returns the annotations of the value history selected by filter between since and now.
+The roll operations are only annotated on the staking addresses history, as watch-only addresses are never operated.
+*/
+func (mgr *HistoryManager) GetValueHistoryAnnotations(since time.Time, net utils.Network, filter ValueHistoryFilter) (ValueHistoryAnnotations, error) {
+	statuses, err := mgr.db.GetNodeStatusHistory(since, net)
+	if err != nil {
+		return ValueHistoryAnnotations{}, err
+	}
+
+	annotations := ValueHistoryAnnotations{Downtimes: downtimes(statuses, since, net)}
+
+	// nil means every address of the sampled kind
+	var addresses []string
+	switch {
+	case filter.Address != "":
+		addresses = []string{filter.Address}
+	case filter.Group != "":
+		labels, err := mgr.db.GetAddressLabels(net)
+		if err != nil {
+			return ValueHistoryAnnotations{}, err
+		}
+
+		addresses = []string{}
+		for _, label := range labels {
+			if label.Group == filter.Group {
+				addresses = append(addresses, label.Address)
+			}
+		}
+	}
+	selected := func(address string) bool {
+		return addresses == nil || slices.Contains(addresses, address)
+	}
+
+	events, err := mgr.db.GetAddressEvents(since, net)
+	if err != nil {
+		return ValueHistoryAnnotations{}, err
+	}
+	for _, event := range events {
+		// an address or group history covers both kinds of addresses
+		if selected(event.Address) && (addresses != nil || event.WatchOnly == filter.WatchOnly) {
+			annotations.AddressEvents = append(annotations.AddressEvents, event)
+		}
+	}
+
+	if !filter.WatchOnly {
+		rollOps, err := mgr.db.GetRollOpHistorySince(since, net)
+		if err != nil {
+			return ValueHistoryAnnotations{}, err
+		}
+		for _, rollOp := range rollOps {
+			// scheduled target changes are recorded with the roll operations but don't move the value
+			isBuySell := rollOp.Op == string(db.RollOpBuy) || rollOp.Op == string(db.RollOpSell)
+			if isBuySell && selected(rollOp.Address) {
+				annotations.RollOps = append(annotations.RollOps, rollOp)
+			}
+		}
+	}
+
+	return annotations, nil
+}
+
+/*
+downtimes computes the ranges after since during which the node was not running on net from the node status history.
+The node is assumed up before its first recorded status.
+*/
+func downtimes(statuses []db.NodeStatusHistory, since time.Time, net utils.Network) []Downtime {
+	var result []Downtime
+	var downSince *time.Time
+	for _, status := range statuses {
+		up := status.Status == string(nodeStatusPkg.NodeStatusOn) && status.Network == net
+
+		switch {
+		case !up && downSince == nil:
+			start := status.Timestamp
+			if start.Before(since) {
+				start = since
+			}
+			downSince = &start
+		case up && downSince != nil:
+			end := status.Timestamp
+			result = append(result, Downtime{Start: *downSince, End: &end})
+			downSince = nil
+		}
+	}
+
+	if downSince != nil {
+		result = append(result, Downtime{Start: *downSince})
+	}
+
+	return result
+}
+
+/*
+RecordNodeStatus records the statuses taken by the node in the node status history until the returned function is called.
+The current status is recorded first, so that a node left running by a previous run of the plugin is known to be down.
+*/
+func (mgr *HistoryManager) RecordNodeStatus(statusDispatcher nodeStatusPkg.NodeStatusDispatcher) func() {
+	statusChan, unsubscribe := statusDispatcher.SubscribeAll("history-manager-node-status")
+
+	mgr.recordNodeStatus(statusDispatcher.GetCurrentStatus())
+
+	go func() {
+		for status := range statusChan {
+			mgr.recordNodeStatus(status)
+		}
+	}()
+
+	return unsubscribe
+}
+
+// recordNodeStatus records a node status, unless it is the last recorded one
+func (mgr *HistoryManager) recordNodeStatus(status nodeStatusPkg.NodeStatus) {
+	mgr.statusMu.Lock()
+	defer mgr.statusMu.Unlock()
+
+	network := config.GlobalPluginInfo.GetNetwork()
+	if status == mgr.lastStatus && network == mgr.lastStatusNetwork {
+		return
+	}
+
+	// the history is read by network: a node leaving a network while running is recorded as off on it
+	if mgr.lastStatus == nodeStatusPkg.NodeStatusOn && network != mgr.lastStatusNetwork {
+		if err := mgr.db.AddNodeStatusHistory(string(nodeStatusPkg.NodeStatusOff), mgr.lastStatusNetwork); err != nil {
+			logger.Errorf("failed to record node status %s on %s: %v", nodeStatusPkg.NodeStatusOff, mgr.lastStatusNetwork, err)
+		}
+	}
+
+	if err := mgr.db.AddNodeStatusHistory(string(status), network); err != nil {
+		logger.Errorf("failed to record node status %s: %v", status, err)
+		return
+	}
+
+	mgr.lastStatus = status
+	mgr.lastStatusNetwork = network
+}
+
+// getTotalEntries retrieves the total value history of the staking addresses, or of the watch-only addresses
+func (mgr *HistoryManager) getTotalEntries(since time.Time, net utils.Network, watchOnly bool) ([]valueEntry, error) {
+	getHistory := mgr.db.GetHistory
+	if watchOnly {
+		getHistory = mgr.db.GetWatchOnlyHistory
+	}
+
+	dbEntries, err := getHistory(since, net)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]valueEntry, len(dbEntries))
+	for i, dbEntry := range dbEntries {
+		entries[i] = newValueEntry(dbEntry.Timestamp, dbEntry.TotalValue.MAS())
+	}
+
+	return entries, nil
+}
+
+// getRollupEntries retrieves the buckets of the total value history of the staking addresses, or of the watch-only addresses
+func (mgr *HistoryManager) getRollupEntries(since time.Time, net utils.Network, watchOnly bool, resolution db.RollupResolution) ([]valueEntry, error) {
+	series := db.ValueSeriesStaking
+	if watchOnly {
+		series = db.ValueSeriesWatchOnly
+	}
+
+	// Retrieve the bucket containing since too
+	rollups, err := mgr.db.GetValueRollups(series, resolution, resolution.BucketStart(since), net)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]valueEntry, len(rollups))
+	for i, rollup := range rollups {
+		entries[i] = valueEntry{
+			timestamp: rollup.BucketStart,
+			open:      rollup.Open.MAS(),
+			close:     rollup.Close.MAS(),
+			min:       rollup.Min.MAS(),
+			max:       rollup.Max.MAS(),
+			sum:       rollup.Avg.MAS() * float64(rollup.Count),
+			count:     rollup.Count,
+		}
+	}
+
+	return entries, nil
+}
+
+// rollupResolution returns the resolution of the rollups to sample at the given interval, false if the raw entries are sampled
+func rollupResolution(interval time.Duration) (db.RollupResolution, bool) {
+	switch {
+	case interval >= dailyRollupMinInterval:
+		return db.RollupDaily, true
+	case interval >= hourlyRollupMinInterval:
+		return db.RollupHourly, true
+	default:
+		return "", false
+	}
+}
+
+// addressHistoryFilter selects the addresses of the filter in the address value history, a group is resolved to its current addresses
+func (mgr *HistoryManager) addressHistoryFilter(net utils.Network, filter ValueHistoryFilter) (db.AddressHistoryFilter, error) {
+	addressFilter := db.AddressHistoryFilter{WatchOnly: filter.WatchOnly}
+	switch {
+	case filter.Address != "":
+		addressFilter.Addresses = []string{filter.Address}
+	case filter.Group != "":
+		labels, err := mgr.db.GetAddressLabels(net)
+		if err != nil {
+			return db.AddressHistoryFilter{}, err
+		}
+
+		addressFilter.Addresses = []string{}
+		for _, label := range labels {
+			if label.Group == filter.Group {
+				addressFilter.Addresses = append(addressFilter.Addresses, label.Address)
+			}
+		}
+	}
+
+	return addressFilter, nil
+}
+
+// getAddressEntries retrieves the value history of the addresses selected by the filter from the address value history
+func (mgr *HistoryManager) getAddressEntries(since time.Time, net utils.Network, filter ValueHistoryFilter) ([]valueEntry, error) {
+	addressFilter, err := mgr.addressHistoryFilter(net, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	dbEntries, err := mgr.db.GetAddressHistory(addressFilter, since, net)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]valueEntry, len(dbEntries))
+	for i, dbEntry := range dbEntries {
+		entries[i] = newValueEntry(dbEntry.Timestamp, (dbEntry.Balance + dbEntry.RollValue + dbEntry.Deferred).MAS())
+		if filter.Breakdown {
+			entries[i].breakdown = &ValueBreakdown{Balance: dbEntry.Balance.MAS(), RollValue: dbEntry.RollValue.MAS(), Deferred: dbEntry.Deferred.MAS()}
+		}
+	}
+
+	return entries, nil
+}
+
+// getAddressRollupEntries retrieves the buckets of the value history of the addresses selected by the filter
+func (mgr *HistoryManager) getAddressRollupEntries(since time.Time, net utils.Network, filter ValueHistoryFilter, resolution db.RollupResolution) ([]valueEntry, error) {
+	addressFilter, err := mgr.addressHistoryFilter(net, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retrieve the bucket containing since too
+	rollups, err := mgr.db.GetAddressValueRollups(addressFilter, resolution, resolution.BucketStart(since), net)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]valueEntry, len(rollups))
+	for i, rollup := range rollups {
+		entries[i] = valueEntry{
+			timestamp: rollup.BucketStart,
+			open:      rollup.Open.MAS(),
+			close:     rollup.Close.MAS(),
+			min:       rollup.Min.MAS(),
+			max:       rollup.Max.MAS(),
+			sum:       rollup.Avg.MAS() * float64(rollup.Count),
+			count:     rollup.Count,
+		}
+		if filter.Breakdown {
+			entries[i].breakdown = &ValueBreakdown{Balance: rollup.Balance.MAS(), RollValue: rollup.RollValue.MAS(), Deferred: rollup.Deferred.MAS()}
+		}
+	}
+
+	return entries, nil
+}
+
+// sampleEntries takes sampleNum samples of the value history entries, starting at since
+func sampleEntries(entries []valueEntry, since time.Time, sampleNum int64, interval time.Duration, aggregation Aggregation) []ValueHistorySample {
+	lenEntries := len(entries)
+	if lenEntries == 0 {
+		return nil
 	}
 
 	result := make([]ValueHistorySample, sampleNum)
-	dbEntryIndex := 0
+	entryIndex := 0
 	for i := int64(0); i < sampleNum; i++ {
 		ts := since.Add(time.Duration(i) * interval)
-		var val *float64
-		chosen := -1
+		first := entryIndex
 
-		for dbEntryIndex < lenDbEntries {
-			if dbEntries[dbEntryIndex].Timestamp.After(ts) {
+		for entryIndex < lenEntries {
+			if entries[entryIndex].timestamp.After(ts) {
 				break
 			}
-			chosen = dbEntryIndex
-			dbEntryIndex++
+			entryIndex++
 		}
 
-		if chosen != -1 {
-			v := dbEntries[chosen].TotalValue
-			val = &v
+		result[i] = ValueHistorySample{Timestamp: ts}
+		if entryIndex > first {
+			aggregateSample(&result[i], entries[first:entryIndex], aggregation)
 		}
-		result[i] = ValueHistorySample{Timestamp: ts, Value: val}
 	}
-	return result, nil
+	return result
+}
+
+// aggregateSample sets the value of a sample from the entries it takes, sorted by timestamp
+func aggregateSample(sample *ValueHistorySample, entries []valueEntry, aggregation Aggregation) {
+	last := entries[len(entries)-1]
+	open, min, max, sum, count := entries[0].open, last.min, last.max, 0.0, uint64(0)
+	for _, entry := range entries {
+		min = math.Min(min, entry.min)
+		max = math.Max(max, entry.max)
+		sum += entry.sum
+		count += entry.count
+	}
+
+	value := last.close
+	if aggregation == AggregationAvg && count > 0 {
+		value = math.Floor(sum/float64(count)*1000) / 1000 // keep only 3 digit after the comma
+	}
+	sample.Value = &value
+	sample.Breakdown = last.breakdown
+
+	if aggregation == AggregationMinMax || aggregation == AggregationOHLC {
+		sample.Min = &min
+		sample.Max = &max
+	}
+	if aggregation == AggregationOHLC {
+		sample.Open = &open
+	}
 }
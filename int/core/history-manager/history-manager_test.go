@@ -4,9 +4,14 @@ import (
 	"testing"
 	"time"
 
+	nodeStatusPkg "github.com/massalabs/node-manager-plugin/int/core/NodeStatus"
 	"github.com/massalabs/node-manager-plugin/int/db"
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
 	"github.com/massalabs/node-manager-plugin/int/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSampleValueHistory(t *testing.T) {
@@ -40,10 +45,10 @@ func TestSampleValueHistory(t *testing.T) {
 			sampleInterval: interval,
 			setupDbMock: func(mockDB *db.MockDB, since time.Time) {
 				dbEntries := []db.ValueHistory{
-					{Timestamp: since.Add(-interval), TotalValue: val1},
-					{Timestamp: since, TotalValue: val2},
-					{Timestamp: since.Add(interval), TotalValue: val3},
-					{Timestamp: since.Add(2 * interval), TotalValue: val4},
+					{Timestamp: since.Add(-interval), TotalValue: massaAmount.FromMAS(val1)},
+					{Timestamp: since, TotalValue: massaAmount.FromMAS(val2)},
+					{Timestamp: since.Add(interval), TotalValue: massaAmount.FromMAS(val3)},
+					{Timestamp: since.Add(2 * interval), TotalValue: massaAmount.FromMAS(val4)},
 				}
 				retrieveSince := since.Add(-interval)
 				mockDB.On("GetHistory", retrieveSince, utils.NetworkBuildnet).Return(dbEntries, nil)
@@ -63,13 +68,13 @@ func TestSampleValueHistory(t *testing.T) {
 			sampleInterval: 2 * interval,
 			setupDbMock: func(mockDB *db.MockDB, since time.Time) {
 				dbEntries := []db.ValueHistory{
-					{Timestamp: since.Add(-interval), TotalValue: val1},
-					{Timestamp: since, TotalValue: val2},
-					{Timestamp: since.Add(interval), TotalValue: val3},
-					{Timestamp: since.Add(2 * interval), TotalValue: val4},
-					{Timestamp: since.Add(3 * interval), TotalValue: val5},
-					{Timestamp: since.Add(4 * interval), TotalValue: val6},
-					{Timestamp: since.Add(5 * interval), TotalValue: val7},
+					{Timestamp: since.Add(-interval), TotalValue: massaAmount.FromMAS(val1)},
+					{Timestamp: since, TotalValue: massaAmount.FromMAS(val2)},
+					{Timestamp: since.Add(interval), TotalValue: massaAmount.FromMAS(val3)},
+					{Timestamp: since.Add(2 * interval), TotalValue: massaAmount.FromMAS(val4)},
+					{Timestamp: since.Add(3 * interval), TotalValue: massaAmount.FromMAS(val5)},
+					{Timestamp: since.Add(4 * interval), TotalValue: massaAmount.FromMAS(val6)},
+					{Timestamp: since.Add(5 * interval), TotalValue: massaAmount.FromMAS(val7)},
 				}
 				retrieveSince := since.Add(-interval)
 				mockDB.On("GetHistory", retrieveSince, utils.NetworkBuildnet).Return(dbEntries, nil)
@@ -89,13 +94,13 @@ func TestSampleValueHistory(t *testing.T) {
 			setupDbMock: func(mockDB *db.MockDB, since time.Time) {
 				intv := time.Duration(totValuePostInterval) * time.Second
 				dbEntries := []db.ValueHistory{
-					{Timestamp: since, TotalValue: val1},
-					{Timestamp: since.Add(intv), TotalValue: val2},
-					{Timestamp: since.Add(2 * intv), TotalValue: val3},
-					{Timestamp: since.Add(3 * intv), TotalValue: val4},
-					{Timestamp: since.Add(4 * intv), TotalValue: val5},
-					{Timestamp: since.Add(5 * intv), TotalValue: val6},
-					{Timestamp: since.Add(6 * intv), TotalValue: val7},
+					{Timestamp: since, TotalValue: massaAmount.FromMAS(val1)},
+					{Timestamp: since.Add(intv), TotalValue: massaAmount.FromMAS(val2)},
+					{Timestamp: since.Add(2 * intv), TotalValue: massaAmount.FromMAS(val3)},
+					{Timestamp: since.Add(3 * intv), TotalValue: massaAmount.FromMAS(val4)},
+					{Timestamp: since.Add(4 * intv), TotalValue: massaAmount.FromMAS(val5)},
+					{Timestamp: since.Add(5 * intv), TotalValue: massaAmount.FromMAS(val6)},
+					{Timestamp: since.Add(6 * intv), TotalValue: massaAmount.FromMAS(val7)},
 				}
 				retrieveSince := since.Add(-intv)
 				mockDB.On("GetHistory", retrieveSince, utils.NetworkBuildnet).Return(dbEntries, nil)
@@ -116,9 +121,9 @@ func TestSampleValueHistory(t *testing.T) {
 			setupDbMock: func(mockDB *db.MockDB, since time.Time) {
 				intv := time.Duration(totValuePostInterval) * time.Second
 				dbEntries := []db.ValueHistory{
-					{Timestamp: since, TotalValue: val1},
-					{Timestamp: since.Add(intv), TotalValue: val2},
-					{Timestamp: since.Add(2 * intv), TotalValue: val3},
+					{Timestamp: since, TotalValue: massaAmount.FromMAS(val1)},
+					{Timestamp: since.Add(intv), TotalValue: massaAmount.FromMAS(val2)},
+					{Timestamp: since.Add(2 * intv), TotalValue: massaAmount.FromMAS(val3)},
 				}
 				retrieveSince := since.Add(-intv)
 				mockDB.On("GetHistory", retrieveSince, utils.NetworkBuildnet).Return(dbEntries, nil)
@@ -135,8 +140,8 @@ func TestSampleValueHistory(t *testing.T) {
 			sampleInterval: interval,
 			setupDbMock: func(mockDB *db.MockDB, since time.Time) {
 				dbEntries := []db.ValueHistory{
-					{Timestamp: since.Add(interval), TotalValue: val1},
-					{Timestamp: since.Add(3 * interval), TotalValue: val2},
+					{Timestamp: since.Add(interval), TotalValue: massaAmount.FromMAS(val1)},
+					{Timestamp: since.Add(3 * interval), TotalValue: massaAmount.FromMAS(val2)},
 				}
 				retrieveSince := since.Add(-interval)
 				mockDB.On("GetHistory", retrieveSince, utils.NetworkBuildnet).Return(dbEntries, nil)
@@ -156,8 +161,8 @@ func TestSampleValueHistory(t *testing.T) {
 			sampleInterval: interval,
 			setupDbMock: func(mockDB *db.MockDB, since time.Time) {
 				dbEntries := []db.ValueHistory{
-					{Timestamp: since, TotalValue: val1},
-					{Timestamp: since.Add(2 * interval), TotalValue: val2},
+					{Timestamp: since, TotalValue: massaAmount.FromMAS(val1)},
+					{Timestamp: since.Add(2 * interval), TotalValue: massaAmount.FromMAS(val2)},
 				}
 				retrieveSince := since.Add(-interval)
 				mockDB.On("GetHistory", retrieveSince, utils.NetworkBuildnet).Return(dbEntries, nil)
@@ -177,9 +182,9 @@ func TestSampleValueHistory(t *testing.T) {
 			sampleInterval: interval,
 			setupDbMock: func(mockDB *db.MockDB, since time.Time) {
 				dbEntries := []db.ValueHistory{
-					{Timestamp: since.Add(interval / 2), TotalValue: val1},
-					{Timestamp: since.Add(interval + interval/2), TotalValue: val2},
-					{Timestamp: since.Add(2*interval + interval/2), TotalValue: val3},
+					{Timestamp: since.Add(interval / 2), TotalValue: massaAmount.FromMAS(val1)},
+					{Timestamp: since.Add(interval + interval/2), TotalValue: massaAmount.FromMAS(val2)},
+					{Timestamp: since.Add(2*interval + interval/2), TotalValue: massaAmount.FromMAS(val3)},
 				}
 				retrieveSince := since.Add(-interval)
 				mockDB.On("GetHistory", retrieveSince, utils.NetworkBuildnet).Return(dbEntries, nil)
@@ -199,8 +204,8 @@ func TestSampleValueHistory(t *testing.T) {
 			sampleInterval: 3 * interval,
 			setupDbMock: func(mockDB *db.MockDB, since time.Time) {
 				dbEntries := []db.ValueHistory{
-					{Timestamp: since.Add(interval), TotalValue: val1},
-					{Timestamp: since.Add(2 * interval), TotalValue: val2},
+					{Timestamp: since.Add(interval), TotalValue: massaAmount.FromMAS(val1)},
+					{Timestamp: since.Add(2 * interval), TotalValue: massaAmount.FromMAS(val2)},
 				}
 				retrieveSince := since.Add(-interval)
 				mockDB.On("GetHistory", retrieveSince, utils.NetworkBuildnet).Return(dbEntries, nil)
@@ -217,8 +222,8 @@ func TestSampleValueHistory(t *testing.T) {
 			sampleInterval: interval,
 			setupDbMock: func(mockDB *db.MockDB, since time.Time) {
 				dbEntries := []db.ValueHistory{
-					{Timestamp: since.Add(interval), TotalValue: val1},
-					{Timestamp: since.Add(2 * interval), TotalValue: val2},
+					{Timestamp: since.Add(interval), TotalValue: massaAmount.FromMAS(val1)},
+					{Timestamp: since.Add(2 * interval), TotalValue: massaAmount.FromMAS(val2)},
 				}
 				retrieveSince := since.Add(-interval)
 				mockDB.On("GetHistory", retrieveSince, utils.NetworkBuildnet).Return(dbEntries, nil)
@@ -267,7 +272,7 @@ func TestSampleValueHistory(t *testing.T) {
 				tc.setupDbMock(mockDB, since)
 			}
 			mgr := NewHistoryManager(mockDB, delAfter, totValuePostInterval)
-			got, err := mgr.SampleValueHistory(since, tc.sampleNum, false, tc.sampleInterval)
+			got, err := mgr.SampleValueHistory(since, tc.sampleNum, utils.NetworkBuildnet, tc.sampleInterval, ValueHistoryFilter{})
 			if tc.hasError {
 				assert.Error(t, err)
 			} else {
@@ -279,3 +284,388 @@ func TestSampleValueHistory(t *testing.T) {
 		})
 	}
 }
+
+func TestSampleValueHistoryFilters(t *testing.T) {
+	totValuePostInterval := int64(180)
+	interval := time.Duration(totValuePostInterval) * time.Second
+	since := time.Now().Truncate(time.Second).Add(-2 * interval)
+	retrieveSince := since.Add(-interval)
+
+	breakdownEntries := []db.ValueBreakdownHistory{
+		{Timestamp: since, Balance: 10 * massaAmount.MAS, RollValue: 100 * massaAmount.MAS, Deferred: massaAmount.MAS},
+		{Timestamp: since.Add(interval), Balance: 20 * massaAmount.MAS, RollValue: 200 * massaAmount.MAS, Deferred: 2 * massaAmount.MAS},
+	}
+	val1 := 111.0
+	val2 := 222.0
+
+	tests := []struct {
+		name        string
+		filter      ValueHistoryFilter
+		setupDbMock func(mockDB *db.MockDB)
+		expected    []ValueHistorySample
+	}{
+		{
+			name:   "watch-only total",
+			filter: ValueHistoryFilter{WatchOnly: true},
+			setupDbMock: func(mockDB *db.MockDB) {
+				mockDB.On("GetWatchOnlyHistory", retrieveSince, utils.NetworkMainnet).Return([]db.ValueHistory{
+					{Timestamp: since, TotalValue: massaAmount.FromMAS(val1)},
+					{Timestamp: since.Add(interval), TotalValue: massaAmount.FromMAS(val2)},
+				}, nil).Once()
+			},
+			expected: []ValueHistorySample{
+				{Timestamp: since, Value: &val1},
+				{Timestamp: since.Add(interval), Value: &val2},
+			},
+		},
+		{
+			name:   "single address with breakdown",
+			filter: ValueHistoryFilter{Address: "address1", Breakdown: true},
+			setupDbMock: func(mockDB *db.MockDB) {
+				mockDB.On("GetAddressHistory", db.AddressHistoryFilter{Addresses: []string{"address1"}}, retrieveSince, utils.NetworkMainnet).Return(breakdownEntries, nil).Once()
+			},
+			expected: []ValueHistorySample{
+				{Timestamp: since, Value: &val1, Breakdown: &ValueBreakdown{Balance: 10, RollValue: 100, Deferred: 1}},
+				{Timestamp: since.Add(interval), Value: &val2, Breakdown: &ValueBreakdown{Balance: 20, RollValue: 200, Deferred: 2}},
+			},
+		},
+		{
+			name:   "group without breakdown",
+			filter: ValueHistoryFilter{Group: "main"},
+			setupDbMock: func(mockDB *db.MockDB) {
+				mockDB.On("GetAddressLabels", utils.NetworkMainnet).Return([]db.AddressLabel{
+					{Address: "address1", Group: "main"},
+					{Address: "address2", Group: "other"},
+					{Address: "address3", Group: "main"},
+				}, nil).Once()
+				mockDB.On("GetAddressHistory", db.AddressHistoryFilter{Addresses: []string{"address1", "address3"}}, retrieveSince, utils.NetworkMainnet).Return(breakdownEntries, nil).Once()
+			},
+			expected: []ValueHistorySample{
+				{Timestamp: since, Value: &val1},
+				{Timestamp: since.Add(interval), Value: &val2},
+			},
+		},
+		{
+			name:   "staking addresses total with breakdown",
+			filter: ValueHistoryFilter{Breakdown: true},
+			setupDbMock: func(mockDB *db.MockDB) {
+				mockDB.On("GetAddressHistory", db.AddressHistoryFilter{}, retrieveSince, utils.NetworkMainnet).Return(breakdownEntries[:1], nil).Once()
+			},
+			expected: []ValueHistorySample{
+				{Timestamp: since, Value: &val1, Breakdown: &ValueBreakdown{Balance: 10, RollValue: 100, Deferred: 1}},
+				{Timestamp: since.Add(interval), Value: nil},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDB := db.NewMockDB(t)
+			tc.setupDbMock(mockDB)
+
+			mgr := NewHistoryManager(mockDB, 3600, totValuePostInterval)
+			got, err := mgr.SampleValueHistory(since, 2, utils.NetworkMainnet, interval, tc.filter)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestSampleValueHistoryAggregation(t *testing.T) {
+	totValuePostInterval := int64(60)
+	interval := 3 * time.Duration(totValuePostInterval) * time.Second
+	since := time.Now().Truncate(time.Second).Add(-interval)
+	retrieveSince := since.Add(-time.Duration(totValuePostInterval) * time.Second)
+
+	// the first sample takes no entry, the second one takes the 3 entries recorded during the interval
+	entries := []db.ValueHistory{
+		{Timestamp: since.Add(time.Minute), TotalValue: 100 * massaAmount.MAS},
+		{Timestamp: since.Add(2 * time.Minute), TotalValue: 130 * massaAmount.MAS},
+		{Timestamp: since.Add(3 * time.Minute), TotalValue: 90 * massaAmount.MAS},
+	}
+	open, last, min, max, avg := 100.0, 90.0, 90.0, 130.0, 106.666
+
+	tests := []struct {
+		name        string
+		aggregation Aggregation
+		expected    ValueHistorySample
+	}{
+		{name: "default", expected: ValueHistorySample{Timestamp: since.Add(interval), Value: &last}},
+		{name: "last", aggregation: AggregationLast, expected: ValueHistorySample{Timestamp: since.Add(interval), Value: &last}},
+		{name: "avg", aggregation: AggregationAvg, expected: ValueHistorySample{Timestamp: since.Add(interval), Value: &avg}},
+		{name: "minmax", aggregation: AggregationMinMax, expected: ValueHistorySample{Timestamp: since.Add(interval), Value: &last, Min: &min, Max: &max}},
+		{name: "ohlc", aggregation: AggregationOHLC, expected: ValueHistorySample{Timestamp: since.Add(interval), Value: &last, Open: &open, Min: &min, Max: &max}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDB := db.NewMockDB(t)
+			mockDB.On("GetHistory", retrieveSince, utils.NetworkMainnet).Return(entries, nil).Once()
+
+			mgr := NewHistoryManager(mockDB, 3600, totValuePostInterval)
+			got, err := mgr.SampleValueHistory(since, 2, utils.NetworkMainnet, interval, ValueHistoryFilter{Aggregation: tc.aggregation})
+			assert.NoError(t, err)
+			assert.Equal(t, []ValueHistorySample{{Timestamp: since}, tc.expected}, got)
+		})
+	}
+}
+
+func TestSampleValueHistoryFiat(t *testing.T) {
+	totValuePostInterval := int64(60)
+	interval := 3 * time.Duration(totValuePostInterval) * time.Second
+	since := time.Now().Truncate(time.Second).Add(-interval)
+	retrieveSince := since.Add(-time.Duration(totValuePostInterval) * time.Second)
+	entries := []db.ValueHistory{{Timestamp: since.Add(time.Minute), TotalValue: 100 * massaAmount.MAS}}
+
+	mockDB := db.NewMockDB(t)
+	mgr := NewHistoryManager(mockDB, 3600, totValuePostInterval)
+
+	// no price source is configured
+	_, err := mgr.SampleValueHistory(since, 2, utils.NetworkMainnet, interval, ValueHistoryFilter{Currency: "EUR"})
+	assert.True(t, nodeManagerError.Is(err, nodeManagerError.ErrPriceSourceNotConfigured))
+
+	mgr.SetPriceProvider(stubPriceProvider{since.Add(interval).UTC().Format("2006-01-02"): 0.02})
+	mockDB.On("GetHistory", retrieveSince, utils.NetworkMainnet).Return(entries, nil).Once()
+
+	got, err := mgr.SampleValueHistory(since, 2, utils.NetworkMainnet, interval, ValueHistoryFilter{Currency: "EUR"})
+	require.NoError(t, err)
+	value, fiatValue := 100.0, 2.0
+	assert.Equal(t, []ValueHistorySample{{Timestamp: since}, {Timestamp: since.Add(interval), Value: &value, FiatValue: &fiatValue}}, got)
+}
+
+func TestSampleValueHistoryRollups(t *testing.T) {
+	totValuePostInterval := int64(180)
+	since := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	retrieveSince := since.Add(-time.Duration(totValuePostInterval) * time.Second)
+
+	tests := []struct {
+		name       string
+		interval   time.Duration
+		filter     ValueHistoryFilter
+		series     db.ValueSeries
+		resolution db.RollupResolution
+		rollups    []db.ValueRollup
+		expected   []ValueHistorySample
+	}{
+		{
+			name:       "hourly rollups of the staking addresses",
+			interval:   2 * time.Hour,
+			filter:     ValueHistoryFilter{Aggregation: AggregationOHLC},
+			series:     db.ValueSeriesStaking,
+			resolution: db.RollupHourly,
+			rollups: []db.ValueRollup{
+				{BucketStart: since.Add(-time.Hour), Open: 10 * massaAmount.MAS, Close: 11 * massaAmount.MAS, Min: 9 * massaAmount.MAS, Max: 12 * massaAmount.MAS, Avg: 10 * massaAmount.MAS, Count: 20},
+				{BucketStart: since.Add(time.Hour), Open: 11 * massaAmount.MAS, Close: 15 * massaAmount.MAS, Min: 11 * massaAmount.MAS, Max: 16 * massaAmount.MAS, Avg: 14 * massaAmount.MAS, Count: 20},
+				{BucketStart: since.Add(2 * time.Hour), Open: 15 * massaAmount.MAS, Close: 13 * massaAmount.MAS, Min: 8 * massaAmount.MAS, Max: 15 * massaAmount.MAS, Avg: 12 * massaAmount.MAS, Count: 20},
+			},
+			expected: []ValueHistorySample{
+				{Timestamp: since, Value: ptr(11.0), Open: ptr(10.0), Min: ptr(9.0), Max: ptr(12.0)},
+				{Timestamp: since.Add(2 * time.Hour), Value: ptr(13.0), Open: ptr(11.0), Min: ptr(8.0), Max: ptr(16.0)},
+			},
+		},
+		{
+			name:       "daily rollups of the watch-only addresses",
+			interval:   24 * time.Hour,
+			filter:     ValueHistoryFilter{WatchOnly: true, Aggregation: AggregationAvg},
+			series:     db.ValueSeriesWatchOnly,
+			resolution: db.RollupDaily,
+			rollups: []db.ValueRollup{
+				{BucketStart: since, Open: 10 * massaAmount.MAS, Close: 20 * massaAmount.MAS, Min: 10 * massaAmount.MAS, Max: 20 * massaAmount.MAS, Avg: 15 * massaAmount.MAS, Count: 480},
+				{BucketStart: since.Add(24 * time.Hour), Open: 20 * massaAmount.MAS, Close: 30 * massaAmount.MAS, Min: 20 * massaAmount.MAS, Max: 30 * massaAmount.MAS, Avg: 25 * massaAmount.MAS, Count: 160},
+			},
+			expected: []ValueHistorySample{
+				{Timestamp: since, Value: ptr(15.0)},
+				{Timestamp: since.Add(24 * time.Hour), Value: ptr(25.0)},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDB := db.NewMockDB(t)
+			mockDB.On("GetValueRollups", tc.series, tc.resolution, tc.resolution.BucketStart(retrieveSince), utils.NetworkMainnet).Return(tc.rollups, nil).Once()
+
+			mgr := NewHistoryManager(mockDB, 3600, totValuePostInterval)
+			got, err := mgr.SampleValueHistory(since, 2, utils.NetworkMainnet, tc.interval, tc.filter)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestSampleAddressValueHistoryRollups(t *testing.T) {
+	totValuePostInterval := int64(180)
+	since := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	retrieveSince := since.Add(-time.Duration(totValuePostInterval) * time.Second)
+
+	mockDB := db.NewMockDB(t)
+	mockDB.On("GetAddressLabels", utils.NetworkMainnet).Return([]db.AddressLabel{
+		{Address: "address1", Group: "cold"},
+		{Address: "address2", Group: "hot"},
+	}, nil).Once()
+	filter := db.AddressHistoryFilter{Addresses: []string{"address1"}}
+	mockDB.On("GetAddressValueRollups", filter, db.RollupHourly, db.RollupHourly.BucketStart(retrieveSince), utils.NetworkMainnet).Return([]db.AddressValueRollup{
+		{
+			ValueRollup: db.ValueRollup{BucketStart: since.Add(-time.Hour), Open: 10 * massaAmount.MAS, Close: 11 * massaAmount.MAS, Min: 9 * massaAmount.MAS, Max: 12 * massaAmount.MAS, Avg: 10 * massaAmount.MAS, Count: 20},
+			Balance:     5 * massaAmount.MAS, RollValue: 6 * massaAmount.MAS,
+		},
+		{
+			ValueRollup: db.ValueRollup{BucketStart: since.Add(time.Hour), Open: 11 * massaAmount.MAS, Close: 15 * massaAmount.MAS, Min: 11 * massaAmount.MAS, Max: 16 * massaAmount.MAS, Avg: 14 * massaAmount.MAS, Count: 20},
+			Balance:     8 * massaAmount.MAS, RollValue: 6 * massaAmount.MAS, Deferred: 1 * massaAmount.MAS,
+		},
+	}, nil).Once()
+
+	mgr := NewHistoryManager(mockDB, 3600, totValuePostInterval)
+	got, err := mgr.SampleValueHistory(since, 2, utils.NetworkMainnet, 2*time.Hour, ValueHistoryFilter{Group: "cold", Breakdown: true, Aggregation: AggregationMinMax})
+	assert.NoError(t, err)
+	assert.Equal(t, []ValueHistorySample{
+		{Timestamp: since, Value: ptr(11.0), Min: ptr(9.0), Max: ptr(12.0), Breakdown: &ValueBreakdown{Balance: 5, RollValue: 6}},
+		{Timestamp: since.Add(2 * time.Hour), Value: ptr(15.0), Min: ptr(11.0), Max: ptr(16.0), Breakdown: &ValueBreakdown{Balance: 8, RollValue: 6, Deferred: 1}},
+	}, got)
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func TestGetValueHistoryAnnotations(t *testing.T) {
+	since := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return since.Add(time.Duration(hours) * time.Hour) }
+
+	statuses := []db.NodeStatusHistory{
+		{Timestamp: since.Add(-time.Hour), Status: "on", Network: utils.NetworkMainnet},
+		{Timestamp: at(1), Status: "stopping", Network: utils.NetworkMainnet},
+		{Timestamp: at(2), Status: "off", Network: utils.NetworkMainnet},
+		{Timestamp: at(3), Status: "on", Network: utils.NetworkMainnet},
+		{Timestamp: at(4), Status: "off", Network: utils.NetworkMainnet},
+	}
+	events := []db.AddressEvent{
+		{Timestamp: at(1), Address: "address1", Event: db.AddressEventAdded},
+		{Timestamp: at(2), Address: "watched", Event: db.AddressEventAdded, WatchOnly: true},
+		{Timestamp: at(3), Address: "address2", Event: db.AddressEventRemoved},
+	}
+	rollOps := []db.AddressRollOpHistory{
+		{Address: "address1", RollOpHistory: db.RollOpHistory{Op: "BUY", Amount: 2, OpId: "op1", Timestamp: at(1)}},
+		{Address: "address2", RollOpHistory: db.RollOpHistory{Op: "SELL", Amount: 1, OpId: "op2", Timestamp: at(2)}},
+		{Address: "address1", RollOpHistory: db.RollOpHistory{Op: "TARGET", Amount: 5, OpId: "op3", Timestamp: at(3)}},
+	}
+	end3 := at(3)
+	downtimes := []Downtime{{Start: at(1), End: &end3}, {Start: at(4)}}
+
+	tests := []struct {
+		name        string
+		filter      ValueHistoryFilter
+		setupDbMock func(mockDB *db.MockDB)
+		expected    ValueHistoryAnnotations
+	}{
+		{
+			name: "staking addresses",
+			setupDbMock: func(mockDB *db.MockDB) {
+				mockDB.On("GetRollOpHistorySince", since, utils.NetworkMainnet).Return(rollOps, nil).Once()
+			},
+			expected: ValueHistoryAnnotations{
+				Downtimes:     downtimes,
+				RollOps:       rollOps[:2],
+				AddressEvents: []db.AddressEvent{events[0], events[2]},
+			},
+		},
+		{
+			name:   "watch-only addresses",
+			filter: ValueHistoryFilter{WatchOnly: true},
+			expected: ValueHistoryAnnotations{
+				Downtimes:     downtimes,
+				AddressEvents: events[1:2],
+			},
+		},
+		{
+			name:   "group",
+			filter: ValueHistoryFilter{Group: "main"},
+			setupDbMock: func(mockDB *db.MockDB) {
+				mockDB.On("GetAddressLabels", utils.NetworkMainnet).Return([]db.AddressLabel{
+					{Address: "address1", Group: "other"},
+					{Address: "address2", Group: "main"},
+					{Address: "watched", Group: "main"},
+				}, nil).Once()
+				mockDB.On("GetRollOpHistorySince", since, utils.NetworkMainnet).Return(rollOps, nil).Once()
+			},
+			expected: ValueHistoryAnnotations{
+				Downtimes:     downtimes,
+				RollOps:       rollOps[1:2],
+				AddressEvents: events[1:],
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDB := db.NewMockDB(t)
+			mockDB.On("GetNodeStatusHistory", since, utils.NetworkMainnet).Return(statuses, nil).Once()
+			mockDB.On("GetAddressEvents", since, utils.NetworkMainnet).Return(events, nil).Once()
+			if tc.setupDbMock != nil {
+				tc.setupDbMock(mockDB)
+			}
+
+			mgr := NewHistoryManager(mockDB, 3600, 60)
+			got, err := mgr.GetValueHistoryAnnotations(since, utils.NetworkMainnet, tc.filter)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestDowntimes(t *testing.T) {
+	since := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	// no recorded status: the node is assumed up
+	assert.Nil(t, downtimes(nil, since, utils.NetworkMainnet))
+
+	// down since before the start of the history, and the node runs on the other network
+	statuses := []db.NodeStatusHistory{
+		{Timestamp: since.Add(-time.Hour), Status: "crashed", Network: utils.NetworkMainnet},
+		{Timestamp: since.Add(time.Hour), Status: "on", Network: utils.NetworkBuildnet},
+	}
+	assert.Equal(t, []Downtime{{Start: since}}, downtimes(statuses, since, utils.NetworkMainnet))
+
+	end := since.Add(time.Hour)
+	assert.Equal(t, []Downtime{{Start: since, End: &end}}, downtimes(statuses, since, utils.NetworkBuildnet))
+}
+
+func TestRecordNodeStatus(t *testing.T) {
+	mockDB := db.NewMockDB(t)
+	recorded := make(chan string, 3)
+	mockDB.On("AddNodeStatusHistory", mock.Anything, utils.NetworkMainnet).Return(nil).Run(func(args mock.Arguments) {
+		recorded <- args.String(0)
+	}).Times(3)
+
+	statusDispatcher := nodeStatusPkg.NewNodeStatusDispatcher()
+	mgr := NewHistoryManager(mockDB, 3600, 60)
+	stop := mgr.RecordNodeStatus(statusDispatcher)
+	defer stop()
+
+	statusDispatcher.Publish(nodeStatusPkg.NodeStatusStarting)
+	statusDispatcher.Publish(nodeStatusPkg.NodeStatusStarting) // not recorded twice
+	statusDispatcher.Publish(nodeStatusPkg.NodeStatusOn)
+
+	for _, expected := range []string{"off", "starting", "on"} {
+		select {
+		case status := <-recorded:
+			assert.Equal(t, expected, status)
+		case <-time.After(time.Second):
+			t.Fatalf("status %s not recorded", expected)
+		}
+	}
+}
+
+func TestRecordNodeStatusNetworkSwitch(t *testing.T) {
+	mockDB := db.NewMockDB(t)
+	// the node was running on buildnet, it is recorded as off there before its status on mainnet
+	mockDB.On("AddNodeStatusHistory", "off", utils.NetworkBuildnet).Return(nil).Once()
+	mockDB.On("AddNodeStatusHistory", "starting", utils.NetworkMainnet).Return(nil).Once()
+
+	mgr := NewHistoryManager(mockDB, 3600, 60)
+	mgr.lastStatus = nodeStatusPkg.NodeStatusOn
+	mgr.lastStatusNetwork = utils.NetworkBuildnet
+	mgr.recordNodeStatus(nodeStatusPkg.NodeStatusStarting)
+
+	assert.Equal(t, utils.NetworkMainnet, mgr.lastStatusNetwork)
+}
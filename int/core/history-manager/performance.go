@@ -0,0 +1,395 @@
+package historymanager
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/massalabs/node-manager-plugin/int/db"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	priceProviderPkg "github.com/massalabs/node-manager-plugin/int/price-provider"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+)
+
+const (
+	year = 365 * 24 * time.Hour
+
+	/*
+		A change of value between two records that is not explained by a sweep is taken as staking rewards (or fees)
+		unless it is a loss of more than flowDetectionFloor, or a gain of more than flowDetectionFloor plus what
+		the value would earn at maxAnnualRewardRate during the elapsed time. It is then a deposit or a withdrawal.
+	*/
+	flowDetectionFloor  = massaAmount.MAS / 10
+	maxAnnualRewardRate = 1.0 // 100% a year, far above the staking yield
+)
+
+// CapitalFlowKind tells how a capital flow has been found
+type CapitalFlowKind string
+
+const (
+	CapitalFlowDeposit        CapitalFlowKind = "DEPOSIT"         // value gained too fast to be rewards
+	CapitalFlowWithdrawal     CapitalFlowKind = "WITHDRAWAL"      // value lost
+	CapitalFlowSweep          CapitalFlowKind = "SWEEP"           // withdrawal made by a sweep of the plugin
+	CapitalFlowAddressAdded   CapitalFlowKind = "ADDRESS_ADDED"   // value of an address entering the history of several addresses
+	CapitalFlowAddressRemoved CapitalFlowKind = "ADDRESS_REMOVED" // value of an address leaving the history of several addresses
+)
+
+// CapitalFlow is money entering (positive amount) or leaving (negative amount) the measured addresses
+type CapitalFlow struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Address   string             `json:"address"`
+	Amount    massaAmount.Amount `json:"amount"`
+	Kind      CapitalFlowKind    `json:"kind"`
+}
+
+/*
+Performance measures the return of the capital of one or several addresses over a date range,
+the deposits and withdrawals being excluded from the gains.
+*/
+type Performance struct {
+	Address             string             `json:"address,omitempty"` // empty for the performance of several addresses
+	Since               time.Time          `json:"since"`             // timestamp of the first value record of the range
+	Until               time.Time          `json:"until"`             // timestamp of the last value record of the range
+	StartValue          massaAmount.Amount `json:"start_value"`
+	EndValue            massaAmount.Amount `json:"end_value"`
+	Deposits            massaAmount.Amount `json:"deposits"`
+	Withdrawals         massaAmount.Amount `json:"withdrawals"`           // positive amount
+	Rewards             massaAmount.Amount `json:"rewards"`               // value gained outside of the capital flows, fees deducted
+	TimeWeightedReturn  float64            `json:"time_weighted_return"`  // over the range, 0.05 for 5%
+	MoneyWeightedReturn *float64           `json:"money_weighted_return"` // annualized internal rate of return, nil if it can't be computed
+	AnnualizedYield     *float64           `json:"annualized_yield"`      // annualized time-weighted return, nil if the range is empty
+	AverageRolls        float64            `json:"average_rolls"`         // final rolls averaged over time
+	RewardsPerRoll      *float64           `json:"rewards_per_roll"`      // nil if the addresses had no roll
+	Flows               []CapitalFlow      `json:"flows"`
+
+	// values in the currency of the filter, nil if no currency is requested or a price is unknown
+	StartValueFiat *float64 `json:"start_value_fiat,omitempty"`
+	EndValueFiat   *float64 `json:"end_value_fiat,omitempty"`
+	RewardsFiat    *float64 `json:"rewards_fiat,omitempty"` // sum of the gains of each step valued at the price of their day
+}
+
+// PerformanceReport is the performance of the selected addresses as a whole and of each of them
+type PerformanceReport struct {
+	Total     Performance   `json:"total"`
+	Addresses []Performance `json:"addresses"`
+}
+
+// performanceStep is a value record of the measured addresses with what happened since the previous one
+type performanceStep struct {
+	timestamp time.Time
+	value     massaAmount.Amount
+	flow      massaAmount.Amount // capital flows since the previous step
+	gain      massaAmount.Amount // rewards and fees since the previous step
+	rolls     float64
+}
+
+/*
+GetPerformance computes the performance of the addresses selected by filter from their value history between since and until.
+Deposits and withdrawals are detected from the changes of value and the sweep history of each address.
+*/
+func (mgr *HistoryManager) GetPerformance(since, until time.Time, net utils.Network, filter ValueHistoryFilter) (PerformanceReport, error) {
+	converter, err := mgr.NewConverter(filter.Currency)
+	if err != nil {
+		return PerformanceReport{}, err
+	}
+
+	addressFilter, err := mgr.addressHistoryFilter(net, filter)
+	if err != nil {
+		return PerformanceReport{}, err
+	}
+
+	histories, err := mgr.db.GetAddressValueHistory(addressFilter, since, until, net)
+	if err != nil {
+		return PerformanceReport{}, err
+	}
+
+	report := PerformanceReport{Addresses: []Performance{}}
+	var series [][]performanceStep
+	flows := []CapitalFlow{}
+	// histories are ordered by address
+	for start := 0; start < len(histories); {
+		end := start
+		for end < len(histories) && histories[end].Address == histories[start].Address {
+			end++
+		}
+		addressHistory := histories[start:end]
+		start = end
+
+		var sweeps []db.SweepHistory
+		// watch-only addresses are never swept
+		if !addressHistory[0].WatchOnly {
+			if sweeps, err = mgr.db.GetSweepHistory(addressHistory[0].Address, net); err != nil {
+				return PerformanceReport{}, err
+			}
+		}
+
+		steps, addressFlows := addressSteps(addressHistory, sweeps)
+		performance := computePerformance(steps)
+		performance.valueInFiat(steps, converter)
+		performance.Address = addressHistory[0].Address
+		performance.Flows = addressFlows
+		report.Addresses = append(report.Addresses, performance)
+
+		series = append(series, steps)
+		flows = append(flows, addressFlows...)
+	}
+
+	steps, membershipFlows := mergeSteps(series, report.Addresses)
+	report.Total = computePerformance(steps)
+	report.Total.valueInFiat(steps, converter)
+	report.Total.Flows = append(flows, membershipFlows...)
+	sort.SliceStable(report.Total.Flows, func(i, j int) bool {
+		return report.Total.Flows[i].Timestamp.Before(report.Total.Flows[j].Timestamp)
+	})
+
+	return report, nil
+}
+
+/*
+addressSteps splits the value changes of an address between capital flows and gains.
+A sweep is matched with the first value change it explains at or after its timestamp, as the swept coins may
+leave the balance some time after the sweep operation is sent.
+*/
+func addressSteps(history []db.AddressValueHistory, sweeps []db.SweepHistory) ([]performanceStep, []CapitalFlow) {
+	steps := make([]performanceStep, len(history))
+	flows := []CapitalFlow{}
+
+	// the sweep history is retrieved newest first
+	sweeps = slices.Clone(sweeps)
+	sort.SliceStable(sweeps, func(i, j int) bool { return sweeps[i].Timestamp.Before(sweeps[j].Timestamp) })
+
+	sweepIndex := 0
+	var pendingAmount, pendingFee massaAmount.Amount
+	for i, record := range history {
+		steps[i] = performanceStep{
+			timestamp: record.Timestamp,
+			value:     record.Balance + record.RollValue + record.Deferred,
+			rolls:     float64(record.Rolls),
+		}
+		if i == 0 {
+			// sweeps sent before the first record are already deducted from its value
+			for sweepIndex < len(sweeps) && !sweeps[sweepIndex].Timestamp.After(record.Timestamp) {
+				sweepIndex++
+			}
+			continue
+		}
+
+		for ; sweepIndex < len(sweeps) && !sweeps[sweepIndex].Timestamp.After(record.Timestamp); sweepIndex++ {
+			if !sweeps[sweepIndex].DryRun {
+				pendingAmount += sweeps[sweepIndex].Amount
+				pendingFee += sweeps[sweepIndex].Fee
+			}
+		}
+
+		previous := steps[i-1]
+		change := steps[i].value - previous.value
+		maxRewards := flowDetectionFloor + massaAmount.FromMAS(maxAnnualRewardRate*max(previous.value, 0).MAS()*float64(record.Timestamp.Sub(previous.timestamp))/float64(year))
+		isReward := func(change massaAmount.Amount) bool {
+			return change >= -flowDetectionFloor && change <= maxRewards
+		}
+
+		if pendingAmount > 0 && isReward(change+pendingAmount+pendingFee) {
+			steps[i].flow -= pendingAmount
+			steps[i].gain -= pendingFee
+			flows = append(flows, CapitalFlow{Timestamp: record.Timestamp, Address: record.Address, Amount: -pendingAmount, Kind: CapitalFlowSweep})
+			change += pendingAmount + pendingFee
+			pendingAmount, pendingFee = 0, 0
+		}
+
+		if isReward(change) {
+			steps[i].gain += change
+			continue
+		}
+
+		steps[i].flow += change
+		kind := CapitalFlowDeposit
+		if change < 0 {
+			kind = CapitalFlowWithdrawal
+		}
+		flows = append(flows, CapitalFlow{Timestamp: record.Timestamp, Address: record.Address, Amount: change, Kind: kind})
+	}
+
+	return steps, flows
+}
+
+/*
+mergeSteps sums the steps of several addresses. An address whose history starts after the others brings its value as a capital flow,
+and an address whose history stops before the others takes its value away at the next step.
+*/
+func mergeSteps(series [][]performanceStep, addresses []Performance) ([]performanceStep, []CapitalFlow) {
+	timestamps := []time.Time{}
+	for _, steps := range series {
+		for _, step := range steps {
+			timestamps = append(timestamps, step.timestamp)
+		}
+	}
+	if len(timestamps) == 0 {
+		return nil, nil
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i].Before(timestamps[j]) })
+	timestamps = compactTimestamps(timestamps)
+
+	merged := make([]performanceStep, len(timestamps))
+	flows := []CapitalFlow{}
+	for s, steps := range series {
+		first, last := steps[0], steps[len(steps)-1]
+		index := 0
+		for i, ts := range timestamps {
+			merged[i].timestamp = ts
+			switch {
+			case ts.Before(first.timestamp):
+				continue
+
+			case ts.After(last.timestamp):
+				// the address leaves at the first step after its last record
+				if timestamps[i-1].Equal(last.timestamp) {
+					merged[i].flow -= last.value
+					flows = append(flows, CapitalFlow{Timestamp: ts, Address: addresses[s].Address, Amount: -last.value, Kind: CapitalFlowAddressRemoved})
+				}
+				continue
+			}
+
+			for index+1 < len(steps) && !steps[index+1].timestamp.After(ts) {
+				index++
+			}
+			current := steps[index]
+			merged[i].value += current.value
+			merged[i].rolls += current.rolls
+			if current.timestamp.Equal(ts) {
+				merged[i].flow += current.flow
+				merged[i].gain += current.gain
+			}
+
+			if ts.Equal(first.timestamp) && i > 0 {
+				merged[i].flow += first.value
+				flows = append(flows, CapitalFlow{Timestamp: ts, Address: addresses[s].Address, Amount: first.value, Kind: CapitalFlowAddressAdded})
+			}
+		}
+	}
+
+	return merged, flows
+}
+
+func compactTimestamps(timestamps []time.Time) []time.Time {
+	result := timestamps[:1]
+	for _, ts := range timestamps[1:] {
+		if !ts.Equal(result[len(result)-1]) {
+			result = append(result, ts)
+		}
+	}
+	return result
+}
+
+// computePerformance computes the performance metrics of chronological steps
+func computePerformance(steps []performanceStep) Performance {
+	performance := Performance{Flows: []CapitalFlow{}}
+	if len(steps) == 0 {
+		return performance
+	}
+
+	first, last := steps[0], steps[len(steps)-1]
+	performance.Since = first.timestamp
+	performance.Until = last.timestamp
+	performance.StartValue = first.value
+	performance.EndValue = last.value
+
+	growth := 1.0
+	rollsTime := 0.0
+	for i := 1; i < len(steps); i++ {
+		step, previous := steps[i], steps[i-1]
+		if step.flow > 0 {
+			performance.Deposits += step.flow
+		} else {
+			performance.Withdrawals -= step.flow
+		}
+		performance.Rewards += step.gain
+
+		// the flows are assumed to happen at the end of the sub-period, a sub-period without capital has no return
+		if previous.value > 0 {
+			growth *= float64(step.value-step.flow) / float64(previous.value)
+		}
+
+		rollsTime += previous.rolls * float64(step.timestamp.Sub(previous.timestamp))
+	}
+	performance.TimeWeightedReturn = growth - 1
+
+	span := last.timestamp.Sub(first.timestamp)
+	performance.AverageRolls = first.rolls
+	if span > 0 {
+		performance.AverageRolls = rollsTime / float64(span)
+
+		annualizedYield := math.Pow(growth, float64(year)/float64(span)) - 1
+		performance.AnnualizedYield = &annualizedYield
+		performance.MoneyWeightedReturn = internalRateOfReturn(steps)
+	}
+
+	if performance.AverageRolls > 0 {
+		rewardsPerRoll := performance.Rewards.MAS() / performance.AverageRolls
+		performance.RewardsPerRoll = &rewardsPerRoll
+	}
+
+	return performance
+}
+
+// valueInFiat values the start and end values and the rewards of the performance of the steps with converter
+func (performance *Performance) valueInFiat(steps []performanceStep, converter *priceProviderPkg.Converter) {
+	if converter == nil || len(steps) == 0 {
+		return
+	}
+
+	first, last := steps[0], steps[len(steps)-1]
+	performance.StartValueFiat = converter.Convert(first.timestamp, first.value.MAS())
+	performance.EndValueFiat = converter.Convert(last.timestamp, last.value.MAS())
+
+	rewards := 0.0
+	for _, step := range steps[1:] {
+		if step.gain == 0 {
+			continue
+		}
+		gain := converter.Convert(step.timestamp, step.gain.MAS())
+		if gain == nil {
+			return
+		}
+		rewards += *gain
+	}
+	performance.RewardsFiat = &rewards
+}
+
+/*
+internalRateOfReturn finds the annual rate at which the start value and the capital flows grow into the end value.
+It returns nil if there is no such rate between -99% and 1000000%.
+*/
+func internalRateOfReturn(steps []performanceStep) *float64 {
+	end := steps[len(steps)-1].timestamp
+	futureValue := func(rate float64) float64 {
+		grow := func(amount massaAmount.Amount, from time.Time) float64 {
+			return amount.MAS() * math.Pow(1+rate, float64(end.Sub(from))/float64(year))
+		}
+
+		result := grow(steps[0].value, steps[0].timestamp)
+		for _, step := range steps[1:] {
+			result += grow(step.flow, step.timestamp)
+		}
+		return result - steps[len(steps)-1].value.MAS()
+	}
+
+	low, high := -0.99, 10000.0
+	lowValue, highValue := futureValue(low), futureValue(high)
+	if math.IsNaN(lowValue) || math.IsNaN(highValue) || (lowValue > 0) == (highValue > 0) {
+		return nil
+	}
+
+	for range 200 {
+		mid := (low + high) / 2
+		midValue := futureValue(mid)
+		if (midValue > 0) == (lowValue > 0) {
+			low, lowValue = mid, midValue
+		} else {
+			high = mid
+		}
+	}
+
+	rate := (low + high) / 2
+	return &rate
+}
@@ -0,0 +1,121 @@
+package historymanager
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/massalabs/node-manager-plugin/int/db"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+performanceFixture is a fixture of test_data/performance: the value and sweep history of some addresses
+and the performance expected for them, computed independently of this package.
+*/
+type performanceFixture struct {
+	Description string `json:"description"`
+	Filter      struct {
+		Address   string `json:"address"`
+		Group     string `json:"group"`
+		WatchOnly bool   `json:"watch_only"`
+	} `json:"filter"`
+	Labels    []db.AddressLabel            `json:"labels"`    // returned by the database when the filter selects a group
+	Addresses []string                     `json:"addresses"` // addresses the value history is retrieved for
+	Since     time.Time                    `json:"since"`
+	Until     time.Time                    `json:"until"`
+	History   []db.AddressValueHistory     `json:"history"` // ordered by address then chronologically
+	Sweeps    map[string][]db.SweepHistory `json:"sweeps"`
+	Expected  PerformanceReport            `json:"expected"`
+}
+
+func TestGetPerformance(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("test_data", "performance", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			content, err := os.ReadFile(file)
+			require.NoError(t, err)
+			var fixture performanceFixture
+			require.NoError(t, json.Unmarshal(content, &fixture))
+
+			mockDB := db.NewMockDB(t)
+			if fixture.Filter.Group != "" {
+				mockDB.On("GetAddressLabels", utils.NetworkMainnet).Return(fixture.Labels, nil).Once()
+			}
+			addressFilter := db.AddressHistoryFilter{Addresses: fixture.Addresses, WatchOnly: fixture.Filter.WatchOnly}
+			mockDB.On("GetAddressValueHistory", addressFilter, fixture.Since, fixture.Until, utils.NetworkMainnet).Return(fixture.History, nil).Once()
+			for address, sweeps := range fixture.Sweeps {
+				mockDB.On("GetSweepHistory", address, utils.NetworkMainnet).Return(sweeps, nil).Once()
+			}
+
+			mgr := NewHistoryManager(mockDB, 3600, 60)
+			filter := ValueHistoryFilter{Address: fixture.Filter.Address, Group: fixture.Filter.Group, WatchOnly: fixture.Filter.WatchOnly}
+			got, err := mgr.GetPerformance(fixture.Since, fixture.Until, utils.NetworkMainnet, filter)
+			require.NoError(t, err, fixture.Description)
+
+			assertPerformance(t, fixture.Expected.Total, got.Total)
+			require.Len(t, got.Addresses, len(fixture.Expected.Addresses))
+			for i, expected := range fixture.Expected.Addresses {
+				assertPerformance(t, expected, got.Addresses[i])
+			}
+		})
+	}
+}
+
+func assertPerformance(t *testing.T, expected, got Performance) {
+	t.Helper()
+	const delta = 1e-6
+
+	assert.Equal(t, expected.Address, got.Address)
+	assert.True(t, expected.Since.Equal(got.Since), "since: expected %s, got %s", expected.Since, got.Since)
+	assert.True(t, expected.Until.Equal(got.Until), "until: expected %s, got %s", expected.Until, got.Until)
+	// amounts are exact, only the ratios are floating point numbers
+	assert.Equal(t, expected.StartValue, got.StartValue, "start value")
+	assert.Equal(t, expected.EndValue, got.EndValue, "end value")
+	assert.Equal(t, expected.Deposits, got.Deposits, "deposits")
+	assert.Equal(t, expected.Withdrawals, got.Withdrawals, "withdrawals")
+	assert.Equal(t, expected.Rewards, got.Rewards, "rewards")
+	assert.InDelta(t, expected.TimeWeightedReturn, got.TimeWeightedReturn, delta, "time-weighted return")
+	assert.InDelta(t, expected.AverageRolls, got.AverageRolls, delta, "average rolls")
+
+	for name, values := range map[string][2]*float64{
+		"money-weighted return": {expected.MoneyWeightedReturn, got.MoneyWeightedReturn},
+		"annualized yield":      {expected.AnnualizedYield, got.AnnualizedYield},
+		"rewards per roll":      {expected.RewardsPerRoll, got.RewardsPerRoll},
+	} {
+		if values[0] == nil {
+			assert.Nil(t, values[1], name)
+			continue
+		}
+		if assert.NotNil(t, values[1], name) {
+			assert.InDelta(t, *values[0], *values[1], delta, name)
+		}
+	}
+
+	require.Len(t, got.Flows, len(expected.Flows))
+	for i, flow := range expected.Flows {
+		assert.True(t, flow.Timestamp.Equal(got.Flows[i].Timestamp), "flow %d timestamp: expected %s, got %s", i, flow.Timestamp, got.Flows[i].Timestamp)
+		assert.Equal(t, flow.Address, got.Flows[i].Address)
+		assert.Equal(t, flow.Kind, got.Flows[i].Kind)
+		assert.Equal(t, flow.Amount, got.Flows[i].Amount, "flow %d amount", i)
+	}
+}
+
+func TestGetPerformanceWithoutHistory(t *testing.T) {
+	mockDB := db.NewMockDB(t)
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+	mockDB.On("GetAddressValueHistory", db.AddressHistoryFilter{}, since, until, utils.NetworkBuildnet).Return(nil, nil).Once()
+
+	mgr := NewHistoryManager(mockDB, 3600, 60)
+	got, err := mgr.GetPerformance(since, until, utils.NetworkBuildnet, ValueHistoryFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, PerformanceReport{Total: Performance{Flows: []CapitalFlow{}}, Addresses: []Performance{}}, got)
+}
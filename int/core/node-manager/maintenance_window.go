@@ -0,0 +1,189 @@
+package nodeManager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	nodeAPI "github.com/massalabs/node-manager-plugin/int/node-api"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+// drawSafetyMargin is the time left to the node after one of its draws to produce the block or the endorsement
+const drawSafetyMargin = 2 * time.Second
+
+// DrawsProvider gives the upcoming block and endorsement draws of the staking addresses of the node
+type DrawsProvider interface {
+	GetNextDraws() ([]nodeAPI.AddressDraws, error)
+}
+
+/*
+StopNodeWhenSafe stops the node at the beginning of the next gap between draws that is long enough for a maintenance.
+If the node can be stopped right away, it is stopped immediately and the zero time is returned.
+Otherwise the stop is scheduled and the time at which the node will be stopped is returned.
+*/
+func (nodeMana *NodeManager) StopNodeWhenSafe() (time.Time, error) {
+	nodeMana.mu.Lock()
+	scheduledStopAt, err := nodeMana.scheduledStop()
+	nodeMana.mu.Unlock()
+	if err != nil || !scheduledStopAt.IsZero() {
+		return scheduledStopAt, err
+	}
+
+	// the draws are retrieved from the node API without holding the lock
+	now := time.Now()
+	stopAt := nodeMana.nextSafeWindow(now)
+
+	nodeMana.mu.Lock()
+
+	// the node may have been stopped or its stop scheduled in the meantime
+	scheduledStopAt, err = nodeMana.scheduledStop()
+	if err != nil || !scheduledStopAt.IsZero() {
+		nodeMana.mu.Unlock()
+		return scheduledStopAt, err
+	}
+
+	if !stopAt.After(now) {
+		nodeMana.mu.Unlock()
+		return time.Time{}, nodeMana.StopNode()
+	}
+
+	nodeMana.scheduledStopAt = stopAt
+	ctx := nodeMana.asyncTaskCtx
+	nodeMana.mu.Unlock()
+
+	logger.Infof("Massa node stop is delayed to %s to avoid missing a draw", stopAt.Format(time.RFC3339))
+
+	go func() {
+		if !waitUntil(ctx, stopAt) { // node has been stopped or restarted in the meantime
+			return
+		}
+		if err := nodeMana.StopNode(); err != nil {
+			logger.Errorf("Failed to stop node at scheduled time: %v", err)
+		}
+	}()
+
+	return stopAt, nil
+}
+
+/*
+scheduledStop returns the time of the scheduled stop of the node, zero if none is scheduled,
+and an error if the node is not running. It must be called with the lock held.
+*/
+func (nodeMana *NodeManager) scheduledStop() (time.Time, error) {
+	if !IsRunning(nodeMana.status) {
+		return time.Time{}, fmt.Errorf("massa node process is already stopped")
+	}
+
+	if !nodeMana.scheduledStopAt.IsZero() {
+		logger.Infof("massa node stop is already scheduled at %s", nodeMana.scheduledStopAt.Format(time.RFC3339))
+	}
+
+	return nodeMana.scheduledStopAt, nil
+}
+
+/*
+waitUntilSafeWindow waits for the next time at which the node can be stopped without missing a draw.
+It returns false if ctx is done before.
+*/
+func (nodeMana *NodeManager) waitUntilSafeWindow(ctx context.Context) bool {
+	stopAt := nodeMana.nextSafeWindow(time.Now())
+	if stopAt.After(time.Now()) {
+		logger.Infof("Massa node restart is delayed to %s to avoid missing a draw", stopAt.Format(time.RFC3339))
+	}
+
+	return waitUntil(ctx, stopAt)
+}
+
+// waitUntil waits until t, it returns false if ctx is done before
+func waitUntil(ctx context.Context, t time.Time) bool {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+/*
+nextSafeWindow retrieves the next draws of the staking addresses and returns the time at which the node can be stopped.
+A negative max maintenance delay disables the wait for a gap between draws.
+It calls the node API, so it must not be called with the lock held.
+*/
+func (nodeMana *NodeManager) nextSafeWindow(now time.Time) time.Time {
+	if nodeMana.drawsProvider == nil || nodeMana.config.MaxMaintenanceDelay < 0 {
+		return now
+	}
+
+	addressesDraws, err := nodeMana.drawsProvider.GetNextDraws()
+	if err != nil {
+		logger.Warnf("could not retrieve next draws of staking addresses, node maintenance will not be delayed: %v", err)
+		return now
+	}
+
+	drawTimes := []time.Time{}
+	for _, addressDraws := range addressesDraws {
+		for _, draw := range addressDraws.Draws {
+			drawTimes = append(drawTimes, draw.Time)
+		}
+	}
+
+	return nextSafeGap(
+		now,
+		drawTimes,
+		time.Duration(nodeMana.config.MaintenanceGap)*time.Second,
+		time.Duration(nodeMana.config.MaxMaintenanceDelay)*time.Second,
+	)
+}
+
+/*
+nextSafeGap returns the start of the first gap of at least gap duration between now and the draws.
+Slots after the last known draw are considered free.
+If no such gap starts before now + maxDelay, the start of the largest gap found in this horizon is returned.
+*/
+func nextSafeGap(now time.Time, drawTimes []time.Time, gap, maxDelay time.Duration) time.Time {
+	sorted := make([]time.Time, 0, len(drawTimes))
+	for _, drawTime := range drawTimes {
+		if drawTime.After(now) {
+			sorted = append(sorted, drawTime)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Before(sorted[j])
+	})
+
+	deadline := now.Add(maxDelay)
+	gapStart := now
+	bestStart := now
+	bestLength := time.Duration(-1)
+
+	for _, drawTime := range sorted {
+		if gapStart.After(deadline) {
+			return bestStart
+		}
+
+		length := drawTime.Sub(gapStart)
+		if length >= gap {
+			return gapStart
+		}
+
+		if length > bestLength {
+			bestStart = gapStart
+			bestLength = length
+		}
+
+		if next := drawTime.Add(drawSafetyMargin); next.After(gapStart) {
+			gapStart = next
+		}
+	}
+
+	if gapStart.After(deadline) {
+		return bestStart
+	}
+
+	return gapStart
+}
@@ -0,0 +1,95 @@
+package nodeManager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/massalabs/node-manager-plugin/int/config"
+	nodeAPI "github.com/massalabs/node-manager-plugin/int/node-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextSafeGap(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time {
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+
+	tests := []struct {
+		name      string
+		drawTimes []time.Time
+		gap       time.Duration
+		maxDelay  time.Duration
+		expected  time.Time
+	}{
+		{
+			name:      "no draws, stop now",
+			drawTimes: nil,
+			gap:       5 * time.Minute,
+			maxDelay:  30 * time.Minute,
+			expected:  now,
+		},
+		{
+			name:      "next draw far enough, stop now",
+			drawTimes: []time.Time{at(600)},
+			gap:       5 * time.Minute,
+			maxDelay:  30 * time.Minute,
+			expected:  now,
+		},
+		{
+			name:      "past draws are ignored",
+			drawTimes: []time.Time{at(-10), at(-100)},
+			gap:       5 * time.Minute,
+			maxDelay:  30 * time.Minute,
+			expected:  now,
+		},
+		{
+			name:      "draw too close, stop after it",
+			drawTimes: []time.Time{at(60)},
+			gap:       5 * time.Minute,
+			maxDelay:  30 * time.Minute,
+			expected:  at(60).Add(drawSafetyMargin),
+		},
+		{
+			name:      "unsorted draws, stop in first large gap",
+			drawTimes: []time.Time{at(1000), at(120), at(60)},
+			gap:       5 * time.Minute,
+			maxDelay:  30 * time.Minute,
+			expected:  at(120).Add(drawSafetyMargin),
+		},
+		{
+			name:      "no gap before max delay, stop at start of largest gap",
+			drawTimes: []time.Time{at(30), at(200), at(250), at(400)},
+			gap:       5 * time.Minute,
+			maxDelay:  5 * time.Minute,
+			expected:  at(30).Add(drawSafetyMargin),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, nextSafeGap(now, test.drawTimes, test.gap, test.maxDelay))
+		})
+	}
+}
+
+type drawsProviderStub []nodeAPI.AddressDraws
+
+func (d drawsProviderStub) GetNextDraws() ([]nodeAPI.AddressDraws, error) {
+	return d, nil
+}
+
+func TestNextSafeWindow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	draws := drawsProviderStub{{Address: "test_address", Draws: []nodeAPI.Draw{{Time: now.Add(time.Minute)}}}}
+
+	nodeMana := &NodeManager{
+		config:        &config.PluginConfig{MaintenanceGap: 300, MaxMaintenanceDelay: 1800},
+		drawsProvider: draws,
+	}
+	assert.Equal(t, now.Add(time.Minute).Add(drawSafetyMargin), nodeMana.nextSafeWindow(now))
+
+	// a negative max delay disables the wait for a gap between draws
+	nodeMana.config.MaxMaintenanceDelay = -1
+	assert.Equal(t, now, nodeMana.nextSafeWindow(now))
+}
@@ -16,6 +16,7 @@ import (
 type INodeManager interface {
 	StartNode(isMainnet bool, pwd string) error
 	StopNode() error
+	StopNodeWhenSafe() (time.Time, error)
 	Logs(isMainnet bool) (string, error)
 
 	GetStatus() nodeStatusPkg.NodeStatus
@@ -31,9 +32,12 @@ type NodeManager struct {
 	processExitedChan <-chan nodeDriver.ProcessExitedResult
 	nodeMonitor       NodeMonitoring
 	NodeLogManager    *NodeLogManager
+	asyncTaskCtx      context.Context
 	cancelAsyncTask   context.CancelFunc // cancel function to stop node subprocess and all concurrent tasks
 	nodeDriver        nodeDriver.NodeDriver
 	statusDispatcher  nodeStatusPkg.NodeStatusDispatcher
+	drawsProvider     DrawsProvider
+	scheduledStopAt   time.Time // time at which a delayed stop will occur, zero if no stop is scheduled
 }
 
 // NewNodeManager creates a new NodeManager instance
@@ -42,6 +46,7 @@ func NewNodeManager(
 	nodeMonitor NodeMonitoring,
 	nodeDriver nodeDriver.NodeDriver,
 	statusDispatcher nodeStatusPkg.NodeStatusDispatcher,
+	drawsProvider DrawsProvider,
 ) (*NodeManager, error) {
 	nodeLogManager, err := NewNodeLogManager(config)
 	if err != nil {
@@ -55,6 +60,7 @@ func NewNodeManager(
 		nodeMonitor:      nodeMonitor,
 		nodeDriver:       nodeDriver,
 		statusDispatcher: statusDispatcher,
+		drawsProvider:    drawsProvider,
 	}, nil
 }
 
@@ -91,17 +97,25 @@ func (nodeMana *NodeManager) StartNode(isMainnet bool, pwd string) error {
 	config.GlobalPluginInfo.SetIsMainnet(isMainnet)
 	config.GlobalPluginInfo.SetPwd(pwd)
 
+	// the tasks of the previous session, like a scheduled stop, must not act on the new one
+	if nodeMana.cancelAsyncTask != nil {
+		nodeMana.cancelAsyncTask()
+	}
+	nodeMana.scheduledStopAt = time.Time{}
+
 	ctx, cancel := context.WithCancel(context.Background())
+	nodeMana.asyncTaskCtx = ctx
 	nodeMana.cancelAsyncTask = cancel
 
 	go nodeMana.HandleBootstrapping(ctx)
 
 	// launch node stopped handler goroutine
-	go nodeMana.handleNodeStopped()
+	go nodeMana.handleNodeStopped(nodeMana.processExitedChan, cancel)
 
 	return nil
 }
 
+// StopNode stops the massa node process immediately
 func (nodeMana *NodeManager) StopNode() error {
 	nodeMana.mu.Lock()
 	defer nodeMana.mu.Unlock()
@@ -117,6 +131,7 @@ func (nodeMana *NodeManager) StopNode() error {
 	}
 
 	nodeMana.setStatus(nodeStatusPkg.NodeStatusStopping)
+	nodeMana.scheduledStopAt = time.Time{}
 
 	logger.Infof("Stopping Massa node process...")
 	nodeMana.cancelAsyncTask()
@@ -191,6 +206,11 @@ func (nodeMana *NodeManager) handleNodeDesync(ctx context.Context) {
 				// Wait for the restart cooldown
 				time.Sleep(time.Duration(nodeMana.config.RestartCooldown) * time.Second)
 
+				// Wait for a gap between the draws, the restart is not urgent
+				if !nodeMana.waitUntilSafeWindow(ctx) {
+					return
+				}
+
 				// Stop the node
 				logger.Info("Auto-restarting node due to desync")
 				if err := nodeMana.StopNode(); err != nil {
@@ -219,11 +239,17 @@ func (nodeMana *NodeManager) handleNodeDesync(ctx context.Context) {
 handleNodeStoped wait for the massa node process to exit.
 If the process has exited with error, it handle it.
 It update the status to NodeStatusOff or NodeStatusCrashed
+The tasks of the session, including a scheduled stop, are cancelled.
 */
-func (nodeMana *NodeManager) handleNodeStopped() {
-	result := <-nodeMana.processExitedChan // Wait for the command to exit
+func (nodeMana *NodeManager) handleNodeStopped(processExitedChan <-chan nodeDriver.ProcessExitedResult, cancelAsyncTask context.CancelFunc) {
+	result := <-processExitedChan // Wait for the command to exit
 	status := nodeStatusPkg.NodeStatusOff
 
+	nodeMana.mu.Lock()
+	cancelAsyncTask()
+	nodeMana.scheduledStopAt = time.Time{}
+	nodeMana.mu.Unlock()
+
 	if result.Err != nil && !isUserInterrupted(result.Err) {
 		logger.Errorf("massa node process exited with error: %v", result.Err)
 		status = nodeStatusPkg.NodeStatusCrashed
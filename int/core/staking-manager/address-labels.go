@@ -0,0 +1,134 @@
+package stakingManager
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/massalabs/node-manager-plugin/int/config"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+// AddressGroup sums the data of the staking and watch-only addresses of a group
+type AddressGroup struct {
+	Name             string             `json:"name"`
+	Addresses        []string           `json:"addresses"`
+	FinalRolls       uint64             `json:"final_roll_count"`
+	CandidateRolls   uint64             `json:"candidate_roll_count"`
+	ActiveRolls      uint64             `json:"active_roll_count"`
+	FinalBalance     massaAmount.Amount `json:"final_balance"`
+	CandidateBalance massaAmount.Amount `json:"candidate_balance"`
+	DeferredCredits  massaAmount.Amount `json:"deferred_credits"`
+	TotalValue       massaAmount.Amount `json:"total_value"` // final balance, final rolls value and deferred credits, as in the value history
+}
+
+// SetAddressLabel sets the label, tags and group of a staking or watch-only address
+func (s *stakingManager) SetAddressLabel(label dbPkg.AddressLabel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ramAddressListContains(label.Address) && !s.watchOnlyListContains(label.Address) {
+		return fmt.Errorf("address %s not found in staking or watch-only addresses", label.Address)
+	}
+
+	label.Label = strings.TrimSpace(label.Label)
+	label.Group = strings.TrimSpace(label.Group)
+
+	tags := []string{}
+	for _, tag := range label.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	label.Tags = tags
+
+	if err := s.db.SetAddressLabel(label, config.GlobalPluginInfo.GetNetwork()); err != nil {
+		return fmt.Errorf("failed to save label of address %s: %w", label.Address, err)
+	}
+
+	return nil
+}
+
+// DeleteAddressLabel deletes the label, tags and group of an address
+func (s *stakingManager) DeleteAddressLabel(address string) error {
+	if err := s.db.DeleteAddressLabel(address, config.GlobalPluginInfo.GetNetwork()); err != nil {
+		return fmt.Errorf("failed to delete label of address %s: %w", address, err)
+	}
+
+	return nil
+}
+
+// GetAddressLabels returns the labels, tags and groups of the addresses of the current network
+func (s *stakingManager) GetAddressLabels() ([]dbPkg.AddressLabel, error) {
+	labels, err := s.db.GetAddressLabels(config.GlobalPluginInfo.GetNetwork())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get address labels: %w", err)
+	}
+
+	return labels, nil
+}
+
+// GetAddressGroups returns the sums of each group of addresses, sorted by group name
+func (s *stakingManager) GetAddressGroups() ([]AddressGroup, error) {
+	labels, err := s.GetAddressLabels()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sumAddressGroups(labels), nil
+}
+
+/*
+sumAddressGroups sums the data of the addresses of each group from the ram lists. The caller must hold s.mu.
+Addresses that are labeled but no longer staked or watched are not counted.
+*/
+func (s *stakingManager) sumAddressGroups(labels []dbPkg.AddressLabel) []AddressGroup {
+	addresses := append(copyAddresses(s.stakingAddresses), s.watchOnlyAddresses...)
+
+	groups := []AddressGroup{}
+	for _, label := range labels {
+		if label.Group == "" {
+			continue
+		}
+
+		addressIndex := slices.IndexFunc(addresses, func(addr StakingAddress) bool { return addr.Address == label.Address })
+		if addressIndex == -1 {
+			continue
+		}
+		address := addresses[addressIndex]
+
+		groupIndex := slices.IndexFunc(groups, func(group AddressGroup) bool { return group.Name == label.Group })
+		if groupIndex == -1 {
+			groups = append(groups, AddressGroup{Name: label.Group, Addresses: []string{}})
+			groupIndex = len(groups) - 1
+		}
+		group := &groups[groupIndex]
+
+		value := s.addressValue(address)
+
+		group.Addresses = append(group.Addresses, address.Address)
+		group.FinalRolls += address.FinalRolls
+		group.CandidateRolls += address.CandidateRolls
+		group.ActiveRolls += address.ActiveRolls
+		group.FinalBalance += address.FinalBalance
+		group.CandidateBalance += address.CandidateBalance
+		group.DeferredCredits += value.Deferred
+		group.TotalValue += value.Balance + value.RollValue + value.Deferred
+	}
+
+	slices.SortFunc(groups, func(a, b AddressGroup) int { return strings.Compare(a.Name, b.Name) })
+	return groups
+}
+
+// labelFromNickname sets the label of a new staking address to its wallet nickname, unless it already has one
+func (s *stakingManager) labelFromNickname(address, nickname string) {
+	if err := s.db.InitAddressLabel(address, nickname, config.GlobalPluginInfo.GetNetwork()); err != nil {
+		logger.Warnf("failed to label address %s with its wallet nickname %s: %v", address, nickname, err)
+	}
+}
@@ -0,0 +1,115 @@
+package stakingManager
+
+import (
+	"testing"
+
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetAddressLabel(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	tests := []struct {
+		name             string
+		label            dbPkg.AddressLabel
+		setupMocks       func(*dbPkg.MockDB)
+		expectedErrorMsg string
+	}{
+		{
+			name:  "Should save a trimmed label with unique tags",
+			label: dbPkg.AddressLabel{Address: "test_address", Label: " main ", Tags: []string{"hot", " hot", "", "team"}, Group: "treasury "},
+			setupMocks: func(mockDB *dbPkg.MockDB) {
+				mockDB.On("SetAddressLabel", dbPkg.AddressLabel{
+					Address: "test_address",
+					Label:   "main",
+					Tags:    []string{"hot", "team"},
+					Group:   "treasury",
+				}, utils.NetworkMainnet).Return(nil).Once()
+			},
+		},
+		{
+			name:  "Should label a watch-only address",
+			label: dbPkg.AddressLabel{Address: "watched_address", Label: "cold"},
+			setupMocks: func(mockDB *dbPkg.MockDB) {
+				mockDB.On("SetAddressLabel", dbPkg.AddressLabel{Address: "watched_address", Label: "cold", Tags: []string{}}, utils.NetworkMainnet).Return(nil).Once()
+			},
+		},
+		{
+			name:             "Should fail for an unknown address",
+			label:            dbPkg.AddressLabel{Address: "other_address", Label: "other"},
+			setupMocks:       func(_ *dbPkg.MockDB) {},
+			expectedErrorMsg: "address other_address not found in staking or watch-only addresses",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := dbPkg.NewMockDB(t)
+			tt.setupMocks(mockDB)
+
+			sm := &stakingManager{
+				db:                 mockDB,
+				stakingAddresses:   []StakingAddress{{Address: "test_address"}},
+				watchOnlyAddresses: []StakingAddress{{Address: "watched_address", WatchOnly: true}},
+			}
+
+			err := sm.SetAddressLabel(tt.label)
+			if tt.expectedErrorMsg != "" {
+				assert.ErrorContains(t, err, tt.expectedErrorMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGetAddressGroups(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	mockDB := dbPkg.NewMockDB(t)
+	mockDB.On("GetAddressLabels", utils.NetworkMainnet).Return([]dbPkg.AddressLabel{
+		{Address: "address_1", Group: "main"},
+		{Address: "address_2", Label: "no group"},
+		{Address: "address_3", Group: "main"},
+		{Address: "removed_address", Group: "main"},
+		{Address: "watched_address", Group: "treasury"},
+	}, nil).Once()
+
+	sm := &stakingManager{
+		db:            mockDB,
+		miscellaneous: Miscellaneous{RollPrice: 100 * massaAmount.MAS},
+		stakingAddresses: []StakingAddress{
+			{Address: "address_1", FinalRolls: 2, CandidateRolls: 3, ActiveRolls: 1, FinalBalance: 100 * massaAmount.MAS, CandidateBalance: 50 * massaAmount.MAS, DeferredCredits: []DeferredCredit{{Amount: 50 * massaAmount.MAS}}},
+			{Address: "address_2", FinalRolls: 10, FinalBalance: 1000 * massaAmount.MAS},
+			{Address: "address_3", FinalRolls: 10, CandidateRolls: 10, ActiveRolls: 10, FinalBalance: 100 * massaAmount.MAS, CandidateBalance: 100 * massaAmount.MAS},
+		},
+		watchOnlyAddresses: []StakingAddress{{Address: "watched_address", FinalBalance: 500 * massaAmount.MAS, WatchOnly: true}},
+	}
+
+	groups, err := sm.GetAddressGroups()
+	assert.NoError(t, err)
+	assert.Equal(t, []AddressGroup{
+		{
+			Name:             "main",
+			Addresses:        []string{"address_1", "address_3"},
+			FinalRolls:       12,
+			CandidateRolls:   13,
+			ActiveRolls:      11,
+			FinalBalance:     200 * massaAmount.MAS,
+			CandidateBalance: 150 * massaAmount.MAS,
+			DeferredCredits:  50 * massaAmount.MAS,
+			TotalValue:       1450 * massaAmount.MAS,
+		},
+		{
+			Name:         "treasury",
+			Addresses:    []string{"watched_address"},
+			FinalBalance: 500 * massaAmount.MAS,
+			TotalValue:   500 * massaAmount.MAS,
+		},
+	}, groups)
+}
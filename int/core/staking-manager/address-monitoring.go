@@ -3,14 +3,14 @@ package stakingManager
 import (
 	"context"
 	"fmt"
-	"math"
 	"slices"
-	"strconv"
 	"time"
 
 	configPkg "github.com/massalabs/node-manager-plugin/int/config"
 	"github.com/massalabs/node-manager-plugin/int/db"
 	errorPkg "github.com/massalabs/node-manager-plugin/int/error"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	massaTime "github.com/massalabs/node-manager-plugin/int/massa-time"
 	"github.com/massalabs/node-manager-plugin/int/utils"
 	"github.com/massalabs/station/pkg/logger"
 )
@@ -22,25 +22,51 @@ func (s *stakingManager) fetchMiscellaneousData() error {
 		return fmt.Errorf("failed to get node status: %v", err)
 	}
 
-	minimalFees, err := strconv.ParseFloat(*status.MinimalFees, 32)
+	minimalFees, err := massaAmount.Parse(*status.MinimalFees)
 	if err != nil {
 		return fmt.Errorf("failed to parse minimal fees: %v", err)
 	}
 
-	s.miscellaneous.MinimalFees = float32(minimalFees)
+	s.miscellaneous.MinimalFees = minimalFees
 
 	if s.miscellaneous.RollPrice == 0 {
-		rollPrice, err := strconv.ParseFloat(*status.Config.RollPrice, 32)
+		rollPrice, err := massaAmount.Parse(*status.Config.RollPrice)
 		if err != nil {
 			return fmt.Errorf("failed to parse roll price: %v", err)
 		}
 
-		s.miscellaneous.RollPrice = float32(rollPrice)
+		s.miscellaneous.RollPrice = rollPrice
+
+		if status.Config.BlockReward != nil {
+			blockReward, err := massaAmount.Parse(*status.Config.BlockReward)
+			if err != nil {
+				return fmt.Errorf("failed to parse block reward: %v", err)
+			}
+
+			s.miscellaneous.BlockReward = blockReward
+		}
 	}
 
 	return nil
 }
 
+// updateClock creates the slot to time converter from the config of the node the staking manager is connected to
+func (s *stakingManager) updateClock() {
+	status, err := s.nodeAPI.GetStatus()
+	if err != nil {
+		logger.Warnf("failed to get node status, slots will not be converted to time: %v", err)
+		return
+	}
+
+	clock, err := massaTime.NewClockFromNodeConfig(status.Config)
+	if err != nil {
+		logger.Warnf("failed to create slot to time converter: %v", err)
+		return
+	}
+
+	s.clock = clock
+}
+
 func (s *stakingManager) stakingAddressMonitoring(ctx context.Context) {
 	s.mu.Lock()
 	if len(s.stakingAddresses) == 0 {
@@ -64,7 +90,8 @@ func (s *stakingManager) stakingAddressMonitoring(ctx context.Context) {
 			return
 		case <-ticker.C:
 			s.mu.Lock()
-			currentAddresses := s.getAddressesFromRamList()
+			// watch-only addresses are polled with the staking addresses
+			currentAddresses := append(s.getAddressesFromRamList(), s.getWatchOnlyAddressesFromRamList()...)
 
 			if len(currentAddresses) == 0 {
 				s.mu.Unlock()
@@ -73,37 +100,46 @@ func (s *stakingManager) stakingAddressMonitoring(ctx context.Context) {
 			}
 
 			// Update staking addresses data
-			newAddresses, err := s.getAddressesDataFromNode(currentAddresses)
+			polledAddresses, err := s.getAddressesDataFromNode(currentAddresses)
 			if err != nil {
 				s.mu.Unlock()
 				logger.Error("failed to retrieve staking addresses from node: %v", err)
 				continue
 			}
 
+			// from here, newAddresses only holds the staking addresses
+			newAddresses, watchOnlyAddresses := s.splitWatchOnlyAddresses(polledAddresses)
+			watchOnlyUpdated := s.updateWatchOnlyAddresses(watchOnlyAddresses)
+
+			s.recordCycleStats(newAddresses)
+			s.reconcileBalances(newAddresses)
+			newAddresses = s.advanceDecommissions(newAddresses)
+
 			if s.addressChangedDispatcher.HasSubscribers() {
 				updated := s.updateStakingAddresses(newAddresses)
-				if updated {
-					s.addressChangedDispatcher.Publish(s.stakingAddresses)
+				if updated || watchOnlyUpdated {
+					s.addressChangedDispatcher.Publish(s.addressesToPublish())
 				}
 			}
 			s.mu.Unlock()
 
 			s.muSellBuyRolls.Lock()
 			s.handleRollsUpdates(newAddresses)
+			s.handleSweeps(newAddresses)
 			s.muSellBuyRolls.Unlock()
 
 		case <-totValueTicker.C:
 			totalValue := s.getTotalValue()
-			currentNetwork := utils.NetworkMainnet
-			if !configPkg.GlobalPluginInfo.GetIsMainnet() {
-				currentNetwork = utils.NetworkBuildnet
-			}
+			currentNetwork := configPkg.GlobalPluginInfo.GetNetwork()
 			if err := s.db.PostHistory(db.ValueHistory{
 				Timestamp:  time.Now(),
 				TotalValue: totalValue,
 			}, currentNetwork); err != nil {
 				logger.Errorf("failed to save total value to database: %v", err)
 			}
+
+			s.postWatchOnlyValue(currentNetwork)
+			s.postAddressValues(currentNetwork)
 		}
 	}
 }
@@ -152,6 +188,12 @@ func (s *stakingManager) updateStakingAddresses(newAddresses []StakingAddress) b
 			s.stakingAddresses[index].DeferredCredits = make([]DeferredCredit, len(newAddress.DeferredCredits))
 			copy(s.stakingAddresses[index].DeferredCredits, newAddress.DeferredCredits)
 		}
+
+		if !slices.Equal(s.stakingAddresses[index].CycleInfos, newAddress.CycleInfos) {
+			updated = true
+			s.stakingAddresses[index].CycleInfos = make([]CycleInfo, len(newAddress.CycleInfos))
+			copy(s.stakingAddresses[index].CycleInfos, newAddress.CycleInfos)
+		}
 	}
 	return updated
 }
@@ -162,7 +204,10 @@ func (s *stakingManager) handleRollsUpdates(newAddresses []StakingAddress) {
 	for _, newAddress := range newAddresses {
 		err := s.sellBuyRollsAddress(newAddress)
 		if err != nil {
-			if errorPkg.Is(err, errorPkg.ErrStakingManagerPendingOperationNotCompleted) {
+			// blocked operations are already logged and recorded by the guardrails
+			if errorPkg.Is(err, errorPkg.ErrStakingManagerPendingOperationNotCompleted) ||
+				errorPkg.Is(err, errorPkg.ErrStakingManagerRollOperationBlocked) ||
+				errorPkg.Is(err, errorPkg.ErrStakingManagerAutomationPaused) {
 				logger.Debugf(err.Error())
 				continue
 			}
@@ -194,19 +239,37 @@ func (s *stakingManager) sellBuyRollsAddress(address StakingAddress) error {
 		)
 	}
 
+	// Target rolls are kept while the automation is paused, they are reached once it is resumed
+	if s.automationIsPaused(address.Address) {
+		return errorPkg.New(
+			errorPkg.ErrStakingManagerAutomationPaused,
+			fmt.Sprintf("staking automation is paused for address %s, skipping rolls update", address.Address),
+		)
+	}
+
+	// The roll price is unknown until the network miscellaneous data is fetched
+	if s.miscellaneous.RollPrice <= 0 {
+		return fmt.Errorf("roll price is unknown, skipping rolls update of address %s", address.Address)
+	}
+
 	// When the target rolls is negative -> auto compound: buy as many rolls as possible
 	if currentRollTarget < 0 {
 		// Calculate maximum rolls that can be bought with available balance
-		maxRollsToBuy := uint64(address.FinalBalance / float64(s.miscellaneous.RollPrice))
+		maxRollsToBuy := uint64(address.FinalBalance / s.miscellaneous.RollPrice)
 
 		if maxRollsToBuy > 0 {
 			// Check if the address has enough balance to pay minimal fees
-			if float64(s.miscellaneous.MinimalFees) > address.FinalBalance {
-				return fmt.Errorf("address %s need to buy rolls but has %f mas which is less than minimal fees (%.2f mas)", address.Address, address.FinalBalance, s.miscellaneous.MinimalFees)
+			if s.miscellaneous.MinimalFees > address.FinalBalance {
+				return fmt.Errorf("address %s need to buy rolls but has %s mas which is less than minimal fees (%s mas)", address.Address, address.FinalBalance, s.miscellaneous.MinimalFees)
+			}
+
+			logger.Infof("Address %s (balance: %s) has %d rolls and target is maximum: Need to buy %d rolls (max possible)", address.Address, address.FinalBalance, address.FinalRolls, maxRollsToBuy)
+			maxRollsToBuy, err = s.applyRollGuardrails(address.Address, db.RollOpBuy, maxRollsToBuy)
+			if err != nil {
+				return err
 			}
 
-			logger.Infof("Address %s (balance: %f) has %d rolls and target is maximum: Need to buy %d rolls (max possible)", address.Address, address.FinalBalance, address.FinalRolls, maxRollsToBuy)
-			opId, err := s.clientDriver.BuyRolls(configPkg.GlobalPluginInfo.GetPwd(), address.Address, maxRollsToBuy, float32(s.miscellaneous.MinimalFees))
+			opId, err := s.clientDriver.BuyRolls(configPkg.GlobalPluginInfo.GetPwd(), address.Address, maxRollsToBuy, s.miscellaneous.MinimalFees)
 			if err != nil {
 				return fmt.Errorf("failed to buy rolls for address %s: %v", address.Address, err)
 			}
@@ -223,13 +286,17 @@ func (s *stakingManager) sellBuyRollsAddress(address StakingAddress) error {
 	// Sell rolls
 	if currentRollTarget < int64(address.CandidateRolls) {
 		// Check if the address has enough balance to pay minimal fees
-		if float64(s.miscellaneous.MinimalFees) > address.FinalBalance {
-			return fmt.Errorf("address %s need to sell rolls but has %f mas which is less than minimal fees (%.2f mas)", address.Address, address.FinalBalance, s.miscellaneous.MinimalFees)
+		if s.miscellaneous.MinimalFees > address.FinalBalance {
+			return fmt.Errorf("address %s need to sell rolls but has %s mas which is less than minimal fees (%s mas)", address.Address, address.FinalBalance, s.miscellaneous.MinimalFees)
 		}
 		rollsToSell := address.CandidateRolls - uint64(currentRollTarget)
 		logger.Infof("Address %s had %d rolls and %d target rolls: Need to sell %d rolls", address.Address, address.FinalRolls, currentRollTarget, rollsToSell)
+		rollsToSell, err = s.applyRollGuardrails(address.Address, db.RollOpSell, rollsToSell)
+		if err != nil {
+			return err
+		}
 
-		opId, err := s.clientDriver.SellRolls(configPkg.GlobalPluginInfo.GetPwd(), address.Address, rollsToSell, float32(s.miscellaneous.MinimalFees))
+		opId, err := s.clientDriver.SellRolls(configPkg.GlobalPluginInfo.GetPwd(), address.Address, rollsToSell, s.miscellaneous.MinimalFees)
 		if err != nil {
 			return fmt.Errorf("failed to sell rolls for address %s: %v", address.Address, err)
 		}
@@ -243,18 +310,23 @@ func (s *stakingManager) sellBuyRollsAddress(address StakingAddress) error {
 		// Buy rolls
 	} else if currentRollTarget > int64(address.CandidateRolls) {
 		// Check if the address has enough balance to pay minimal fees
-		if float64(s.miscellaneous.MinimalFees) > address.FinalBalance {
-			return fmt.Errorf("address %s need to buy rolls but has %f mas which is less than minimal fees (%.2f mas)", address.Address, address.FinalBalance, s.miscellaneous.MinimalFees)
+		if s.miscellaneous.MinimalFees > address.FinalBalance {
+			return fmt.Errorf("address %s need to buy rolls but has %s mas which is less than minimal fees (%s mas)", address.Address, address.FinalBalance, s.miscellaneous.MinimalFees)
 		}
 
-		rollsToBuy := uint64(min(
-			float64(currentRollTarget-int64(address.CandidateRolls)),
-			address.FinalBalance/float64(s.miscellaneous.RollPrice),
-		))
+		rollsToBuy := min(
+			uint64(currentRollTarget-int64(address.CandidateRolls)),
+			uint64(max(address.FinalBalance/s.miscellaneous.RollPrice, 0)),
+		)
 
 		if rollsToBuy > 0 {
-			logger.Infof("Address %s (balance: %f) has %d rolls and %d target rolls: Need to buy %d rolls", address.Address, address.FinalBalance, address.FinalBalance, address.FinalRolls, currentRollTarget, rollsToBuy)
-			opId, err := s.clientDriver.BuyRolls(configPkg.GlobalPluginInfo.GetPwd(), address.Address, rollsToBuy, float32(s.miscellaneous.MinimalFees))
+			logger.Infof("Address %s (balance: %s) has %d rolls and %d target rolls: Need to buy %d rolls", address.Address, address.FinalBalance, address.FinalRolls, currentRollTarget, rollsToBuy)
+			rollsToBuy, err = s.applyRollGuardrails(address.Address, db.RollOpBuy, rollsToBuy)
+			if err != nil {
+				return err
+			}
+
+			opId, err := s.clientDriver.BuyRolls(configPkg.GlobalPluginInfo.GetPwd(), address.Address, rollsToBuy, s.miscellaneous.MinimalFees)
 			if err != nil {
 				return fmt.Errorf("failed to buy rolls for address %s: %v", address.Address, err)
 			}
@@ -272,13 +344,10 @@ func (s *stakingManager) sellBuyRollsAddress(address StakingAddress) error {
 func (s *stakingManager) handleRollOpMonitoring(index int, opId string, operationType db.RollOp, amount uint64) error {
 	/* if the buyRolls or sellRolls op has been sent, we need to wait for it to be completed.
 	so we save it's op id to be able tocheck later if it has been completed */
-	s.stakingAddresses[index].pendingOperationId = &opId
+	s.setPendingOperation(index, opId)
 
 	// Record the roll operation in the database
-	currentNetwork := utils.NetworkMainnet
-	if !configPkg.GlobalPluginInfo.GetIsMainnet() {
-		currentNetwork = utils.NetworkBuildnet
-	}
+	currentNetwork := configPkg.GlobalPluginInfo.GetNetwork()
 
 	if err := s.db.AddRollOpHistory(s.stakingAddresses[index].Address, operationType, amount, opId, currentNetwork); err != nil {
 		if operationType == db.RollOpBuy {
@@ -314,7 +383,7 @@ func (s *stakingManager) checkIfPendingOperationIsCompleted(index int) (bool, er
 	}
 
 	if operation.IsFinal {
-		s.stakingAddresses[index].pendingOperationId = nil
+		s.clearPendingOperation(index)
 		return true, nil
 	} else {
 		// if the op is not final, check if it has expired
@@ -333,27 +402,114 @@ func (s *stakingManager) checkIfPendingOperationIsCompleted(index int) (bool, er
 
 		// if the operation has been expired, we can remove it from the staking addresses list
 		if operation.Detail.Content.ExpirePeriod < uint(status.LastSlot.Period) {
-			s.stakingAddresses[index].pendingOperationId = nil
+			s.clearPendingOperation(index)
 			logger.Debugf("Pending operation '%s' for address %s has been expired", *pendingOpId, s.stakingAddresses[index].Address)
 			return true, nil
 		} else {
-			s.stakingAddresses[index].pendingOperationId = nil
+			// the operation is kept until it is final or expired, so that no other operation is sent for the address meanwhile
+			s.setPendingOperationExpiry(index, uint64(operation.Detail.Content.ExpirePeriod))
 			logger.Debugf("Pending operation '%s' for address %s is still pending", *pendingOpId, s.stakingAddresses[index].Address)
 			return false, nil
 		}
 	}
 }
 
+// setPendingOperation stores the id of the operation sent for the address, no other operation is sent until it is completed
+func (s *stakingManager) setPendingOperation(index int, opId string) {
+	s.stakingAddresses[index].pendingOperationId = &opId
+	s.stakingAddresses[index].PendingOperation = &PendingOperation{ID: opId}
+}
+
+func (s *stakingManager) clearPendingOperation(index int) {
+	s.stakingAddresses[index].pendingOperationId = nil
+	s.stakingAddresses[index].PendingOperation = nil
+}
+
+// setPendingOperationExpiry stores the expire period of the pending operation of the address and its estimated time
+func (s *stakingManager) setPendingOperationExpiry(index int, expirePeriod uint64) {
+	pendingOp := &PendingOperation{
+		ID:           *s.stakingAddresses[index].pendingOperationId,
+		ExpirePeriod: &expirePeriod,
+	}
+
+	if s.clock != nil {
+		// the operation can be included until the end of its expire period
+		expireTime := s.clock.PeriodToTime(expirePeriod + 1)
+		pendingOp.ExpireTime = &expireTime
+	}
+
+	s.stakingAddresses[index].PendingOperation = pendingOp
+}
+
 // getTotalValue returns the total value of all staking addresses
 // it takes into account the final balance, the final rolls and the deferred credits
-func (s *stakingManager) getTotalValue() float64 {
-	totalValue := float64(0)
-	for _, address := range s.stakingAddresses {
-		deferredCredits := float64(0)
-		for _, defCredit := range address.DeferredCredits {
-			deferredCredits += defCredit.Amount
-		}
-		totalValue += address.FinalBalance + float64(address.FinalRolls)*float64(s.miscellaneous.RollPrice) + deferredCredits
+func (s *stakingManager) getTotalValue() massaAmount.Amount {
+	return s.addressesValue(s.stakingAddresses)
+}
+
+// postWatchOnlyValue records the total value of the watch-only addresses as a separate value history series, if there are any
+func (s *stakingManager) postWatchOnlyValue(network utils.Network) {
+	s.mu.Lock()
+	if len(s.watchOnlyAddresses) == 0 {
+		s.mu.Unlock()
+		return
+	}
+	totalValue := s.addressesValue(s.watchOnlyAddresses)
+	s.mu.Unlock()
+
+	if err := s.db.PostWatchOnlyHistory(db.ValueHistory{
+		Timestamp:  time.Now(),
+		TotalValue: totalValue,
+	}, network); err != nil {
+		logger.Errorf("failed to save watch-only total value to database: %v", err)
+	}
+}
+
+// addressesValue returns the total value of the given addresses
+func (s *stakingManager) addressesValue(addresses []StakingAddress) massaAmount.Amount {
+	var totalValue massaAmount.Amount
+	for _, address := range addresses {
+		value := s.addressValue(address)
+		totalValue += value.Balance + value.RollValue + value.Deferred
+	}
+	return totalValue
+}
+
+// addressValue splits the value of an address between its final balance, its final rolls and its deferred credits
+func (s *stakingManager) addressValue(address StakingAddress) db.AddressValueHistory {
+	var deferredCredits massaAmount.Amount
+	for _, defCredit := range address.DeferredCredits {
+		deferredCredits += defCredit.Amount
+	}
+
+	return db.AddressValueHistory{
+		Address:   address.Address,
+		WatchOnly: address.WatchOnly,
+		Balance:   address.FinalBalance,
+		RollValue: massaAmount.Amount(address.FinalRolls) * s.miscellaneous.RollPrice,
+		Rolls:     address.FinalRolls,
+		Deferred:  deferredCredits,
+	}
+}
+
+// postAddressValues records the value of each staking and watch-only address, split by part, in the address value history
+func (s *stakingManager) postAddressValues(network utils.Network) {
+	now := time.Now()
+
+	s.mu.Lock()
+	histories := make([]db.AddressValueHistory, 0, len(s.stakingAddresses)+len(s.watchOnlyAddresses))
+	for _, address := range append(copyAddresses(s.stakingAddresses), s.watchOnlyAddresses...) {
+		history := s.addressValue(address)
+		history.Timestamp = now
+		histories = append(histories, history)
+	}
+	s.mu.Unlock()
+
+	if len(histories) == 0 {
+		return
+	}
+
+	if err := s.db.PostAddressHistory(histories, network); err != nil {
+		logger.Errorf("failed to save addresses value to database: %v", err)
 	}
-	return math.Floor(totalValue*1000) / 1000 // keep only 3 digit after the comma
 }
@@ -7,11 +7,14 @@ import (
 
 	clientDriver "github.com/massalabs/node-manager-plugin/int/client-driver"
 	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	massaTime "github.com/massalabs/node-manager-plugin/int/massa-time"
 	nodeAPIPkg "github.com/massalabs/node-manager-plugin/int/node-api"
 	"github.com/massalabs/node-manager-plugin/int/utils"
 	"github.com/massalabs/station/pkg/node"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHandleRollsUpdates(t *testing.T) {
@@ -20,8 +23,8 @@ func TestHandleRollsUpdates(t *testing.T) {
 
 	// Constants for testing
 	const (
-		minimalFees = 0.1
-		rollPrice   = 100.0
+		minimalFees = massaAmount.MAS / 10
+		rollPrice   = 100 * massaAmount.MAS
 	)
 
 	tests := []struct {
@@ -37,7 +40,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 				{
 					Address:        "test_address_1",
 					CandidateRolls: 10,
-					FinalBalance:   1000.0,
+					FinalBalance:   1000 * massaAmount.MAS,
 				},
 			},
 			existingAddrs: []StakingAddress{
@@ -47,7 +50,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 				},
 			},
 			expectedCalls: func(mockClient *clientDriver.MockClientDriver, mockDB *dbPkg.MockDB, t *testing.T) {
-				mockClient.On("SellRolls", mock.Anything, "test_address_1", uint64(5), float32(minimalFees)).Return("tx_hash", nil).Once()
+				mockClient.On("SellRolls", mock.Anything, "test_address_1", uint64(5), minimalFees).Return("tx_hash", nil).Once()
 				mockDB.On("AddRollOpHistory", "test_address_1", dbPkg.RollOpSell, uint64(5), "tx_hash", utils.NetworkMainnet).Return(nil).Once()
 			},
 			expectedPendingOperationId: []string{
@@ -60,7 +63,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 				{
 					Address:        "test_address_2",
 					CandidateRolls: 5,
-					FinalBalance:   1000.0, // There is enough balance to buy 5 rolls
+					FinalBalance:   1000 * massaAmount.MAS, // There is enough balance to buy 5 rolls
 				},
 			},
 			existingAddrs: []StakingAddress{
@@ -73,7 +76,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 				// Should buy 5 rolls (difference between target 10 and current 5)
 				// But limited by available balance: 1000.0 / 100.0 = 10 rolls max
 				// So should buy 5 rolls (the minimum of difference and available)
-				mockClient.On("BuyRolls", mock.Anything, "test_address_2", uint64(5), float32(minimalFees)).Return("tx_hash", nil).Once()
+				mockClient.On("BuyRolls", mock.Anything, "test_address_2", uint64(5), minimalFees).Return("tx_hash", nil).Once()
 				mockDB.On("AddRollOpHistory", "test_address_2", dbPkg.RollOpBuy, uint64(5), "tx_hash", utils.NetworkMainnet).Return(nil).Once()
 			},
 			expectedPendingOperationId: []string{
@@ -86,7 +89,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 				{
 					Address:        "test_address_negative",
 					CandidateRolls: 5,
-					FinalBalance:   1000.0, // Can buy 10 rolls with this balance
+					FinalBalance:   1000 * massaAmount.MAS, // Can buy 10 rolls with this balance
 				},
 			},
 			existingAddrs: []StakingAddress{
@@ -97,7 +100,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 			},
 			expectedCalls: func(mockClient *clientDriver.MockClientDriver, mockDB *dbPkg.MockDB, t *testing.T) {
 				// Should buy maximum rolls possible: 1000.0 / 100.0 = 10 rolls
-				mockClient.On("BuyRolls", mock.Anything, "test_address_negative", uint64(10), float32(minimalFees)).Return("tx_hash", nil).Once()
+				mockClient.On("BuyRolls", mock.Anything, "test_address_negative", uint64(10), minimalFees).Return("tx_hash", nil).Once()
 				mockDB.On("AddRollOpHistory", "test_address_negative", dbPkg.RollOpBuy, uint64(10), "tx_hash", utils.NetworkMainnet).Return(nil).Once()
 			},
 			expectedPendingOperationId: []string{
@@ -110,7 +113,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 				{
 					Address:        "test_address_3",
 					CandidateRolls: 5,
-					FinalBalance:   0.05, // Less than minimal fees (0.1)
+					FinalBalance:   massaAmount.FromMAS(0.05), // Less than minimal fees (0.1)
 				},
 			},
 			existingAddrs: []StakingAddress{
@@ -133,7 +136,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 				{
 					Address:        "test_address_4",
 					CandidateRolls: 10,
-					FinalBalance:   0.05, // Less than minimal fees (0.1)
+					FinalBalance:   massaAmount.FromMAS(0.05), // Less than minimal fees (0.1)
 				},
 			},
 			existingAddrs: []StakingAddress{
@@ -155,7 +158,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 				{
 					Address:        "test_address_5",
 					CandidateRolls: 5,
-					FinalBalance:   301.13, // Can buy 3 rolls (300/100), but needs to buy 5
+					FinalBalance:   massaAmount.FromMAS(301.13), // Can buy 3 rolls (300/100), but needs to buy 5
 				},
 			},
 			existingAddrs: []StakingAddress{
@@ -166,7 +169,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 			},
 			expectedCalls: func(mockClient *clientDriver.MockClientDriver, mockDB *dbPkg.MockDB, t *testing.T) {
 				// Should buy 3 rolls (limited by balance: 300/100 = 3)
-				mockClient.On("BuyRolls", mock.Anything, "test_address_5", uint64(3), float32(minimalFees)).Return("tx_hash", nil).Once()
+				mockClient.On("BuyRolls", mock.Anything, "test_address_5", uint64(3), minimalFees).Return("tx_hash", nil).Once()
 				mockDB.On("AddRollOpHistory", "test_address_5", dbPkg.RollOpBuy, uint64(3), "tx_hash", utils.NetworkMainnet).Return(nil).Once()
 			},
 			expectedPendingOperationId: []string{"tx_hash"},
@@ -177,12 +180,12 @@ func TestHandleRollsUpdates(t *testing.T) {
 				{
 					Address:        "test_address_6",
 					CandidateRolls: 10,
-					FinalBalance:   1000.0,
+					FinalBalance:   1000 * massaAmount.MAS,
 				},
 				{
 					Address:        "test_address_7",
 					CandidateRolls: 5,
-					FinalBalance:   1000.0,
+					FinalBalance:   1000 * massaAmount.MAS,
 				},
 			},
 			existingAddrs: []StakingAddress{
@@ -197,9 +200,9 @@ func TestHandleRollsUpdates(t *testing.T) {
 			},
 			expectedCalls: func(mockClient *clientDriver.MockClientDriver, mockDB *dbPkg.MockDB, t *testing.T) {
 				// Address 6: sell 3 rolls (7 target - 10 current)
-				mockClient.On("SellRolls", mock.Anything, "test_address_6", uint64(3), float32(minimalFees)).Return("tx_hash_1", nil).Once()
+				mockClient.On("SellRolls", mock.Anything, "test_address_6", uint64(3), minimalFees).Return("tx_hash_1", nil).Once()
 				// Address 7: buy 4 rolls (9 current - 5 target)
-				mockClient.On("BuyRolls", mock.Anything, "test_address_7", uint64(4), float32(minimalFees)).Return("tx_hash_2", nil).Once()
+				mockClient.On("BuyRolls", mock.Anything, "test_address_7", uint64(4), minimalFees).Return("tx_hash_2", nil).Once()
 				mockDB.On("AddRollOpHistory", "test_address_6", dbPkg.RollOpSell, uint64(3), "tx_hash_1", utils.NetworkMainnet).Return(nil).Once()
 				mockDB.On("AddRollOpHistory", "test_address_7", dbPkg.RollOpBuy, uint64(4), "tx_hash_2", utils.NetworkMainnet).Return(nil).Once()
 			},
@@ -211,7 +214,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 				{
 					Address:        "test_address_8",
 					CandidateRolls: 7,
-					FinalBalance:   1000.0,
+					FinalBalance:   1000 * massaAmount.MAS,
 				},
 			},
 			existingAddrs: []StakingAddress{
@@ -222,7 +225,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 			},
 			expectedCalls: func(mockClient *clientDriver.MockClientDriver, mockDB *dbPkg.MockDB, t *testing.T) {
 				// Should attempt to sell rolls but fail
-				mockClient.On("SellRolls", mock.Anything, "test_address_8", uint64(4), float32(minimalFees)).Return("", assert.AnError).Once()
+				mockClient.On("SellRolls", mock.Anything, "test_address_8", uint64(4), minimalFees).Return("", assert.AnError).Once()
 				mockDB.AssertNotCalled(t, "AddRollOpHistory")
 			},
 			expectedPendingOperationId: []string{""},
@@ -233,7 +236,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 				{
 					Address:        "test_address_9",
 					CandidateRolls: 3,
-					FinalBalance:   1000.0,
+					FinalBalance:   1000 * massaAmount.MAS,
 				},
 			},
 			existingAddrs: []StakingAddress{
@@ -244,7 +247,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 			},
 			expectedCalls: func(mockClient *clientDriver.MockClientDriver, mockDB *dbPkg.MockDB, t *testing.T) {
 				// Should attempt to sell rolls but fail
-				mockClient.On("BuyRolls", mock.Anything, "test_address_9", uint64(4), float32(minimalFees)).Return("", assert.AnError).Once()
+				mockClient.On("BuyRolls", mock.Anything, "test_address_9", uint64(4), minimalFees).Return("", assert.AnError).Once()
 				mockDB.AssertNotCalled(t, "AddRollOpHistory")
 			},
 			expectedPendingOperationId: []string{""},
@@ -255,7 +258,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 				{
 					Address:        "test_address_10",
 					CandidateRolls: 10,
-					FinalBalance:   1000.0,
+					FinalBalance:   1000 * massaAmount.MAS,
 				},
 			},
 			existingAddrs: []StakingAddress{
@@ -265,7 +268,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 				},
 			},
 			expectedCalls: func(mockClient *clientDriver.MockClientDriver, mockDB *dbPkg.MockDB, t *testing.T) {
-				mockClient.On("SellRolls", mock.Anything, "test_address_10", uint64(3), float32(minimalFees)).Return("tx_hash", nil).Once()
+				mockClient.On("SellRolls", mock.Anything, "test_address_10", uint64(3), minimalFees).Return("tx_hash", nil).Once()
 				mockDB.On("AddRollOpHistory", "test_address_10", dbPkg.RollOpSell, uint64(3), "tx_hash", utils.NetworkMainnet).Return(assert.AnError).Once()
 			},
 			expectedPendingOperationId: []string{
@@ -278,7 +281,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 				{
 					Address:        "test_address_11",
 					CandidateRolls: 7,
-					FinalBalance:   1000.0,
+					FinalBalance:   1000 * massaAmount.MAS,
 				},
 			},
 			existingAddrs: []StakingAddress{
@@ -288,7 +291,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 				},
 			},
 			expectedCalls: func(mockClient *clientDriver.MockClientDriver, mockDB *dbPkg.MockDB, t *testing.T) {
-				mockClient.On("BuyRolls", mock.Anything, "test_address_11", uint64(3), float32(minimalFees)).Return("tx_hash", nil).Once()
+				mockClient.On("BuyRolls", mock.Anything, "test_address_11", uint64(3), minimalFees).Return("tx_hash", nil).Once()
 				mockDB.On("AddRollOpHistory", "test_address_11", dbPkg.RollOpBuy, uint64(3), "tx_hash", utils.NetworkMainnet).Return(assert.AnError).Once()
 			},
 			expectedPendingOperationId: []string{"tx_hash"},
@@ -299,7 +302,7 @@ func TestHandleRollsUpdates(t *testing.T) {
 				{
 					Address:        "test_address_9",
 					CandidateRolls: 10,
-					FinalBalance:   1000.0,
+					FinalBalance:   1000 * massaAmount.MAS,
 				},
 			},
 			existingAddrs: []StakingAddress{
@@ -331,22 +334,22 @@ func TestHandleRollsUpdates(t *testing.T) {
 				{
 					Address:        "addr1",
 					CandidateRolls: 15,
-					FinalBalance:   1000.0,
+					FinalBalance:   1000 * massaAmount.MAS,
 				},
 				{
 					Address:        "addr2",
 					CandidateRolls: 3,
-					FinalBalance:   1000.0,
+					FinalBalance:   1000 * massaAmount.MAS,
 				},
 				{
 					Address:        "addr3",
 					CandidateRolls: 8,
-					FinalBalance:   1000.0,
+					FinalBalance:   1000 * massaAmount.MAS,
 				},
 				{
 					Address:        "addr4",
 					CandidateRolls: 10,
-					FinalBalance:   99.99,
+					FinalBalance:   massaAmount.FromMAS(99.99),
 				},
 			},
 			existingAddrs: []StakingAddress{
@@ -369,9 +372,9 @@ func TestHandleRollsUpdates(t *testing.T) {
 			},
 			expectedCalls: func(mockClient *clientDriver.MockClientDriver, mockDB *dbPkg.MockDB, t *testing.T) {
 				// addr1: sell 5 rolls (15 current - 10 target)
-				mockClient.On("SellRolls", mock.Anything, "addr1", uint64(5), float32(minimalFees)).Return("tx_hash1", nil).Once()
+				mockClient.On("SellRolls", mock.Anything, "addr1", uint64(5), minimalFees).Return("tx_hash1", nil).Once()
 				// addr2: buy 2 rolls (5 target - 3 current)
-				mockClient.On("BuyRolls", mock.Anything, "addr2", uint64(2), float32(minimalFees)).Return("tx_hash2", nil).Once()
+				mockClient.On("BuyRolls", mock.Anything, "addr2", uint64(2), minimalFees).Return("tx_hash2", nil).Once()
 				// addr3: no action needed (8 current = 8 target)
 				// addr4: insufficient balance for buying rolls
 				mockDB.On("AddRollOpHistory", "addr1", dbPkg.RollOpSell, uint64(5), "tx_hash1", utils.NetworkMainnet).Return(nil).Once()
@@ -418,6 +421,24 @@ func TestHandleRollsUpdates(t *testing.T) {
 	}
 }
 
+func TestSellBuyRollsAddressWithoutRollPrice(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	for _, targetRolls := range []int64{-1, 0, 10} {
+		address := StakingAddress{Address: "test_address", FinalBalance: 1000 * massaAmount.MAS, CandidateRolls: 5, TargetRolls: targetRolls}
+		sm := &stakingManager{
+			clientDriver:     clientDriver.NewMockClientDriver(t),
+			db:               dbPkg.NewMockDB(t),
+			stakingAddresses: []StakingAddress{address},
+			miscellaneous:    Miscellaneous{MinimalFees: massaAmount.MAS / 10},
+		}
+
+		err := sm.sellBuyRollsAddress(address)
+		assert.ErrorContains(t, err, "roll price is unknown", "target rolls %d", targetRolls)
+	}
+}
+
 func TestUpdateStakingAddresses(t *testing.T) {
 	cleanup := setupLog(t)
 	defer cleanup()
@@ -471,14 +492,14 @@ func TestUpdateStakingAddresses(t *testing.T) {
 			existingAddrs: []StakingAddress{
 				{
 					Address:          "addr1",
-					CandidateBalance: 100.0,
+					CandidateBalance: 100 * massaAmount.MAS,
 					TargetRolls:      10,
 				},
 			},
 			newAddresses: []StakingAddress{
 				{
 					Address:          "addr1",
-					CandidateBalance: 150.0,
+					CandidateBalance: 150 * massaAmount.MAS,
 					TargetRolls:      10,
 				},
 			},
@@ -525,14 +546,14 @@ func TestUpdateStakingAddresses(t *testing.T) {
 			existingAddrs: []StakingAddress{
 				{
 					Address:      "addr1",
-					FinalBalance: 200.0,
+					FinalBalance: 200 * massaAmount.MAS,
 					TargetRolls:  10,
 				},
 			},
 			newAddresses: []StakingAddress{
 				{
 					Address:      "addr1",
-					FinalBalance: 250.0,
+					FinalBalance: 250 * massaAmount.MAS,
 					TargetRolls:  10,
 				},
 			},
@@ -550,7 +571,7 @@ func TestUpdateStakingAddresses(t *testing.T) {
 								Period: 100,
 								Thread: 1,
 							},
-							Amount: 50.0,
+							Amount: 50 * massaAmount.MAS,
 						},
 					},
 				},
@@ -565,7 +586,7 @@ func TestUpdateStakingAddresses(t *testing.T) {
 								Period: 100,
 								Thread: 1,
 							},
-							Amount: 75.0,
+							Amount: 75 * massaAmount.MAS,
 						},
 					},
 				},
@@ -591,7 +612,7 @@ func TestUpdateStakingAddresses(t *testing.T) {
 								Period: 100,
 								Thread: 1,
 							},
-							Amount: 50.0,
+							Amount: 50 * massaAmount.MAS,
 						},
 					},
 				},
@@ -604,10 +625,10 @@ func TestUpdateStakingAddresses(t *testing.T) {
 				{
 					Address:          "addr1",
 					CandidateRolls:   5,
-					CandidateBalance: 100.0,
+					CandidateBalance: 100 * massaAmount.MAS,
 					FinalRolls:       7,
 					ActiveRolls:      6,
-					FinalBalance:     200.0,
+					FinalBalance:     200 * massaAmount.MAS,
 					TargetRolls:      10,
 					DeferredCredits: []DeferredCredit{
 						{
@@ -615,7 +636,7 @@ func TestUpdateStakingAddresses(t *testing.T) {
 								Period: 100,
 								Thread: 1,
 							},
-							Amount: 50.0,
+							Amount: 50 * massaAmount.MAS,
 						},
 					},
 				},
@@ -624,10 +645,10 @@ func TestUpdateStakingAddresses(t *testing.T) {
 				{
 					Address:          "addr1",
 					CandidateRolls:   5,
-					CandidateBalance: 100.0,
+					CandidateBalance: 100 * massaAmount.MAS,
 					FinalRolls:       7,
 					ActiveRolls:      6,
-					FinalBalance:     200.0,
+					FinalBalance:     200 * massaAmount.MAS,
 					TargetRolls:      10,
 					DeferredCredits: []DeferredCredit{
 						{
@@ -635,7 +656,7 @@ func TestUpdateStakingAddresses(t *testing.T) {
 								Period: 100,
 								Thread: 1,
 							},
-							Amount: 50.0,
+							Amount: 50 * massaAmount.MAS,
 						},
 					},
 				},
@@ -752,48 +773,48 @@ func TestGetTotalValue(t *testing.T) {
 	cleanup := setupLog(t)
 	defer cleanup()
 
-	const rollPrice = 100.0
+	const rollPrice = 100 * massaAmount.MAS
 
 	tests := []struct {
 		name           string
 		stakingAddrs   []StakingAddress
-		expectedResult float64
+		expectedResult massaAmount.Amount
 	}{
 		{
 			name:           "Should return zero for empty addresses",
 			stakingAddrs:   []StakingAddress{},
-			expectedResult: 0.0,
+			expectedResult: 0,
 		},
 		{
 			name: "Should calculate total value for single address with balance only",
 			stakingAddrs: []StakingAddress{
 				{
 					Address:         "addr1",
-					FinalBalance:    500.0,
+					FinalBalance:    500 * massaAmount.MAS,
 					FinalRolls:      0,
 					DeferredCredits: []DeferredCredit{},
 				},
 			},
-			expectedResult: 500.0,
+			expectedResult: 500 * massaAmount.MAS,
 		},
 		{
 			name: "Should calculate total value for single address with rolls only",
 			stakingAddrs: []StakingAddress{
 				{
 					Address:         "addr1",
-					FinalBalance:    0.0,
+					FinalBalance:    0,
 					FinalRolls:      5,
 					DeferredCredits: []DeferredCredit{},
 				},
 			},
-			expectedResult: 500.0, // 5 rolls × 100.0 roll price
+			expectedResult: 500 * massaAmount.MAS, // 5 rolls × 100.0 roll price
 		},
 		{
 			name: "Should calculate total value for single address with deferred credits only",
 			stakingAddrs: []StakingAddress{
 				{
 					Address:      "addr1",
-					FinalBalance: 0.0,
+					FinalBalance: 0,
 					FinalRolls:   0,
 					DeferredCredits: []DeferredCredit{
 						{
@@ -801,26 +822,26 @@ func TestGetTotalValue(t *testing.T) {
 								Period: 100,
 								Thread: 1,
 							},
-							Amount: 250.0,
+							Amount: 250 * massaAmount.MAS,
 						},
 						{
 							Slot: Slot{
 								Period: 101,
 								Thread: 2,
 							},
-							Amount: 150.0,
+							Amount: 150 * massaAmount.MAS,
 						},
 					},
 				},
 			},
-			expectedResult: 400.0, // 250.0 + 150.0
+			expectedResult: 400 * massaAmount.MAS, // 250.0 + 150.0
 		},
 		{
 			name: "Should calculate total value for single address with all components",
 			stakingAddrs: []StakingAddress{
 				{
 					Address:      "addr1",
-					FinalBalance: 1000.0,
+					FinalBalance: 1000 * massaAmount.MAS,
 					FinalRolls:   3,
 					DeferredCredits: []DeferredCredit{
 						{
@@ -828,19 +849,19 @@ func TestGetTotalValue(t *testing.T) {
 								Period: 100,
 								Thread: 1,
 							},
-							Amount: 200.0,
+							Amount: 200 * massaAmount.MAS,
 						},
 					},
 				},
 			},
-			expectedResult: 1500.0, // 1000.0 + (3 × 100.0) + 200.0
+			expectedResult: 1500 * massaAmount.MAS, // 1000.0 + (3 × 100.0) + 200.0
 		},
 		{
 			name: "Should calculate total value for multiple addresses",
 			stakingAddrs: []StakingAddress{
 				{
 					Address:      "addr1",
-					FinalBalance: 500.0,
+					FinalBalance: 500 * massaAmount.MAS,
 					FinalRolls:   2,
 					DeferredCredits: []DeferredCredit{
 						{
@@ -848,13 +869,13 @@ func TestGetTotalValue(t *testing.T) {
 								Period: 100,
 								Thread: 1,
 							},
-							Amount: 100.0,
+							Amount: 100 * massaAmount.MAS,
 						},
 					},
 				},
 				{
 					Address:      "addr2",
-					FinalBalance: 750.0,
+					FinalBalance: 750 * massaAmount.MAS,
 					FinalRolls:   1,
 					DeferredCredits: []DeferredCredit{
 						{
@@ -862,26 +883,26 @@ func TestGetTotalValue(t *testing.T) {
 								Period: 101,
 								Thread: 1,
 							},
-							Amount: 50.0,
+							Amount: 50 * massaAmount.MAS,
 						},
 						{
 							Slot: Slot{
 								Period: 102,
 								Thread: 2,
 							},
-							Amount: 75.0,
+							Amount: 75 * massaAmount.MAS,
 						},
 					},
 				},
 			},
-			expectedResult: 1775.0, // (500 + 200 + 100) + (750 + 100 + 50 + 75)
+			expectedResult: 1775 * massaAmount.MAS, // (500 + 200 + 100) + (750 + 100 + 50 + 75)
 		},
 		{
 			name: "Should handle large numbers",
 			stakingAddrs: []StakingAddress{
 				{
 					Address:      "addr1",
-					FinalBalance: 1000000.0,
+					FinalBalance: 1000000 * massaAmount.MAS,
 					FinalRolls:   1000,
 					DeferredCredits: []DeferredCredit{
 						{
@@ -889,37 +910,37 @@ func TestGetTotalValue(t *testing.T) {
 								Period: 100,
 								Thread: 1,
 							},
-							Amount: 500000.0,
+							Amount: 500000 * massaAmount.MAS,
 						},
 					},
 				},
 			},
-			expectedResult: 1600000.0, // 1000000.0 + (1000 × 100.0) + 500000.0
+			expectedResult: 1600000 * massaAmount.MAS, // 1000000.0 + (1000 × 100.0) + 500000.0
 		},
 		{
 			name: "Should handle addresses with no deferred credits",
 			stakingAddrs: []StakingAddress{
 				{
 					Address:         "addr1",
-					FinalBalance:    500.0,
+					FinalBalance:    500 * massaAmount.MAS,
 					FinalRolls:      2,
 					DeferredCredits: []DeferredCredit{},
 				},
 				{
 					Address:         "addr2",
-					FinalBalance:    300.0,
+					FinalBalance:    300 * massaAmount.MAS,
 					FinalRolls:      1,
 					DeferredCredits: []DeferredCredit{},
 				},
 			},
-			expectedResult: 1100.0, // (500 + 200) + (300 + 100)
+			expectedResult: 1100 * massaAmount.MAS, // (500 + 200) + (300 + 100)
 		},
 		{
 			name: "Should handle addresses with only deferred credits",
 			stakingAddrs: []StakingAddress{
 				{
 					Address:      "addr1",
-					FinalBalance: 0.0,
+					FinalBalance: 0,
 					FinalRolls:   0,
 					DeferredCredits: []DeferredCredit{
 						{
@@ -927,13 +948,13 @@ func TestGetTotalValue(t *testing.T) {
 								Period: 100,
 								Thread: 1,
 							},
-							Amount: 100.0,
+							Amount: 100 * massaAmount.MAS,
 						},
 					},
 				},
 				{
 					Address:      "addr2",
-					FinalBalance: 0.0,
+					FinalBalance: 0,
 					FinalRolls:   0,
 					DeferredCredits: []DeferredCredit{
 						{
@@ -941,19 +962,19 @@ func TestGetTotalValue(t *testing.T) {
 								Period: 101,
 								Thread: 1,
 							},
-							Amount: 200.0,
+							Amount: 200 * massaAmount.MAS,
 						},
 						{
 							Slot: Slot{
 								Period: 102,
 								Thread: 2,
 							},
-							Amount: 300.0,
+							Amount: 300 * massaAmount.MAS,
 						},
 					},
 				},
 			},
-			expectedResult: 600.0, // 100.0 + (200.0 + 300.0)
+			expectedResult: 600 * massaAmount.MAS, // 100.0 + (200.0 + 300.0)
 		},
 	}
 
@@ -970,12 +991,42 @@ func TestGetTotalValue(t *testing.T) {
 			// Execute the function under test
 			result := sm.getTotalValue()
 
-			// Assert the result with tolerance for floating point precision
-			assert.InDelta(t, tt.expectedResult, result, 0.01, "Total value calculation mismatch")
+			// Assert the exact result
+			assert.Equal(t, tt.expectedResult, result, "Total value calculation mismatch")
 		})
 	}
 }
 
+func TestPostAddressValues(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	mockDB := dbPkg.NewMockDB(t)
+	mockDB.On("PostAddressHistory", mock.MatchedBy(func(histories []dbPkg.AddressValueHistory) bool {
+		// all the addresses are recorded at the same time
+		if len(histories) != 2 || !histories[0].Timestamp.Equal(histories[1].Timestamp) {
+			return false
+		}
+		return histories[0] == dbPkg.AddressValueHistory{Timestamp: histories[0].Timestamp, Address: "addr1", Balance: 10 * massaAmount.MAS, RollValue: 500 * massaAmount.MAS, Rolls: 5, Deferred: 30 * massaAmount.MAS} &&
+			histories[1] == dbPkg.AddressValueHistory{Timestamp: histories[0].Timestamp, Address: "watched", WatchOnly: true, Balance: 1000 * massaAmount.MAS}
+	}), utils.NetworkMainnet).Return(nil).Once()
+
+	sm := &stakingManager{
+		db:            mockDB,
+		miscellaneous: Miscellaneous{RollPrice: 100 * massaAmount.MAS},
+		stakingAddresses: []StakingAddress{
+			{Address: "addr1", FinalBalance: 10 * massaAmount.MAS, FinalRolls: 5, DeferredCredits: []DeferredCredit{{Amount: 10 * massaAmount.MAS}, {Amount: 20 * massaAmount.MAS}}},
+		},
+		watchOnlyAddresses: []StakingAddress{{Address: "watched", FinalBalance: 1000 * massaAmount.MAS, WatchOnly: true}},
+	}
+
+	sm.postAddressValues(utils.NetworkMainnet)
+
+	// nothing is recorded without addresses
+	sm = &stakingManager{db: dbPkg.NewMockDB(t)}
+	sm.postAddressValues(utils.NetworkMainnet)
+}
+
 func TestCheckIfPendingOperationIsCompleted(t *testing.T) {
 	cleanup := setupLog(t)
 	defer cleanup()
@@ -1223,8 +1274,55 @@ func TestCheckIfPendingOperationIsCompleted(t *testing.T) {
 				assert.Nil(t, sm.stakingAddresses[0].pendingOperationId)
 			}
 
+			// If the operation is still pending, it should be kept with its expire period
+			if !result && tt.expectedError == "" {
+				require.NotNil(t, sm.stakingAddresses[0].pendingOperationId)
+				assert.Equal(t, opId, *sm.stakingAddresses[0].pendingOperationId)
+				require.NotNil(t, sm.stakingAddresses[0].PendingOperation)
+				assert.Equal(t, uint64(100), *sm.stakingAddresses[0].PendingOperation.ExpirePeriod)
+			}
+
 			// Assert that all expected mock calls were made
 			mockNodeAPI.AssertExpectations(t)
 		})
 	}
 }
+
+func TestSetPendingOperationExpiry(t *testing.T) {
+	opId := "O1testOperationId"
+
+	clock, err := massaTime.NewClock(1704289800000, 16000, 32, 128)
+	assert.NoError(t, err)
+
+	t.Run("with clock", func(t *testing.T) {
+		sm := &stakingManager{
+			stakingAddresses: []StakingAddress{{Address: "test_address", pendingOperationId: &opId}},
+			clock:            clock,
+		}
+
+		sm.setPendingOperationExpiry(0, 100)
+
+		pendingOp := sm.stakingAddresses[0].PendingOperation
+		assert.NotNil(t, pendingOp)
+		assert.Equal(t, opId, pendingOp.ID)
+		assert.Equal(t, uint64(100), *pendingOp.ExpirePeriod)
+		assert.Equal(t, clock.PeriodToTime(101), *pendingOp.ExpireTime)
+
+		sm.clearPendingOperation(0)
+		assert.Nil(t, sm.stakingAddresses[0].PendingOperation)
+		assert.Nil(t, sm.stakingAddresses[0].pendingOperationId)
+	})
+
+	t.Run("without clock", func(t *testing.T) {
+		sm := &stakingManager{
+			stakingAddresses: []StakingAddress{{Address: "test_address", pendingOperationId: &opId}},
+		}
+
+		sm.setPendingOperationExpiry(0, 100)
+
+		pendingOp := sm.stakingAddresses[0].PendingOperation
+		assert.NotNil(t, pendingOp)
+		assert.Equal(t, uint64(100), *pendingOp.ExpirePeriod)
+		assert.Nil(t, pendingOp.ExpireTime)
+	})
+}
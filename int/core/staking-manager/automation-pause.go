@@ -0,0 +1,123 @@
+package stakingManager
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/massalabs/node-manager-plugin/int/config"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+// AutomationPauses lists what is held: the automation of all the staking addresses (Global) or of some of them
+type AutomationPauses struct {
+	Global    bool     `json:"global"`
+	Addresses []string `json:"addresses"`
+}
+
+/*
+SetAutomationPaused pauses or resumes the roll and sweep automation of a staking address,
+or of all the staking addresses if address is empty.
+While paused, target rolls can still be changed but no roll is bought or sold and no sweep is sent.
+Monitoring and value history keep running. The pause is stored in the database so that it survives restarts.
+*/
+func (s *stakingManager) SetAutomationPaused(address string, paused bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if address != dbPkg.GlobalPauseAddress && !s.ramAddressListContains(address) {
+		return fmt.Errorf("address %s not found in staking addresses", address)
+	}
+
+	currentNetwork := config.GlobalPluginInfo.GetNetwork()
+
+	if paused {
+		if err := s.db.AddAutomationPause(address, currentNetwork); err != nil {
+			return fmt.Errorf("failed to save automation pause: %w", err)
+		}
+	} else if err := s.db.DeleteAutomationPause(address, currentNetwork); err != nil && !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		return fmt.Errorf("failed to delete automation pause: %w", err)
+	}
+
+	// the roll engine reads the pauses while holding muSellBuyRolls
+	s.muSellBuyRolls.Lock()
+	defer s.muSellBuyRolls.Unlock()
+
+	if address == dbPkg.GlobalPauseAddress {
+		s.automationPaused = paused
+		logger.Infof("Staking automation paused for all addresses: %t", paused)
+		return nil
+	}
+
+	if s.pausedAddresses == nil {
+		s.pausedAddresses = make(map[string]bool)
+	}
+	if paused {
+		s.pausedAddresses[address] = true
+	} else {
+		delete(s.pausedAddresses, address)
+	}
+
+	logger.Infof("Staking automation paused for address %s: %t", address, paused)
+	return nil
+}
+
+// GetAutomationPauses returns the automation pauses of the current network
+func (s *stakingManager) GetAutomationPauses() AutomationPauses {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	addresses := slices.Sorted(maps.Keys(s.pausedAddresses))
+	if addresses == nil {
+		addresses = []string{}
+	}
+
+	return AutomationPauses{Global: s.automationPaused, Addresses: addresses}
+}
+
+// loadAutomationPauses loads the automation pauses of the current network from the database
+func (s *stakingManager) loadAutomationPauses() error {
+	pauses, err := s.db.GetAutomationPauses(config.GlobalPluginInfo.GetNetwork())
+	if err != nil {
+		return fmt.Errorf("failed to get automation pauses from database: %w", err)
+	}
+
+	s.muSellBuyRolls.Lock()
+	defer s.muSellBuyRolls.Unlock()
+
+	s.automationPaused = false
+	s.pausedAddresses = make(map[string]bool)
+	for _, pause := range pauses {
+		if pause.Address == dbPkg.GlobalPauseAddress {
+			s.automationPaused = true
+		} else {
+			s.pausedAddresses[pause.Address] = true
+		}
+	}
+
+	return nil
+}
+
+/*
+deleteAutomationPause resumes the automation of an address that is no longer staked,
+so that it is not paused if it is added again. The caller must hold s.mu.
+*/
+func (s *stakingManager) deleteAutomationPause(address string, network utils.Network) error {
+	if err := s.db.DeleteAutomationPause(address, network); err != nil && !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		return fmt.Errorf("failed to delete automation pause of address %s: %w", address, err)
+	}
+
+	s.muSellBuyRolls.Lock()
+	defer s.muSellBuyRolls.Unlock()
+
+	delete(s.pausedAddresses, address)
+	return nil
+}
+
+// automationIsPaused returns whether the automation of an address is held, globally or for this address
+func (s *stakingManager) automationIsPaused(address string) bool {
+	return s.automationPaused || s.pausedAddresses[address]
+}
@@ -0,0 +1,192 @@
+package stakingManager
+
+import (
+	"testing"
+
+	clientDriverPkg "github.com/massalabs/node-manager-plugin/int/client-driver"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetAutomationPaused(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	tests := []struct {
+		name             string
+		address          string
+		paused           bool
+		initiallyPaused  bool
+		setupMocks       func(*dbPkg.MockDB)
+		expectedPauses   AutomationPauses
+		expectedErrorMsg string
+	}{
+		{
+			name:    "Should pause all addresses",
+			address: dbPkg.GlobalPauseAddress,
+			paused:  true,
+			setupMocks: func(mockDB *dbPkg.MockDB) {
+				mockDB.On("AddAutomationPause", dbPkg.GlobalPauseAddress, utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedPauses: AutomationPauses{Global: true, Addresses: []string{}},
+		},
+		{
+			name:    "Should pause an address",
+			address: "test_address",
+			paused:  true,
+			setupMocks: func(mockDB *dbPkg.MockDB) {
+				mockDB.On("AddAutomationPause", "test_address", utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedPauses: AutomationPauses{Addresses: []string{"test_address"}},
+		},
+		{
+			name:            "Should resume an address",
+			address:         "test_address",
+			initiallyPaused: true,
+			setupMocks: func(mockDB *dbPkg.MockDB) {
+				mockDB.On("DeleteAutomationPause", "test_address", utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedPauses: AutomationPauses{Addresses: []string{}},
+		},
+		{
+			name:    "Should resume an address that is not paused",
+			address: "test_address",
+			setupMocks: func(mockDB *dbPkg.MockDB) {
+				mockDB.On("DeleteAutomationPause", "test_address", utils.NetworkMainnet).Return(
+					nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, "automation pause not found"),
+				).Once()
+			},
+			expectedPauses: AutomationPauses{Addresses: []string{}},
+		},
+		{
+			name:             "Should fail for an address that is not staked",
+			address:          "other_address",
+			paused:           true,
+			setupMocks:       func(_ *dbPkg.MockDB) {},
+			expectedPauses:   AutomationPauses{Addresses: []string{}},
+			expectedErrorMsg: "address other_address not found in staking addresses",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := dbPkg.NewMockDB(t)
+			tt.setupMocks(mockDB)
+
+			sm := &stakingManager{
+				db:               mockDB,
+				stakingAddresses: []StakingAddress{{Address: "test_address"}},
+				pausedAddresses:  map[string]bool{},
+			}
+			if tt.initiallyPaused {
+				sm.pausedAddresses["test_address"] = true
+			}
+
+			err := sm.SetAutomationPaused(tt.address, tt.paused)
+			if tt.expectedErrorMsg != "" {
+				assert.ErrorContains(t, err, tt.expectedErrorMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedPauses, sm.GetAutomationPauses())
+		})
+	}
+}
+
+func TestLoadAutomationPauses(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	mockDB := dbPkg.NewMockDB(t)
+	mockDB.On("GetAutomationPauses", utils.NetworkMainnet).Return([]dbPkg.AutomationPause{
+		{Address: dbPkg.GlobalPauseAddress},
+		{Address: "test_address"},
+	}, nil).Once()
+
+	sm := &stakingManager{db: mockDB, pausedAddresses: map[string]bool{"stale_address": true}}
+
+	assert.NoError(t, sm.loadAutomationPauses())
+	assert.Equal(t, AutomationPauses{Global: true, Addresses: []string{"test_address"}}, sm.GetAutomationPauses())
+}
+
+func TestPausedAutomation(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	t.Run("handleRollsUpdates skips paused addresses", func(t *testing.T) {
+		mockClient := clientDriverPkg.NewMockClientDriver(t)
+		mockDB := dbPkg.NewMockDB(t)
+		mockClient.On("BuyRolls", "test_password", "running_address", uint64(10), massaAmount.FromMAS(0.1)).Return("buy_op", nil).Once()
+		mockDB.On("AddRollOpHistory", "running_address", dbPkg.RollOpBuy, uint64(10), "buy_op", utils.NetworkMainnet).Return(nil).Once()
+
+		addresses := []StakingAddress{
+			{Address: "paused_address", FinalBalance: 100000 * massaAmount.MAS, TargetRolls: 10},
+			{Address: "running_address", FinalBalance: 100000 * massaAmount.MAS, TargetRolls: 10},
+		}
+		sm := &stakingManager{
+			db:               mockDB,
+			clientDriver:     mockClient,
+			miscellaneous:    Miscellaneous{MinimalFees: massaAmount.FromMAS(0.1), RollPrice: 100 * massaAmount.MAS},
+			stakingAddresses: addresses,
+			pausedAddresses:  map[string]bool{"paused_address": true},
+		}
+
+		sm.handleRollsUpdates(addresses)
+	})
+
+	t.Run("handleRollsUpdates skips all addresses when paused globally", func(t *testing.T) {
+		addresses := []StakingAddress{
+			{Address: "test_address", FinalBalance: 100000 * massaAmount.MAS, TargetRolls: 10},
+			{Address: "other_address", FinalBalance: 100000 * massaAmount.MAS, CandidateRolls: 10, TargetRolls: 0},
+		}
+		sm := &stakingManager{
+			db:               dbPkg.NewMockDB(t),
+			clientDriver:     clientDriverPkg.NewMockClientDriver(t),
+			miscellaneous:    Miscellaneous{MinimalFees: massaAmount.FromMAS(0.1), RollPrice: 100 * massaAmount.MAS},
+			stakingAddresses: addresses,
+			automationPaused: true,
+		}
+
+		sm.handleRollsUpdates(addresses)
+	})
+
+	t.Run("SetTargetRolls saves the target without selling or buying", func(t *testing.T) {
+		mockDB := dbPkg.NewMockDB(t)
+		mockDB.On("UpdateRollsTarget", "test_address", int64(10), utils.NetworkMainnet).Return(nil).Once()
+
+		sm := &stakingManager{
+			db:                       mockDB,
+			clientDriver:             clientDriverPkg.NewMockClientDriver(t),
+			addressChangedDispatcher: NewAddressChangedDispatcher(),
+			miscellaneous:            Miscellaneous{MinimalFees: massaAmount.FromMAS(0.1), RollPrice: 100 * massaAmount.MAS},
+			stakingAddresses:         []StakingAddress{{Address: "test_address", FinalBalance: 100000 * massaAmount.MAS, TargetRolls: 5}},
+			automationPaused:         true,
+		}
+
+		assert.NoError(t, sm.SetTargetRolls("test_address", 10))
+		assert.Equal(t, int64(10), sm.stakingAddresses[0].TargetRolls)
+	})
+
+	t.Run("handleSweeps skips paused addresses", func(t *testing.T) {
+		mockDB := dbPkg.NewMockDB(t)
+		mockDB.On("GetSweepRules", utils.NetworkMainnet).Return([]dbPkg.SweepRule{
+			{Address: sweepAddress, ColdAddress: sweepColdAddress, Threshold: 100 * massaAmount.MAS, Reserve: 50 * massaAmount.MAS},
+		}, nil).Once()
+
+		addresses := []StakingAddress{{Address: sweepAddress, FinalBalance: 250 * massaAmount.MAS}}
+		sm := &stakingManager{
+			db:               mockDB,
+			clientDriver:     clientDriverPkg.NewMockClientDriver(t),
+			miscellaneous:    Miscellaneous{MinimalFees: massaAmount.FromMAS(0.01)},
+			stakingAddresses: addresses,
+			pausedAddresses:  map[string]bool{sweepAddress: true},
+		}
+
+		sm.handleSweeps(addresses)
+		assert.Nil(t, sm.stakingAddresses[0].pendingOperationId)
+	})
+}
@@ -0,0 +1,78 @@
+package stakingManager
+
+import (
+	configPkg "github.com/massalabs/node-manager-plugin/int/config"
+	"github.com/massalabs/node-manager-plugin/int/db"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+// MissRate returns the percentage of blocks missed by the address during the cycle
+func (c CycleInfo) MissRate() float64 {
+	total := c.OkCount + c.NokCount
+	if total == 0 {
+		return 0
+	}
+	return float64(c.NokCount) * 100 / float64(total)
+}
+
+/*
+recordCycleStats saves the produced and missed blocks count of each cycle of the addresses in database.
+For final cycles, it raises an EventMissRateThresholdExceeded event if the miss rate is above the configured threshold.
+*/
+func (s *stakingManager) recordCycleStats(addresses []StakingAddress) {
+	currentNetwork := configPkg.GlobalPluginInfo.GetNetwork()
+
+	for _, address := range addresses {
+		for _, cycleInfo := range address.CycleInfos {
+			if err := s.db.UpsertCycleStats(db.CycleStats{
+				Address:     address.Address,
+				Cycle:       cycleInfo.Cycle,
+				OkCount:     cycleInfo.OkCount,
+				NokCount:    cycleInfo.NokCount,
+				ActiveRolls: cycleInfo.ActiveRolls,
+				IsFinal:     cycleInfo.IsFinal,
+			}, currentNetwork); err != nil {
+				logger.Errorf("failed to save cycle %d stats of address %s: %v", cycleInfo.Cycle, address.Address, err)
+			}
+
+			if cycleInfo.IsFinal {
+				s.checkMissRate(address.Address, cycleInfo)
+			}
+		}
+	}
+}
+
+/*
+checkMissRate raises an alert the first time a final cycle of the address has a miss rate above the threshold,
+a negative threshold disables the alert
+*/
+func (s *stakingManager) checkMissRate(address string, cycleInfo CycleInfo) {
+	if s.config == nil || s.config.MissRateAlertThreshold < 0 {
+		return
+	}
+
+	if lastCycle, ok := s.lastMissRateAlertCycle[address]; ok && lastCycle >= cycleInfo.Cycle {
+		return
+	}
+
+	missRate := cycleInfo.MissRate()
+	if missRate <= float64(s.config.MissRateAlertThreshold) {
+		return
+	}
+
+	logger.Warnf("address %s missed %d of its %d blocks in cycle %d (%.2f%%)", address, cycleInfo.NokCount, cycleInfo.OkCount+cycleInfo.NokCount, cycleInfo.Cycle, missRate)
+
+	if s.lastMissRateAlertCycle == nil {
+		s.lastMissRateAlertCycle = make(map[string]uint64)
+	}
+	s.lastMissRateAlertCycle[address] = cycleInfo.Cycle
+
+	s.publishEvent(EventMissRateThresholdExceeded, MissRateAlert{
+		Address:   address,
+		Cycle:     cycleInfo.Cycle,
+		OkCount:   cycleInfo.OkCount,
+		NokCount:  cycleInfo.NokCount,
+		MissRate:  missRate,
+		Threshold: s.config.MissRateAlertThreshold,
+	})
+}
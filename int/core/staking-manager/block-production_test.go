@@ -0,0 +1,189 @@
+package stakingManager
+
+import (
+	"testing"
+
+	configPkg "github.com/massalabs/node-manager-plugin/int/config"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	eventBusPkg "github.com/massalabs/node-manager-plugin/pkg/event-bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCycleInfoMissRate(t *testing.T) {
+	tests := []struct {
+		name      string
+		cycleInfo CycleInfo
+		expected  float64
+	}{
+		{name: "no block drawn", cycleInfo: CycleInfo{}, expected: 0},
+		{name: "no block missed", cycleInfo: CycleInfo{OkCount: 10}, expected: 0},
+		{name: "all blocks missed", cycleInfo: CycleInfo{NokCount: 4}, expected: 100},
+		{name: "one block out of four missed", cycleInfo: CycleInfo{OkCount: 3, NokCount: 1}, expected: 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.cycleInfo.MissRate())
+		})
+	}
+}
+
+func TestRecordCycleStats(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	tests := []struct {
+		name                string
+		threshold           int // 10 if not set
+		addresses           []StakingAddress
+		lastAlertCycle      map[string]uint64
+		setupMocks          func(*dbPkg.MockDB)
+		expectedAlerts      []MissRateAlert
+		expectedAlertCycles map[string]uint64
+	}{
+		{
+			name: "Should save stats without alert when miss rate is under threshold",
+			addresses: []StakingAddress{
+				{
+					Address: "test_address_1",
+					CycleInfos: []CycleInfo{
+						{Cycle: 10, IsFinal: true, OkCount: 19, NokCount: 1, ActiveRolls: 100},
+						{Cycle: 11, IsFinal: false, OkCount: 2, NokCount: 0},
+					},
+				},
+			},
+			setupMocks: func(mockDB *dbPkg.MockDB) {
+				mockDB.On("UpsertCycleStats", dbPkg.CycleStats{Address: "test_address_1", Cycle: 10, IsFinal: true, OkCount: 19, NokCount: 1, ActiveRolls: 100}, utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("UpsertCycleStats", dbPkg.CycleStats{Address: "test_address_1", Cycle: 11, IsFinal: false, OkCount: 2, NokCount: 0}, utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedAlerts:      []MissRateAlert{},
+			expectedAlertCycles: map[string]uint64{},
+		},
+		{
+			name: "Should raise an alert when miss rate of a final cycle is above threshold",
+			addresses: []StakingAddress{
+				{
+					Address: "test_address_1",
+					CycleInfos: []CycleInfo{
+						{Cycle: 10, IsFinal: true, OkCount: 3, NokCount: 1, ActiveRolls: 100},
+					},
+				},
+			},
+			setupMocks: func(mockDB *dbPkg.MockDB) {
+				mockDB.On("UpsertCycleStats", dbPkg.CycleStats{Address: "test_address_1", Cycle: 10, IsFinal: true, OkCount: 3, NokCount: 1, ActiveRolls: 100}, utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedAlerts: []MissRateAlert{
+				{Address: "test_address_1", Cycle: 10, OkCount: 3, NokCount: 1, MissRate: 25, Threshold: 10},
+			},
+			expectedAlertCycles: map[string]uint64{"test_address_1": 10},
+		},
+		{
+			name: "Should not raise an alert for a cycle that is not final",
+			addresses: []StakingAddress{
+				{
+					Address: "test_address_1",
+					CycleInfos: []CycleInfo{
+						{Cycle: 11, IsFinal: false, OkCount: 0, NokCount: 2},
+					},
+				},
+			},
+			setupMocks: func(mockDB *dbPkg.MockDB) {
+				mockDB.On("UpsertCycleStats", dbPkg.CycleStats{Address: "test_address_1", Cycle: 11, IsFinal: false, OkCount: 0, NokCount: 2}, utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedAlerts:      []MissRateAlert{},
+			expectedAlertCycles: map[string]uint64{},
+		},
+		{
+			name: "Should not raise the same alert twice",
+			addresses: []StakingAddress{
+				{
+					Address: "test_address_1",
+					CycleInfos: []CycleInfo{
+						{Cycle: 10, IsFinal: true, OkCount: 0, NokCount: 2},
+					},
+				},
+			},
+			lastAlertCycle: map[string]uint64{"test_address_1": 10},
+			setupMocks: func(mockDB *dbPkg.MockDB) {
+				mockDB.On("UpsertCycleStats", dbPkg.CycleStats{Address: "test_address_1", Cycle: 10, IsFinal: true, OkCount: 0, NokCount: 2}, utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedAlerts:      []MissRateAlert{},
+			expectedAlertCycles: map[string]uint64{"test_address_1": 10},
+		},
+		{
+			name:      "Should not alert when the miss rate alert is disabled",
+			threshold: -1,
+			addresses: []StakingAddress{
+				{
+					Address: "test_address_1",
+					CycleInfos: []CycleInfo{
+						{Cycle: 10, IsFinal: true, OkCount: 0, NokCount: 2},
+					},
+				},
+			},
+			setupMocks: func(mockDB *dbPkg.MockDB) {
+				mockDB.On("UpsertCycleStats", dbPkg.CycleStats{Address: "test_address_1", Cycle: 10, IsFinal: true, OkCount: 0, NokCount: 2}, utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedAlerts:      []MissRateAlert{},
+			expectedAlertCycles: map[string]uint64{},
+		},
+		{
+			name: "Should still check miss rate when stats could not be saved",
+			addresses: []StakingAddress{
+				{
+					Address: "test_address_2",
+					CycleInfos: []CycleInfo{
+						{Cycle: 12, IsFinal: true, OkCount: 1, NokCount: 1},
+					},
+				},
+			},
+			setupMocks: func(mockDB *dbPkg.MockDB) {
+				mockDB.On("UpsertCycleStats", dbPkg.CycleStats{Address: "test_address_2", Cycle: 12, IsFinal: true, OkCount: 1, NokCount: 1}, utils.NetworkMainnet).Return(assert.AnError).Once()
+			},
+			expectedAlerts: []MissRateAlert{
+				{Address: "test_address_2", Cycle: 12, OkCount: 1, NokCount: 1, MissRate: 50, Threshold: 10},
+			},
+			expectedAlertCycles: map[string]uint64{"test_address_2": 12},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := dbPkg.NewMockDB(t)
+			tt.setupMocks(mockDB)
+
+			eventBus := eventBusPkg.NewEventBus(10)
+			eventChan := eventBus.Subscribe(EventMissRateThresholdExceeded, "test")
+
+			threshold := 10
+			if tt.threshold != 0 {
+				threshold = tt.threshold
+			}
+
+			lastAlertCycle := tt.lastAlertCycle
+			if lastAlertCycle == nil {
+				lastAlertCycle = make(map[string]uint64)
+			}
+
+			sm := &stakingManager{
+				db:                     mockDB,
+				config:                 &configPkg.PluginConfig{MissRateAlertThreshold: threshold},
+				eventBus:               eventBus,
+				lastMissRateAlertCycle: lastAlertCycle,
+			}
+
+			sm.recordCycleStats(tt.addresses)
+
+			alerts := []MissRateAlert{}
+			for len(eventChan) > 0 {
+				event := <-eventChan
+				alerts = append(alerts, event.Data.(MissRateAlert))
+			}
+
+			assert.Equal(t, tt.expectedAlerts, alerts)
+			assert.Equal(t, tt.expectedAlertCycles, sm.lastMissRateAlertCycle)
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package stakingManager
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/massalabs/node-manager-plugin/int/config"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+// AccountCredentials identifies a wallet plugin account and the password to unlock it
+type AccountCredentials struct {
+	Nickname string `json:"nickname"`
+	Password string `json:"password"`
+}
+
+/*
+ImportResult is the outcome of the import of one account as a staking address.
+Success is true when the address is staked by the node, Error may then still report a non blocking failure.
+*/
+type ImportResult struct {
+	Nickname string `json:"nickname"`
+	Address  string `json:"address,omitempty"` // empty if the account could not be unlocked
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ListWalletAccounts returns the accounts of the wallet plugin and whether they are already staking addresses
+func (s *stakingManager) ListWalletAccounts() ([]WalletAccount, error) {
+	accounts, err := s.walletManager.ListAccounts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallet accounts: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range accounts {
+		accounts[i].IsStaking = s.ramAddressListContains(accounts[i].Address)
+	}
+
+	return accounts, nil
+}
+
+/*
+AddStakingAddresses imports several wallet plugin accounts as staking addresses.
+All the accounts are validated first, then the keys of the valid ones are added to the node with a single call.
+It returns a result for each account, in the same order. An error is returned only if nothing could be attempted.
+*/
+func (s *stakingManager) AddStakingAddresses(pwdNode string, accounts []AccountCredentials) ([]ImportResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.nodeIsUp {
+		return nil, fmt.Errorf("massa node is not up")
+	}
+
+	results := make([]ImportResult, len(accounts))
+	validIndexes := []int{}
+	privateKeys := []string{}
+	addresses := []string{}
+
+	// validate every account before touching the node
+	for i, account := range accounts {
+		results[i].Nickname = account.Nickname
+
+		if account.Nickname == "" {
+			results[i].Error = "nickname is empty"
+			continue
+		}
+
+		if slices.ContainsFunc(accounts[:i], func(a AccountCredentials) bool { return a.Nickname == account.Nickname }) {
+			results[i].Error = fmt.Sprintf("nickname %s is duplicated in the request", account.Nickname)
+			continue
+		}
+
+		privateKey, address, err := s.walletManager.GetPrivateKeyFromNickname(account.Password, account.Nickname)
+		if err != nil {
+			results[i].Error = fmt.Sprintf("failed to get address and priv key from nickname %s: %v", account.Nickname, err)
+			continue
+		}
+		results[i].Address = address
+
+		if s.ramAddressListContains(address) {
+			results[i].Error = fmt.Sprintf("address %s already in staking addresses", address)
+			continue
+		}
+
+		if slices.Contains(addresses, address) {
+			results[i].Error = fmt.Sprintf("address %s is duplicated in the request", address)
+			continue
+		}
+
+		validIndexes = append(validIndexes, i)
+		privateKeys = append(privateKeys, privateKey)
+		addresses = append(addresses, address)
+	}
+
+	if len(addresses) == 0 {
+		return results, nil
+	}
+
+	// add all the keys at once
+	if err := s.clientDriver.AddStakingAddresses(pwdNode, privateKeys, addresses); err != nil {
+		for _, i := range validIndexes {
+			results[i].Error = fmt.Sprintf("failed to add address %s to node staking addresses: %v", results[i].Address, err)
+		}
+		return results, nil
+	}
+
+	currentNetwork := config.GlobalPluginInfo.GetNetwork()
+
+	addedAddresses := []string{}
+	for _, i := range validIndexes {
+		// Default to -1 rolls target (auto-compounding) -> buy as many rolls as possible
+		if err := s.db.AddRollsTarget(results[i].Address, -1, currentNetwork); err != nil {
+			results[i].Error = fmt.Sprintf("address added to node staking addresses but failed to add address to rolls_target table in local database: %v", err)
+		}
+		results[i].Success = true
+		addedAddresses = append(addedAddresses, results[i].Address)
+
+		// the address is no longer watch-only
+		s.dropWatchOnlyAddress(results[i].Address)
+		s.labelFromNickname(results[i].Address, results[i].Nickname)
+		s.recordAddressEvent(results[i].Address, dbPkg.AddressEventAdded, false)
+	}
+
+	// get addresses data from node
+	addressesData, err := s.getAddressesDataFromNode(addedAddresses)
+	if err != nil {
+		// the addresses are staked: add them without data to the ram list, it will be filled at next poll
+		logger.Errorf("addresses added to node staking addresses but failed to get their data from node to update ram list: %v", err)
+		addressesData = make([]StakingAddress, len(addedAddresses))
+		for i, address := range addedAddresses {
+			addressesData[i] = StakingAddress{Address: address, TargetRolls: -1}
+		}
+	}
+
+	s.stakingAddresses = append(s.stakingAddresses, addressesData...)
+	s.addressChangedDispatcher.Publish(s.stakingAddresses)
+
+	logger.Infof("%d staking addresses imported out of %d", len(addedAddresses), len(accounts))
+
+	return results, nil
+}
@@ -0,0 +1,144 @@
+package stakingManager
+
+import (
+	"testing"
+
+	clientDriverPkg "github.com/massalabs/node-manager-plugin/int/client-driver"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	nodeAPIPkg "github.com/massalabs/node-manager-plugin/int/node-api"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddStakingAddresses(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	addressesResponse := []byte(`[
+		{"address":"address_1","final_roll_count":0,"candidate_roll_count":0,"final_balance":"100.0","candidate_balance":"100.0","thread":0,"deferred_credits":[]},
+		{"address":"address_2","final_roll_count":0,"candidate_roll_count":0,"final_balance":"50.0","candidate_balance":"50.0","thread":1,"deferred_credits":[]}
+	]`)
+
+	tests := []struct {
+		name            string
+		nodeIsUp        bool
+		accounts        []AccountCredentials
+		existingAddr    []StakingAddress
+		setupMocks      func(*clientDriverPkg.MockClientDriver, *dbPkg.MockDB, *nodeAPIPkg.MockNodeAPI, *MockMassaWalletManager)
+		expectedResults []ImportResult
+		expectedError   string
+		expectedInRam   []string
+	}{
+		{
+			name:     "Should fail when node is not up",
+			nodeIsUp: false,
+			accounts: []AccountCredentials{{Nickname: "wallet_1", Password: "pwd"}},
+			setupMocks: func(*clientDriverPkg.MockClientDriver, *dbPkg.MockDB, *nodeAPIPkg.MockNodeAPI, *MockMassaWalletManager) {
+			},
+			expectedError: "massa node is not up",
+		},
+		{
+			name:     "Should add all valid accounts with a single call and report invalid ones",
+			nodeIsUp: true,
+			accounts: []AccountCredentials{
+				{Nickname: "wallet_1", Password: "pwd1"},
+				{Nickname: "wallet_bad_pwd", Password: "wrong"},
+				{Nickname: "wallet_2", Password: "pwd2"},
+				{Nickname: "wallet_1", Password: "pwd1"},
+				{Nickname: "wallet_staked", Password: "pwd3"},
+				{Nickname: "", Password: "pwd"},
+			},
+			existingAddr: []StakingAddress{{Address: "address_staked"}},
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB, mockNodeAPI *nodeAPIPkg.MockNodeAPI, mockWallet *MockMassaWalletManager) {
+				mockWallet.On("GetPrivateKeyFromNickname", "pwd1", "wallet_1").Return("key_1", "address_1", nil).Once()
+				mockWallet.On("GetPrivateKeyFromNickname", "wrong", "wallet_bad_pwd").Return("", "", assert.AnError).Once()
+				mockWallet.On("GetPrivateKeyFromNickname", "pwd2", "wallet_2").Return("key_2", "address_2", nil).Once()
+				mockWallet.On("GetPrivateKeyFromNickname", "pwd3", "wallet_staked").Return("key_3", "address_staked", nil).Once()
+				mockClient.On("AddStakingAddresses", "node_password", []string{"key_1", "key_2"}, []string{"address_1", "address_2"}).Return(nil).Once()
+				mockDB.On("AddRollsTarget", "address_1", int64(-1), utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("AddRollsTarget", "address_2", int64(-1), utils.NetworkMainnet).Return(assert.AnError).Once()
+				mockDB.On("AddAddressEvent", "address_1", dbPkg.AddressEventAdded, false, utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("AddAddressEvent", "address_2", dbPkg.AddressEventAdded, false, utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("InitAddressLabel", "address_1", "wallet_1", utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("InitAddressLabel", "address_2", "wallet_2", utils.NetworkMainnet).Return(nil).Once()
+				mockNodeAPI.On("GetAddresses", []string{"address_1", "address_2"}).Return(addressesResponse, nil).Once()
+				mockClient.On("WalletInfo", mock.Anything).Return(map[string]clientDriverPkg.WalletInfo{}, nil).Once()
+				mockDB.On("GetRollsTarget", utils.NetworkMainnet).Return([]dbPkg.AddressInfo{{Address: "address_1", RollTarget: -1}}, nil).Once()
+			},
+			expectedResults: []ImportResult{
+				{Nickname: "wallet_1", Address: "address_1", Success: true},
+				{Nickname: "wallet_bad_pwd", Error: "failed to get address and priv key from nickname wallet_bad_pwd"},
+				{Nickname: "wallet_2", Address: "address_2", Success: true, Error: "failed to add address to rolls_target table"},
+				{Nickname: "wallet_1", Error: "nickname wallet_1 is duplicated in the request"},
+				{Nickname: "wallet_staked", Address: "address_staked", Error: "address address_staked already in staking addresses"},
+				{Nickname: "", Error: "nickname is empty"},
+			},
+			expectedInRam: []string{"address_staked", "address_1", "address_2"},
+		},
+		{
+			name:     "Should report the node error for every valid account",
+			nodeIsUp: true,
+			accounts: []AccountCredentials{
+				{Nickname: "wallet_1", Password: "pwd1"},
+				{Nickname: "wallet_2", Password: "pwd2"},
+			},
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, _ *dbPkg.MockDB, _ *nodeAPIPkg.MockNodeAPI, mockWallet *MockMassaWalletManager) {
+				mockWallet.On("GetPrivateKeyFromNickname", "pwd1", "wallet_1").Return("key_1", "address_1", nil).Once()
+				mockWallet.On("GetPrivateKeyFromNickname", "pwd2", "wallet_2").Return("key_2", "address_2", nil).Once()
+				mockClient.On("AddStakingAddresses", "node_password", []string{"key_1", "key_2"}, []string{"address_1", "address_2"}).Return(assert.AnError).Once()
+			},
+			expectedResults: []ImportResult{
+				{Nickname: "wallet_1", Address: "address_1", Error: "failed to add address address_1 to node staking addresses"},
+				{Nickname: "wallet_2", Address: "address_2", Error: "failed to add address address_2 to node staking addresses"},
+			},
+			expectedInRam: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := clientDriverPkg.NewMockClientDriver(t)
+			mockDB := dbPkg.NewMockDB(t)
+			mockNodeAPI := nodeAPIPkg.NewMockNodeAPI(t)
+			mockWalletManager := &MockMassaWalletManager{}
+
+			tt.setupMocks(mockClient, mockDB, mockNodeAPI, mockWalletManager)
+
+			sm := &stakingManager{
+				clientDriver:             mockClient,
+				nodeAPI:                  mockNodeAPI,
+				db:                       mockDB,
+				nodeIsUp:                 tt.nodeIsUp,
+				stakingAddresses:         append([]StakingAddress{}, tt.existingAddr...),
+				walletManager:            mockWalletManager,
+				addressChangedDispatcher: NewAddressChangedDispatcher(),
+			}
+
+			results, err := sm.AddStakingAddresses("node_password", tt.accounts)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+
+			assert.Len(t, results, len(tt.expectedResults))
+			for i, expected := range tt.expectedResults {
+				assert.Equal(t, expected.Nickname, results[i].Nickname)
+				assert.Equal(t, expected.Address, results[i].Address)
+				assert.Equal(t, expected.Success, results[i].Success)
+				if expected.Error == "" {
+					assert.Empty(t, results[i].Error)
+				} else {
+					assert.Contains(t, results[i].Error, expected.Error)
+				}
+			}
+
+			assert.Equal(t, tt.expectedInRam, sm.getAddressesFromRamList())
+
+			mockWalletManager.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package stakingManager
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/massalabs/node-manager-plugin/int/config"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+// rollsDeactivationCycles is the number of cycles after which sold rolls stop being active and are credited back
+const rollsDeactivationCycles = 3
+
+// DecommissionStatus is the progress of the decommissioning of a staking address
+type DecommissionStatus struct {
+	Address   string                 `json:"address"`
+	Step      dbPkg.DecommissionStep `json:"step"`
+	StepIndex int                    `json:"step_index"` // 1-based position of the current step
+	StepCount int                    `json:"step_count"`
+	StartedAt time.Time              `json:"started_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+	ETA       *time.Time             `json:"eta,omitempty"` // estimated end of the decommission, nil if unknown
+}
+
+/*
+DecommissionStakingAddress starts the staged removal of a staking address:
+its rolls are sold but the address keeps staking until they are no longer active,
+then the deferred credits are waited for, the key is removed from the node and the history is archived.
+The workflow is stored in the database and performed by the monitoring loop.
+*/
+func (s *stakingManager) DecommissionStakingAddress(address string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.nodeIsUp {
+		return fmt.Errorf("massa node is not up")
+	}
+
+	index, ok := s.getAddressIndexFromRamList(address)
+	if !ok {
+		return fmt.Errorf("address %s not found in staking addresses", address)
+	}
+
+	currentNetwork := config.GlobalPluginInfo.GetNetwork()
+
+	_, err := s.db.GetDecommission(address, currentNetwork)
+	if err == nil {
+		return fmt.Errorf("address %s is already being decommissioned", address)
+	}
+	if !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		return fmt.Errorf("failed to check if address %s is being decommissioned: %w", address, err)
+	}
+
+	// a target of 0 rolls makes the roll automation sell all the rolls of the address
+	if err := s.db.UpdateRollsTarget(address, 0, currentNetwork); err != nil {
+		if !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+			return fmt.Errorf("failed to set target rolls of address %s (%s) to 0: %w", address, string(currentNetwork), err)
+		}
+		if err := s.db.AddRollsTarget(address, 0, currentNetwork); err != nil {
+			return fmt.Errorf("failed to set target rolls of address %s (%s) to 0: %w", address, string(currentNetwork), err)
+		}
+	}
+
+	if err := s.db.AddDecommission(address, currentNetwork); err != nil {
+		return fmt.Errorf("failed to start decommission of address %s: %w", address, err)
+	}
+
+	s.stakingAddresses[index].TargetRolls = 0
+	s.stakingAddresses[index].decommissioning = true
+	s.addressChangedDispatcher.Publish(s.stakingAddresses)
+
+	logger.Infof("Decommission of address %s started", address)
+
+	s.muSellBuyRolls.Lock()
+	defer s.muSellBuyRolls.Unlock()
+
+	if err := s.sellBuyRollsAddress(s.stakingAddresses[index]); err != nil {
+		logger.Warnf("failed to sell rolls of decommissioned address %s, will retry at next poll: %v", address, err)
+	}
+
+	return nil
+}
+
+// GetDecommissions returns the progress of the decommissions in progress on the current network
+func (s *stakingManager) GetDecommissions() ([]DecommissionStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	decommissions, err := s.db.GetDecommissions(config.GlobalPluginInfo.GetNetwork())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decommissions from database: %w", err)
+	}
+
+	statuses := make([]DecommissionStatus, len(decommissions))
+	for i, decommission := range decommissions {
+		statuses[i] = DecommissionStatus{
+			Address:   decommission.Address,
+			Step:      decommission.Step,
+			StepIndex: slices.Index(dbPkg.DecommissionSteps, decommission.Step) + 1,
+			StepCount: len(dbPkg.DecommissionSteps),
+			StartedAt: decommission.StartedAt,
+			UpdatedAt: decommission.UpdatedAt,
+			ETA:       s.decommissionETA(decommission, time.Now()),
+		}
+	}
+
+	return statuses, nil
+}
+
+/*
+advanceDecommissions moves each decommission forward as far as the addresses data allows.
+It returns the addresses that are still staked, without those whose key has been removed.
+*/
+func (s *stakingManager) advanceDecommissions(addresses []StakingAddress) []StakingAddress {
+	currentNetwork := config.GlobalPluginInfo.GetNetwork()
+
+	decommissions, err := s.db.GetDecommissions(currentNetwork)
+	if err != nil {
+		logger.Errorf("failed to get decommissions from database: %v", err)
+		return addresses
+	}
+
+	for _, decommission := range decommissions {
+		var addressData *StakingAddress
+		if i := slices.IndexFunc(addresses, func(addr StakingAddress) bool { return addr.Address == decommission.Address }); i >= 0 {
+			addressData = &addresses[i]
+		}
+
+		if index, ok := s.getAddressIndexFromRamList(decommission.Address); ok {
+			s.stakingAddresses[index].decommissioning = true
+		}
+
+		step, err := s.advanceDecommission(decommission.Address, decommission.Step, addressData, currentNetwork)
+		if err != nil {
+			logger.Errorf("failed to advance decommission of address %s (step %s): %v", decommission.Address, step, err)
+		}
+
+		if step == dbPkg.DecommissionStepArchivingHistory {
+			addresses = slices.DeleteFunc(addresses, func(addr StakingAddress) bool { return addr.Address == decommission.Address })
+		}
+	}
+
+	return addresses
+}
+
+/*
+advanceDecommission performs the decommission steps of an address until one of them can't be completed yet.
+Each completed step is saved in the database so that the workflow resumes where it stopped after a restart.
+addressData is nil if the address is no longer staked by the node. It returns the step reached.
+*/
+func (s *stakingManager) advanceDecommission(address string, step dbPkg.DecommissionStep, addressData *StakingAddress, network utils.Network) (dbPkg.DecommissionStep, error) {
+	for {
+		var next dbPkg.DecommissionStep
+
+		switch step {
+		case dbPkg.DecommissionStepSellingRolls:
+			if addressData != nil && (addressData.CandidateRolls > 0 || addressData.FinalRolls > 0 || addressData.ActiveRolls > 0) {
+				return step, nil
+			}
+			next = dbPkg.DecommissionStepWaitingDeferredCredits
+
+		case dbPkg.DecommissionStepWaitingDeferredCredits:
+			if addressData != nil && len(addressData.DeferredCredits) > 0 {
+				return step, nil
+			}
+			next = dbPkg.DecommissionStepRemovingKey
+
+		case dbPkg.DecommissionStepRemovingKey:
+			if addressData != nil {
+				if err := s.clientDriver.RemoveStakingAddress(config.GlobalPluginInfo.GetPwd(), address); err != nil {
+					return step, fmt.Errorf("failed to remove address %s from staking: %w", address, err)
+				}
+				s.removeAddressFromRamList(address)
+				s.addressChangedDispatcher.Publish(s.stakingAddresses)
+			}
+
+			if err := s.db.DeleteRollsTarget(address, network); err != nil && !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+				logger.Errorf("failed to remove rolls target data for address %s (%s) from database: %v", address, string(network), err)
+			}
+			s.recordAddressEvent(address, dbPkg.AddressEventRemoved, false)
+			next = dbPkg.DecommissionStepArchivingHistory
+
+		case dbPkg.DecommissionStepArchivingHistory:
+			if err := s.db.ArchiveRollOpHistoryByAddress(address, network); err != nil && !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+				return step, fmt.Errorf("failed to archive roll operation history: %w", err)
+			}
+
+			if err := s.deleteAutomationPause(address, network); err != nil {
+				return step, err
+			}
+
+			if err := s.db.DeleteDecommission(address, network); err != nil {
+				return step, fmt.Errorf("failed to delete completed decommission: %w", err)
+			}
+
+			logger.Infof("Decommission of address %s completed", address)
+			return step, nil
+
+		default:
+			return step, fmt.Errorf("unknown decommission step %s", step)
+		}
+
+		if err := s.db.UpdateDecommissionStep(address, next, network); err != nil {
+			return step, fmt.Errorf("failed to save decommission step %s: %w", next, err)
+		}
+
+		logger.Infof("Decommission of address %s: step %s completed, now %s", address, step, next)
+		step = next
+	}
+}
+
+/*
+decommissionETA estimates when the decommission of an address will be completed.
+The key can be removed once the rolls are inactive and the deferred credits paid, which happens at the latest
+deferred credit slot or, if the rolls are not sold yet, rollsDeactivationCycles cycles after the current one.
+*/
+func (s *stakingManager) decommissionETA(decommission dbPkg.Decommission, now time.Time) *time.Time {
+	eta := now.Add(time.Duration(s.stakingAddressDataPollInterval) * time.Second)
+
+	index, ok := s.getAddressIndexFromRamList(decommission.Address)
+	if !ok || decommission.Step == dbPkg.DecommissionStepRemovingKey || decommission.Step == dbPkg.DecommissionStepArchivingHistory {
+		// remaining steps are performed at next poll
+		return &eta
+	}
+
+	if s.clock == nil {
+		return nil
+	}
+
+	address := s.stakingAddresses[index]
+
+	if address.CandidateRolls > 0 || (address.ActiveRolls > 0 && len(address.DeferredCredits) == 0) {
+		currentCycle, err := s.clock.TimeToCycle(now)
+		if err != nil {
+			return nil
+		}
+		eta = s.clock.CycleEndTime(currentCycle + rollsDeactivationCycles)
+	}
+
+	for _, credit := range address.DeferredCredits {
+		if credit.UnlockTime != nil && credit.UnlockTime.After(eta) {
+			eta = *credit.UnlockTime
+		}
+	}
+
+	return &eta
+}
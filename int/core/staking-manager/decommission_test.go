@@ -0,0 +1,189 @@
+package stakingManager
+
+import (
+	"testing"
+	"time"
+
+	clientDriverPkg "github.com/massalabs/node-manager-plugin/int/client-driver"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	massaTime "github.com/massalabs/node-manager-plugin/int/massa-time"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdvanceDecommission(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	const address = "test_address"
+
+	tests := []struct {
+		name             string
+		step             dbPkg.DecommissionStep
+		addressData      *StakingAddress
+		setupMocks       func(*clientDriverPkg.MockClientDriver, *dbPkg.MockDB)
+		expectedStep     dbPkg.DecommissionStep
+		expectedError    string
+		expectedInRam    bool
+		expectedRamCount int
+	}{
+		{
+			name:          "Should wait while rolls are still active",
+			step:          dbPkg.DecommissionStepSellingRolls,
+			addressData:   &StakingAddress{Address: address, ActiveRolls: 2},
+			setupMocks:    func(*clientDriverPkg.MockClientDriver, *dbPkg.MockDB) {},
+			expectedStep:  dbPkg.DecommissionStepSellingRolls,
+			expectedInRam: true,
+		},
+		{
+			name: "Should wait for deferred credits once rolls are inactive",
+			step: dbPkg.DecommissionStepSellingRolls,
+			addressData: &StakingAddress{
+				Address:         address,
+				DeferredCredits: []DeferredCredit{{Slot: Slot{Period: 100, Thread: 0}, Amount: 200 * massaAmount.MAS}},
+			},
+			setupMocks: func(_ *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockDB.On("UpdateDecommissionStep", address, dbPkg.DecommissionStepWaitingDeferredCredits, utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedStep:  dbPkg.DecommissionStepWaitingDeferredCredits,
+			expectedInRam: true,
+		},
+		{
+			name:        "Should complete all remaining steps when credits are paid",
+			step:        dbPkg.DecommissionStepWaitingDeferredCredits,
+			addressData: &StakingAddress{Address: address},
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockDB.On("UpdateDecommissionStep", address, dbPkg.DecommissionStepRemovingKey, utils.NetworkMainnet).Return(nil).Once()
+				mockClient.On("RemoveStakingAddress", "test_password", address).Return(nil).Once()
+				mockDB.On("DeleteRollsTarget", address, utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("AddAddressEvent", address, dbPkg.AddressEventRemoved, false, utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("UpdateDecommissionStep", address, dbPkg.DecommissionStepArchivingHistory, utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("ArchiveRollOpHistoryByAddress", address, utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("DeleteAutomationPause", address, utils.NetworkMainnet).Return(nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, "not paused")).Once()
+				mockDB.On("DeleteDecommission", address, utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedStep:  dbPkg.DecommissionStepArchivingHistory,
+			expectedInRam: false,
+		},
+		{
+			name:        "Should stay at key removal step when the key can't be removed",
+			step:        dbPkg.DecommissionStepRemovingKey,
+			addressData: &StakingAddress{Address: address},
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, _ *dbPkg.MockDB) {
+				mockClient.On("RemoveStakingAddress", "test_password", address).Return(assert.AnError).Once()
+			},
+			expectedStep:  dbPkg.DecommissionStepRemovingKey,
+			expectedError: "failed to remove address test_address from staking",
+			expectedInRam: true,
+		},
+		{
+			name:        "Should archive history of an address no longer staked by the node",
+			step:        dbPkg.DecommissionStepSellingRolls,
+			addressData: nil,
+			setupMocks: func(_ *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockDB.On("UpdateDecommissionStep", address, dbPkg.DecommissionStepWaitingDeferredCredits, utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("UpdateDecommissionStep", address, dbPkg.DecommissionStepRemovingKey, utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("DeleteRollsTarget", address, utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("AddAddressEvent", address, dbPkg.AddressEventRemoved, false, utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("UpdateDecommissionStep", address, dbPkg.DecommissionStepArchivingHistory, utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("ArchiveRollOpHistoryByAddress", address, utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("DeleteAutomationPause", address, utils.NetworkMainnet).Return(nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, "not paused")).Once()
+				mockDB.On("DeleteDecommission", address, utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedStep:  dbPkg.DecommissionStepArchivingHistory,
+			expectedInRam: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := clientDriverPkg.NewMockClientDriver(t)
+			mockDB := dbPkg.NewMockDB(t)
+			tt.setupMocks(mockClient, mockDB)
+
+			sm := &stakingManager{
+				clientDriver:             mockClient,
+				db:                       mockDB,
+				addressChangedDispatcher: NewAddressChangedDispatcher(),
+				stakingAddresses:         []StakingAddress{{Address: address}},
+			}
+
+			step, err := sm.advanceDecommission(address, tt.step, tt.addressData, utils.NetworkMainnet)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedStep, step)
+			assert.Equal(t, tt.expectedInRam, sm.ramAddressListContains(address))
+		})
+	}
+}
+
+func TestDecommissionETA(t *testing.T) {
+	clock, err := massaTime.NewClock(0, 16000, 32, 128)
+	assert.NoError(t, err)
+
+	now := time.UnixMilli(0).Add(10 * time.Minute) // cycle 0
+	pollInterval := 30 * time.Second
+	creditTime := now.Add(2 * time.Hour)
+
+	tests := []struct {
+		name     string
+		step     dbPkg.DecommissionStep
+		address  StakingAddress
+		clock    *massaTime.Clock
+		expected *time.Time
+	}{
+		{
+			name:     "rolls not sold yet",
+			step:     dbPkg.DecommissionStepSellingRolls,
+			address:  StakingAddress{Address: "addr", CandidateRolls: 3},
+			clock:    clock,
+			expected: ptr(clock.CycleEndTime(rollsDeactivationCycles)),
+		},
+		{
+			name: "waiting for deferred credits",
+			step: dbPkg.DecommissionStepWaitingDeferredCredits,
+			address: StakingAddress{
+				Address:         "addr",
+				DeferredCredits: []DeferredCredit{{Amount: 100 * massaAmount.MAS, UnlockTime: &creditTime}},
+			},
+			clock:    clock,
+			expected: &creditTime,
+		},
+		{
+			name:     "key removal at next poll",
+			step:     dbPkg.DecommissionStepRemovingKey,
+			address:  StakingAddress{Address: "addr"},
+			expected: ptr(now.Add(pollInterval)),
+		},
+		{
+			name:     "unknown without clock",
+			step:     dbPkg.DecommissionStepSellingRolls,
+			address:  StakingAddress{Address: "addr", CandidateRolls: 3},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := &stakingManager{
+				stakingAddresses:               []StakingAddress{tt.address},
+				stakingAddressDataPollInterval: uint64(pollInterval.Seconds()),
+				clock:                          tt.clock,
+			}
+
+			eta := sm.decommissionETA(dbPkg.Decommission{Address: "addr", Step: tt.step}, now)
+			assert.Equal(t, tt.expected, eta)
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
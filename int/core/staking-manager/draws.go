@@ -0,0 +1,33 @@
+package stakingManager
+
+import (
+	"fmt"
+
+	nodeAPI "github.com/massalabs/node-manager-plugin/int/node-api"
+)
+
+// GetNextDraws returns the upcoming block and endorsement draws of the staking addresses
+func (s *stakingManager) GetNextDraws() ([]nodeAPI.AddressDraws, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.nodeIsUp {
+		return nil, fmt.Errorf("massa node is not up")
+	}
+
+	if len(s.stakingAddresses) == 0 {
+		return []nodeAPI.AddressDraws{}, nil
+	}
+
+	addresses := make([]string, len(s.stakingAddresses))
+	for i, addr := range s.stakingAddresses {
+		addresses[i] = addr.Address
+	}
+
+	draws, err := s.nodeAPI.GetNextDraws(addresses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next draws of staking addresses: %w", err)
+	}
+
+	return draws, nil
+}
@@ -0,0 +1,43 @@
+package stakingManager
+
+import (
+	eventBusPkg "github.com/massalabs/node-manager-plugin/pkg/event-bus"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+const (
+	// EventMissRateThresholdExceeded is raised when the ratio of missed blocks of a staking address over a final cycle is above the configured threshold
+	EventMissRateThresholdExceeded eventBusPkg.EventType = "MISS_RATE_THRESHOLD_EXCEEDED"
+	// EventRollOperationBlocked is raised when a spending guardrail prevents a roll operation from being sent, its data is a db.BlockedRollOp
+	EventRollOperationBlocked eventBusPkg.EventType = "ROLL_OPERATION_BLOCKED"
+	// EventUnexplainedBalanceChange is raised when a balance change of a staking address can't be explained by the staking activity, its data is a db.LedgerEntry
+	EventUnexplainedBalanceChange eventBusPkg.EventType = "UNEXPLAINED_BALANCE_CHANGE"
+)
+
+// StakingEventTypes lists all the event types raised by the staking manager
+var StakingEventTypes = []eventBusPkg.EventType{
+	EventMissRateThresholdExceeded,
+	EventRollOperationBlocked,
+	EventUnexplainedBalanceChange,
+}
+
+// MissRateAlert is the data of an EventMissRateThresholdExceeded event
+type MissRateAlert struct {
+	Address   string  `json:"address"`
+	Cycle     uint64  `json:"cycle"`
+	OkCount   uint64  `json:"ok_count"`
+	NokCount  uint64  `json:"nok_count"`
+	MissRate  float64 `json:"miss_rate"` // percentage
+	Threshold int     `json:"threshold"` // percentage
+}
+
+// publishEvent publishes an event on the event bus if the staking manager has one
+func (s *stakingManager) publishEvent(eventType eventBusPkg.EventType, data interface{}) {
+	if s.eventBus == nil {
+		return
+	}
+
+	if err := s.eventBus.Publish(eventBusPkg.Event{Type: eventType, Data: data}); err != nil {
+		logger.Debugf("failed to publish event %s: %v", eventType, err)
+	}
+}
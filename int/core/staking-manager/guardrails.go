@@ -0,0 +1,131 @@
+package stakingManager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/massalabs/node-manager-plugin/int/config"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+// rollSpendWindow is the period over which the MAS spent on rolls is limited by MaxRollSpendPerDay
+const rollSpendWindow = 24 * time.Hour
+
+/*
+applyRollGuardrails checks the spending guardrails of the plugin config before the roll engine sends a roll operation.
+It returns the amount of rolls that can be sent: the amount is lowered to the maximum rolls per operation
+and to the rolls the remaining daily budget can buy.
+If nothing can be sent, the operation is blocked: it is recorded with the reason, an EventRollOperationBlocked event is raised
+and an ErrStakingManagerRollOperationBlocked error is returned.
+*/
+func (s *stakingManager) applyRollGuardrails(address string, op dbPkg.RollOp, amount uint64) (uint64, error) {
+	if s.config == nil {
+		return amount, nil
+	}
+
+	currentNetwork := config.GlobalPluginInfo.GetNetwork()
+
+	reason, allowed, err := s.rollGuardrailsVerdict(address, op, amount, currentNetwork, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to check spending guardrails: %w", err)
+	}
+
+	if reason != "" {
+		s.blockRollOperation(dbPkg.BlockedRollOp{Address: address, Op: op, Amount: amount, Reason: reason}, currentNetwork)
+		return 0, nodeManagerError.New(
+			nodeManagerError.ErrStakingManagerRollOperationBlocked,
+			fmt.Sprintf("%s of %d rolls for address %s blocked: %s", op, amount, address, reason),
+		)
+	}
+
+	if allowed < amount {
+		logger.Infof("%s of %d rolls for address %s limited to %d rolls by the spending guardrails", op, amount, address, allowed)
+	}
+
+	// the address is no longer blocked, a new block will be recorded
+	delete(s.lastBlockedRollOp, address)
+
+	return allowed, nil
+}
+
+// rollGuardrailsVerdict returns why the operation is blocked, or the amount of rolls that can be sent if it is not
+func (s *stakingManager) rollGuardrailsVerdict(address string, op dbPkg.RollOp, amount uint64, network utils.Network, now time.Time) (string, uint64, error) {
+	if s.config.RollOperationsKillSwitch {
+		return "roll operations kill switch is on", 0, nil
+	}
+
+	if s.config.MinRollOperationInterval > 0 {
+		lastOp, err := s.lastRollOperationTime(address, network)
+		if err != nil {
+			return "", 0, err
+		}
+
+		minInterval := time.Duration(s.config.MinRollOperationInterval) * time.Second
+		if lastOp != nil && now.Sub(*lastOp) < minInterval {
+			return fmt.Sprintf("last roll operation of the address was sent %s ago, minimum interval is %s", now.Sub(*lastOp).Round(time.Second), minInterval), 0, nil
+		}
+	}
+
+	if s.config.MaxRollsPerOperation > 0 {
+		amount = min(amount, uint64(s.config.MaxRollsPerOperation))
+	}
+
+	if op == dbPkg.RollOpBuy && s.config.MaxRollSpendPerDay > 0 && s.miscellaneous.RollPrice > 0 {
+		bought, err := s.db.GetRollsBoughtSince(now.Add(-rollSpendWindow), network)
+		if err != nil {
+			return "", 0, err
+		}
+
+		spendLimit := s.config.MaxRollSpendPerDay
+		spent := massaAmount.Amount(bought) * s.miscellaneous.RollPrice
+		affordable := uint64(max((spendLimit-spent)/s.miscellaneous.RollPrice, 0))
+		if affordable == 0 {
+			return fmt.Sprintf("daily roll spending limit of %s MAS reached: %s MAS spent in the last 24 hours", spendLimit, spent), 0, nil
+		}
+		amount = min(amount, affordable)
+	}
+
+	return "", amount, nil
+}
+
+// lastRollOperationTime returns when the last roll buy or sell of the address was sent, nil if there is none
+func (s *stakingManager) lastRollOperationTime(address string, network utils.Network) (*time.Time, error) {
+	history, err := s.db.GetRollOpHistory(address, network)
+	if err != nil {
+		return nil, err
+	}
+
+	// history is sorted newest first, skip the scheduled target changes
+	for _, op := range history {
+		if op.Op == string(dbPkg.RollOpBuy) || op.Op == string(dbPkg.RollOpSell) {
+			return &op.Timestamp, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// blockRollOperation records a blocked roll operation and raises an event, unless it is the same as the last one of the address
+func (s *stakingManager) blockRollOperation(blocked dbPkg.BlockedRollOp, network utils.Network) {
+	if last, ok := s.lastBlockedRollOp[blocked.Address]; ok && last.Op == blocked.Op && last.Amount == blocked.Amount && last.Reason == blocked.Reason {
+		return
+	}
+
+	logger.Warnf("%s of %d rolls for address %s blocked: %s", blocked.Op, blocked.Amount, blocked.Address, blocked.Reason)
+
+	if s.lastBlockedRollOp == nil {
+		s.lastBlockedRollOp = make(map[string]dbPkg.BlockedRollOp)
+	}
+	s.lastBlockedRollOp[blocked.Address] = blocked
+
+	if err := s.db.AddBlockedRollOp(blocked, network); err != nil {
+		logger.Errorf("failed to record blocked roll operation of address %s: %v", blocked.Address, err)
+	}
+
+	blocked.Timestamp = time.Now()
+	s.publishEvent(EventRollOperationBlocked, blocked)
+}
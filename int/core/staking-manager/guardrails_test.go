@@ -0,0 +1,185 @@
+package stakingManager
+
+import (
+	"testing"
+	"time"
+
+	clientDriverPkg "github.com/massalabs/node-manager-plugin/int/client-driver"
+	configPkg "github.com/massalabs/node-manager-plugin/int/config"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRollGuardrails(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	tests := []struct {
+		name            string
+		config          configPkg.PluginConfig
+		targetRolls     int64
+		candidateRolls  uint64
+		setupMocks      func(*clientDriverPkg.MockClientDriver, *dbPkg.MockDB)
+		expectedBlocked bool
+	}{
+		{
+			name:        "Should buy when no guardrail is set",
+			targetRolls: 10,
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockClient.On("BuyRolls", "test_password", "test_address", uint64(10), massaAmount.FromMAS(0.1)).Return("buy_op", nil).Once()
+				mockDB.On("AddRollOpHistory", "test_address", dbPkg.RollOpBuy, uint64(10), "buy_op", utils.NetworkMainnet).Return(nil).Once()
+			},
+		},
+		{
+			name:        "Should block every operation when the kill switch is on",
+			config:      configPkg.PluginConfig{RollOperationsKillSwitch: true},
+			targetRolls: 10,
+			setupMocks: func(_ *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockDB.On("AddBlockedRollOp", dbPkg.BlockedRollOp{
+					Address: "test_address",
+					Op:      dbPkg.RollOpBuy,
+					Amount:  10,
+					Reason:  "roll operations kill switch is on",
+				}, utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedBlocked: true,
+		},
+		{
+			name:        "Should limit the rolls bought by an operation",
+			config:      configPkg.PluginConfig{MaxRollsPerOperation: 4},
+			targetRolls: 10,
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockClient.On("BuyRolls", "test_password", "test_address", uint64(4), massaAmount.FromMAS(0.1)).Return("buy_op", nil).Once()
+				mockDB.On("AddRollOpHistory", "test_address", dbPkg.RollOpBuy, uint64(4), "buy_op", utils.NetworkMainnet).Return(nil).Once()
+			},
+		},
+		{
+			name:           "Should limit the rolls sold by an operation",
+			config:         configPkg.PluginConfig{MaxRollsPerOperation: 4},
+			targetRolls:    0,
+			candidateRolls: 10,
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockClient.On("SellRolls", "test_password", "test_address", uint64(4), massaAmount.FromMAS(0.1)).Return("sell_op", nil).Once()
+				mockDB.On("AddRollOpHistory", "test_address", dbPkg.RollOpSell, uint64(4), "sell_op", utils.NetworkMainnet).Return(nil).Once()
+			},
+		},
+		{
+			name:           "Should block an operation sent too soon after the previous one",
+			config:         configPkg.PluginConfig{MinRollOperationInterval: 3600},
+			targetRolls:    0,
+			candidateRolls: 10,
+			setupMocks: func(_ *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockDB.On("GetRollOpHistory", "test_address", utils.NetworkMainnet).Return([]dbPkg.RollOpHistory{
+					{Op: string(dbPkg.RollOpSetTarget), Amount: 0, Timestamp: time.Now()},
+					{Op: string(dbPkg.RollOpBuy), Amount: 10, Timestamp: time.Now().Add(-10 * time.Minute)},
+				}, nil).Once()
+				mockDB.On("AddBlockedRollOp", mock.MatchedBy(func(blocked dbPkg.BlockedRollOp) bool {
+					return blocked.Op == dbPkg.RollOpSell && blocked.Amount == 10 && blocked.Reason == "last roll operation of the address was sent 10m0s ago, minimum interval is 1h0m0s"
+				}), utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedBlocked: true,
+		},
+		{
+			name:        "Should send an operation once the minimum interval has elapsed",
+			config:      configPkg.PluginConfig{MinRollOperationInterval: 3600},
+			targetRolls: 10,
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockDB.On("GetRollOpHistory", "test_address", utils.NetworkMainnet).Return([]dbPkg.RollOpHistory{
+					{Op: string(dbPkg.RollOpSell), Amount: 10, Timestamp: time.Now().Add(-2 * time.Hour)},
+				}, nil).Once()
+				mockClient.On("BuyRolls", "test_password", "test_address", uint64(10), massaAmount.FromMAS(0.1)).Return("buy_op", nil).Once()
+				mockDB.On("AddRollOpHistory", "test_address", dbPkg.RollOpBuy, uint64(10), "buy_op", utils.NetworkMainnet).Return(nil).Once()
+			},
+		},
+		{
+			name:        "Should limit the rolls bought to the remaining daily budget",
+			config:      configPkg.PluginConfig{MaxRollSpendPerDay: 1000 * massaAmount.MAS},
+			targetRolls: 10,
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockDB.On("GetRollsBoughtSince", mock.Anything, utils.NetworkMainnet).Return(uint64(7), nil).Once()
+				mockClient.On("BuyRolls", "test_password", "test_address", uint64(3), massaAmount.FromMAS(0.1)).Return("buy_op", nil).Once()
+				mockDB.On("AddRollOpHistory", "test_address", dbPkg.RollOpBuy, uint64(3), "buy_op", utils.NetworkMainnet).Return(nil).Once()
+			},
+		},
+		{
+			name:        "Should block buying when the daily budget is spent",
+			config:      configPkg.PluginConfig{MaxRollSpendPerDay: 1000 * massaAmount.MAS},
+			targetRolls: -1,
+			setupMocks: func(_ *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockDB.On("GetRollsBoughtSince", mock.Anything, utils.NetworkMainnet).Return(uint64(10), nil).Once()
+				mockDB.On("AddBlockedRollOp", dbPkg.BlockedRollOp{
+					Address: "test_address",
+					Op:      dbPkg.RollOpBuy,
+					Amount:  1000,
+					Reason:  "daily roll spending limit of 1000 MAS reached: 1000 MAS spent in the last 24 hours",
+				}, utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedBlocked: true,
+		},
+		{
+			name:           "Should not apply the daily budget to sells",
+			config:         configPkg.PluginConfig{MaxRollSpendPerDay: 1000 * massaAmount.MAS},
+			targetRolls:    0,
+			candidateRolls: 10,
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockClient.On("SellRolls", "test_password", "test_address", uint64(10), massaAmount.FromMAS(0.1)).Return("sell_op", nil).Once()
+				mockDB.On("AddRollOpHistory", "test_address", dbPkg.RollOpSell, uint64(10), "sell_op", utils.NetworkMainnet).Return(nil).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := clientDriverPkg.NewMockClientDriver(t)
+			mockDB := dbPkg.NewMockDB(t)
+			tt.setupMocks(mockClient, mockDB)
+
+			address := StakingAddress{
+				Address:        "test_address",
+				FinalBalance:   100000 * massaAmount.MAS,
+				CandidateRolls: tt.candidateRolls,
+				TargetRolls:    tt.targetRolls,
+			}
+			sm := &stakingManager{
+				db:                mockDB,
+				clientDriver:      mockClient,
+				config:            &tt.config,
+				miscellaneous:     Miscellaneous{MinimalFees: massaAmount.FromMAS(0.1), RollPrice: 100 * massaAmount.MAS},
+				stakingAddresses:  []StakingAddress{address},
+				lastBlockedRollOp: make(map[string]dbPkg.BlockedRollOp),
+			}
+
+			err := sm.sellBuyRollsAddress(address)
+			if !tt.expectedBlocked {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, nodeManagerError.Is(err, nodeManagerError.ErrStakingManagerRollOperationBlocked))
+			assert.Nil(t, sm.stakingAddresses[0].pendingOperationId)
+		})
+	}
+}
+
+func TestBlockRollOperationRecordedOnce(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	blocked := dbPkg.BlockedRollOp{Address: "test_address", Op: dbPkg.RollOpBuy, Amount: 10, Reason: "roll operations kill switch is on"}
+
+	mockDB := dbPkg.NewMockDB(t)
+	mockDB.On("AddBlockedRollOp", blocked, utils.NetworkMainnet).Return(nil).Once()
+	changed := blocked
+	changed.Amount = 11
+	mockDB.On("AddBlockedRollOp", changed, utils.NetworkMainnet).Return(nil).Once()
+
+	sm := &stakingManager{db: mockDB}
+
+	sm.blockRollOperation(blocked, utils.NetworkMainnet)
+	sm.blockRollOperation(blocked, utils.NetworkMainnet) // same block at next poll, not recorded
+	sm.blockRollOperation(changed, utils.NetworkMainnet)
+}
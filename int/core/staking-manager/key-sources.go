@@ -0,0 +1,66 @@
+package stakingManager
+
+import (
+	"fmt"
+
+	"github.com/massalabs/station/pkg/logger"
+)
+
+// KeySourceType is where the private key of a staking address to add comes from
+type KeySourceType string
+
+const (
+	KeySourceWalletFile KeySourceType = "WALLET_FILE" // massa wallet account file uploaded by the user
+	KeySourceSecretKey  KeySourceType = "SECRET_KEY"  // raw secret key (S1...)
+	KeySourceGenerated  KeySourceType = "GENERATED"   // new key generated and stored encrypted by the plugin
+)
+
+// KeySource describes a private key to stake with that does not come from the massa wallet plugin
+type KeySource struct {
+	Type       KeySourceType
+	WalletFile []byte // content of the account file, for KeySourceWalletFile
+	Password   string // password of the account file, or of the plugin keystore for KeySourceGenerated
+	SecretKey  string // for KeySourceSecretKey
+	Nickname   string // nickname of the key to generate, for KeySourceGenerated
+}
+
+/*
+AddStakingAddressFromKey adds a staking address whose key is provided directly instead of being read
+from the massa wallet plugin: an uploaded wallet file, a raw secret key or a key generated by the plugin.
+*/
+func (s *stakingManager) AddStakingAddressFromKey(pwdNode string, source KeySource) (StakingAddress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.nodeIsUp {
+		return StakingAddress{}, fmt.Errorf("massa node is not up")
+	}
+
+	var privateKey, address string
+	var err error
+	switch source.Type {
+	case KeySourceWalletFile:
+		privateKey, address, err = s.walletManager.GetPrivateKeyFromWalletFile(source.Password, source.WalletFile)
+	case KeySourceSecretKey:
+		privateKey, address, err = s.walletManager.GetPrivateKeyFromSecretKey(source.SecretKey)
+	case KeySourceGenerated:
+		privateKey, address, err = s.walletManager.GenerateKey(source.Password, source.Nickname)
+	default:
+		return StakingAddress{}, fmt.Errorf("unknown key source %s", source.Type)
+	}
+	if err != nil {
+		return StakingAddress{}, fmt.Errorf("failed to get address and priv key from %s key source: %w", source.Type, err)
+	}
+
+	if err := s.addNodeStakingKey(pwdNode, privateKey, address); err != nil {
+		// the generated key is not used, so that generating it again with the same nickname is possible
+		if source.Type == KeySourceGenerated {
+			if deleteErr := s.walletManager.DeleteGeneratedKey(source.Nickname); deleteErr != nil {
+				logger.Errorf("failed to delete unused generated key %s: %v", source.Nickname, deleteErr)
+			}
+		}
+		return StakingAddress{}, err
+	}
+
+	return s.registerStakingAddress(address)
+}
@@ -0,0 +1,315 @@
+package stakingManager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	clientDriverPkg "github.com/massalabs/node-manager-plugin/int/client-driver"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	nodeAPIPkg "github.com/massalabs/node-manager-plugin/int/node-api"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fixtures of test_data: wallet_upload.yaml is a massa wallet plugin account file,
+// keystore/ is a plugin keystore whose master seed derived the key "generated"
+const (
+	uploadPassword   = "fixture_password"
+	uploadSecretKey  = "S1Ka3NKcnfs8d67EZYU5mbTCVY7Znnd2YQAYjbBfb4XmeYfTma5"
+	uploadAddress    = "AU1KBGdEiu8zYtJ79ouX1Jv7HsWMgXRKK6yWgt93joD2HLQ9euFp"
+	keystorePassword = "keystore_password"
+	generatedKey     = "S12hj7Qmp9qZi9kzG1TEbuyshAey8aKPsTiWA1L1YqHbwrdiA9rC"
+	generatedAddress = "AU18Me3WjZUQH3xSC2sZ4uWBHLzoTCnfWDzr9tgEG2rNpHQKF5hh"
+	secondKey        = "S12hNv2XfNGrXHtXwJSFk7wnWpPrcEFEN419ckC9xF6PYYV5HNqG"
+	secondAddress    = "AU12hHvKMBNUDMuhLuE8oDJ3iBGjEk5eRETdqGL7MWYqvy1NJkhor"
+)
+
+// copyKeystoreFixture copies the keystore fixture in a temporary directory so tests can write in it
+func copyKeystoreFixture(t *testing.T) string {
+	dir := t.TempDir()
+	entries, err := os.ReadDir(filepath.Join("test_data", "keystore"))
+	require.NoError(t, err)
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join("test_data", "keystore", entry.Name()))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, entry.Name()), content, 0o600))
+	}
+	return dir
+}
+
+func TestGetPrivateKeyFromWalletFile(t *testing.T) {
+	walletFile, err := os.ReadFile(filepath.Join("test_data", "wallet_upload.yaml"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		walletFile    []byte
+		password      string
+		expectedError string
+	}{
+		{name: "valid wallet file", walletFile: walletFile, password: uploadPassword},
+		{name: "wrong password", walletFile: walletFile, password: "wrong", expectedError: "failed to decrypt wallet file of account fixture"},
+		{name: "not a wallet file", walletFile: []byte("Version: 3"), password: uploadPassword, expectedError: "invalid wallet file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewMassaWalletManager(t.TempDir())
+			privateKey, address, err := manager.GetPrivateKeyFromWalletFile(tt.password, tt.walletFile)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, uploadSecretKey, privateKey)
+			assert.Equal(t, uploadAddress, address)
+		})
+	}
+}
+
+func TestGetPrivateKeyFromSecretKey(t *testing.T) {
+	manager := NewMassaWalletManager(t.TempDir())
+
+	privateKey, address, err := manager.GetPrivateKeyFromSecretKey(uploadSecretKey)
+	require.NoError(t, err)
+	assert.Equal(t, uploadSecretKey, privateKey)
+	assert.Equal(t, uploadAddress, address)
+
+	_, _, err = manager.GetPrivateKeyFromSecretKey(uploadAddress)
+	assert.ErrorContains(t, err, "invalid secret key")
+}
+
+func TestGenerateKey(t *testing.T) {
+	tests := []struct {
+		name            string
+		password        string
+		nickname        string
+		expectedKey     string
+		expectedAddress string
+		expectedError   string
+	}{
+		{name: "derives the key from the master seed", password: keystorePassword, nickname: "second", expectedKey: secondKey, expectedAddress: secondAddress},
+		{name: "existing nickname", password: keystorePassword, nickname: "generated", expectedError: "a generated key with nickname generated already exists"},
+		{name: "wrong keystore password", password: "wrong", nickname: "third", expectedError: "failed to unlock keystore master seed"},
+		{name: "empty nickname", password: keystorePassword, nickname: "", expectedError: "nickname is required"},
+		{name: "nickname with path separator", password: keystorePassword, nickname: "../outside", expectedError: "must not contain path separators"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keystoreDir := copyKeystoreFixture(t)
+			manager := NewMassaWalletManager(keystoreDir)
+
+			privateKey, address, err := manager.GenerateKey(tt.password, tt.nickname)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedKey, privateKey)
+			assert.Equal(t, tt.expectedAddress, address)
+
+			// the generated key is stored encrypted and can be unlocked by nickname
+			privateKey, address, err = manager.GetPrivateKeyFromNickname(tt.password, tt.nickname)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedKey, privateKey)
+			assert.Equal(t, tt.expectedAddress, address)
+		})
+	}
+}
+
+func TestGenerateKeyCreatesMasterSeed(t *testing.T) {
+	keystoreDir := filepath.Join(t.TempDir(), "keystore")
+	manager := NewMassaWalletManager(keystoreDir)
+
+	privateKey, address, err := manager.GenerateKey(keystorePassword, "first")
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(keystoreDir, masterSeedFileName))
+	assert.FileExists(t, filepath.Join(keystoreDir, "wallet_first.yaml"))
+
+	// a new master seed is random so the key differs from the fixture keystore one
+	assert.NotEqual(t, generatedKey, privateKey)
+
+	// the same master seed always derives the same key for a nickname
+	require.NoError(t, os.Remove(filepath.Join(keystoreDir, "wallet_first.yaml")))
+	privateKeyAgain, addressAgain, err := manager.GenerateKey(keystorePassword, "first")
+	require.NoError(t, err)
+	assert.Equal(t, privateKey, privateKeyAgain)
+	assert.Equal(t, address, addressAgain)
+}
+
+func TestGetPrivateKeyFromNicknameInKeystore(t *testing.T) {
+	manager := NewMassaWalletManager(copyKeystoreFixture(t))
+
+	privateKey, address, err := manager.GetPrivateKeyFromNickname(keystorePassword, "generated")
+	require.NoError(t, err)
+	assert.Equal(t, generatedKey, privateKey)
+	assert.Equal(t, generatedAddress, address)
+
+	_, _, err = manager.GetPrivateKeyFromNickname("wrong", "generated")
+	assert.ErrorContains(t, err, "failed to load generated key generated")
+}
+
+func TestDeleteGeneratedKey(t *testing.T) {
+	manager := NewMassaWalletManager(copyKeystoreFixture(t))
+
+	_, _, err := manager.GenerateKey(keystorePassword, "second")
+	require.NoError(t, err)
+
+	require.NoError(t, manager.DeleteGeneratedKey("second"))
+
+	// the nickname is free again and derives the same key
+	privateKey, address, err := manager.GenerateKey(keystorePassword, "second")
+	require.NoError(t, err)
+	assert.Equal(t, secondKey, privateKey)
+	assert.Equal(t, secondAddress, address)
+
+	assert.ErrorContains(t, manager.DeleteGeneratedKey("missing"), "failed to delete generated key missing")
+	assert.ErrorContains(t, manager.DeleteGeneratedKey("../outside"), "must not contain path separators")
+}
+
+func TestListKeystoreAccounts(t *testing.T) {
+	manager := &massaWalletManager{keystoreDir: copyKeystoreFixture(t)}
+
+	accounts, err := manager.listKeystoreAccounts()
+	require.NoError(t, err)
+	assert.Equal(t, []WalletAccount{{Nickname: "generated", Address: generatedAddress}}, accounts)
+
+	manager.keystoreDir = filepath.Join(t.TempDir(), "missing")
+	accounts, err = manager.listKeystoreAccounts()
+	require.NoError(t, err)
+	assert.Empty(t, accounts)
+}
+
+func TestAddStakingAddressFromKey(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	addressResponse := []byte(`[{"address":"address_1","final_roll_count":0,"candidate_roll_count":0,"final_balance":"100.0","candidate_balance":"100.0","thread":0,"deferred_credits":[]}]`)
+
+	expectNodeRegistration := func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB, mockNodeAPI *nodeAPIPkg.MockNodeAPI) {
+		mockClient.On("AddStakingAddress", "node_password", "key_1", "address_1").Return(nil).Once()
+		mockDB.On("AddRollsTarget", "address_1", int64(-1), utils.NetworkMainnet).Return(nil).Once()
+		mockDB.On("AddAddressEvent", "address_1", dbPkg.AddressEventAdded, false, utils.NetworkMainnet).Return(nil).Once()
+		mockNodeAPI.On("GetAddresses", []string{"address_1"}).Return(addressResponse, nil).Once()
+		mockClient.On("WalletInfo", mock.Anything).Return(map[string]clientDriverPkg.WalletInfo{}, nil).Once()
+		mockDB.On("GetRollsTarget", utils.NetworkMainnet).Return([]dbPkg.AddressInfo{{Address: "address_1", RollTarget: -1}}, nil).Once()
+	}
+
+	tests := []struct {
+		name          string
+		nodeIsUp      bool
+		existingAddr  []StakingAddress
+		source        KeySource
+		setupMocks    func(*clientDriverPkg.MockClientDriver, *dbPkg.MockDB, *nodeAPIPkg.MockNodeAPI, *MockMassaWalletManager)
+		expectedError string
+	}{
+		{
+			name:   "Should fail when node is not up",
+			source: KeySource{Type: KeySourceSecretKey, SecretKey: "key_1"},
+			setupMocks: func(*clientDriverPkg.MockClientDriver, *dbPkg.MockDB, *nodeAPIPkg.MockNodeAPI, *MockMassaWalletManager) {
+			},
+			expectedError: "massa node is not up",
+		},
+		{
+			name:     "Should add an address from an uploaded wallet file",
+			nodeIsUp: true,
+			source:   KeySource{Type: KeySourceWalletFile, WalletFile: []byte("wallet file"), Password: "pwd"},
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB, mockNodeAPI *nodeAPIPkg.MockNodeAPI, mockWallet *MockMassaWalletManager) {
+				mockWallet.On("GetPrivateKeyFromWalletFile", "pwd", []byte("wallet file")).Return("key_1", "address_1", nil).Once()
+				expectNodeRegistration(mockClient, mockDB, mockNodeAPI)
+			},
+		},
+		{
+			name:     "Should add an address from a raw secret key",
+			nodeIsUp: true,
+			source:   KeySource{Type: KeySourceSecretKey, SecretKey: "key_1"},
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB, mockNodeAPI *nodeAPIPkg.MockNodeAPI, mockWallet *MockMassaWalletManager) {
+				mockWallet.On("GetPrivateKeyFromSecretKey", "key_1").Return("key_1", "address_1", nil).Once()
+				expectNodeRegistration(mockClient, mockDB, mockNodeAPI)
+			},
+		},
+		{
+			name:     "Should add an address from a generated key",
+			nodeIsUp: true,
+			source:   KeySource{Type: KeySourceGenerated, Nickname: "generated", Password: "pwd"},
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB, mockNodeAPI *nodeAPIPkg.MockNodeAPI, mockWallet *MockMassaWalletManager) {
+				mockWallet.On("GenerateKey", "pwd", "generated").Return("key_1", "address_1", nil).Once()
+				expectNodeRegistration(mockClient, mockDB, mockNodeAPI)
+			},
+		},
+		{
+			name:     "Should delete the generated key when the node can't stake it",
+			nodeIsUp: true,
+			source:   KeySource{Type: KeySourceGenerated, Nickname: "generated", Password: "pwd"},
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, _ *dbPkg.MockDB, _ *nodeAPIPkg.MockNodeAPI, mockWallet *MockMassaWalletManager) {
+				mockWallet.On("GenerateKey", "pwd", "generated").Return("key_1", "address_1", nil).Once()
+				mockClient.On("AddStakingAddress", "node_password", "key_1", "address_1").Return(assert.AnError).Once()
+				mockWallet.On("DeleteGeneratedKey", "generated").Return(nil).Once()
+			},
+			expectedError: "failed to add address address_1 to node staking addresses",
+		},
+		{
+			name:     "Should fail when the key can't be read",
+			nodeIsUp: true,
+			source:   KeySource{Type: KeySourceWalletFile, WalletFile: []byte("wallet file"), Password: "wrong"},
+			setupMocks: func(_ *clientDriverPkg.MockClientDriver, _ *dbPkg.MockDB, _ *nodeAPIPkg.MockNodeAPI, mockWallet *MockMassaWalletManager) {
+				mockWallet.On("GetPrivateKeyFromWalletFile", "wrong", []byte("wallet file")).Return("", "", assert.AnError).Once()
+			},
+			expectedError: "failed to get address and priv key from WALLET_FILE key source",
+		},
+		{
+			name:         "Should fail when the address is already staking",
+			nodeIsUp:     true,
+			existingAddr: []StakingAddress{{Address: "address_1"}},
+			source:       KeySource{Type: KeySourceSecretKey, SecretKey: "key_1"},
+			setupMocks: func(_ *clientDriverPkg.MockClientDriver, _ *dbPkg.MockDB, _ *nodeAPIPkg.MockNodeAPI, mockWallet *MockMassaWalletManager) {
+				mockWallet.On("GetPrivateKeyFromSecretKey", "key_1").Return("key_1", "address_1", nil).Once()
+			},
+			expectedError: "address address_1 already in staking addresses",
+		},
+		{
+			name:     "Should fail on an unknown key source",
+			nodeIsUp: true,
+			source:   KeySource{Type: "LEDGER"},
+			setupMocks: func(*clientDriverPkg.MockClientDriver, *dbPkg.MockDB, *nodeAPIPkg.MockNodeAPI, *MockMassaWalletManager) {
+			},
+			expectedError: "unknown key source LEDGER",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := clientDriverPkg.NewMockClientDriver(t)
+			mockDB := dbPkg.NewMockDB(t)
+			mockNodeAPI := nodeAPIPkg.NewMockNodeAPI(t)
+			mockWalletManager := &MockMassaWalletManager{}
+
+			tt.setupMocks(mockClient, mockDB, mockNodeAPI, mockWalletManager)
+
+			sm := &stakingManager{
+				clientDriver:             mockClient,
+				nodeAPI:                  mockNodeAPI,
+				db:                       mockDB,
+				nodeIsUp:                 tt.nodeIsUp,
+				stakingAddresses:         append([]StakingAddress{}, tt.existingAddr...),
+				walletManager:            mockWalletManager,
+				addressChangedDispatcher: NewAddressChangedDispatcher(),
+			}
+
+			stakingAddress, err := sm.AddStakingAddressFromKey("node_password", tt.source)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "address_1", stakingAddress.Address)
+				assert.Equal(t, []string{"address_1"}, sm.getAddressesFromRamList())
+			}
+
+			mockWalletManager.AssertExpectations(t)
+		})
+	}
+}
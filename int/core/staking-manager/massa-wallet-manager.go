@@ -1,28 +1,73 @@
 package stakingManager
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"os"
 	"path"
+	"strings"
 
 	"github.com/awnumar/memguard"
+	massaKeys "github.com/massalabs/node-manager-plugin/int/massa-keys"
 	WalletPkg "github.com/massalabs/station-massa-wallet/pkg/wallet"
+	"github.com/massalabs/station/pkg/logger"
 )
 
-// MassaWalletManager defines the interface for managing Massa wallet operations
+// WalletAccount is an account available in the massa wallet plugin
+type WalletAccount struct {
+	Nickname  string `json:"nickname"`
+	Address   string `json:"address"`
+	IsStaking bool   `json:"is_staking"` // set by the staking manager
+}
+
+const (
+	masterSeedFileName = "master_seed.yaml"
+	// keyDerivationPrefix is mixed with the nickname to derive a generated key from the keystore master seed
+	keyDerivationPrefix = "massa-node-manager-staking-key/"
+)
+
+/*
+MassaWalletManager defines the interface for managing Massa wallet operations.
+Keys can come from the massa wallet plugin, from an uploaded account file, from a raw secret key
+or be generated by the plugin and stored encrypted in its own keystore.
+All methods return the private key and the address of the account.
+*/
 type MassaWalletManager interface {
 	GetPrivateKeyFromNickname(pwd, nickname string) (string, string, error)
+	GetPrivateKeyFromWalletFile(pwd string, walletFile []byte) (string, string, error)
+	GetPrivateKeyFromSecretKey(secretKey string) (string, string, error)
+	GenerateKey(pwd, nickname string) (string, string, error)
+	DeleteGeneratedKey(nickname string) error
+	ListAccounts() ([]WalletAccount, error)
 }
 
 // massaWalletManager implements the MassaWalletManager interface
-type massaWalletManager struct{}
+type massaWalletManager struct {
+	keystoreDir string // directory of the keys generated by the plugin
+}
 
-// NewMassaWalletManager creates a new instance of MassaWalletManager
-func NewMassaWalletManager() MassaWalletManager {
-	return &massaWalletManager{}
+// NewMassaWalletManager creates a new instance of MassaWalletManager storing its generated keys in keystoreDir
+func NewMassaWalletManager(keystoreDir string) MassaWalletManager {
+	return &massaWalletManager{keystoreDir: keystoreDir}
 }
 
-// GetPrivateKeyFromNickname returns the private key and address from a nickname
+/*
+GetPrivateKeyFromNickname returns the private key and address from a nickname.
+Keys generated by the plugin are looked up first, then the accounts of the massa wallet plugin.
+*/
 func (m *massaWalletManager) GetPrivateKeyFromNickname(pwd, nickname string) (string, string, error) {
+	keystoreFilePath := path.Join(m.keystoreDir, WalletPkg.Filename(nickname))
+	if _, err := os.Stat(keystoreFilePath); err == nil {
+		keyPair, err := m.loadKeystoreAccount(keystoreFilePath, pwd)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to load generated key %s: %w", nickname, err)
+		}
+		return keyPair.SecretKey(), keyPair.Address(), nil
+	}
+
 	wallet, err := WalletPkg.New("")
 	if err != nil {
 		return "", "", fmt.Errorf("failed to create wallet from nickname %s: %v", nickname, err)
@@ -47,3 +92,234 @@ func (m *massaWalletManager) GetPrivateKeyFromNickname(pwd, nickname string) (st
 
 	return privateKey.String(), address, nil
 }
+
+// GetPrivateKeyFromWalletFile returns the private key and address of an uploaded massa wallet account file
+func (m *massaWalletManager) GetPrivateKeyFromWalletFile(pwd string, walletFile []byte) (string, string, error) {
+	accountFile, err := massaKeys.ParseAccountFile(walletFile)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid wallet file: %w", err)
+	}
+
+	keyPair, err := accountFile.Decrypt(pwd)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decrypt wallet file of account %s: %w", accountFile.Nickname, err)
+	}
+
+	return keyPair.SecretKey(), keyPair.Address(), nil
+}
+
+// GetPrivateKeyFromSecretKey checks a raw secret key (S1...) and returns it with its address
+func (m *massaWalletManager) GetPrivateKeyFromSecretKey(secretKey string) (string, string, error) {
+	keyPair, err := massaKeys.ParseSecretKey(secretKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	return keyPair.SecretKey(), keyPair.Address(), nil
+}
+
+/*
+GenerateKey generates a new key and stores it encrypted with pwd in the plugin keystore under nickname.
+The key is derived from the keystore master seed and the nickname, so it can be generated again
+from a backup of the master seed. The master seed is created on first use and encrypted with the same password.
+*/
+func (m *massaWalletManager) GenerateKey(pwd, nickname string) (string, string, error) {
+	if nickname == "" {
+		return "", "", fmt.Errorf("nickname is required to generate a key")
+	}
+
+	if strings.ContainsAny(nickname, `/\`) {
+		return "", "", fmt.Errorf("nickname %s must not contain path separators", nickname)
+	}
+
+	if err := os.MkdirAll(m.keystoreDir, 0o700); err != nil {
+		return "", "", fmt.Errorf("failed to create keystore directory %s: %w", m.keystoreDir, err)
+	}
+
+	filePath := path.Join(m.keystoreDir, WalletPkg.Filename(nickname))
+	if _, err := os.Stat(filePath); err == nil {
+		return "", "", fmt.Errorf("a generated key with nickname %s already exists", nickname)
+	}
+
+	masterSeed, err := m.loadOrCreateMasterSeed(pwd)
+	if err != nil {
+		return "", "", err
+	}
+
+	mac := hmac.New(sha256.New, masterSeed)
+	mac.Write([]byte(keyDerivationPrefix + nickname))
+	keyPair, err := massaKeys.NewKeyPairFromSeed(mac.Sum(nil))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to derive key %s: %w", nickname, err)
+	}
+
+	if err := writeAccountFile(filePath, nickname, pwd, keyPair); err != nil {
+		return "", "", err
+	}
+
+	return keyPair.SecretKey(), keyPair.Address(), nil
+}
+
+// DeleteGeneratedKey removes a key generated by the plugin from its keystore
+func (m *massaWalletManager) DeleteGeneratedKey(nickname string) error {
+	if strings.ContainsAny(nickname, `/\`) {
+		return fmt.Errorf("nickname %s must not contain path separators", nickname)
+	}
+
+	filePath := path.Join(m.keystoreDir, WalletPkg.Filename(nickname))
+	if err := os.Remove(filePath); err != nil {
+		return fmt.Errorf("failed to delete generated key %s: %w", nickname, err)
+	}
+
+	return nil
+}
+
+// ListAccounts returns the nickname and address of every account stored in the massa wallet plugin and in the plugin keystore
+func (m *massaWalletManager) ListAccounts() ([]WalletAccount, error) {
+	accounts, err := m.listKeystoreAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := WalletPkg.New("")
+	if err != nil {
+		return walletPluginUnavailable(accounts, fmt.Errorf("failed to create wallet: %v", err))
+	}
+
+	entries, err := os.ReadDir(wallet.WalletPath)
+	if err != nil {
+		return walletPluginUnavailable(accounts, fmt.Errorf("failed to read wallet directory %s: %v", wallet.WalletPath, err))
+	}
+
+	for _, entry := range entries {
+		if !isAccountFile(entry) {
+			continue
+		}
+
+		account, err := wallet.Load(path.Join(wallet.WalletPath, entry.Name()))
+		if err != nil {
+			logger.Warnf("failed to load wallet account file %s: %v", entry.Name(), err)
+			continue
+		}
+
+		address, err := account.Address.String()
+		if err != nil {
+			logger.Warnf("failed to get address of wallet account %s: %v", account.Nickname, err)
+			continue
+		}
+
+		accounts = append(accounts, WalletAccount{Nickname: account.Nickname, Address: address})
+	}
+
+	return accounts, nil
+}
+
+// walletPluginUnavailable returns the keystore accounts alone when the massa wallet plugin can't be read, or the error if there are none
+func walletPluginUnavailable(keystoreAccounts []WalletAccount, err error) ([]WalletAccount, error) {
+	if len(keystoreAccounts) == 0 {
+		return nil, err
+	}
+
+	logger.Warnf("only listing the keys generated by the plugin: %v", err)
+	return keystoreAccounts, nil
+}
+
+// listKeystoreAccounts returns the accounts generated by the plugin
+func (m *massaWalletManager) listKeystoreAccounts() ([]WalletAccount, error) {
+	accounts := []WalletAccount{}
+
+	entries, err := os.ReadDir(m.keystoreDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return accounts, nil
+		}
+		return nil, fmt.Errorf("failed to read keystore directory %s: %w", m.keystoreDir, err)
+	}
+
+	for _, entry := range entries {
+		if !isAccountFile(entry) {
+			continue
+		}
+
+		content, err := os.ReadFile(path.Join(m.keystoreDir, entry.Name()))
+		if err != nil {
+			logger.Warnf("failed to read keystore file %s: %v", entry.Name(), err)
+			continue
+		}
+
+		accountFile, err := massaKeys.ParseAccountFile(content)
+		if err != nil {
+			logger.Warnf("failed to parse keystore file %s: %v", entry.Name(), err)
+			continue
+		}
+
+		accounts = append(accounts, WalletAccount{Nickname: accountFile.Nickname, Address: accountFile.Address})
+	}
+
+	return accounts, nil
+}
+
+// loadOrCreateMasterSeed decrypts the keystore master seed, creating it if the keystore has none yet
+func (m *massaWalletManager) loadOrCreateMasterSeed(pwd string) ([]byte, error) {
+	filePath := path.Join(m.keystoreDir, masterSeedFileName)
+
+	if _, err := os.Stat(filePath); err == nil {
+		keyPair, err := m.loadKeystoreAccount(filePath, pwd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unlock keystore master seed: %w", err)
+		}
+		return keyPair.Seed(), nil
+	}
+
+	seed := make([]byte, sha256.Size)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, fmt.Errorf("failed to generate keystore master seed: %w", err)
+	}
+
+	keyPair, err := massaKeys.NewKeyPairFromSeed(seed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate keystore master seed: %w", err)
+	}
+
+	if err := writeAccountFile(filePath, "", pwd, keyPair); err != nil {
+		return nil, err
+	}
+
+	return seed, nil
+}
+
+func (m *massaWalletManager) loadKeystoreAccount(filePath, pwd string) (*massaKeys.KeyPair, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore file %s: %w", filePath, err)
+	}
+
+	accountFile, err := massaKeys.ParseAccountFile(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return accountFile.Decrypt(pwd)
+}
+
+func writeAccountFile(filePath, nickname, pwd string, keyPair *massaKeys.KeyPair) error {
+	accountFile, err := massaKeys.EncryptAccount(nickname, pwd, keyPair)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt key %s: %w", nickname, err)
+	}
+
+	content, err := accountFile.Marshal()
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filePath, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write keystore file %s: %w", filePath, err)
+	}
+
+	return nil
+}
+
+func isAccountFile(entry os.DirEntry) bool {
+	return !entry.IsDir() && strings.HasPrefix(entry.Name(), "wallet_") && strings.HasSuffix(entry.Name(), ".yaml")
+}
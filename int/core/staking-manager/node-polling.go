@@ -0,0 +1,163 @@
+package stakingManager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/massalabs/node-manager-plugin/int/config"
+)
+
+const (
+	getAddressesChunkSize      = 50 // number of addresses sent in a single get_addresses call
+	getAddressesMaxConcurrency = 4  // maximum number of get_addresses calls running at the same time
+)
+
+/*
+activeRollsCache keeps the active rolls read from wallet_info. Active rolls only change at cycle boundaries,
+so the massa-client subprocess spawned by wallet_info only needs to run once per cycle.
+It is guarded by the staking manager mutex, like every caller of getAddressesDataFromNode.
+*/
+type activeRollsCache struct {
+	cycle *uint64 // cycle during which the cache has been filled, nil if the cycle was unknown
+	rolls map[string]uint64
+}
+
+// fetchAddresses calls get_addresses by chunks of chunkSize addresses, running at most maxConcurrency calls at the same time
+func (s *stakingManager) fetchAddresses(addresses []string, chunkSize, maxConcurrency int) ([]getAddressesResponse, error) {
+	chunks := slices.Collect(slices.Chunk(addresses, chunkSize))
+	results := make([][]getAddressesResponse, len(chunks))
+	errs := make([]error, len(chunks))
+
+	semaphore := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			results[i], errs[i] = s.fetchAddressesChunk(chunk)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return slices.Concat(results...), nil
+}
+
+func (s *stakingManager) fetchAddressesChunk(addresses []string) ([]getAddressesResponse, error) {
+	js, err := s.nodeAPI.GetAddresses(addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []getAddressesResponse
+	if err := json.Unmarshal(js, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+/*
+getActiveRolls returns the active rolls of the addresses for the current cycle.
+They are read from the cycle_infos returned by get_addresses when the node knows them,
+otherwise from wallet_info, which is cached until the next cycle.
+*/
+func (s *stakingManager) getActiveRolls(addresses []getAddressesResponse) (map[string]uint64, error) {
+	currentCycle := s.currentCycle(addresses)
+
+	activeRolls := make(map[string]uint64, len(addresses))
+	missing := []string{}
+	for _, addr := range addresses {
+		if rolls, ok := activeRollsFromCycleInfos(addr, currentCycle); ok {
+			activeRolls[addr.Address] = rolls
+			continue
+		}
+		missing = append(missing, addr.Address)
+	}
+
+	if len(missing) == 0 {
+		return activeRolls, nil
+	}
+
+	if !s.activeRollsCache.covers(currentCycle, missing) {
+		walletInfos, err := s.clientDriver.WalletInfo(config.GlobalPluginInfo.GetPwd())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get wallet info: %v", err)
+		}
+
+		// wallet_info returns every staking address of the node, addresses it doesn't know have no active rolls
+		rolls := make(map[string]uint64, len(walletInfos)+len(missing))
+		for address, walletInfo := range walletInfos {
+			rolls[address] = walletInfo.AddressInfo.ActiveRolls
+		}
+		for _, address := range missing {
+			rolls[address] = walletInfos[address].AddressInfo.ActiveRolls
+		}
+		s.activeRollsCache = activeRollsCache{cycle: currentCycle, rolls: rolls}
+	}
+
+	for _, address := range missing {
+		activeRolls[address] = s.activeRollsCache.rolls[address]
+	}
+
+	return activeRolls, nil
+}
+
+// covers returns whether the cache has been filled during the cycle and contains all the addresses
+func (c activeRollsCache) covers(cycle *uint64, addresses []string) bool {
+	if c.cycle == nil || cycle == nil || *c.cycle != *cycle {
+		return false
+	}
+
+	for _, address := range addresses {
+		if _, ok := c.rolls[address]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// currentCycle returns the current cycle from the clock if available, otherwise the latest cycle known by the node. It returns nil if unknown
+func (s *stakingManager) currentCycle(addresses []getAddressesResponse) *uint64 {
+	if s.clock != nil {
+		if cycle, err := s.clock.TimeToCycle(time.Now()); err == nil {
+			return &cycle
+		}
+	}
+
+	var latest *uint64
+	for _, addr := range addresses {
+		for _, cycleInfo := range addr.CycleInfos {
+			if latest == nil || cycleInfo.Cycle > *latest {
+				cycle := cycleInfo.Cycle
+				latest = &cycle
+			}
+		}
+	}
+
+	return latest
+}
+
+func activeRollsFromCycleInfos(addr getAddressesResponse, cycle *uint64) (uint64, bool) {
+	if cycle == nil {
+		return 0, false
+	}
+
+	for _, cycleInfo := range addr.CycleInfos {
+		if cycleInfo.Cycle == *cycle && cycleInfo.ActiveRolls != nil {
+			return *cycleInfo.ActiveRolls, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,238 @@
+package stakingManager
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	clientDriverPkg "github.com/massalabs/node-manager-plugin/int/client-driver"
+	nodeAPIPkg "github.com/massalabs/node-manager-plugin/int/node-api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func makeAddresses(count int) []string {
+	addresses := make([]string, count)
+	for i := range addresses {
+		addresses[i] = fmt.Sprintf("address_%d", i)
+	}
+	return addresses
+}
+
+// getAddressesJSON builds a get_addresses response for the addresses, with a single non final cycle
+func getAddressesJSON(t testing.TB, addresses []string, cycle uint64) []byte {
+	res := make([]getAddressesResponse, len(addresses))
+	for i, address := range addresses {
+		res[i] = getAddressesResponse{
+			Address:          address,
+			FinalBalance:     "100",
+			CandidateBalance: "100",
+			CycleInfos:       []CycleInfoDtoNode{{Cycle: cycle}},
+		}
+	}
+	js, err := json.Marshal(res)
+	require.NoError(t, err)
+	return js
+}
+
+func TestFetchAddresses(t *testing.T) {
+	addresses := makeAddresses(120)
+
+	t.Run("Should split the addresses in chunks and keep their order", func(t *testing.T) {
+		mockNodeAPI := nodeAPIPkg.NewMockNodeAPI(t)
+		for _, chunk := range [][]string{addresses[:50], addresses[50:100], addresses[100:]} {
+			mockNodeAPI.On("GetAddresses", chunk).Return(getAddressesJSON(t, chunk, 10), nil).Once()
+		}
+		sm := &stakingManager{nodeAPI: mockNodeAPI}
+
+		res, err := sm.fetchAddresses(addresses, 50, 2)
+		require.NoError(t, err)
+		require.Len(t, res, len(addresses))
+		for i, addr := range res {
+			assert.Equal(t, addresses[i], addr.Address)
+		}
+	})
+
+	t.Run("Should fail if a chunk fails", func(t *testing.T) {
+		mockNodeAPI := nodeAPIPkg.NewMockNodeAPI(t)
+		mockNodeAPI.On("GetAddresses", addresses[:50]).Return(getAddressesJSON(t, addresses[:50], 10), nil).Once()
+		mockNodeAPI.On("GetAddresses", addresses[50:100]).Return(nil, assert.AnError).Once()
+		mockNodeAPI.On("GetAddresses", addresses[100:]).Return(getAddressesJSON(t, addresses[100:], 10), nil).Once()
+		sm := &stakingManager{nodeAPI: mockNodeAPI}
+
+		_, err := sm.fetchAddresses(addresses, 50, 4)
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestGetActiveRolls(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	withCycle := func(address string, cycle uint64, activeRolls *uint64) getAddressesResponse {
+		return getAddressesResponse{Address: address, CycleInfos: []CycleInfoDtoNode{{Cycle: cycle - 1, ActiveRolls: ptr(uint64(1))}, {Cycle: cycle, ActiveRolls: activeRolls}}}
+	}
+	walletInfo := func(rolls map[string]uint64) map[string]clientDriverPkg.WalletInfo {
+		infos := map[string]clientDriverPkg.WalletInfo{}
+		for address, activeRolls := range rolls {
+			infos[address] = clientDriverPkg.WalletInfo{AddressInfo: clientDriverPkg.AddressInfo{ActiveRolls: activeRolls}}
+		}
+		return infos
+	}
+
+	tests := []struct {
+		name          string
+		cache         activeRollsCache
+		addresses     []getAddressesResponse
+		setupMocks    func(*clientDriverPkg.MockClientDriver)
+		expected      map[string]uint64
+		expectedCache *uint64
+		expectedError string
+	}{
+		{
+			name:       "Should read active rolls from cycle infos without calling wallet_info",
+			addresses:  []getAddressesResponse{withCycle("address_1", 10, ptr(uint64(5))), withCycle("address_2", 10, ptr(uint64(0)))},
+			setupMocks: func(*clientDriverPkg.MockClientDriver) {},
+			expected:   map[string]uint64{"address_1": 5, "address_2": 0},
+		},
+		{
+			name:      "Should call wallet_info when the node does not know the active rolls of the current cycle",
+			addresses: []getAddressesResponse{withCycle("address_1", 10, ptr(uint64(5))), withCycle("address_2", 10, nil)},
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver) {
+				mockClient.On("WalletInfo", mock.Anything).Return(walletInfo(map[string]uint64{"address_1": 4, "address_2": 7}), nil).Once()
+			},
+			expected:      map[string]uint64{"address_1": 5, "address_2": 7},
+			expectedCache: ptr(uint64(10)),
+		},
+		{
+			name:       "Should use the cache during the same cycle",
+			cache:      activeRollsCache{cycle: ptr(uint64(10)), rolls: map[string]uint64{"address_1": 3, "address_2": 7}},
+			addresses:  []getAddressesResponse{withCycle("address_1", 10, nil), withCycle("address_2", 10, nil)},
+			setupMocks: func(*clientDriverPkg.MockClientDriver) {},
+			expected:   map[string]uint64{"address_1": 3, "address_2": 7},
+		},
+		{
+			name:      "Should refresh the cache when the cycle changes",
+			cache:     activeRollsCache{cycle: ptr(uint64(9)), rolls: map[string]uint64{"address_1": 3}},
+			addresses: []getAddressesResponse{withCycle("address_1", 10, nil)},
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver) {
+				mockClient.On("WalletInfo", mock.Anything).Return(walletInfo(map[string]uint64{"address_1": 6}), nil).Once()
+			},
+			expected:      map[string]uint64{"address_1": 6},
+			expectedCache: ptr(uint64(10)),
+		},
+		{
+			name:      "Should refresh the cache when an address is missing from it",
+			cache:     activeRollsCache{cycle: ptr(uint64(10)), rolls: map[string]uint64{"address_1": 3}},
+			addresses: []getAddressesResponse{withCycle("address_1", 10, nil), withCycle("address_new", 10, nil)},
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver) {
+				mockClient.On("WalletInfo", mock.Anything).Return(walletInfo(map[string]uint64{"address_1": 3}), nil).Once()
+			},
+			expected:      map[string]uint64{"address_1": 3, "address_new": 0},
+			expectedCache: ptr(uint64(10)),
+		},
+		{
+			name:      "Should not cache when the cycle is unknown",
+			cache:     activeRollsCache{rolls: map[string]uint64{"address_1": 3}},
+			addresses: []getAddressesResponse{{Address: "address_1"}},
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver) {
+				mockClient.On("WalletInfo", mock.Anything).Return(walletInfo(map[string]uint64{"address_1": 2}), nil).Once()
+			},
+			expected: map[string]uint64{"address_1": 2},
+		},
+		{
+			name:      "Should fail when wallet_info fails",
+			addresses: []getAddressesResponse{withCycle("address_1", 10, nil)},
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver) {
+				mockClient.On("WalletInfo", mock.Anything).Return(nil, assert.AnError).Once()
+			},
+			expectedError: "failed to get wallet info",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := clientDriverPkg.NewMockClientDriver(t)
+			tt.setupMocks(mockClient)
+
+			sm := &stakingManager{clientDriver: mockClient, activeRollsCache: tt.cache}
+
+			activeRolls, err := sm.getActiveRolls(tt.addresses)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, activeRolls)
+			if tt.expectedCache != nil {
+				assert.Equal(t, tt.expectedCache, sm.activeRollsCache.cycle)
+			}
+		})
+	}
+}
+
+// benchNodeAPI answers get_addresses with a latency growing with the number of requested addresses
+type benchNodeAPI struct {
+	nodeAPIPkg.NodeAPI
+	tb testing.TB
+}
+
+func (n *benchNodeAPI) GetAddresses(addresses []string) ([]byte, error) {
+	time.Sleep(time.Millisecond + time.Duration(len(addresses))*20*time.Microsecond)
+	return getAddressesJSON(n.tb, addresses, 10), nil
+}
+
+// benchClientDriver answers wallet_info with the latency of spawning a massa-client subprocess
+type benchClientDriver struct {
+	clientDriverPkg.ClientDriver
+}
+
+func (c *benchClientDriver) WalletInfo(string) (map[string]clientDriverPkg.WalletInfo, error) {
+	time.Sleep(50 * time.Millisecond)
+	return map[string]clientDriverPkg.WalletInfo{}, nil
+}
+
+/*
+BenchmarkAddressesPolling compares a polling tick of 200 addresses done the previous way, with a single
+get_addresses call and a wallet_info call on every tick, with the chunked get_addresses calls and the cached wallet_info.
+*/
+func BenchmarkAddressesPolling(b *testing.B) {
+	cleanup := setupLog(b)
+	defer cleanup()
+
+	addresses := makeAddresses(200)
+
+	benchmarks := []struct {
+		name           string
+		chunkSize      int
+		maxConcurrency int
+		cached         bool
+	}{
+		{name: "single call and wallet_info every tick", chunkSize: len(addresses), maxConcurrency: 1},
+		{name: "chunked calls and wallet_info every tick", chunkSize: getAddressesChunkSize, maxConcurrency: getAddressesMaxConcurrency},
+		{name: "chunked calls and cached wallet_info", chunkSize: getAddressesChunkSize, maxConcurrency: getAddressesMaxConcurrency, cached: true},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			sm := &stakingManager{nodeAPI: &benchNodeAPI{tb: b}, clientDriver: &benchClientDriver{}}
+
+			for b.Loop() {
+				if !bm.cached {
+					sm.activeRollsCache = activeRollsCache{}
+				}
+
+				res, err := sm.fetchAddresses(addresses, bm.chunkSize, bm.maxConcurrency)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				if _, err := sm.getActiveRolls(res); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package stakingManager
+
+import (
+	"slices"
+	"time"
+
+	configPkg "github.com/massalabs/node-manager-plugin/int/config"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+// pendingLedgerOpTimeout is the time after which an operation sent by the plugin that never showed up in the balance is forgotten
+const pendingLedgerOpTimeout = 10 * time.Minute
+
+// reconciliationState holds what the reconciliation of the balance changes needs to remember between two polls
+type reconciliationState struct {
+	network    utils.Network
+	lastTime   time.Time                    // time of the last reconciliation, zero if none has been done yet
+	addresses  map[string]reconciledAddress // address -> state at the last reconciliation
+	pendingOps map[string][]pendingLedgerOp // address -> operations sent by the plugin not reconciled yet, oldest first
+}
+
+// reconciledAddress is the final state of an address at the last reconciliation
+type reconciledAddress struct {
+	finalBalance    massaAmount.Amount
+	finalRolls      uint64
+	activeRolls     uint64
+	deferredCredits map[Slot]massaAmount.Amount // slot -> amount
+	okCounts        map[uint64]uint64           // cycle -> produced blocks
+}
+
+// pendingLedgerOp is an operation sent by the plugin that is expected to change the balance of an address
+type pendingLedgerOp struct {
+	kind      dbPkg.LedgerEntryKind // LedgerRollBuy, LedgerRollSell or LedgerSweep
+	rolls     uint64
+	amount    massaAmount.Amount // swept amount
+	fee       massaAmount.Amount
+	opId      string
+	timestamp time.Time
+}
+
+func newReconciledAddress(address StakingAddress) reconciledAddress {
+	state := reconciledAddress{
+		finalBalance:    address.FinalBalance,
+		finalRolls:      address.FinalRolls,
+		activeRolls:     address.ActiveRolls,
+		deferredCredits: make(map[Slot]massaAmount.Amount, len(address.DeferredCredits)),
+		okCounts:        make(map[uint64]uint64, len(address.CycleInfos)),
+	}
+	for _, credit := range address.DeferredCredits {
+		state.deferredCredits[credit.Slot] += credit.Amount
+	}
+	for _, cycleInfo := range address.CycleInfos {
+		state.okCounts[cycleInfo.Cycle] = cycleInfo.OkCount
+	}
+	return state
+}
+
+/*
+reconcileBalances explains the change of the final balance of each staking address since the previous poll
+and records it in the ledger. Roll operations and sweeps sent by the plugin are matched with the roll and balance changes,
+released deferred credits are detected from the credits that disappeared and what remains is attributed to rewards
+as long as the blocks produced and the time elapsed allow it.
+Any movement left is recorded as an external transfer and raises an EventUnexplainedBalanceChange event.
+*/
+func (s *stakingManager) reconcileBalances(addresses []StakingAddress) {
+	now := time.Now()
+	network := configPkg.GlobalPluginInfo.GetNetwork()
+	state := &s.reconciliation
+
+	// the previous states are meaningless on another network
+	if state.network != network || state.lastTime.IsZero() {
+		state.network = network
+		state.pendingOps = make(map[string][]pendingLedgerOp)
+	} else {
+		if err := s.collectPendingLedgerOps(state.lastTime, network); err != nil {
+			// keep the previous states, the next poll reconciles both intervals at once
+			logger.Errorf("failed to reconcile balance changes: %v", err)
+			return
+		}
+
+		var entries []dbPkg.LedgerEntry
+		for _, address := range addresses {
+			previous, ok := state.addresses[address.Address]
+			if !ok {
+				continue
+			}
+			entries = append(entries, s.reconcileAddress(previous, address, now.Sub(state.lastTime), now)...)
+		}
+
+		if len(entries) > 0 {
+			if err := s.db.AddLedgerEntries(entries, network); err != nil {
+				logger.Errorf("failed to save ledger entries: %v", err)
+			}
+		}
+
+		for _, entry := range entries {
+			if entry.Kind == dbPkg.LedgerExternalTransfer {
+				logger.Warnf("unexplained balance change of %s MAS for address %s", entry.Amount, entry.Address)
+				s.publishEvent(EventUnexplainedBalanceChange, entry)
+			}
+		}
+	}
+
+	state.addresses = make(map[string]reconciledAddress, len(addresses))
+	for _, address := range addresses {
+		state.addresses[address.Address] = newReconciledAddress(address)
+	}
+
+	for address, ops := range state.pendingOps {
+		if _, ok := state.addresses[address]; !ok {
+			delete(state.pendingOps, address)
+			continue
+		}
+		ops = slices.DeleteFunc(ops, func(op pendingLedgerOp) bool {
+			return now.Sub(op.timestamp) > pendingLedgerOpTimeout
+		})
+		if len(ops) == 0 {
+			delete(state.pendingOps, address)
+		} else {
+			state.pendingOps[address] = ops
+		}
+	}
+
+	state.lastTime = now
+}
+
+// collectPendingLedgerOps adds the roll operations and sweeps sent since the last reconciliation to the pending operations
+func (s *stakingManager) collectPendingLedgerOps(since time.Time, network utils.Network) error {
+	rollOps, err := s.db.GetRollOpHistorySince(since, network)
+	if err != nil {
+		return err
+	}
+
+	sweeps, err := s.db.GetSweepHistorySince(since, network)
+	if err != nil {
+		return err
+	}
+
+	fee := s.miscellaneous.MinimalFees
+	for _, rollOp := range rollOps {
+		var kind dbPkg.LedgerEntryKind
+		switch dbPkg.RollOp(rollOp.Op) {
+		case dbPkg.RollOpBuy:
+			kind = dbPkg.LedgerRollBuy
+		case dbPkg.RollOpSell:
+			kind = dbPkg.LedgerRollSell
+		default:
+			continue
+		}
+		s.reconciliation.pendingOps[rollOp.Address] = append(s.reconciliation.pendingOps[rollOp.Address], pendingLedgerOp{
+			kind:      kind,
+			rolls:     rollOp.Amount,
+			fee:       fee,
+			opId:      rollOp.OpId,
+			timestamp: rollOp.Timestamp,
+		})
+	}
+
+	for _, sweep := range sweeps {
+		if sweep.DryRun {
+			continue
+		}
+		s.reconciliation.pendingOps[sweep.Address] = append(s.reconciliation.pendingOps[sweep.Address], pendingLedgerOp{
+			kind:      dbPkg.LedgerSweep,
+			amount:    sweep.Amount,
+			fee:       sweep.Fee,
+			opId:      sweep.OpId,
+			timestamp: sweep.Timestamp,
+		})
+	}
+
+	return nil
+}
+
+// reconcileAddress classifies the balance change of an address since its previous state, consuming the pending operations it explains
+func (s *stakingManager) reconcileAddress(previous reconciledAddress, address StakingAddress, elapsed time.Duration, now time.Time) []dbPkg.LedgerEntry {
+	cycle := s.ledgerCycle(address, now)
+
+	var entries []dbPkg.LedgerEntry
+	addEntry := func(kind dbPkg.LedgerEntryKind, amount massaAmount.Amount, rolls int64, opId string) {
+		entries = append(entries, dbPkg.LedgerEntry{
+			Timestamp: now,
+			Address:   address.Address,
+			Cycle:     cycle,
+			Kind:      kind,
+			Amount:    amount,
+			Rolls:     rolls,
+			OpId:      opId,
+		})
+	}
+
+	rollPrice := s.miscellaneous.RollPrice
+	pending := s.reconciliation.pendingOps[address.Address]
+	residual := address.FinalBalance - previous.finalBalance
+
+	// consumeRollOps matches the rolls bought or sold with the roll operations sent by the plugin
+	consumeRollOps := func(kind dbPkg.LedgerEntryKind, rolls uint64) {
+		sign := int64(1)
+		price := rollPrice
+		if kind == dbPkg.LedgerRollSell {
+			// sold rolls are credited later as deferred credits
+			sign = -1
+			price = 0
+		}
+
+		var remaining []pendingLedgerOp
+		for _, op := range pending {
+			if op.kind != kind || op.rolls > rolls {
+				remaining = append(remaining, op)
+				continue
+			}
+			rolls -= op.rolls
+			addEntry(kind, -massaAmount.Amount(op.rolls)*price, sign*int64(op.rolls), op.opId)
+			addEntry(dbPkg.LedgerFee, -op.fee, 0, op.opId)
+			residual += massaAmount.Amount(op.rolls)*price + op.fee
+		}
+		pending = remaining
+
+		// rolls bought or sold outside of the plugin
+		if rolls > 0 {
+			addEntry(kind, -massaAmount.Amount(rolls)*price, sign*int64(rolls), "")
+			residual += massaAmount.Amount(rolls) * price
+		}
+	}
+
+	if address.FinalRolls > previous.finalRolls {
+		consumeRollOps(dbPkg.LedgerRollBuy, address.FinalRolls-previous.finalRolls)
+	} else if address.FinalRolls < previous.finalRolls {
+		consumeRollOps(dbPkg.LedgerRollSell, previous.finalRolls-address.FinalRolls)
+	}
+
+	current := newReconciledAddress(address)
+	var released massaAmount.Amount
+	for slot, amount := range previous.deferredCredits {
+		if _, ok := current.deferredCredits[slot]; !ok {
+			released += amount
+		}
+	}
+	if released > 0 {
+		addEntry(dbPkg.LedgerDeferredCreditRelease, released, 0, "")
+		residual -= released
+	}
+
+	// the rewards can't exceed the reward of the blocks produced, and the rewards of every slot for the endorsements
+	var blocksProduced uint64
+	for cycle, okCount := range current.okCounts {
+		if okCount > previous.okCounts[cycle] {
+			blocksProduced += okCount - previous.okCounts[cycle]
+		}
+	}
+	blockReward := s.miscellaneous.BlockReward
+	maxBlockRewards := massaAmount.Amount(blocksProduced) * blockReward
+	var maxEndorsementRewards massaAmount.Amount
+	if s.clock != nil && (previous.activeRolls > 0 || address.ActiveRolls > 0) {
+		slotDuration := s.clock.SlotDuration()
+		slots := (elapsed + slotDuration - 1) / slotDuration
+		maxEndorsementRewards = massaAmount.Amount(slots) * blockReward
+	}
+
+	// a sweep is matched once the balance dropped by its amount and fee, give or take the rewards of the interval
+	var remaining []pendingLedgerOp
+	for _, op := range pending {
+		if op.kind != dbPkg.LedgerSweep || residual >= 0 || residual+op.amount+op.fee > maxBlockRewards+maxEndorsementRewards {
+			remaining = append(remaining, op)
+			continue
+		}
+		addEntry(dbPkg.LedgerSweep, -op.amount, 0, op.opId)
+		addEntry(dbPkg.LedgerFee, -op.fee, 0, op.opId)
+		residual += op.amount + op.fee
+	}
+	pending = remaining
+
+	if residual > 0 {
+		if rewards := min(residual, maxBlockRewards); rewards > 0 {
+			addEntry(dbPkg.LedgerBlockReward, rewards, 0, "")
+			residual -= rewards
+		}
+		if rewards := min(residual, maxEndorsementRewards); rewards > 0 {
+			addEntry(dbPkg.LedgerEndorsementReward, rewards, 0, "")
+			residual -= rewards
+		}
+	}
+
+	if residual != 0 {
+		addEntry(dbPkg.LedgerExternalTransfer, residual, 0, "")
+	}
+
+	if len(pending) == 0 {
+		delete(s.reconciliation.pendingOps, address.Address)
+	} else {
+		s.reconciliation.pendingOps[address.Address] = pending
+	}
+
+	return entries
+}
+
+// ledgerCycle returns the cycle at the given time, or the last cycle the address has info about if the node config is unknown
+func (s *stakingManager) ledgerCycle(address StakingAddress, now time.Time) uint64 {
+	if s.clock != nil {
+		if cycle, err := s.clock.TimeToCycle(now); err == nil {
+			return cycle
+		}
+	}
+
+	var cycle uint64
+	for _, cycleInfo := range address.CycleInfos {
+		cycle = max(cycle, cycleInfo.Cycle)
+	}
+	return cycle
+}
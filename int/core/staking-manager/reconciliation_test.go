@@ -0,0 +1,226 @@
+package stakingManager
+
+import (
+	"testing"
+	"time"
+
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	massaTime "github.com/massalabs/node-manager-plugin/int/massa-time"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	eventBusPkg "github.com/massalabs/node-manager-plugin/pkg/event-bus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileAddress(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	// 500ms slots: 20 slots elapse between the two polls
+	clock, err := massaTime.NewClock(0, 16000, 32, 128)
+	require.NoError(t, err)
+	const elapsed = 10 * time.Second
+	now := time.Now()
+	cycle, err := clock.TimeToCycle(now)
+	require.NoError(t, err)
+
+	creditSlot := Slot{Period: 100, Thread: 3}
+
+	tests := []struct {
+		name            string
+		previous        StakingAddress
+		current         StakingAddress
+		pending         []pendingLedgerOp
+		expectedEntries []dbPkg.LedgerEntry
+		expectedPending []pendingLedgerOp
+	}{
+		{
+			name:     "Should attribute a balance increase to the blocks produced then to endorsements",
+			previous: StakingAddress{FinalBalance: 10 * massaAmount.MAS, ActiveRolls: 5, CycleInfos: []CycleInfo{{Cycle: 7, OkCount: 2}}},
+			current:  StakingAddress{FinalBalance: massaAmount.FromMAS(11.5), ActiveRolls: 5, CycleInfos: []CycleInfo{{Cycle: 7, OkCount: 3}}},
+			expectedEntries: []dbPkg.LedgerEntry{
+				{Kind: dbPkg.LedgerBlockReward, Amount: massaAmount.MAS},
+				{Kind: dbPkg.LedgerEndorsementReward, Amount: massaAmount.FromMAS(0.5)},
+			},
+		},
+		{
+			name:     "Should match a roll buy with the operation sent by the plugin",
+			previous: StakingAddress{FinalBalance: 300 * massaAmount.MAS, FinalRolls: 1},
+			current:  StakingAddress{FinalBalance: massaAmount.FromMAS(99.99), FinalRolls: 3},
+			pending:  []pendingLedgerOp{{kind: dbPkg.LedgerRollBuy, rolls: 2, fee: massaAmount.FromMAS(0.01), opId: "op1", timestamp: now}},
+			expectedEntries: []dbPkg.LedgerEntry{
+				{Kind: dbPkg.LedgerRollBuy, Amount: -200 * massaAmount.MAS, Rolls: 2, OpId: "op1"},
+				{Kind: dbPkg.LedgerFee, Amount: -massaAmount.FromMAS(0.01), OpId: "op1"},
+			},
+		},
+		{
+			name:     "Should match a roll sell with the operation sent by the plugin",
+			previous: StakingAddress{FinalBalance: massaAmount.MAS, FinalRolls: 3},
+			current:  StakingAddress{FinalBalance: massaAmount.FromMAS(0.99), DeferredCredits: []DeferredCredit{{Slot: creditSlot, Amount: 300 * massaAmount.MAS}}},
+			pending:  []pendingLedgerOp{{kind: dbPkg.LedgerRollSell, rolls: 3, fee: massaAmount.FromMAS(0.01), opId: "op2", timestamp: now}},
+			expectedEntries: []dbPkg.LedgerEntry{
+				{Kind: dbPkg.LedgerRollSell, Rolls: -3, OpId: "op2"},
+				{Kind: dbPkg.LedgerFee, Amount: -massaAmount.FromMAS(0.01), OpId: "op2"},
+			},
+		},
+		{
+			name:     "Should explain a balance increase by the release of a deferred credit",
+			previous: StakingAddress{FinalBalance: massaAmount.FromMAS(0.99), DeferredCredits: []DeferredCredit{{Slot: creditSlot, Amount: 300 * massaAmount.MAS}}},
+			current:  StakingAddress{FinalBalance: massaAmount.FromMAS(300.99)},
+			expectedEntries: []dbPkg.LedgerEntry{
+				{Kind: dbPkg.LedgerDeferredCreditRelease, Amount: 300 * massaAmount.MAS},
+			},
+		},
+		{
+			name:     "Should match a sweep with rewards received in the meantime",
+			previous: StakingAddress{FinalBalance: 60 * massaAmount.MAS, ActiveRolls: 5},
+			current:  StakingAddress{FinalBalance: massaAmount.FromMAS(10.19), ActiveRolls: 5},
+			pending:  []pendingLedgerOp{{kind: dbPkg.LedgerSweep, amount: 50 * massaAmount.MAS, fee: massaAmount.FromMAS(0.01), opId: "op3", timestamp: now}},
+			expectedEntries: []dbPkg.LedgerEntry{
+				{Kind: dbPkg.LedgerSweep, Amount: -50 * massaAmount.MAS, OpId: "op3"},
+				{Kind: dbPkg.LedgerFee, Amount: -massaAmount.FromMAS(0.01), OpId: "op3"},
+				{Kind: dbPkg.LedgerEndorsementReward, Amount: massaAmount.FromMAS(0.2)},
+			},
+		},
+		{
+			name:     "Should keep the operations that did not change the balance yet",
+			previous: StakingAddress{FinalBalance: 60 * massaAmount.MAS, FinalRolls: 1},
+			current:  StakingAddress{FinalBalance: 60 * massaAmount.MAS, FinalRolls: 1},
+			pending: []pendingLedgerOp{
+				{kind: dbPkg.LedgerSweep, amount: 50 * massaAmount.MAS, fee: massaAmount.FromMAS(0.01), opId: "op3", timestamp: now},
+				{kind: dbPkg.LedgerRollBuy, rolls: 1, fee: massaAmount.FromMAS(0.01), opId: "op4", timestamp: now},
+			},
+			expectedPending: []pendingLedgerOp{
+				{kind: dbPkg.LedgerSweep, amount: 50 * massaAmount.MAS, fee: massaAmount.FromMAS(0.01), opId: "op3", timestamp: now},
+				{kind: dbPkg.LedgerRollBuy, rolls: 1, fee: massaAmount.FromMAS(0.01), opId: "op4", timestamp: now},
+			},
+		},
+		{
+			name:     "Should flag a deposit on an address without active rolls as an external transfer",
+			previous: StakingAddress{FinalBalance: 10 * massaAmount.MAS},
+			current:  StakingAddress{FinalBalance: 110 * massaAmount.MAS},
+			expectedEntries: []dbPkg.LedgerEntry{
+				{Kind: dbPkg.LedgerExternalTransfer, Amount: 100 * massaAmount.MAS},
+			},
+		},
+		{
+			name:     "Should flag a deposit above the possible rewards as an external transfer",
+			previous: StakingAddress{FinalBalance: 10 * massaAmount.MAS, ActiveRolls: 5},
+			current:  StakingAddress{FinalBalance: 60 * massaAmount.MAS, ActiveRolls: 5},
+			expectedEntries: []dbPkg.LedgerEntry{
+				{Kind: dbPkg.LedgerEndorsementReward, Amount: 20 * massaAmount.MAS},
+				{Kind: dbPkg.LedgerExternalTransfer, Amount: 30 * massaAmount.MAS},
+			},
+		},
+		{
+			name:     "Should flag a withdrawal as an external transfer",
+			previous: StakingAddress{FinalBalance: 50 * massaAmount.MAS, ActiveRolls: 5},
+			current:  StakingAddress{FinalBalance: 20 * massaAmount.MAS, ActiveRolls: 5},
+			expectedEntries: []dbPkg.LedgerEntry{
+				{Kind: dbPkg.LedgerExternalTransfer, Amount: -30 * massaAmount.MAS},
+			},
+		},
+		{
+			name:     "Should record rolls bought outside of the plugin without their fee",
+			previous: StakingAddress{FinalBalance: 150 * massaAmount.MAS},
+			current:  StakingAddress{FinalBalance: massaAmount.FromMAS(49.98), FinalRolls: 1},
+			expectedEntries: []dbPkg.LedgerEntry{
+				{Kind: dbPkg.LedgerRollBuy, Amount: -100 * massaAmount.MAS, Rolls: 1},
+				{Kind: dbPkg.LedgerExternalTransfer, Amount: -massaAmount.FromMAS(0.02)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.previous.Address = "test_address"
+			tt.current.Address = "test_address"
+
+			sm := &stakingManager{
+				miscellaneous: Miscellaneous{MinimalFees: massaAmount.FromMAS(0.01), RollPrice: 100 * massaAmount.MAS, BlockReward: massaAmount.MAS},
+				clock:         clock,
+				reconciliation: reconciliationState{
+					pendingOps: map[string][]pendingLedgerOp{},
+				},
+			}
+			if tt.pending != nil {
+				sm.reconciliation.pendingOps["test_address"] = tt.pending
+			}
+
+			entries := sm.reconcileAddress(newReconciledAddress(tt.previous), tt.current, elapsed, now)
+
+			require.Len(t, entries, len(tt.expectedEntries))
+			for i, expected := range tt.expectedEntries {
+				assert.Equal(t, "test_address", entries[i].Address)
+				assert.True(t, now.Equal(entries[i].Timestamp))
+				assert.Equal(t, cycle, entries[i].Cycle)
+				assert.Equal(t, expected.Kind, entries[i].Kind, "entry %d", i)
+				assert.Equal(t, expected.Amount, entries[i].Amount, "entry %d amount", i)
+				assert.Equal(t, expected.Rolls, entries[i].Rolls, "entry %d rolls", i)
+				assert.Equal(t, expected.OpId, entries[i].OpId, "entry %d op id", i)
+			}
+			assert.Equal(t, tt.expectedPending, sm.reconciliation.pendingOps["test_address"])
+		})
+	}
+}
+
+func TestReconcileBalances(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	mockDB := dbPkg.NewMockDB(t)
+	eventBus := eventBusPkg.NewEventBus(10)
+	eventChan := eventBus.Subscribe(EventUnexplainedBalanceChange, "test")
+
+	sm := &stakingManager{
+		db:            mockDB,
+		eventBus:      eventBus,
+		miscellaneous: Miscellaneous{MinimalFees: massaAmount.FromMAS(0.01), RollPrice: 100 * massaAmount.MAS, BlockReward: massaAmount.MAS},
+	}
+
+	// the first poll only records the balances
+	sm.reconcileBalances([]StakingAddress{
+		{Address: "address_1", FinalBalance: 250 * massaAmount.MAS, FinalRolls: 1},
+		{Address: "address_2", FinalBalance: 10 * massaAmount.MAS},
+	})
+	require.False(t, sm.reconciliation.lastTime.IsZero())
+	lastTime := sm.reconciliation.lastTime
+
+	mockDB.On("GetRollOpHistorySince", lastTime, utils.NetworkMainnet).Return([]dbPkg.AddressRollOpHistory{
+		{Address: "address_1", RollOpHistory: dbPkg.RollOpHistory{Op: string(dbPkg.RollOpBuy), Amount: 2, OpId: "op1", Timestamp: lastTime.Add(time.Second)}},
+		{Address: "address_1", RollOpHistory: dbPkg.RollOpHistory{Op: string(dbPkg.RollOpSetTarget), Amount: 3, Timestamp: lastTime.Add(time.Second)}},
+	}, nil).Once()
+	mockDB.On("GetSweepHistorySince", lastTime, utils.NetworkMainnet).Return([]dbPkg.SweepHistory{
+		{Address: "address_2", Amount: 5 * massaAmount.MAS, Fee: massaAmount.FromMAS(0.01), DryRun: true, Timestamp: lastTime.Add(time.Second)},
+	}, nil).Once()
+	mockDB.On("AddLedgerEntries", mock.MatchedBy(func(entries []dbPkg.LedgerEntry) bool {
+		return len(entries) == 3 &&
+			entries[0].Address == "address_1" && entries[0].Kind == dbPkg.LedgerRollBuy && entries[0].OpId == "op1" &&
+			entries[1].Address == "address_1" && entries[1].Kind == dbPkg.LedgerFee &&
+			entries[2].Address == "address_2" && entries[2].Kind == dbPkg.LedgerExternalTransfer && entries[2].Amount == -5*massaAmount.MAS
+	}), utils.NetworkMainnet).Return(nil).Once()
+
+	// address_3 is new, it is only reconciled from the next poll
+	sm.reconcileBalances([]StakingAddress{
+		{Address: "address_1", FinalBalance: massaAmount.FromMAS(49.99), FinalRolls: 3},
+		{Address: "address_2", FinalBalance: 5 * massaAmount.MAS},
+		{Address: "address_3", FinalBalance: 1000 * massaAmount.MAS},
+	})
+
+	require.Len(t, eventChan, 1)
+	event := <-eventChan
+	entry, ok := event.Data.(dbPkg.LedgerEntry)
+	require.True(t, ok)
+	assert.Equal(t, "address_2", entry.Address)
+	assert.Empty(t, sm.reconciliation.pendingOps)
+	assert.Len(t, sm.reconciliation.addresses, 3)
+
+	// nothing is recorded when the pending operations can't be retrieved
+	mockDB.On("GetRollOpHistorySince", sm.reconciliation.lastTime, utils.NetworkMainnet).Return(nil, assert.AnError).Once()
+	previousTime := sm.reconciliation.lastTime
+	sm.reconcileBalances([]StakingAddress{{Address: "address_1", FinalBalance: 0}})
+	assert.Equal(t, previousTime, sm.reconciliation.lastTime)
+	assert.Len(t, sm.reconciliation.addresses, 3)
+}
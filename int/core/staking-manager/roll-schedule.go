@@ -0,0 +1,238 @@
+package stakingManager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/massalabs/node-manager-plugin/int/config"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+// rollSchedulerInterval is how often the roll scheduler looks for schedules to run
+const rollSchedulerInterval = 10 * time.Second
+
+/*
+ScheduleRollChange stores a change of the target rolls of a staking address, to be run at a date or at the start of a cycle.
+The schedule is stored in the database so that it survives restarts. It returns the stored schedule.
+*/
+func (s *stakingManager) ScheduleRollChange(schedule dbPkg.RollSchedule) (dbPkg.RollSchedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ramAddressListContains(schedule.Address) {
+		return dbPkg.RollSchedule{}, fmt.Errorf("address %s not found in staking addresses", schedule.Address)
+	}
+
+	if err := s.validateRollSchedule(schedule, time.Now()); err != nil {
+		return dbPkg.RollSchedule{}, err
+	}
+
+	currentNetwork := config.GlobalPluginInfo.GetNetwork()
+
+	id, err := s.db.AddRollSchedule(schedule, currentNetwork)
+	if err != nil {
+		return dbPkg.RollSchedule{}, fmt.Errorf("failed to save roll schedule of address %s: %w", schedule.Address, err)
+	}
+
+	stored, err := s.db.GetRollSchedule(id, currentNetwork)
+	if err != nil {
+		return dbPkg.RollSchedule{}, fmt.Errorf("failed to get saved roll schedule %d: %w", id, err)
+	}
+
+	logger.Infof("Roll schedule %d added for address %s: %s %d", id, schedule.Address, schedule.Action, schedule.Amount)
+	return stored, nil
+}
+
+// UpdateRollSchedule changes the action, amount and trigger of a pending roll schedule. Its address can't be changed.
+func (s *stakingManager) UpdateRollSchedule(schedule dbPkg.RollSchedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.validateRollSchedule(schedule, time.Now()); err != nil {
+		return err
+	}
+
+	if err := s.db.UpdateRollSchedule(schedule, config.GlobalPluginInfo.GetNetwork()); err != nil {
+		return fmt.Errorf("failed to update roll schedule %d: %w", schedule.ID, err)
+	}
+
+	return nil
+}
+
+// CancelRollSchedule deletes a pending roll schedule
+func (s *stakingManager) CancelRollSchedule(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.db.DeleteRollSchedule(id, config.GlobalPluginInfo.GetNetwork()); err != nil {
+		return fmt.Errorf("failed to cancel roll schedule %d: %w", id, err)
+	}
+
+	logger.Infof("Roll schedule %d cancelled", id)
+	return nil
+}
+
+// GetRollSchedules returns the roll schedules of the current network, including those that have run
+func (s *stakingManager) GetRollSchedules() ([]dbPkg.RollSchedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules, err := s.db.GetRollSchedules(config.GlobalPluginInfo.GetNetwork())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roll schedules from database: %w", err)
+	}
+
+	return schedules, nil
+}
+
+func (s *stakingManager) validateRollSchedule(schedule dbPkg.RollSchedule, now time.Time) error {
+	switch schedule.Action {
+	case dbPkg.RollScheduleSetTarget:
+	case dbPkg.RollScheduleBuy, dbPkg.RollScheduleSell:
+		if schedule.Amount == 0 {
+			return fmt.Errorf("the amount of rolls to %s must be positive", schedule.Action)
+		}
+	default:
+		return fmt.Errorf("unknown roll schedule action %s", schedule.Action)
+	}
+
+	if (schedule.RunAt == nil) == (schedule.Cycle == nil) {
+		return fmt.Errorf("a roll schedule must run either at a date or at the start of a cycle")
+	}
+
+	if schedule.RunAt != nil && !schedule.RunAt.After(now) {
+		return fmt.Errorf("roll schedule date %s is in the past", schedule.RunAt.Format(time.RFC3339))
+	}
+
+	// the cycle can only be checked once the node config is known
+	if schedule.Cycle != nil && s.clock != nil {
+		currentCycle, err := s.clock.TimeToCycle(now)
+		if err == nil && *schedule.Cycle <= currentCycle {
+			return fmt.Errorf("roll schedule cycle %d has already started, current cycle is %d", *schedule.Cycle, currentCycle)
+		}
+	}
+
+	return nil
+}
+
+// rollScheduler runs the roll schedules that are due while the node is up
+func (s *stakingManager) rollScheduler(ctx context.Context) {
+	ticker := time.NewTicker(rollSchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runDueRollSchedules(time.Now())
+		}
+	}
+}
+
+/*
+runDueRollSchedules applies the pending roll schedules that are due at "now".
+Schedules of addresses that are not in the staking addresses list wait until they are,
+so that a schedule is not lost because the list has not been initialized yet.
+*/
+func (s *stakingManager) runDueRollSchedules(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	currentNetwork := config.GlobalPluginInfo.GetNetwork()
+
+	schedules, err := s.db.GetRollSchedules(currentNetwork)
+	if err != nil {
+		logger.Errorf("failed to get roll schedules from database: %v", err)
+		return
+	}
+
+	for _, schedule := range schedules {
+		if schedule.Status != dbPkg.RollSchedulePending || !s.rollScheduleIsDue(schedule, now) {
+			continue
+		}
+
+		index, ok := s.getAddressIndexFromRamList(schedule.Address)
+		if !ok {
+			logger.Debugf("address %s of roll schedule %d not found in staking addresses, skipping", schedule.Address, schedule.ID)
+			continue
+		}
+
+		status, errMsg := dbPkg.RollScheduleDone, ""
+		if err := s.applyRollSchedule(index, schedule, currentNetwork); err != nil {
+			logger.Errorf("failed to run roll schedule %d of address %s: %v", schedule.ID, schedule.Address, err)
+			status, errMsg = dbPkg.RollScheduleFailed, err.Error()
+		}
+
+		if err := s.db.SetRollScheduleStatus(schedule.ID, status, errMsg, currentNetwork); err != nil {
+			logger.Errorf("failed to set status of roll schedule %d: %v", schedule.ID, err)
+		}
+	}
+}
+
+func (s *stakingManager) rollScheduleIsDue(schedule dbPkg.RollSchedule, now time.Time) bool {
+	if schedule.RunAt != nil {
+		return !now.Before(*schedule.RunAt)
+	}
+
+	// cycle schedules wait for the node config to know when the cycle starts
+	return s.clock != nil && !now.Before(s.clock.CycleStartTime(*schedule.Cycle))
+}
+
+// applyRollSchedule sets the target rolls of the address at "index" as scheduled and records it in the roll history
+func (s *stakingManager) applyRollSchedule(index int, schedule dbPkg.RollSchedule, currentNetwork utils.Network) error {
+	if s.stakingAddresses[index].decommissioning {
+		return fmt.Errorf("address %s is being decommissioned, its target rolls can't be changed", schedule.Address)
+	}
+
+	targetRolls := scheduledTargetRolls(s.stakingAddresses[index], schedule)
+	if err := s.updateTargetRolls(index, targetRolls, currentNetwork); err != nil {
+		return err
+	}
+
+	logger.Infof("Roll schedule %d run: target rolls of address %s set to %d", schedule.ID, schedule.Address, targetRolls)
+
+	if err := s.db.AddRollOpHistory(schedule.Address, dbPkg.RollOpSetTarget, uint64(targetRolls), dbPkg.RollScheduleHistoryOpId(schedule.ID), currentNetwork); err != nil {
+		logger.Errorf("failed to record roll schedule %d in roll history: %v", schedule.ID, err)
+	}
+
+	s.muSellBuyRolls.Lock()
+	defer s.muSellBuyRolls.Unlock()
+
+	// the target is saved, the monitoring loop will retry if the operation can't be sent now
+	err := s.sellBuyRollsAddress(s.stakingAddresses[index])
+	if nodeManagerError.Is(err, nodeManagerError.ErrStakingManagerAutomationPaused) {
+		logger.Infof("Rolls of address %s will be bought or sold after roll schedule %d once the automation is resumed", schedule.Address, schedule.ID)
+		return nil
+	}
+	if err != nil {
+		logger.Warnf("failed to sell or buy rolls for address %s after roll schedule %d, will retry at next poll: %v", schedule.Address, schedule.ID, err)
+	}
+
+	return nil
+}
+
+/*
+scheduledTargetRolls returns the target rolls of an address once the schedule is applied.
+Buy and sell schedules are relative to the current target, or to the candidate rolls if the address is auto-compounding.
+*/
+func scheduledTargetRolls(address StakingAddress, schedule dbPkg.RollSchedule) int64 {
+	if schedule.Action == dbPkg.RollScheduleSetTarget {
+		return int64(schedule.Amount)
+	}
+
+	current := address.TargetRolls
+	if current < 0 {
+		current = int64(address.CandidateRolls)
+	}
+
+	if schedule.Action == dbPkg.RollScheduleBuy {
+		return current + int64(schedule.Amount)
+	}
+
+	return max(current-int64(schedule.Amount), 0)
+}
@@ -0,0 +1,227 @@
+package stakingManager
+
+import (
+	"testing"
+	"time"
+
+	clientDriverPkg "github.com/massalabs/node-manager-plugin/int/client-driver"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	massaTime "github.com/massalabs/node-manager-plugin/int/massa-time"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduledTargetRolls(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  StakingAddress
+		action   dbPkg.RollScheduleAction
+		amount   uint64
+		expected int64
+	}{
+		{name: "set target", address: StakingAddress{TargetRolls: 10}, action: dbPkg.RollScheduleSetTarget, amount: 200, expected: 200},
+		{name: "buy from target", address: StakingAddress{TargetRolls: 10}, action: dbPkg.RollScheduleBuy, amount: 5, expected: 15},
+		{name: "sell from target", address: StakingAddress{TargetRolls: 100}, action: dbPkg.RollScheduleSell, amount: 50, expected: 50},
+		{name: "sell more than target", address: StakingAddress{TargetRolls: 10}, action: dbPkg.RollScheduleSell, amount: 50, expected: 0},
+		{name: "sell from auto-compounding", address: StakingAddress{TargetRolls: -1, CandidateRolls: 80}, action: dbPkg.RollScheduleSell, amount: 50, expected: 30},
+		{name: "buy from auto-compounding", address: StakingAddress{TargetRolls: -1, CandidateRolls: 80}, action: dbPkg.RollScheduleBuy, amount: 20, expected: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := dbPkg.RollSchedule{Action: tt.action, Amount: tt.amount}
+			assert.Equal(t, tt.expected, scheduledTargetRolls(tt.address, schedule))
+		})
+	}
+}
+
+func TestValidateRollSchedule(t *testing.T) {
+	clock, err := massaTime.NewClock(0, 16000, 32, 128)
+	assert.NoError(t, err)
+
+	now := time.UnixMilli(0).Add(time.Hour) // cycle 1
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+	nextCycle := uint64(2)
+	currentCycle := uint64(1)
+
+	tests := []struct {
+		name          string
+		schedule      dbPkg.RollSchedule
+		expectedError string
+	}{
+		{
+			name:     "Should accept a sell at a future date",
+			schedule: dbPkg.RollSchedule{Action: dbPkg.RollScheduleSell, Amount: 50, RunAt: &future},
+		},
+		{
+			name:     "Should accept a target at the start of a future cycle",
+			schedule: dbPkg.RollSchedule{Action: dbPkg.RollScheduleSetTarget, Amount: 200, Cycle: &nextCycle},
+		},
+		{
+			name:          "Should reject an unknown action",
+			schedule:      dbPkg.RollSchedule{Action: "STAKE", Amount: 1, RunAt: &future},
+			expectedError: "unknown roll schedule action STAKE",
+		},
+		{
+			name:          "Should reject buying no roll",
+			schedule:      dbPkg.RollSchedule{Action: dbPkg.RollScheduleBuy, RunAt: &future},
+			expectedError: "the amount of rolls to BUY must be positive",
+		},
+		{
+			name:          "Should reject a schedule without trigger",
+			schedule:      dbPkg.RollSchedule{Action: dbPkg.RollScheduleSetTarget, Amount: 10},
+			expectedError: "either at a date or at the start of a cycle",
+		},
+		{
+			name:          "Should reject a schedule with both triggers",
+			schedule:      dbPkg.RollSchedule{Action: dbPkg.RollScheduleSetTarget, Amount: 10, RunAt: &future, Cycle: &nextCycle},
+			expectedError: "either at a date or at the start of a cycle",
+		},
+		{
+			name:          "Should reject a date in the past",
+			schedule:      dbPkg.RollSchedule{Action: dbPkg.RollScheduleSell, Amount: 50, RunAt: &past},
+			expectedError: "is in the past",
+		},
+		{
+			name:          "Should reject a cycle that has already started",
+			schedule:      dbPkg.RollSchedule{Action: dbPkg.RollScheduleSetTarget, Amount: 10, Cycle: &currentCycle},
+			expectedError: "roll schedule cycle 1 has already started",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := &stakingManager{clock: clock}
+
+			err := sm.validateRollSchedule(tt.schedule, now)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRunDueRollSchedules(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	clock, err := massaTime.NewClock(0, 16000, 32, 128)
+	assert.NoError(t, err)
+
+	now := time.UnixMilli(0).Add(time.Hour) // cycle 1
+	due := now.Add(-time.Minute)
+	notDue := now.Add(time.Minute)
+	startedCycle := uint64(1)
+	nextCycle := uint64(2)
+
+	tests := []struct {
+		name            string
+		schedule        dbPkg.RollSchedule
+		decommissioning bool
+		noClock         bool
+		setupMocks      func(*clientDriverPkg.MockClientDriver, *dbPkg.MockDB)
+		expectedTarget  int64
+	}{
+		{
+			name:     "Should sell rolls when the date is reached",
+			schedule: dbPkg.RollSchedule{ID: 1, Address: "test_address", Action: dbPkg.RollScheduleSell, Amount: 50, RunAt: &due},
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockDB.On("UpdateRollsTarget", "test_address", int64(50), utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("AddRollOpHistory", "test_address", dbPkg.RollOpSetTarget, uint64(50), "schedule-1", utils.NetworkMainnet).Return(nil).Once()
+				mockClient.On("SellRolls", "test_password", "test_address", uint64(50), massaAmount.FromMAS(0.1)).Return("sell_op", nil).Once()
+				mockDB.On("AddRollOpHistory", "test_address", dbPkg.RollOpSell, uint64(50), "sell_op", utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("SetRollScheduleStatus", int64(1), dbPkg.RollScheduleDone, "", utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedTarget: 50,
+		},
+		{
+			name:     "Should set the target at the start of the cycle",
+			schedule: dbPkg.RollSchedule{ID: 2, Address: "test_address", Action: dbPkg.RollScheduleSetTarget, Amount: 100, Cycle: &startedCycle},
+			setupMocks: func(_ *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockDB.On("UpdateRollsTarget", "test_address", int64(100), utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("AddRollOpHistory", "test_address", dbPkg.RollOpSetTarget, uint64(100), "schedule-2", utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("SetRollScheduleStatus", int64(2), dbPkg.RollScheduleDone, "", utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedTarget: 100,
+		},
+		{
+			name:           "Should wait for the date",
+			schedule:       dbPkg.RollSchedule{ID: 3, Address: "test_address", Action: dbPkg.RollScheduleSell, Amount: 50, RunAt: &notDue},
+			setupMocks:     func(_ *clientDriverPkg.MockClientDriver, _ *dbPkg.MockDB) {},
+			expectedTarget: 100,
+		},
+		{
+			name:           "Should wait for the cycle",
+			schedule:       dbPkg.RollSchedule{ID: 4, Address: "test_address", Action: dbPkg.RollScheduleSetTarget, Amount: 10, Cycle: &nextCycle},
+			setupMocks:     func(_ *clientDriverPkg.MockClientDriver, _ *dbPkg.MockDB) {},
+			expectedTarget: 100,
+		},
+		{
+			name:           "Should wait for the node config to run cycle schedules",
+			schedule:       dbPkg.RollSchedule{ID: 5, Address: "test_address", Action: dbPkg.RollScheduleSetTarget, Amount: 10, Cycle: &startedCycle},
+			noClock:        true,
+			setupMocks:     func(_ *clientDriverPkg.MockClientDriver, _ *dbPkg.MockDB) {},
+			expectedTarget: 100,
+		},
+		{
+			name:           "Should wait for the address to be in the staking addresses",
+			schedule:       dbPkg.RollSchedule{ID: 6, Address: "other_address", Action: dbPkg.RollScheduleSell, Amount: 50, RunAt: &due},
+			setupMocks:     func(_ *clientDriverPkg.MockClientDriver, _ *dbPkg.MockDB) {},
+			expectedTarget: 100,
+		},
+		{
+			name:            "Should fail when the address is being decommissioned",
+			schedule:        dbPkg.RollSchedule{ID: 7, Address: "test_address", Action: dbPkg.RollScheduleBuy, Amount: 50, RunAt: &due},
+			decommissioning: true,
+			setupMocks: func(_ *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockDB.On("SetRollScheduleStatus", int64(7), dbPkg.RollScheduleFailed, "address test_address is being decommissioned, its target rolls can't be changed", utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedTarget: 100,
+		},
+		{
+			name:           "Should ignore schedules that have already run",
+			schedule:       dbPkg.RollSchedule{ID: 8, Address: "test_address", Action: dbPkg.RollScheduleSell, Amount: 50, RunAt: &due, Status: dbPkg.RollScheduleDone},
+			setupMocks:     func(_ *clientDriverPkg.MockClientDriver, _ *dbPkg.MockDB) {},
+			expectedTarget: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := clientDriverPkg.NewMockClientDriver(t)
+			mockDB := dbPkg.NewMockDB(t)
+
+			schedule := tt.schedule
+			if schedule.Status == "" {
+				schedule.Status = dbPkg.RollSchedulePending
+			}
+			mockDB.On("GetRollSchedules", utils.NetworkMainnet).Return([]dbPkg.RollSchedule{schedule}, nil).Once()
+			tt.setupMocks(mockClient, mockDB)
+
+			sm := &stakingManager{
+				db:                       mockDB,
+				clientDriver:             mockClient,
+				addressChangedDispatcher: NewAddressChangedDispatcher(),
+				miscellaneous:            Miscellaneous{MinimalFees: massaAmount.FromMAS(0.1), RollPrice: 100 * massaAmount.MAS},
+				stakingAddresses: []StakingAddress{{
+					Address:         "test_address",
+					FinalBalance:    1000 * massaAmount.MAS,
+					CandidateRolls:  100,
+					TargetRolls:     100,
+					decommissioning: tt.decommissioning,
+				}},
+			}
+			if !tt.noClock {
+				sm.clock = clock
+			}
+
+			sm.runDueRollSchedules(now)
+
+			assert.Equal(t, tt.expectedTarget, sm.stakingAddresses[0].TargetRolls)
+		})
+	}
+}
@@ -2,10 +2,8 @@ package stakingManager
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"strconv"
 	"sync"
 	"time"
 
@@ -14,9 +12,12 @@ import (
 	nodeStatusPkg "github.com/massalabs/node-manager-plugin/int/core/NodeStatus"
 	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
 	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	massaTime "github.com/massalabs/node-manager-plugin/int/massa-time"
 	nodeAPI "github.com/massalabs/node-manager-plugin/int/node-api"
 	nodeDirManagerPkg "github.com/massalabs/node-manager-plugin/int/node-bin-dir-manager"
 	"github.com/massalabs/node-manager-plugin/int/utils"
+	eventBusPkg "github.com/massalabs/node-manager-plugin/pkg/event-bus"
 	"github.com/massalabs/station/pkg/logger"
 )
 
@@ -31,14 +32,33 @@ type DeferredCreditDtoNode struct {
 }
 
 type DeferredCredit struct {
-	Slot   Slot    `json:"slot"`
-	Amount float64 `json:"amount"`
+	Slot       Slot               `json:"slot"`
+	Amount     massaAmount.Amount `json:"amount"`
+	UnlockTime *time.Time         `json:"unlock_time,omitempty"` // estimated time at which the credit is released, nil if unknown
 }
 
 // Miscellaneous contains various node-related data
 type Miscellaneous struct {
-	MinimalFees float32 `json:"minimal_fees"`
-	RollPrice   float32 `json:"roll_price"`
+	MinimalFees massaAmount.Amount `json:"minimal_fees"`
+	RollPrice   massaAmount.Amount `json:"roll_price"`
+	BlockReward massaAmount.Amount `json:"block_reward"`
+}
+
+type CycleInfoDtoNode struct {
+	Cycle       uint64  `json:"cycle"`
+	IsFinal     bool    `json:"is_final"`
+	OkCount     uint64  `json:"ok_count"`
+	NokCount    uint64  `json:"nok_count"`
+	ActiveRolls *uint64 `json:"active_rolls"`
+}
+
+// CycleInfo contains the block production statistics of an address for a cycle
+type CycleInfo struct {
+	Cycle       uint64 `json:"cycle"`
+	IsFinal     bool   `json:"is_final"`
+	OkCount     uint64 `json:"ok_count"`
+	NokCount    uint64 `json:"nok_count"`
+	ActiveRolls uint64 `json:"active_rolls"`
 }
 
 type getAddressesResponse struct {
@@ -49,27 +69,61 @@ type getAddressesResponse struct {
 	CandidateBalance string                  `json:"candidate_balance"`
 	Thread           uint8                   `json:"thread"`
 	DeferredCredits  []DeferredCreditDtoNode `json:"deferred_credits"`
+	CycleInfos       []CycleInfoDtoNode      `json:"cycle_infos"`
+}
+
+// PendingOperation is a roll operation or a sweep sent for a staking address that is not final yet
+type PendingOperation struct {
+	ID           string     `json:"id"`
+	ExpirePeriod *uint64    `json:"expire_period,omitempty"` // nil until the operation has been retrieved from the node
+	ExpireTime   *time.Time `json:"expire_time,omitempty"`   // estimated time at which the operation expires if not included
 }
 
 // StakingAddress represents a staking address with its information
 type StakingAddress struct {
-	Address            string           `json:"address"`
-	FinalRolls         uint64           `json:"final_roll_count"`
-	CandidateRolls     uint64           `json:"candidate_roll_count"`
-	ActiveRolls        uint64           `json:"active_roll_count"`
-	FinalBalance       float64          `json:"final_balance"`
-	CandidateBalance   float64          `json:"candidate_balance"`
-	Thread             uint8            `json:"thread"`
-	DeferredCredits    []DeferredCredit `json:"deferred_credits"`
-	TargetRolls        int64            `json:"target_rolls"` // if target rolls is negative, it means that the address is auto-compounding: buy as many rolls as possible
+	Address            string             `json:"address"`
+	FinalRolls         uint64             `json:"final_roll_count"`
+	CandidateRolls     uint64             `json:"candidate_roll_count"`
+	ActiveRolls        uint64             `json:"active_roll_count"`
+	FinalBalance       massaAmount.Amount `json:"final_balance"`
+	CandidateBalance   massaAmount.Amount `json:"candidate_balance"`
+	Thread             uint8              `json:"thread"`
+	DeferredCredits    []DeferredCredit   `json:"deferred_credits"`
+	CycleInfos         []CycleInfo        `json:"cycle_infos"`
+	TargetRolls        int64              `json:"target_rolls"` // if target rolls is negative, it means that the address is auto-compounding: buy as many rolls as possible
+	PendingOperation   *PendingOperation  `json:"pending_operation,omitempty"`
+	WatchOnly          bool               `json:"watch_only"` // the address is monitored but not staked by the node, its rolls are never bought or sold
 	pendingOperationId *string
+	decommissioning    bool // the address is being decommissioned, its target rolls can't be changed
 }
 
 type StakingManager interface {
 	GetStakingAddresses(pwd string) ([]StakingAddress, AddressChangedDispatcher, error)
 	AddStakingAddress(pwdNode, pwdAccount, nickname string) (StakingAddress, error)
+	AddStakingAddresses(pwdNode string, accounts []AccountCredentials) ([]ImportResult, error)
+	AddStakingAddressFromKey(pwdNode string, source KeySource) (StakingAddress, error)
+	ListWalletAccounts() ([]WalletAccount, error)
 	RemoveStakingAddress(pwd, address string) error
+	DecommissionStakingAddress(address string) error
+	GetDecommissions() ([]DecommissionStatus, error)
 	SetTargetRolls(address string, targetRolls int64) error
+	SetAutomationPaused(address string, paused bool) error
+	GetAutomationPauses() AutomationPauses
+	AddWatchOnlyAddress(address string) (StakingAddress, error)
+	RemoveWatchOnlyAddress(address string) error
+	GetWatchOnlyAddresses() []StakingAddress
+	SetAddressLabel(label dbPkg.AddressLabel) error
+	DeleteAddressLabel(address string) error
+	GetAddressLabels() ([]dbPkg.AddressLabel, error)
+	GetAddressGroups() ([]AddressGroup, error)
+	SetSweepRule(rule dbPkg.SweepRule) error
+	DeleteSweepRule(address string) error
+	GetSweepRules() ([]dbPkg.SweepRule, error)
+	ScheduleRollChange(schedule dbPkg.RollSchedule) (dbPkg.RollSchedule, error)
+	UpdateRollSchedule(schedule dbPkg.RollSchedule) error
+	CancelRollSchedule(id int64) error
+	GetRollSchedules() ([]dbPkg.RollSchedule, error)
+	GetNextDraws() ([]nodeAPI.AddressDraws, error)
 	Close() error
 }
 
@@ -91,6 +145,15 @@ type stakingManager struct {
 	clientTimeout                  uint64
 	walletManager                  MassaWalletManager
 	config                         *config.PluginConfig
+	eventBus                       eventBusPkg.EventBus
+	lastMissRateAlertCycle         map[string]uint64 // address -> last cycle for which a miss rate alert has been raised
+	clock                          *massaTime.Clock  // converts slots to time, nil until the node config has been fetched
+	activeRollsCache               activeRollsCache
+	lastBlockedRollOp              map[string]dbPkg.BlockedRollOp // address -> last roll operation blocked by the guardrails, recorded only once
+	automationPaused               bool                           // the automation of all the addresses is paused, written with mu and muSellBuyRolls held
+	pausedAddresses                map[string]bool                // addresses whose automation is paused, written with mu and muSellBuyRolls held
+	watchOnlyAddresses             []StakingAddress               // addresses monitored without being staked, kept apart from stakingAddresses
+	reconciliation                 reconciliationState            // balances of the previous poll, used to explain the balance changes
 }
 
 func NewStakingManager(
@@ -102,6 +165,7 @@ func NewStakingManager(
 	clientTimeout uint64,
 	walletManager MassaWalletManager,
 	config *config.PluginConfig,
+	eventBus eventBusPkg.EventBus,
 ) StakingManager {
 	sm := &stakingManager{
 		nodeAPI:                        nodeAPI,
@@ -114,6 +178,9 @@ func NewStakingManager(
 		clientTimeout:                  clientTimeout,
 		walletManager:                  walletManager,
 		config:                         config,
+		eventBus:                       eventBus,
+		lastMissRateAlertCycle:         make(map[string]uint64),
+		lastBlockedRollOp:              make(map[string]dbPkg.BlockedRollOp),
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -134,7 +201,7 @@ func (s *stakingManager) GetStakingAddresses(pwd string) ([]StakingAddress, Addr
 		}
 	}
 
-	return copyAddresses(s.stakingAddresses), s.addressChangedDispatcher, nil
+	return copyAddresses(s.addressesToPublish()), s.addressChangedDispatcher, nil
 }
 
 // AddStakingAddress add an address to the massa node for staking
@@ -151,21 +218,44 @@ func (s *stakingManager) AddStakingAddress(pwdNode, pwdAccount, nickname string)
 		return StakingAddress{}, fmt.Errorf("failed to get address and priv key from nickname %s: %w", nickname, err)
 	}
 
+	stakingAddress, err := s.addStakingKey(pwdNode, privateKey, address)
+	if err != nil {
+		return StakingAddress{}, err
+	}
+
+	s.labelFromNickname(address, nickname)
+
+	return stakingAddress, nil
+}
+
+// addStakingKey adds a private key to the node staking keys and registers its address. The caller must hold s.mu
+func (s *stakingManager) addStakingKey(pwdNode, privateKey, address string) (StakingAddress, error) {
+	if err := s.addNodeStakingKey(pwdNode, privateKey, address); err != nil {
+		return StakingAddress{}, err
+	}
+
+	return s.registerStakingAddress(address)
+}
+
+// addNodeStakingKey adds a private key to the node staking keys. The caller must hold s.mu
+func (s *stakingManager) addNodeStakingKey(pwdNode, privateKey, address string) error {
 	if s.ramAddressListContains(address) {
-		return StakingAddress{}, fmt.Errorf("address %s already in staking addresses", address)
+		return fmt.Errorf("address %s already in staking addresses", address)
 	}
 
 	// add address to node staking addresses
-	err = s.clientDriver.AddStakingAddress(pwdNode, privateKey, address)
+	err := s.clientDriver.AddStakingAddress(pwdNode, privateKey, address)
 	if err != nil {
-		return StakingAddress{}, fmt.Errorf("failed to add address %s to node staking addresses: %w", address, err)
+		return fmt.Errorf("failed to add address %s to node staking addresses: %w", address, err)
 	}
 
+	return nil
+}
+
+// registerStakingAddress registers an address whose key has been added to the node staking keys. The caller must hold s.mu
+func (s *stakingManager) registerStakingAddress(address string) (StakingAddress, error) {
 	// Add to database
-	currentNetwork := utils.NetworkMainnet
-	if !config.GlobalPluginInfo.GetIsMainnet() {
-		currentNetwork = utils.NetworkBuildnet
-	}
+	currentNetwork := config.GlobalPluginInfo.GetNetwork()
 
 	// Default to -1 rolls target (auto-compounding) -> buy as many rolls as possible
 	if err := s.db.AddRollsTarget(address, -1, currentNetwork); err != nil {
@@ -178,13 +268,28 @@ func (s *stakingManager) AddStakingAddress(pwdNode, pwdAccount, nickname string)
 		return StakingAddress{}, fmt.Errorf("address added to node staking addresses but failed to get address data from node to update ram list: %w", err)
 	}
 
-	// add address to staking addresses list in ram
+	// add address to staking addresses list in ram, it is no longer watch-only
+	s.dropWatchOnlyAddress(address)
 	s.stakingAddresses = append(s.stakingAddresses, addressData[0])
+	s.recordAddressEvent(address, dbPkg.AddressEventAdded, false)
 
 	return addressData[0], nil
 }
 
-// RemoveStakingAddress remove an address from the massa node. The address will be removed from staking.
+/*
+recordAddressEvent records an address being added to or removed from the monitored addresses.
+Failures are only logged, the address events are only used to annotate the value history.
+*/
+func (s *stakingManager) recordAddressEvent(address string, event dbPkg.AddressEventKind, watchOnly bool) {
+	if err := s.db.AddAddressEvent(address, event, watchOnly, config.GlobalPluginInfo.GetNetwork()); err != nil {
+		logger.Errorf("failed to record address event %s of address %s: %v", event, address, err)
+	}
+}
+
+/*
+RemoveStakingAddress remove an address from the massa node immediately. The address will be removed from staking.
+Rolls that are still active and pending deferred credits are not waited for, use DecommissionStakingAddress for that.
+*/
 func (s *stakingManager) RemoveStakingAddress(pwd, address string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -216,12 +321,10 @@ func (s *stakingManager) RemoveStakingAddress(pwd, address string) error {
 
 	// remove address from staking addresses list
 	s.removeAddressFromRamList(address)
+	s.recordAddressEvent(address, dbPkg.AddressEventRemoved, false)
 
 	// Remove from database if available
-	currentNetwork := utils.NetworkMainnet
-	if !config.GlobalPluginInfo.GetIsMainnet() {
-		currentNetwork = utils.NetworkBuildnet
-	}
+	currentNetwork := config.GlobalPluginInfo.GetNetwork()
 
 	errs := []error{}
 
@@ -233,15 +336,19 @@ func (s *stakingManager) RemoveStakingAddress(pwd, address string) error {
 		}
 	}
 
-	// Remove roll operation history from database
-	if err := s.db.DeleteRollOpHistoryByAddress(address); err != nil {
+	// Archive roll operation history
+	if err := s.db.ArchiveRollOpHistoryByAddress(address, currentNetwork); err != nil {
 		if nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
-			logger.Info("[RemoveStakingAddress] roll operation history for address %s is not in database. Nothing to remove from DB", address)
+			logger.Info("[RemoveStakingAddress] roll operation history for address %s is not in database. Nothing to archive", address)
 		} else {
-			errs = append(errs, fmt.Errorf("failed to remove rolls operation history for address %s from database: %w", address, err))
+			errs = append(errs, fmt.Errorf("failed to archive rolls operation history for address %s: %w", address, err))
 		}
 	}
 
+	if err := s.deleteAutomationPause(address, currentNetwork); err != nil {
+		errs = append(errs, err)
+	}
+
 	if len(errs) > 0 {
 		errMsg := fmt.Sprintf("failed to remove address %s from database, got following errors: ", address)
 		for i, err := range errs {
@@ -266,12 +373,37 @@ func (s *stakingManager) SetTargetRolls(address string, targetRolls int64) error
 		return fmt.Errorf("address not found for address %s", address)
 	}
 
+	if s.stakingAddresses[index].decommissioning {
+		return fmt.Errorf("address %s is being decommissioned, its target rolls can't be changed", address)
+	}
+
 	if s.stakingAddresses[index].TargetRolls == targetRolls {
 		return nil
 	}
 
-	// Update database
-	currentNetwork := config.GlobalPluginInfo.GetNetwork()
+	if err := s.updateTargetRolls(index, targetRolls, config.GlobalPluginInfo.GetNetwork()); err != nil {
+		return err
+	}
+
+	// sell or buy rolls for the address according to new target rolls
+	s.muSellBuyRolls.Lock()
+	defer s.muSellBuyRolls.Unlock()
+
+	err := s.sellBuyRollsAddress(s.stakingAddresses[index])
+	if nodeManagerError.Is(err, nodeManagerError.ErrStakingManagerAutomationPaused) {
+		logger.Infof("Target rolls of address %s set to %d, rolls will be bought or sold once the automation is resumed", address, targetRolls)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to sell or buy rolls for address %s: %w", address, err)
+	}
+
+	return nil
+}
+
+// updateTargetRolls saves the target rolls of the address at "index" in the database and in the ram list
+func (s *stakingManager) updateTargetRolls(index int, targetRolls int64, currentNetwork utils.Network) error {
+	address := s.stakingAddresses[index].Address
 
 	err := s.db.UpdateRollsTarget(address, targetRolls, currentNetwork)
 	if err != nil {
@@ -293,15 +425,6 @@ func (s *stakingManager) SetTargetRolls(address string, targetRolls int64) error
 	// publish the new staking addresses list to the front
 	s.addressChangedDispatcher.Publish(s.stakingAddresses)
 
-	// sell or buy rolls for the address according to new target rolls
-	s.muSellBuyRolls.Lock()
-	defer s.muSellBuyRolls.Unlock()
-
-	err = s.sellBuyRollsAddress(s.stakingAddresses[index])
-	if err != nil {
-		return fmt.Errorf("failed to sell or buy rolls for address %s: %w", address, err)
-	}
-
 	return nil
 }
 
@@ -343,11 +466,7 @@ func (s *stakingManager) asyncTask(ctx context.Context) {
 			s.nodeIsUp = true
 			s.mu.Unlock()
 
-			clientDriver, err := clientDriverPkg.NewClientDriver(
-				config.GlobalPluginInfo.GetIsMainnet(),
-				s.nodeDirManager,
-				time.Duration(s.clientTimeout)*time.Second,
-			)
+			clientDriver, err := s.newClientDriver()
 			if err != nil {
 				logger.Error("failed to create client driver: %v", err)
 				continue
@@ -355,6 +474,16 @@ func (s *stakingManager) asyncTask(ctx context.Context) {
 
 			s.clientDriver = clientDriver
 
+			s.mu.Lock()
+			s.updateClock()
+			if err := s.loadAutomationPauses(); err != nil {
+				logger.Errorf("failed to load automation pauses: %v", err)
+			}
+			if err := s.loadWatchOnlyAddresses(); err != nil {
+				logger.Errorf("failed to load watch-only addresses: %v", err)
+			}
+			s.mu.Unlock()
+
 			// Check if miscellaneous data has been initialized
 			if s.miscellaneous.RollPrice == 0 {
 				// if not, fetch it
@@ -366,6 +495,7 @@ func (s *stakingManager) asyncTask(ctx context.Context) {
 			ctxMonitoring, cancel := context.WithCancel(ctx)
 			s.stopStakingMonitoringFunc = cancel
 			go s.stakingAddressMonitoring(ctxMonitoring)
+			go s.rollScheduler(ctxMonitoring)
 
 		case <-nodeDownChan:
 			s.mu.Lock()
@@ -379,6 +509,41 @@ func (s *stakingManager) asyncTask(ctx context.Context) {
 	}
 }
 
+// newClientDriver creates the client driver selected in the plugin config
+func (s *stakingManager) newClientDriver() (clientDriverPkg.ClientDriver, error) {
+	isMainnet := config.GlobalPluginInfo.GetIsMainnet()
+	timeout := time.Duration(s.clientTimeout) * time.Second
+
+	var nativeRolls bool
+	switch s.config.RollOperations {
+	case config.RollOperationsMassaClient:
+	case config.RollOperationsNative:
+		nativeRolls = true
+	default:
+		return nil, fmt.Errorf("unknown roll operations %q", s.config.RollOperations)
+	}
+
+	var driver clientDriverPkg.ClientDriver
+	var err error
+	switch s.config.ClientDriver {
+	case config.ClientDriverMassaClient:
+		driver, err = clientDriverPkg.NewClientDriver(isMainnet, s.nodeDirManager, timeout)
+	case config.ClientDriverPrivateAPI:
+		driver, err = clientDriverPkg.NewPrivateAPIClientDriver(isMainnet, s.nodeDirManager, timeout)
+	default:
+		return nil, fmt.Errorf("unknown client driver %q", s.config.ClientDriver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if nativeRolls {
+		driver = clientDriverPkg.NewNativeRollsClientDriver(isMainnet, s.nodeDirManager.GetClientWalletDir(isMainnet), driver, timeout)
+	}
+
+	return driver, nil
+}
+
 /* initStakingAddresses Retrieve data from the node and the db to initialize the staking addresses list in ram
  */
 func (s *stakingManager) initStakingAddresses() error {
@@ -442,34 +607,27 @@ func (s *stakingManager) cleanAddressesNotInNodeButInDB(addressesInNode []string
 
 // getAddressesDataFromNode gets the addresses data from the node and convert it to the StakingAddress struct
 func (s *stakingManager) getAddressesDataFromNode(addresses []string) ([]StakingAddress, error) {
-	js, err := s.nodeAPI.GetAddresses(addresses)
+	res, err := s.fetchAddresses(addresses, getAddressesChunkSize, getAddressesMaxConcurrency)
 	if err != nil {
 		return nil, err
 	}
 
-	var res []getAddressesResponse
-
-	err = json.Unmarshal(js, &res)
+	activeRolls, err := s.getActiveRolls(res)
 	if err != nil {
 		return nil, err
 	}
 
-	walletInfos, err := s.clientDriver.WalletInfo(config.GlobalPluginInfo.GetPwd())
-	if err != nil {
-		return nil, fmt.Errorf("failed to get wallet info: %v", err)
-	}
-
-	return s.convertToStakingAddress(res, walletInfos)
+	return s.convertToStakingAddress(res, activeRolls)
 }
 
-func (s *stakingManager) convertToStakingAddress(addresses []getAddressesResponse, walletInfos map[string]clientDriverPkg.WalletInfo) ([]StakingAddress, error) {
+func (s *stakingManager) convertToStakingAddress(addresses []getAddressesResponse, activeRolls map[string]uint64) ([]StakingAddress, error) {
 	stakingAddresses := make([]StakingAddress, len(addresses))
 	for i, addr := range addresses {
-		finalBalance, err := strconv.ParseFloat(addr.FinalBalance, 64)
+		finalBalance, err := massaAmount.Parse(addr.FinalBalance)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse final balance: %v", err)
 		}
-		candidateBalance, err := strconv.ParseFloat(addr.CandidateBalance, 64)
+		candidateBalance, err := massaAmount.Parse(addr.CandidateBalance)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse candidate balance: %v", err)
 		}
@@ -478,7 +636,7 @@ func (s *stakingManager) convertToStakingAddress(addresses []getAddressesRespons
 			Address:          addr.Address,
 			FinalRolls:       addr.FinalRolls,
 			CandidateRolls:   addr.CandidateRolls,
-			ActiveRolls:      walletInfos[addr.Address].AddressInfo.ActiveRolls,
+			ActiveRolls:      activeRolls[addr.Address],
 			FinalBalance:     finalBalance,
 			CandidateBalance: candidateBalance,
 			Thread:           addr.Thread,
@@ -486,7 +644,7 @@ func (s *stakingManager) convertToStakingAddress(addresses []getAddressesRespons
 
 		stakingAddresses[i].DeferredCredits = make([]DeferredCredit, len(addr.DeferredCredits))
 		for j, credit := range addr.DeferredCredits {
-			amount, err := strconv.ParseFloat(credit.Amount, 64)
+			amount, err := massaAmount.Parse(credit.Amount)
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse deferred credit amount: %v", err)
 			}
@@ -494,8 +652,25 @@ func (s *stakingManager) convertToStakingAddress(addresses []getAddressesRespons
 				Slot:   credit.Slot,
 				Amount: amount,
 			}
+			if s.clock != nil {
+				unlockTime := s.clock.SlotToTime(massaTime.Slot{Period: credit.Slot.Period, Thread: credit.Slot.Thread})
+				stakingAddresses[i].DeferredCredits[j].UnlockTime = &unlockTime
+			}
 		}
 
+		stakingAddresses[i].CycleInfos = make([]CycleInfo, len(addr.CycleInfos))
+		for j, cycleInfo := range addr.CycleInfos {
+			stakingAddresses[i].CycleInfos[j] = CycleInfo{
+				Cycle:    cycleInfo.Cycle,
+				IsFinal:  cycleInfo.IsFinal,
+				OkCount:  cycleInfo.OkCount,
+				NokCount: cycleInfo.NokCount,
+			}
+			// active rolls are not known by the node for cycles that are not final yet
+			if cycleInfo.ActiveRolls != nil {
+				stakingAddresses[i].CycleInfos[j].ActiveRolls = *cycleInfo.ActiveRolls
+			}
+		}
 	}
 
 	return s.WithTargetRolls(stakingAddresses)
@@ -9,6 +9,7 @@ import (
 	configPkg "github.com/massalabs/node-manager-plugin/int/config"
 	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
 	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
 	nodeAPIPkg "github.com/massalabs/node-manager-plugin/int/node-api"
 	"github.com/massalabs/node-manager-plugin/int/utils"
 	"github.com/massalabs/station/pkg/logger"
@@ -17,7 +18,7 @@ import (
 )
 
 // setupLog initializes the logger for testing and returns a cleanup function
-func setupLog(t *testing.T) func() {
+func setupLog(t testing.TB) func() {
 	tempDir := t.TempDir()
 	logPath := filepath.Join(tempDir, "test.log")
 	err := logger.InitializeGlobal(logPath)
@@ -100,6 +101,8 @@ func TestAddStakingAddress(t *testing.T) {
 				mockWalletManager.On("GetPrivateKeyFromNickname", "account_password", "test_wallet").Return("test_private_key", "test_address", nil).Once()
 				mockClient.On("AddStakingAddress", "node_password", "test_private_key", "test_address").Return(nil).Once()
 				mockDB.On("AddRollsTarget", "test_address", int64(-1), utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("AddAddressEvent", "test_address", dbPkg.AddressEventAdded, false, utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("InitAddressLabel", "test_address", "test_wallet", utils.NetworkMainnet).Return(nil).Once()
 				mockNodeAPI.On("GetAddresses", []string{"test_address"}).Return([]byte(`[{"address":"test_address","final_roll_count":0,"candidate_roll_count":0,"final_balance":"100.0","candidate_balance":"100.0","thread":0,"deferred_credits":[]}]`), nil).Once()
 				mockClient.On("WalletInfo", mock.Anything).Return(map[string]clientDriverPkg.WalletInfo{
 					"test_address": {
@@ -190,8 +193,10 @@ func TestRemoveStakingAddress(t *testing.T) {
 			},
 			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB, t *testing.T) {
 				mockClient.On("RemoveStakingAddress", "test_password", "test_address").Return(nil).Once()
+				mockDB.On("AddAddressEvent", "test_address", dbPkg.AddressEventRemoved, false, utils.NetworkMainnet).Return(nil).Once()
 				mockDB.On("DeleteRollsTarget", "test_address", utils.NetworkMainnet).Return(nil).Once()
-				mockDB.On("DeleteRollOpHistoryByAddress", "test_address").Return(nil).Once()
+				mockDB.On("ArchiveRollOpHistoryByAddress", "test_address", utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("DeleteAutomationPause", "test_address", utils.NetworkMainnet).Return(nil).Once()
 			},
 			expectedError: "",
 		},
@@ -225,10 +230,12 @@ func TestRemoveStakingAddress(t *testing.T) {
 				CandidateRolls: 5,
 			},
 			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB, t *testing.T) {
-				mockClient.On("SellRolls", "test_password", "test_address", uint64(5), float32(0.1)).Return("tx_hash", nil).Once()
+				mockClient.On("SellRolls", "test_password", "test_address", uint64(5), massaAmount.FromMAS(0.1)).Return("tx_hash", nil).Once()
 				mockClient.On("RemoveStakingAddress", "test_password", "test_address").Return(nil).Once()
+				mockDB.On("AddAddressEvent", "test_address", dbPkg.AddressEventRemoved, false, utils.NetworkMainnet).Return(nil).Once()
 				mockDB.On("DeleteRollsTarget", "test_address", utils.NetworkMainnet).Return(nil).Once()
-				mockDB.On("DeleteRollOpHistoryByAddress", "test_address").Return(nil).Once()
+				mockDB.On("ArchiveRollOpHistoryByAddress", "test_address", utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("DeleteAutomationPause", "test_address", utils.NetworkMainnet).Return(nil).Once()
 			},
 			expectedError: "",
 		},
@@ -242,7 +249,7 @@ func TestRemoveStakingAddress(t *testing.T) {
 				CandidateRolls: 5,
 			},
 			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB, t *testing.T) {
-				mockClient.On("SellRolls", "test_password", "test_address", uint64(5), float32(0.1)).Return("", assert.AnError).Once()
+				mockClient.On("SellRolls", "test_password", "test_address", uint64(5), massaAmount.FromMAS(0.1)).Return("", assert.AnError).Once()
 			},
 			expectedError: "failed to sell the 5 candidate rolls of the address test_address. Can't remove it from staking: assert.AnError general error for testing",
 		},
@@ -272,15 +279,18 @@ func TestRemoveStakingAddress(t *testing.T) {
 			},
 			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB, t *testing.T) {
 				mockClient.On("RemoveStakingAddress", "test_password", "test_address").Return(nil).Once()
+				mockDB.On("AddAddressEvent", "test_address", dbPkg.AddressEventRemoved, false, utils.NetworkMainnet).Return(nil).Once()
 
 				mockDB.On("DeleteRollsTarget", "test_address", utils.NetworkMainnet).Return(assert.AnError).Once()
-				mockDB.On("DeleteRollOpHistoryByAddress", "test_address").Return(assert.AnError).Once()
+				mockDB.On("ArchiveRollOpHistoryByAddress", "test_address", utils.NetworkMainnet).Return(assert.AnError).Once()
+				mockDB.On("DeleteAutomationPause", "test_address", utils.NetworkMainnet).Return(assert.AnError).Once()
 			},
 			expectedError: fmt.Sprintf(
-				"%s%s%s",
+				"%s%s%s%s",
 				"failed to remove address test_address from database, got following errors: ",
 				"failed to remove rolls target data for address test_address (mainnet) from database: assert.AnError general error for testing, ",
-				"failed to remove rolls operation history for address test_address from database: assert.AnError general error for testing",
+				"failed to archive rolls operation history for address test_address: assert.AnError general error for testing, ",
+				"failed to delete automation pause of address test_address: assert.AnError general error for testing",
 			),
 		},
 	}
@@ -300,8 +310,9 @@ func TestRemoveStakingAddress(t *testing.T) {
 				db:           mockDB,
 				nodeIsUp:     tt.nodeIsUp,
 				miscellaneous: Miscellaneous{
-					MinimalFees: 0.1,
+					MinimalFees: massaAmount.FromMAS(0.1),
 				},
+				pausedAddresses: map[string]bool{"test_address": true},
 			}
 
 			// Add existing address if provided
@@ -318,6 +329,8 @@ func TestRemoveStakingAddress(t *testing.T) {
 				assert.Contains(t, err.Error(), tt.expectedError)
 			} else {
 				assert.NoError(t, err)
+				// a removed address is not paused if it is added again
+				assert.False(t, sm.pausedAddresses[tt.address])
 			}
 
 			// Assert that all expected calls were made
@@ -345,7 +358,7 @@ func TestSetTargetRolls(t *testing.T) {
 			targetRolls: 10,
 			existingAddr: &StakingAddress{
 				Address:      "test_address",
-				FinalBalance: 100000,
+				FinalBalance: 100000 * massaAmount.MAS,
 				TargetRolls:  5,
 			},
 			setupMocks: func(mockDB *dbPkg.MockDB, mockAddressChangedDispatcher *MockAddressChangedDispatcher, mockClient *clientDriverPkg.MockClientDriver) {
@@ -353,11 +366,11 @@ func TestSetTargetRolls(t *testing.T) {
 				mockAddressChangedDispatcher.On("Publish", []StakingAddress{
 					{
 						Address:      "test_address",
-						FinalBalance: 100000,
+						FinalBalance: 100000 * massaAmount.MAS,
 						TargetRolls:  10,
 					},
 				}).Return().Once()
-				mockClient.On("BuyRolls", "test_password", "test_address", uint64(10), float32(0.1)).Return("tx_hash", nil).Once()
+				mockClient.On("BuyRolls", "test_password", "test_address", uint64(10), massaAmount.FromMAS(0.1)).Return("tx_hash", nil).Once()
 				mockDB.On("AddRollOpHistory", "test_address", dbPkg.RollOpBuy, uint64(10), "tx_hash", utils.NetworkMainnet).Return(nil).Once()
 			},
 			expectedError: "",
@@ -390,7 +403,7 @@ func TestSetTargetRolls(t *testing.T) {
 			targetRolls: 10,
 			existingAddr: &StakingAddress{
 				Address:      "test_address",
-				FinalBalance: 100000,
+				FinalBalance: 100000 * massaAmount.MAS,
 				TargetRolls:  5,
 			},
 			setupMocks: func(mockDB *dbPkg.MockDB, mockAddressChangedDispatcher *MockAddressChangedDispatcher, mockClient *clientDriverPkg.MockClientDriver) {
@@ -401,11 +414,11 @@ func TestSetTargetRolls(t *testing.T) {
 				mockAddressChangedDispatcher.On("Publish", []StakingAddress{
 					{
 						Address:      "test_address",
-						FinalBalance: 100000,
+						FinalBalance: 100000 * massaAmount.MAS,
 						TargetRolls:  10,
 					},
 				}).Return().Once()
-				mockClient.On("BuyRolls", "test_password", "test_address", uint64(10), float32(0.1)).Return("tx_hash", nil).Once()
+				mockClient.On("BuyRolls", "test_password", "test_address", uint64(10), massaAmount.FromMAS(0.1)).Return("tx_hash", nil).Once()
 				mockDB.On("AddRollOpHistory", "test_address", dbPkg.RollOpBuy, uint64(10), "tx_hash", utils.NetworkMainnet).Return(nil).Once()
 			},
 			expectedError: "",
@@ -444,8 +457,8 @@ func TestSetTargetRolls(t *testing.T) {
 				addressChangedDispatcher: mockAddressChangedDispatcher,
 				clientDriver:             mockClient,
 				miscellaneous: Miscellaneous{
-					MinimalFees: 0.1,
-					RollPrice:   100,
+					MinimalFees: massaAmount.FromMAS(0.1),
+					RollPrice:   100 * massaAmount.MAS,
 				},
 			}
 
@@ -479,7 +492,7 @@ func TestConvertToStakingAddress(t *testing.T) {
 	tests := []struct {
 		name           string
 		addresses      []getAddressesResponse
-		walletInfos    map[string]clientDriverPkg.WalletInfo
+		activeRolls    map[string]uint64
 		expectedResult []StakingAddress
 		expectedError  string
 	}{
@@ -513,17 +526,9 @@ func TestConvertToStakingAddress(t *testing.T) {
 					DeferredCredits:  []DeferredCreditDtoNode{},
 				},
 			},
-			walletInfos: map[string]clientDriverPkg.WalletInfo{
-				"test_address_1": {
-					AddressInfo: clientDriverPkg.AddressInfo{
-						ActiveRolls: 8,
-					},
-				},
-				"test_address_2": {
-					AddressInfo: clientDriverPkg.AddressInfo{
-						ActiveRolls: 18,
-					},
-				},
+			activeRolls: map[string]uint64{
+				"test_address_1": 8,
+				"test_address_2": 18,
 			},
 			expectedResult: []StakingAddress{
 				{
@@ -531,8 +536,8 @@ func TestConvertToStakingAddress(t *testing.T) {
 					FinalRolls:       10,
 					CandidateRolls:   5,
 					ActiveRolls:      8,
-					FinalBalance:     1000.5,
-					CandidateBalance: 500.25,
+					FinalBalance:     massaAmount.FromMAS(1000.5),
+					CandidateBalance: massaAmount.FromMAS(500.25),
 					Thread:           1,
 					DeferredCredits: []DeferredCredit{
 						{
@@ -540,7 +545,7 @@ func TestConvertToStakingAddress(t *testing.T) {
 								Period: 100,
 								Thread: 1,
 							},
-							Amount: 50.0,
+							Amount: 50 * massaAmount.MAS,
 						},
 					},
 				},
@@ -549,8 +554,8 @@ func TestConvertToStakingAddress(t *testing.T) {
 					FinalRolls:       20,
 					CandidateRolls:   15,
 					ActiveRolls:      18,
-					FinalBalance:     2000.75,
-					CandidateBalance: 1500.50,
+					FinalBalance:     massaAmount.FromMAS(2000.75),
+					CandidateBalance: massaAmount.FromMAS(1500.50),
 					Thread:           2,
 					DeferredCredits:  []DeferredCredit{},
 				},
@@ -570,12 +575,8 @@ func TestConvertToStakingAddress(t *testing.T) {
 					DeferredCredits:  []DeferredCreditDtoNode{},
 				},
 			},
-			walletInfos: map[string]clientDriverPkg.WalletInfo{
-				"test_address_1": {
-					AddressInfo: clientDriverPkg.AddressInfo{
-						ActiveRolls: 8,
-					},
-				},
+			activeRolls: map[string]uint64{
+				"test_address_1": 8,
 			},
 			expectedError: "failed to parse final balance",
 		},
@@ -592,19 +593,15 @@ func TestConvertToStakingAddress(t *testing.T) {
 					DeferredCredits:  []DeferredCreditDtoNode{},
 				},
 			},
-			walletInfos: map[string]clientDriverPkg.WalletInfo{
-				"test_address_1": {
-					AddressInfo: clientDriverPkg.AddressInfo{
-						ActiveRolls: 8,
-					},
-				},
+			activeRolls: map[string]uint64{
+				"test_address_1": 8,
 			},
 			expectedError: "failed to parse candidate balance",
 		},
 		{
 			name:           "Should handle empty addresses list",
 			addresses:      []getAddressesResponse{},
-			walletInfos:    map[string]clientDriverPkg.WalletInfo{},
+			activeRolls:    map[string]uint64{},
 			expectedResult: []StakingAddress{},
 			expectedError:  "",
 		},
@@ -621,8 +618,8 @@ func TestConvertToStakingAddress(t *testing.T) {
 					DeferredCredits:  []DeferredCreditDtoNode{},
 				},
 			},
-			walletInfos: map[string]clientDriverPkg.WalletInfo{
-				// Empty wallet infos - address not found
+			activeRolls: map[string]uint64{
+				// address not found
 			},
 			expectedResult: []StakingAddress{
 				{
@@ -630,8 +627,8 @@ func TestConvertToStakingAddress(t *testing.T) {
 					FinalRolls:       10,
 					CandidateRolls:   5,
 					ActiveRolls:      0, // Default value when not found
-					FinalBalance:     1000.5,
-					CandidateBalance: 500.25,
+					FinalBalance:     massaAmount.FromMAS(1000.5),
+					CandidateBalance: massaAmount.FromMAS(500.25),
 					Thread:           1,
 					DeferredCredits:  []DeferredCredit{},
 				},
@@ -651,7 +648,7 @@ func TestConvertToStakingAddress(t *testing.T) {
 			}
 
 			// Execute the function under test
-			result, err := sm.convertToStakingAddress(tt.addresses, tt.walletInfos)
+			result, err := sm.convertToStakingAddress(tt.addresses, tt.activeRolls)
 
 			// Assert results
 			if tt.expectedError != "" {
@@ -706,8 +703,8 @@ func TestWithTargetRolls(t *testing.T) {
 					FinalRolls:       10,
 					CandidateRolls:   5,
 					ActiveRolls:      8,
-					FinalBalance:     1000.0,
-					CandidateBalance: 500.0,
+					FinalBalance:     1000 * massaAmount.MAS,
+					CandidateBalance: 500 * massaAmount.MAS,
 					Thread:           1,
 					TargetRolls:      0, // Will be hydrated from DB
 				},
@@ -716,8 +713,8 @@ func TestWithTargetRolls(t *testing.T) {
 					FinalRolls:       20,
 					CandidateRolls:   15,
 					ActiveRolls:      18,
-					FinalBalance:     2000.0,
-					CandidateBalance: 1500.0,
+					FinalBalance:     2000 * massaAmount.MAS,
+					CandidateBalance: 1500 * massaAmount.MAS,
 					Thread:           2,
 					TargetRolls:      0, // Will be hydrated from DB
 				},
@@ -755,8 +752,8 @@ func TestWithTargetRolls(t *testing.T) {
 					FinalRolls:       10,
 					CandidateRolls:   5,
 					ActiveRolls:      8,
-					FinalBalance:     1000.0,
-					CandidateBalance: 500.0,
+					FinalBalance:     1000 * massaAmount.MAS,
+					CandidateBalance: 500 * massaAmount.MAS,
 					Thread:           1,
 					TargetRolls:      15, // Hydrated from DB
 				},
@@ -765,8 +762,8 @@ func TestWithTargetRolls(t *testing.T) {
 					FinalRolls:       20,
 					CandidateRolls:   15,
 					ActiveRolls:      18,
-					FinalBalance:     2000.0,
-					CandidateBalance: 1500.0,
+					FinalBalance:     2000 * massaAmount.MAS,
+					CandidateBalance: 1500 * massaAmount.MAS,
 					Thread:           2,
 					TargetRolls:      25, // Hydrated from DB
 				},
@@ -781,8 +778,8 @@ func TestWithTargetRolls(t *testing.T) {
 					FinalRolls:       10,
 					CandidateRolls:   5,
 					ActiveRolls:      8,
-					FinalBalance:     1000.0,
-					CandidateBalance: 500.0,
+					FinalBalance:     1000 * massaAmount.MAS,
+					CandidateBalance: 500 * massaAmount.MAS,
 					Thread:           1,
 					TargetRolls:      0,
 				},
@@ -810,8 +807,8 @@ func TestWithTargetRolls(t *testing.T) {
 					FinalRolls:       10,
 					CandidateRolls:   5,
 					ActiveRolls:      8,
-					FinalBalance:     1000.0,
-					CandidateBalance: 500.0,
+					FinalBalance:     1000 * massaAmount.MAS,
+					CandidateBalance: 500 * massaAmount.MAS,
 					Thread:           1,
 					TargetRolls:      15,
 				},
@@ -826,8 +823,8 @@ func TestWithTargetRolls(t *testing.T) {
 					FinalRolls:       10,
 					CandidateRolls:   5,
 					ActiveRolls:      8,
-					FinalBalance:     1000.0,
-					CandidateBalance: 500.0,
+					FinalBalance:     1000 * massaAmount.MAS,
+					CandidateBalance: 500 * massaAmount.MAS,
 					Thread:           1,
 					TargetRolls:      0,
 				},
@@ -870,8 +867,8 @@ func TestWithTargetRolls(t *testing.T) {
 					FinalRolls:       10,
 					CandidateRolls:   5,
 					ActiveRolls:      8,
-					FinalBalance:     1000.0,
-					CandidateBalance: 500.0,
+					FinalBalance:     1000 * massaAmount.MAS,
+					CandidateBalance: 500 * massaAmount.MAS,
 					Thread:           1,
 					TargetRolls:      0,
 				},
@@ -887,8 +884,8 @@ func TestWithTargetRolls(t *testing.T) {
 					FinalRolls:       10,
 					CandidateRolls:   5,
 					ActiveRolls:      8,
-					FinalBalance:     1000.0,
-					CandidateBalance: 500.0,
+					FinalBalance:     1000 * massaAmount.MAS,
+					CandidateBalance: 500 * massaAmount.MAS,
 					Thread:           1,
 					TargetRolls:      0, // Should remain 0 since no DB entry
 				},
@@ -1051,3 +1048,13 @@ func TestCleanAddressesNotInNodeButInDB(t *testing.T) {
 		})
 	}
 }
+
+func TestNewClientDriverUnknownValues(t *testing.T) {
+	sm := &stakingManager{config: &configPkg.PluginConfig{ClientDriver: "massa-client", RollOperations: configPkg.RollOperationsMassaClient}}
+	_, err := sm.newClientDriver()
+	assert.EqualError(t, err, `unknown client driver "massa-client"`)
+
+	sm.config = &configPkg.PluginConfig{ClientDriver: configPkg.ClientDriverMassaClient, RollOperations: "nativ"}
+	_, err = sm.newClientDriver()
+	assert.EqualError(t, err, `unknown roll operations "nativ"`)
+}
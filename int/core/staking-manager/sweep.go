@@ -0,0 +1,165 @@
+package stakingManager
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/massalabs/node-manager-plugin/int/config"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	massaKeys "github.com/massalabs/node-manager-plugin/int/massa-keys"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+// SetSweepRule sets the rule sweeping the excess balance of a staking address to a cold address
+func (s *stakingManager) SetSweepRule(rule dbPkg.SweepRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ramAddressListContains(rule.Address) {
+		return fmt.Errorf("address %s not found in staking addresses", rule.Address)
+	}
+
+	if _, err := massaKeys.AddressBytes(rule.ColdAddress); err != nil {
+		return fmt.Errorf("invalid cold address: %w", err)
+	}
+
+	if rule.ColdAddress == rule.Address {
+		return fmt.Errorf("cold address must be different from the staking address")
+	}
+
+	if rule.Threshold <= 0 || rule.Reserve < 0 {
+		return fmt.Errorf("threshold must be positive and reserve can't be negative, got threshold %s and reserve %s", rule.Threshold, rule.Reserve)
+	}
+
+	if err := s.db.SetSweepRule(rule, config.GlobalPluginInfo.GetNetwork()); err != nil {
+		return fmt.Errorf("failed to save sweep rule of address %s: %w", rule.Address, err)
+	}
+
+	logger.Infof("Sweep rule of address %s set: above %s + %s MAS to %s (dry run: %t)", rule.Address, rule.Threshold, rule.Reserve, rule.ColdAddress, rule.DryRun)
+	return nil
+}
+
+// DeleteSweepRule stops sweeping the balance of a staking address
+func (s *stakingManager) DeleteSweepRule(address string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.db.DeleteSweepRule(address, config.GlobalPluginInfo.GetNetwork()); err != nil {
+		return fmt.Errorf("failed to delete sweep rule of address %s: %w", address, err)
+	}
+
+	return nil
+}
+
+// GetSweepRules returns the sweep rules of the current network
+func (s *stakingManager) GetSweepRules() ([]dbPkg.SweepRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules, err := s.db.GetSweepRules(config.GlobalPluginInfo.GetNetwork())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sweep rules from database: %w", err)
+	}
+
+	return rules, nil
+}
+
+/*
+handleSweeps sends the excess balance of the addresses having a sweep rule to their cold address.
+It must be called after handleRollsUpdates: a sweep shares the pending operation of the roll operations,
+so an address sends at most one operation at a time and a sweep waits for the roll operation to be final.
+Rules of addresses that are no longer staked or whose automation is paused are ignored.
+*/
+func (s *stakingManager) handleSweeps(newAddresses []StakingAddress) {
+	currentNetwork := config.GlobalPluginInfo.GetNetwork()
+
+	rules, err := s.db.GetSweepRules(currentNetwork)
+	if err != nil {
+		logger.Errorf("failed to get sweep rules from database: %v", err)
+		return
+	}
+
+	for _, rule := range rules {
+		i := slices.IndexFunc(newAddresses, func(addr StakingAddress) bool { return addr.Address == rule.Address })
+		if i < 0 || s.automationIsPaused(rule.Address) {
+			continue
+		}
+
+		if err := s.sweepAddress(newAddresses[i], rule, currentNetwork); err != nil {
+			logger.Errorf("failed to sweep balance of address %s: %v", rule.Address, err)
+		}
+	}
+}
+
+func (s *stakingManager) sweepAddress(address StakingAddress, rule dbPkg.SweepRule, network utils.Network) error {
+	index, ok := s.getAddressIndexFromRamList(address.Address)
+	if !ok {
+		return nil
+	}
+
+	// handleRollsUpdates has already cleared the pending operation if it is completed
+	if s.stakingAddresses[index].pendingOperationId != nil {
+		logger.Debugf("pending operation for address %s is not completed, skipping sweep", address.Address)
+		return nil
+	}
+
+	amount := sweepAmount(address.FinalBalance, rule, s.miscellaneous.MinimalFees)
+	if amount <= 0 {
+		return nil
+	}
+
+	sweep := dbPkg.SweepHistory{
+		Address:     address.Address,
+		ColdAddress: rule.ColdAddress,
+		Amount:      amount,
+		Fee:         s.miscellaneous.MinimalFees,
+		DryRun:      rule.DryRun,
+	}
+
+	if rule.DryRun {
+		// the balance is polled continuously, only record a dry run when it would sweep something else than the last one
+		history, err := s.db.GetSweepHistory(address.Address, network)
+		if err != nil {
+			return fmt.Errorf("failed to get sweep history: %w", err)
+		}
+		if len(history) > 0 && history[0].DryRun && history[0].Amount == amount && history[0].ColdAddress == rule.ColdAddress {
+			return nil
+		}
+
+		logger.Infof("[dry run] Address %s (balance: %s) would sweep %s MAS to %s", address.Address, address.FinalBalance, amount, rule.ColdAddress)
+		if err := s.db.AddSweepHistory(sweep, network); err != nil {
+			return fmt.Errorf("failed to record dry run sweep: %w", err)
+		}
+		return nil
+	}
+
+	logger.Infof("Address %s (balance: %s) is above its sweep threshold: sending %s MAS to %s", address.Address, address.FinalBalance, amount, rule.ColdAddress)
+	opId, err := s.clientDriver.SendTransaction(config.GlobalPluginInfo.GetPwd(), address.Address, rule.ColdAddress, amount, s.miscellaneous.MinimalFees)
+	if err != nil {
+		return fmt.Errorf("failed to send %s MAS to %s: %w", amount, rule.ColdAddress, err)
+	}
+
+	s.setPendingOperation(index, opId)
+
+	sweep.OpId = opId
+	if err := s.db.AddSweepHistory(sweep, network); err != nil {
+		return fmt.Errorf("failed to record sweep operation %s: %w", opId, err)
+	}
+
+	logger.Infof("Swept %s MAS from address %s to %s", amount, address.Address, rule.ColdAddress)
+	return nil
+}
+
+/*
+sweepAmount returns the amount to send to the cold address: everything above the reserve, minus the fees,
+once the balance is above threshold + reserve.
+*/
+func sweepAmount(balance massaAmount.Amount, rule dbPkg.SweepRule, fee massaAmount.Amount) massaAmount.Amount {
+	if balance <= rule.Threshold+rule.Reserve {
+		return 0
+	}
+
+	return max(balance-rule.Reserve-fee, 0)
+}
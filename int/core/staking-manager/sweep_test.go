@@ -0,0 +1,211 @@
+package stakingManager
+
+import (
+	"testing"
+
+	clientDriverPkg "github.com/massalabs/node-manager-plugin/int/client-driver"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	sweepAddress     = "AU1KBGdEiu8zYtJ79ouX1Jv7HsWMgXRKK6yWgt93joD2HLQ9euFp"
+	sweepColdAddress = "AU12hHvKMBNUDMuhLuE8oDJ3iBGjEk5eRETdqGL7MWYqvy1NJkhor"
+)
+
+func TestSweepAmount(t *testing.T) {
+	rule := dbPkg.SweepRule{Threshold: 100 * massaAmount.MAS, Reserve: 50 * massaAmount.MAS}
+
+	tests := []struct {
+		name     string
+		balance  massaAmount.Amount
+		expected massaAmount.Amount
+	}{
+		{name: "below reserve", balance: 20 * massaAmount.MAS, expected: 0},
+		{name: "below threshold plus reserve", balance: 149 * massaAmount.MAS, expected: 0},
+		{name: "exactly threshold plus reserve", balance: 150 * massaAmount.MAS, expected: 0},
+		{name: "above threshold plus reserve", balance: 250 * massaAmount.MAS, expected: massaAmount.FromMAS(199.99)},
+		{name: "exact to the nanoMAS", balance: 150*massaAmount.MAS + massaAmount.NanoMAS, expected: massaAmount.FromMAS(99.99) + massaAmount.NanoMAS},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, sweepAmount(tt.balance, rule, massaAmount.FromMAS(0.01)))
+		})
+	}
+}
+
+func TestHandleSweeps(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	minimalFees := massaAmount.FromMAS(0.01)
+	rule := dbPkg.SweepRule{Address: sweepAddress, ColdAddress: sweepColdAddress, Threshold: 100 * massaAmount.MAS, Reserve: 50 * massaAmount.MAS}
+	dryRunRule := rule
+	dryRunRule.DryRun = true
+
+	tests := []struct {
+		name              string
+		balance           massaAmount.Amount
+		pendingOperation  bool
+		setupMocks        func(*clientDriverPkg.MockClientDriver, *dbPkg.MockDB)
+		expectedPendingOp string
+	}{
+		{
+			name:    "Should sweep the balance above the reserve",
+			balance: 250 * massaAmount.MAS,
+			setupMocks: func(mockClient *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockDB.On("GetSweepRules", utils.NetworkMainnet).Return([]dbPkg.SweepRule{rule}, nil).Once()
+				mockClient.On("SendTransaction", "test_password", sweepAddress, sweepColdAddress, massaAmount.FromMAS(199.99), minimalFees).Return("sweep_op", nil).Once()
+				mockDB.On("AddSweepHistory", dbPkg.SweepHistory{
+					Address:     sweepAddress,
+					ColdAddress: sweepColdAddress,
+					Amount:      massaAmount.FromMAS(199.99),
+					Fee:         minimalFees,
+					OpId:        "sweep_op",
+				}, utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedPendingOp: "sweep_op",
+		},
+		{
+			name:    "Should not sweep below threshold plus reserve",
+			balance: 120 * massaAmount.MAS,
+			setupMocks: func(_ *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockDB.On("GetSweepRules", utils.NetworkMainnet).Return([]dbPkg.SweepRule{rule}, nil).Once()
+			},
+		},
+		{
+			name:             "Should wait for the pending operation to be completed",
+			balance:          250 * massaAmount.MAS,
+			pendingOperation: true,
+			setupMocks: func(_ *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockDB.On("GetSweepRules", utils.NetworkMainnet).Return([]dbPkg.SweepRule{rule}, nil).Once()
+			},
+			expectedPendingOp: "roll_op",
+		},
+		{
+			name:    "Should ignore rules of addresses that are not staked",
+			balance: 250 * massaAmount.MAS,
+			setupMocks: func(_ *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				otherRule := rule
+				otherRule.Address = "other_address"
+				mockDB.On("GetSweepRules", utils.NetworkMainnet).Return([]dbPkg.SweepRule{otherRule}, nil).Once()
+			},
+		},
+		{
+			name:    "Should only record the sweep in dry run mode",
+			balance: 250 * massaAmount.MAS,
+			setupMocks: func(_ *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockDB.On("GetSweepRules", utils.NetworkMainnet).Return([]dbPkg.SweepRule{dryRunRule}, nil).Once()
+				mockDB.On("GetSweepHistory", sweepAddress, utils.NetworkMainnet).Return([]dbPkg.SweepHistory{}, nil).Once()
+				mockDB.On("AddSweepHistory", dbPkg.SweepHistory{
+					Address:     sweepAddress,
+					ColdAddress: sweepColdAddress,
+					Amount:      massaAmount.FromMAS(199.99),
+					Fee:         minimalFees,
+					DryRun:      true,
+				}, utils.NetworkMainnet).Return(nil).Once()
+			},
+		},
+		{
+			name:    "Should not record the same dry run twice",
+			balance: 250 * massaAmount.MAS,
+			setupMocks: func(_ *clientDriverPkg.MockClientDriver, mockDB *dbPkg.MockDB) {
+				mockDB.On("GetSweepRules", utils.NetworkMainnet).Return([]dbPkg.SweepRule{dryRunRule}, nil).Once()
+				mockDB.On("GetSweepHistory", sweepAddress, utils.NetworkMainnet).Return([]dbPkg.SweepHistory{
+					{Address: sweepAddress, ColdAddress: sweepColdAddress, Amount: massaAmount.FromMAS(199.99), DryRun: true},
+				}, nil).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := clientDriverPkg.NewMockClientDriver(t)
+			mockDB := dbPkg.NewMockDB(t)
+			tt.setupMocks(mockClient, mockDB)
+
+			existing := StakingAddress{Address: sweepAddress, FinalBalance: tt.balance}
+			if tt.pendingOperation {
+				opId := "roll_op"
+				existing.pendingOperationId = &opId
+			}
+
+			sm := &stakingManager{
+				clientDriver:     mockClient,
+				db:               mockDB,
+				stakingAddresses: []StakingAddress{existing},
+				miscellaneous:    Miscellaneous{MinimalFees: minimalFees},
+			}
+
+			sm.handleSweeps([]StakingAddress{{Address: sweepAddress, FinalBalance: tt.balance}})
+
+			if tt.expectedPendingOp == "" {
+				assert.Nil(t, sm.stakingAddresses[0].pendingOperationId)
+			} else {
+				assert.Equal(t, tt.expectedPendingOp, *sm.stakingAddresses[0].pendingOperationId)
+			}
+		})
+	}
+}
+
+func TestSetSweepRule(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	tests := []struct {
+		name          string
+		rule          dbPkg.SweepRule
+		expectSave    bool
+		expectedError string
+	}{
+		{
+			name:       "Should save a valid rule",
+			rule:       dbPkg.SweepRule{Address: sweepAddress, ColdAddress: sweepColdAddress, Threshold: 100 * massaAmount.MAS, Reserve: 50 * massaAmount.MAS, DryRun: true},
+			expectSave: true,
+		},
+		{
+			name:          "Should reject an address that is not staked",
+			rule:          dbPkg.SweepRule{Address: "other_address", ColdAddress: sweepColdAddress, Threshold: 100 * massaAmount.MAS},
+			expectedError: "address other_address not found in staking addresses",
+		},
+		{
+			name:          "Should reject an invalid cold address",
+			rule:          dbPkg.SweepRule{Address: sweepAddress, ColdAddress: "cold", Threshold: 100 * massaAmount.MAS},
+			expectedError: "invalid cold address",
+		},
+		{
+			name:          "Should reject sweeping to the staking address itself",
+			rule:          dbPkg.SweepRule{Address: sweepAddress, ColdAddress: sweepAddress, Threshold: 100 * massaAmount.MAS},
+			expectedError: "cold address must be different from the staking address",
+		},
+		{
+			name:          "Should reject a zero threshold",
+			rule:          dbPkg.SweepRule{Address: sweepAddress, ColdAddress: sweepColdAddress},
+			expectedError: "threshold must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := dbPkg.NewMockDB(t)
+			if tt.expectSave {
+				mockDB.On("SetSweepRule", tt.rule, utils.NetworkMainnet).Return(nil).Once()
+			}
+
+			sm := &stakingManager{
+				db:               mockDB,
+				stakingAddresses: []StakingAddress{{Address: sweepAddress}},
+			}
+
+			err := sm.SetSweepRule(tt.rule)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -39,6 +39,12 @@ func copyAddresses(src []StakingAddress) []StakingAddress {
 	for i := range dst {
 		dst[i].DeferredCredits = make([]DeferredCredit, len(src[i].DeferredCredits))
 		copy(dst[i].DeferredCredits, src[i].DeferredCredits)
+		dst[i].CycleInfos = make([]CycleInfo, len(src[i].CycleInfos))
+		copy(dst[i].CycleInfos, src[i].CycleInfos)
+		if src[i].PendingOperation != nil {
+			pendingOp := *src[i].PendingOperation
+			dst[i].PendingOperation = &pendingOp
+		}
 	}
 	return dst
 }
@@ -3,6 +3,8 @@ package stakingManager
 import (
 	"slices"
 	"testing"
+
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
 )
 
 // createTestStakingManager creates a stakingManager instance for testing
@@ -13,8 +15,8 @@ func createTestStakingManager() *stakingManager {
 				Address:          "AU1test123",
 				FinalRolls:       10,
 				CandidateRolls:   8,
-				FinalBalance:     1000.0,
-				CandidateBalance: 950.0,
+				FinalBalance:     1000 * massaAmount.MAS,
+				CandidateBalance: 950 * massaAmount.MAS,
 				Thread:           0,
 				TargetRolls:      15,
 			},
@@ -22,8 +24,8 @@ func createTestStakingManager() *stakingManager {
 				Address:          "AU1test456",
 				FinalRolls:       5,
 				CandidateRolls:   5,
-				FinalBalance:     500.0,
-				CandidateBalance: 500.0,
+				FinalBalance:     500 * massaAmount.MAS,
+				CandidateBalance: 500 * massaAmount.MAS,
 				Thread:           1,
 				TargetRolls:      10,
 			},
@@ -31,8 +33,8 @@ func createTestStakingManager() *stakingManager {
 				Address:          "AU1test789",
 				FinalRolls:       20,
 				CandidateRolls:   18,
-				FinalBalance:     2000.0,
-				CandidateBalance: 1900.0,
+				FinalBalance:     2000 * massaAmount.MAS,
+				CandidateBalance: 1900 * massaAmount.MAS,
 				Thread:           2,
 				TargetRolls:      25,
 			},
@@ -0,0 +1,186 @@
+package stakingManager
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/massalabs/node-manager-plugin/int/config"
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	massaKeys "github.com/massalabs/node-manager-plugin/int/massa-keys"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+/*
+AddWatchOnlyAddress adds an address that is monitored without being staked by the node,
+for instance an address staked on another machine or a treasury address.
+It is polled with the staking addresses, sent on the staking addresses feed with the watch-only flag
+and its value is recorded in a separate value history series. Its rolls are never bought or sold and it is never swept.
+*/
+func (s *stakingManager) AddWatchOnlyAddress(address string) (StakingAddress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := massaKeys.AddressBytes(address); err != nil {
+		return StakingAddress{}, err
+	}
+
+	if s.ramAddressListContains(address) {
+		return StakingAddress{}, fmt.Errorf("address %s is already a staking address", address)
+	}
+
+	if s.watchOnlyListContains(address) {
+		return StakingAddress{}, fmt.Errorf("address %s already in watch-only addresses", address)
+	}
+
+	if err := s.db.AddWatchOnlyAddress(address, config.GlobalPluginInfo.GetNetwork()); err != nil {
+		return StakingAddress{}, fmt.Errorf("failed to save watch-only address %s: %w", address, err)
+	}
+
+	watchOnly := StakingAddress{Address: address, WatchOnly: true}
+
+	// the data are fetched at next poll if the node can't be reached now
+	if s.nodeIsUp {
+		addressData, err := s.getAddressesDataFromNode([]string{address})
+		if err != nil {
+			logger.Warnf("failed to get data of watch-only address %s from node: %v", address, err)
+		} else if len(addressData) == 1 {
+			watchOnly = addressData[0]
+			watchOnly.WatchOnly = true
+		}
+	}
+
+	s.watchOnlyAddresses = append(s.watchOnlyAddresses, watchOnly)
+	s.recordAddressEvent(address, dbPkg.AddressEventAdded, true)
+	s.addressChangedDispatcher.Publish(s.addressesToPublish())
+
+	logger.Infof("Watch-only address %s added", address)
+	return watchOnly, nil
+}
+
+// RemoveWatchOnlyAddress stops monitoring a watch-only address, its value history is kept
+func (s *stakingManager) RemoveWatchOnlyAddress(address string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.db.DeleteWatchOnlyAddress(address, config.GlobalPluginInfo.GetNetwork()); err != nil {
+		return fmt.Errorf("failed to delete watch-only address %s: %w", address, err)
+	}
+
+	s.watchOnlyAddresses = slices.DeleteFunc(s.watchOnlyAddresses, func(addr StakingAddress) bool {
+		return addr.Address == address
+	})
+	s.recordAddressEvent(address, dbPkg.AddressEventRemoved, true)
+	s.addressChangedDispatcher.Publish(s.addressesToPublish())
+
+	logger.Infof("Watch-only address %s removed", address)
+	return nil
+}
+
+// GetWatchOnlyAddresses returns the watch-only addresses of the current network with their last polled data
+func (s *stakingManager) GetWatchOnlyAddresses() []StakingAddress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return copyAddresses(s.watchOnlyAddresses)
+}
+
+// loadWatchOnlyAddresses loads the watch-only addresses of the current network from the database, their data are fetched at next poll
+func (s *stakingManager) loadWatchOnlyAddresses() error {
+	addresses, err := s.db.GetWatchOnlyAddresses(config.GlobalPluginInfo.GetNetwork())
+	if err != nil {
+		return fmt.Errorf("failed to get watch-only addresses from database: %w", err)
+	}
+
+	s.watchOnlyAddresses = make([]StakingAddress, 0, len(addresses))
+	for _, address := range addresses {
+		s.watchOnlyAddresses = append(s.watchOnlyAddresses, StakingAddress{Address: address.Address, WatchOnly: true})
+	}
+
+	return nil
+}
+
+// dropWatchOnlyAddress stops watching an address that has become a staking address. The caller must hold s.mu
+func (s *stakingManager) dropWatchOnlyAddress(address string) {
+	if !s.watchOnlyListContains(address) {
+		return
+	}
+
+	if err := s.db.DeleteWatchOnlyAddress(address, config.GlobalPluginInfo.GetNetwork()); err != nil && !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		logger.Errorf("failed to delete watch-only address %s that is now staked: %v", address, err)
+	}
+	s.recordAddressEvent(address, dbPkg.AddressEventRemoved, true)
+
+	s.watchOnlyAddresses = slices.DeleteFunc(s.watchOnlyAddresses, func(addr StakingAddress) bool {
+		return addr.Address == address
+	})
+}
+
+/*
+splitWatchOnlyAddresses separates the polled watch-only addresses from the staking addresses,
+so that the watch-only ones never reach the roll and sweep automation
+*/
+func (s *stakingManager) splitWatchOnlyAddresses(polled []StakingAddress) ([]StakingAddress, []StakingAddress) {
+	if len(s.watchOnlyAddresses) == 0 {
+		return polled, nil
+	}
+
+	staking := make([]StakingAddress, 0, len(polled))
+	watchOnly := make([]StakingAddress, 0, len(s.watchOnlyAddresses))
+	for _, address := range polled {
+		if s.watchOnlyListContains(address.Address) {
+			address.WatchOnly = true
+			address.TargetRolls = 0
+			watchOnly = append(watchOnly, address)
+		} else {
+			staking = append(staking, address)
+		}
+	}
+
+	return staking, watchOnly
+}
+
+// updateWatchOnlyAddresses replaces the watch-only addresses data with the polled ones, returns true if they have changed
+func (s *stakingManager) updateWatchOnlyAddresses(polled []StakingAddress) bool {
+	if len(polled) == 0 {
+		return false
+	}
+
+	updated := !slices.EqualFunc(s.watchOnlyAddresses, polled, func(a, b StakingAddress) bool {
+		return a.Address == b.Address &&
+			a.FinalRolls == b.FinalRolls &&
+			a.CandidateRolls == b.CandidateRolls &&
+			a.ActiveRolls == b.ActiveRolls &&
+			a.FinalBalance == b.FinalBalance &&
+			a.CandidateBalance == b.CandidateBalance &&
+			slices.EqualFunc(a.DeferredCredits, b.DeferredCredits, func(c, d DeferredCredit) bool { return c.Amount == d.Amount }) &&
+			slices.Equal(a.CycleInfos, b.CycleInfos)
+	})
+
+	s.watchOnlyAddresses = copyAddresses(polled)
+	return updated
+}
+
+// addressesToPublish returns the staking addresses followed by the watch-only addresses, as sent on the staking addresses feed
+func (s *stakingManager) addressesToPublish() []StakingAddress {
+	if len(s.watchOnlyAddresses) == 0 {
+		return s.stakingAddresses
+	}
+
+	return append(copyAddresses(s.stakingAddresses), copyAddresses(s.watchOnlyAddresses)...)
+}
+
+// getWatchOnlyAddressesFromRamList returns the watch-only addresses to poll
+func (s *stakingManager) getWatchOnlyAddressesFromRamList() []string {
+	addresses := make([]string, 0, len(s.watchOnlyAddresses))
+	for _, addr := range s.watchOnlyAddresses {
+		addresses = append(addresses, addr.Address)
+	}
+	return addresses
+}
+
+func (s *stakingManager) watchOnlyListContains(address string) bool {
+	return slices.ContainsFunc(s.watchOnlyAddresses, func(addr StakingAddress) bool {
+		return addr.Address == address
+	})
+}
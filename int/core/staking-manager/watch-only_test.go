@@ -0,0 +1,149 @@
+package stakingManager
+
+import (
+	"testing"
+
+	dbPkg "github.com/massalabs/node-manager-plugin/int/db"
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddWatchOnlyAddress(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	tests := []struct {
+		name             string
+		address          string
+		setupMocks       func(*dbPkg.MockDB)
+		expectedWatched  []string
+		expectedErrorMsg string
+	}{
+		{
+			name:    "Should add a watch-only address",
+			address: sweepColdAddress,
+			setupMocks: func(mockDB *dbPkg.MockDB) {
+				mockDB.On("AddWatchOnlyAddress", sweepColdAddress, utils.NetworkMainnet).Return(nil).Once()
+				mockDB.On("AddAddressEvent", sweepColdAddress, dbPkg.AddressEventAdded, true, utils.NetworkMainnet).Return(nil).Once()
+			},
+			expectedWatched: []string{"watched_address", sweepColdAddress},
+		},
+		{
+			name:             "Should fail for an invalid address",
+			address:          "invalid_address",
+			setupMocks:       func(_ *dbPkg.MockDB) {},
+			expectedWatched:  []string{"watched_address"},
+			expectedErrorMsg: "invalid address invalid_address",
+		},
+		{
+			name:             "Should fail for a staking address",
+			address:          sweepAddress,
+			setupMocks:       func(_ *dbPkg.MockDB) {},
+			expectedWatched:  []string{"watched_address"},
+			expectedErrorMsg: "is already a staking address",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := dbPkg.NewMockDB(t)
+			tt.setupMocks(mockDB)
+
+			sm := &stakingManager{
+				db:                       mockDB,
+				addressChangedDispatcher: NewAddressChangedDispatcher(),
+				stakingAddresses:         []StakingAddress{{Address: sweepAddress}},
+				watchOnlyAddresses:       []StakingAddress{{Address: "watched_address", WatchOnly: true}},
+			}
+
+			address, err := sm.AddWatchOnlyAddress(tt.address)
+			if tt.expectedErrorMsg != "" {
+				assert.ErrorContains(t, err, tt.expectedErrorMsg)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, address.WatchOnly)
+			}
+
+			assert.Equal(t, tt.expectedWatched, sm.getWatchOnlyAddressesFromRamList())
+		})
+	}
+}
+
+func TestRemoveWatchOnlyAddress(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	mockDB := dbPkg.NewMockDB(t)
+	mockDB.On("DeleteWatchOnlyAddress", "watched_address", utils.NetworkMainnet).Return(nil).Once()
+	mockDB.On("AddAddressEvent", "watched_address", dbPkg.AddressEventRemoved, true, utils.NetworkMainnet).Return(nil).Once()
+	mockDB.On("DeleteWatchOnlyAddress", "other_address", utils.NetworkMainnet).Return(
+		nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, "watch-only address not found"),
+	).Once()
+
+	sm := &stakingManager{
+		db:                       mockDB,
+		addressChangedDispatcher: NewAddressChangedDispatcher(),
+		watchOnlyAddresses:       []StakingAddress{{Address: "watched_address", WatchOnly: true}},
+	}
+
+	assert.NoError(t, sm.RemoveWatchOnlyAddress("watched_address"))
+	assert.Empty(t, sm.GetWatchOnlyAddresses())
+	assert.Error(t, sm.RemoveWatchOnlyAddress("other_address"))
+}
+
+func TestWatchOnlyAddressesPolling(t *testing.T) {
+	cleanup := setupLog(t)
+	defer cleanup()
+
+	t.Run("watch-only addresses are split from the staking addresses", func(t *testing.T) {
+		sm := &stakingManager{
+			stakingAddresses:   []StakingAddress{{Address: "test_address"}},
+			watchOnlyAddresses: []StakingAddress{{Address: "watched_address", WatchOnly: true}},
+		}
+
+		staking, watchOnly := sm.splitWatchOnlyAddresses([]StakingAddress{
+			{Address: "test_address", FinalBalance: 10 * massaAmount.MAS},
+			{Address: "watched_address", FinalBalance: 20 * massaAmount.MAS},
+		})
+
+		assert.Equal(t, []StakingAddress{{Address: "test_address", FinalBalance: 10 * massaAmount.MAS}}, staking)
+		assert.Equal(t, []StakingAddress{{Address: "watched_address", FinalBalance: 20 * massaAmount.MAS, WatchOnly: true}}, watchOnly)
+
+		assert.True(t, sm.updateWatchOnlyAddresses(watchOnly))
+		assert.False(t, sm.updateWatchOnlyAddresses(watchOnly))
+		assert.Equal(t, []string{"test_address", "watched_address"}, addressesOf(sm.addressesToPublish()))
+	})
+
+	t.Run("watch-only value is recorded in its own series", func(t *testing.T) {
+		mockDB := dbPkg.NewMockDB(t)
+		mockDB.On("PostWatchOnlyHistory", mock.MatchedBy(func(history dbPkg.ValueHistory) bool {
+			return history.TotalValue == 350*massaAmount.MAS
+		}), utils.NetworkMainnet).Return(nil).Once()
+
+		sm := &stakingManager{
+			db:                 mockDB,
+			miscellaneous:      Miscellaneous{RollPrice: 100 * massaAmount.MAS},
+			stakingAddresses:   []StakingAddress{{Address: "test_address", FinalBalance: 1000 * massaAmount.MAS}},
+			watchOnlyAddresses: []StakingAddress{{Address: "watched_address", FinalBalance: 200 * massaAmount.MAS, FinalRolls: 1, DeferredCredits: []DeferredCredit{{Amount: 50 * massaAmount.MAS}}, WatchOnly: true}},
+		}
+
+		assert.Equal(t, 1000*massaAmount.MAS, sm.getTotalValue())
+		sm.postWatchOnlyValue(utils.NetworkMainnet)
+	})
+
+	t.Run("nothing is recorded without watch-only addresses", func(t *testing.T) {
+		sm := &stakingManager{db: dbPkg.NewMockDB(t)}
+		sm.postWatchOnlyValue(utils.NetworkMainnet)
+	})
+}
+
+func addressesOf(addresses []StakingAddress) []string {
+	result := make([]string, len(addresses))
+	for i, address := range addresses {
+		result[i] = address.Address
+	}
+	return result
+}
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	logger "github.com/massalabs/station/pkg/logger"
+)
+
+// AddressLabel is the user-defined label, tags and group of an address, a staking address or a watch-only one
+type AddressLabel struct {
+	Address string   `json:"address"`
+	Label   string   `json:"label"`
+	Tags    []string `json:"tags"`
+	Group   string   `json:"group"` // empty if the address is in no group
+}
+
+// SetAddressLabel sets the label, tags and group of an address, replacing the previous ones
+func (d *dB) SetAddressLabel(label AddressLabel, network utils.Network) error {
+	tags, err := marshalTags(label.Tags)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO address_labels (address, network, label, tags, group_name) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (address, network) DO UPDATE SET label = excluded.label, tags = excluded.tags, group_name = excluded.group_name`
+
+	if _, err := d.db.Exec(query, label.Address, string(network), label.Label, tags, label.Group); err != nil {
+		return fmt.Errorf("failed to set label of address %q: %w", label.Address, err)
+	}
+
+	return nil
+}
+
+// InitAddressLabel sets the label of an address if it has none yet, its tags and group are left unchanged
+func (d *dB) InitAddressLabel(address string, label string, network utils.Network) error {
+	query := `INSERT INTO address_labels (address, network, label, tags, group_name) VALUES (?, ?, ?, '[]', '')
+		ON CONFLICT (address, network) DO UPDATE SET label = excluded.label WHERE address_labels.label = ''`
+
+	if _, err := d.db.Exec(query, address, string(network), label); err != nil {
+		return fmt.Errorf("failed to init label of address %q: %w", address, err)
+	}
+
+	return nil
+}
+
+// GetAddressLabel retrieves the label, tags and group of an address
+func (d *dB) GetAddressLabel(address string, network utils.Network) (AddressLabel, error) {
+	query := `SELECT address, label, tags, group_name FROM address_labels WHERE address = ? AND network = ?`
+
+	label, err := scanAddressLabel(d.db.QueryRow(query, address, string(network)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return AddressLabel{}, nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, fmt.Sprintf("label of address %q (%s) not found in database", address, string(network)))
+	}
+	if err != nil {
+		return AddressLabel{}, fmt.Errorf("failed to get label of address %q: %w", address, err)
+	}
+
+	return label, nil
+}
+
+// GetAddressLabels retrieves the labels, tags and groups of the addresses of a network, ordered by address
+func (d *dB) GetAddressLabels(network utils.Network) ([]AddressLabel, error) {
+	query := `SELECT address, label, tags, group_name FROM address_labels WHERE network = ? ORDER BY address`
+
+	rows, err := d.db.Query(query, string(network))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query address labels: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close address labels rows: %v", err)
+		}
+	}()
+
+	var labels []AddressLabel
+	for rows.Next() {
+		label, err := scanAddressLabel(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan address labels row: %w", err)
+		}
+		labels = append(labels, label)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over address labels rows: %w", err)
+	}
+
+	return labels, nil
+}
+
+// DeleteAddressLabel deletes the label, tags and group of an address
+func (d *dB) DeleteAddressLabel(address string, network utils.Network) error {
+	query := `DELETE FROM address_labels WHERE address = ? AND network = ?`
+
+	result, err := d.db.Exec(query, address, string(network))
+	if err != nil {
+		return fmt.Errorf("failed to delete label of address %q: %w", address, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, fmt.Sprintf("label of address %q (%s) not found in database", address, string(network)))
+	}
+
+	return nil
+}
+
+// scanAddressLabel scans an address_labels row, tags are stored as a json array
+func scanAddressLabel(row interface{ Scan(dest ...any) error }) (AddressLabel, error) {
+	var label AddressLabel
+	var tags string
+	if err := row.Scan(&label.Address, &label.Label, &tags, &label.Group); err != nil {
+		return AddressLabel{}, err
+	}
+
+	if err := json.Unmarshal([]byte(tags), &label.Tags); err != nil {
+		return AddressLabel{}, fmt.Errorf("failed to parse tags of address %q: %w", label.Address, err)
+	}
+	if label.Tags == nil {
+		label.Tags = []string{}
+	}
+
+	return label, nil
+}
+
+func marshalTags(tags []string) (string, error) {
+	if tags == nil {
+		tags = []string{}
+	}
+
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize tags: %w", err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,178 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	logger "github.com/massalabs/station/pkg/logger"
+)
+
+// AddressValueHistory is the value of an address at a given time, split between its liquid balance, its rolls and its deferred credits
+type AddressValueHistory struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Address   string             `json:"address"`
+	WatchOnly bool               `json:"watch_only"`
+	Balance   massaAmount.Amount `json:"balance"`    // final balance
+	RollValue massaAmount.Amount `json:"roll_value"` // final rolls times the roll price
+	Rolls     uint64             `json:"rolls"`      // final rolls
+	Deferred  massaAmount.Amount `json:"deferred"`   // sum of the deferred credits
+}
+
+// ValueBreakdownHistory is the value of several addresses at a given time, summed by part
+type ValueBreakdownHistory struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Balance   massaAmount.Amount `json:"balance"`
+	RollValue massaAmount.Amount `json:"roll_value"`
+	Deferred  massaAmount.Amount `json:"deferred"`
+}
+
+// AddressHistoryFilter selects the addresses whose value history is summed
+type AddressHistoryFilter struct {
+	Addresses []string // the addresses to sum, nil for all the staking or watch-only addresses
+	WatchOnly bool     // when Addresses is nil, sum the watch-only addresses instead of the staking addresses
+}
+
+/*
+PostAddressHistory adds the value history records of several addresses and adds them to the buckets of the address rollup tables,
+in a single transaction. Records are expected in chronological order: the last value added to a bucket becomes its close value.
+*/
+func (d *dB) PostAddressHistory(histories []AddressValueHistory, network utils.Network) (err error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logger.Errorf("Failed to rollback address value history insertion: %v", rbErr)
+			}
+		}
+	}()
+
+	query := `INSERT INTO address_value_history (timestamp, network, address, watch_only, balance, roll_value, rolls, deferred) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	for _, history := range histories {
+		if _, err = tx.Exec(query, history.Timestamp, string(network), history.Address, history.WatchOnly, history.Balance, history.RollValue, history.Rolls, history.Deferred); err != nil {
+			return fmt.Errorf("failed to insert address_value_history of address %q: %w", history.Address, err)
+		}
+
+		if err = addToAddressRollups(tx, history, network); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit address_value_history: %w", err)
+	}
+
+	return nil
+}
+
+// GetAddressHistory retrieves the value history of the selected addresses after a given timestamp for a specific network, summed by timestamp and ordered chronologically
+func (d *dB) GetAddressHistory(filter AddressHistoryFilter, since time.Time, network utils.Network) ([]ValueBreakdownHistory, error) {
+	if filter.Addresses != nil && len(filter.Addresses) == 0 {
+		return nil, nil
+	}
+
+	filterClause, filterArgs := addressFilterClause(filter)
+	query := `SELECT timestamp, SUM(balance), SUM(roll_value), SUM(deferred) FROM address_value_history WHERE network = ? AND timestamp > ?` +
+		filterClause + ` GROUP BY timestamp ORDER BY timestamp ASC`
+	args := append([]any{string(network), since}, filterArgs...)
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query address_value_history: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close address_value_history rows: %v", err)
+		}
+	}()
+
+	var histories []ValueBreakdownHistory
+	for rows.Next() {
+		var history ValueBreakdownHistory
+		if err := rows.Scan(&history.Timestamp, &history.Balance, &history.RollValue, &history.Deferred); err != nil {
+			return nil, fmt.Errorf("failed to scan address_value_history row: %w", err)
+		}
+		histories = append(histories, history)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over address_value_history rows: %w", err)
+	}
+
+	return histories, nil
+}
+
+/*
+GetAddressValueHistory retrieves the value history records of the selected addresses between two timestamps (both included)
+for a specific network, without summing them, ordered by address then chronologically
+*/
+func (d *dB) GetAddressValueHistory(filter AddressHistoryFilter, since, until time.Time, network utils.Network) ([]AddressValueHistory, error) {
+	if filter.Addresses != nil && len(filter.Addresses) == 0 {
+		return nil, nil
+	}
+
+	filterClause, filterArgs := addressFilterClause(filter)
+	query := `SELECT timestamp, address, watch_only, balance, roll_value, rolls, deferred FROM address_value_history
+		WHERE network = ? AND timestamp >= ? AND timestamp <= ?` + filterClause + ` ORDER BY address ASC, timestamp ASC`
+	args := append([]any{string(network), since, until}, filterArgs...)
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query address_value_history: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close address_value_history rows: %v", err)
+		}
+	}()
+
+	var histories []AddressValueHistory
+	for rows.Next() {
+		var history AddressValueHistory
+		if err := rows.Scan(&history.Timestamp, &history.Address, &history.WatchOnly, &history.Balance, &history.RollValue, &history.Rolls, &history.Deferred); err != nil {
+			return nil, fmt.Errorf("failed to scan address_value_history row: %w", err)
+		}
+		histories = append(histories, history)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over address_value_history rows: %w", err)
+	}
+
+	return histories, nil
+}
+
+/*
+addRollsColumn records the final roll count in the address value history. The roll count of the existing records
+is derived from their roll value at 100 MAS a roll, the roll price the previous versions of the plugin assumed.
+*/
+func addRollsColumn(tx *sql.Tx) error {
+	if _, err := tx.Exec(`ALTER TABLE address_value_history ADD COLUMN rolls INTEGER NOT NULL DEFAULT 0`); err != nil {
+		return fmt.Errorf("failed to add rolls column to address_value_history: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE address_value_history SET rolls = roll_value / ?`, 100*int64(massaAmount.MAS)); err != nil {
+		return fmt.Errorf("failed to set rolls of address_value_history: %w", err)
+	}
+
+	return nil
+}
+
+// addressFilterClause returns the condition selecting the addresses of the filter in the address_value_history table, with its arguments
+func addressFilterClause(filter AddressHistoryFilter) (string, []any) {
+	if filter.Addresses == nil {
+		return ` AND watch_only = ?`, []any{filter.WatchOnly}
+	}
+
+	args := make([]any, len(filter.Addresses))
+	for i, address := range filter.Addresses {
+		args[i] = address
+	}
+	return ` AND address IN (?` + strings.Repeat(`, ?`, len(filter.Addresses)-1) + `)`, args
+}
@@ -0,0 +1,227 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	logger "github.com/massalabs/station/pkg/logger"
+)
+
+/*
+AddressValueRollup summarizes the values of the selected addresses recorded during a bucket, summed over the addresses.
+The breakdown is the one of the close value. The min and max of the addresses are summed too, they bound the lowest and
+highest total values of the bucket.
+*/
+type AddressValueRollup struct {
+	ValueRollup
+	Balance   massaAmount.Amount `json:"balance"`
+	RollValue massaAmount.Amount `json:"roll_value"`
+	Deferred  massaAmount.Amount `json:"deferred"`
+}
+
+func (r RollupResolution) addressTable() string {
+	if r == RollupDaily {
+		return "address_value_history_daily"
+	}
+	return "address_value_history_hourly"
+}
+
+/*
+createAddressValueRollups creates the rollup tables of the address value history, filled from the address_value_history
+table when the database is opened
+*/
+func createAddressValueRollups(tx *sql.Tx) error {
+	for _, resolution := range rollupResolutions {
+		table := fmt.Sprintf(`
+		CREATE TABLE %s (
+			network TEXT NOT NULL,
+			address TEXT NOT NULL,
+			watch_only BOOLEAN NOT NULL,
+			bucket_start DATETIME NOT NULL,
+			open INTEGER NOT NULL,
+			close INTEGER NOT NULL,
+			min INTEGER NOT NULL,
+			max INTEGER NOT NULL,
+			avg INTEGER NOT NULL,
+			count INTEGER NOT NULL,
+			balance INTEGER NOT NULL,
+			roll_value INTEGER NOT NULL,
+			deferred INTEGER NOT NULL,
+			PRIMARY KEY (network, address, bucket_start)
+		);`, resolution.addressTable())
+
+		// Index used to sum the buckets of all the staking or watch-only addresses
+		index := fmt.Sprintf(`
+		CREATE INDEX idx_%s_bucket ON %s (network, bucket_start);`, resolution.addressTable(), resolution.addressTable())
+
+		if _, err := tx.Exec(table); err != nil {
+			return fmt.Errorf("failed to create %s table: %w", resolution.addressTable(), err)
+		}
+
+		if _, err := tx.Exec(index); err != nil {
+			return fmt.Errorf("failed to create %s index: %w", resolution.addressTable(), err)
+		}
+	}
+
+	return nil
+}
+
+/*
+GetAddressValueRollups retrieves the buckets of the value history of the selected addresses starting from a given timestamp
+for a specific network, summed by bucket and ordered chronologically. The count of a bucket is the highest count of its addresses.
+*/
+func (d *dB) GetAddressValueRollups(filter AddressHistoryFilter, resolution RollupResolution, since time.Time, network utils.Network) ([]AddressValueRollup, error) {
+	if filter.Addresses != nil && len(filter.Addresses) == 0 {
+		return nil, nil
+	}
+
+	filterClause, filterArgs := addressFilterClause(filter)
+	query := fmt.Sprintf(`SELECT bucket_start, SUM(open), SUM(close), SUM(min), SUM(max), SUM(avg), MAX(count), SUM(balance), SUM(roll_value), SUM(deferred)
+		FROM %s WHERE network = ? AND bucket_start >= ?`, resolution.addressTable()) + filterClause + ` GROUP BY bucket_start ORDER BY bucket_start ASC`
+	args := append([]any{string(network), since.UTC()}, filterArgs...)
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", resolution.addressTable(), err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close %s rows: %v", resolution.addressTable(), err)
+		}
+	}()
+
+	var rollups []AddressValueRollup
+	for rows.Next() {
+		var rollup AddressValueRollup
+		if err := rows.Scan(&rollup.BucketStart, &rollup.Open, &rollup.Close, &rollup.Min, &rollup.Max, &rollup.Avg, &rollup.Count,
+			&rollup.Balance, &rollup.RollValue, &rollup.Deferred); err != nil {
+			return nil, fmt.Errorf("failed to scan %s row: %w", resolution.addressTable(), err)
+		}
+		rollups = append(rollups, rollup)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over %s rows: %w", resolution.addressTable(), err)
+	}
+
+	return rollups, nil
+}
+
+// addToAddressRollups adds a value of an address to the buckets containing it in every address rollup table
+func addToAddressRollups(tx *sql.Tx, history AddressValueHistory, network utils.Network) error {
+	for _, resolution := range rollupResolutions {
+		query := fmt.Sprintf(`INSERT INTO %s (network, address, watch_only, bucket_start, open, close, min, max, avg, count, balance, roll_value, deferred)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 1, ?, ?, ?)
+			ON CONFLICT (network, address, bucket_start) DO UPDATE SET
+				watch_only = excluded.watch_only,
+				close = excluded.close,
+				min = MIN(min, excluded.min),
+				max = MAX(max, excluded.max),
+				avg = CAST(ROUND((avg * 1.0 * count + excluded.avg) / (count + 1)) AS INTEGER),
+				count = count + 1,
+				balance = excluded.balance,
+				roll_value = excluded.roll_value,
+				deferred = excluded.deferred`, resolution.addressTable())
+
+		value := history.Balance + history.RollValue + history.Deferred
+		bucketStart := resolution.BucketStart(history.Timestamp)
+		if _, err := tx.Exec(query, string(network), history.Address, history.WatchOnly, bucketStart, value, value, value, value, value,
+			history.Balance, history.RollValue, history.Deferred); err != nil {
+			return fmt.Errorf("failed to update %s of address %q: %w", resolution.addressTable(), history.Address, err)
+		}
+	}
+
+	return nil
+}
+
+/*
+backfillAddressValueRollups builds the rollups of the addresses that have raw values but no rollup yet,
+which happens the first time the plugin runs with the address rollup tables
+*/
+func (d *dB) backfillAddressValueRollups() error {
+	pending, err := d.addressesWithoutRollups()
+	if err != nil {
+		return err
+	}
+
+	for _, addressNetwork := range pending {
+		filter := AddressHistoryFilter{Addresses: []string{addressNetwork.address}}
+		histories, err := d.GetAddressValueHistory(filter, time.Time{}, time.Now(), addressNetwork.network)
+		if err != nil {
+			return err
+		}
+
+		if err := d.addAddressHistoriesToRollups(histories, addressNetwork.network); err != nil {
+			return err
+		}
+
+		logger.Infof("Value history rollups of address %s (%s) built from %d values", addressNetwork.address, string(addressNetwork.network), len(histories))
+	}
+
+	return nil
+}
+
+type addressNetwork struct {
+	address string
+	network utils.Network
+}
+
+// addressesWithoutRollups returns the addresses and networks having values in the address_value_history table but no hourly rollup
+func (d *dB) addressesWithoutRollups() ([]addressNetwork, error) {
+	query := fmt.Sprintf(`SELECT DISTINCT network, address FROM address_value_history v
+		WHERE NOT EXISTS (SELECT 1 FROM %s r WHERE r.network = v.network AND r.address = v.address)`, RollupHourly.addressTable())
+
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query addresses without rollups: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close addresses without rollups rows: %v", err)
+		}
+	}()
+
+	var pending []addressNetwork
+	for rows.Next() {
+		var network, address string
+		if err := rows.Scan(&network, &address); err != nil {
+			return nil, fmt.Errorf("failed to scan addresses without rollups row: %w", err)
+		}
+		pending = append(pending, addressNetwork{address: address, network: utils.Network(network)})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over addresses without rollups rows: %w", err)
+	}
+
+	return pending, nil
+}
+
+func (d *dB) addAddressHistoriesToRollups(histories []AddressValueHistory, network utils.Network) (err error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logger.Errorf("Failed to rollback address value history rollups backfill: %v", rbErr)
+			}
+		}
+	}()
+
+	for _, history := range histories {
+		if err = addToAddressRollups(tx, history, network); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit address value history rollups backfill: %w", err)
+	}
+
+	return nil
+}
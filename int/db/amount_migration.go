@@ -0,0 +1,107 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	logger "github.com/massalabs/station/pkg/logger"
+)
+
+// amountColumns lists the columns holding amounts of MAS, stored in nanoMAS in INTEGER columns
+var amountColumns = []struct {
+	table   string
+	columns []string
+}{
+	{"value_history_mainnet", []string{"total_value"}},
+	{"value_history_buildnet", []string{"total_value"}},
+	{"watch_only_value_history", []string{"total_value"}},
+	{"sweep_rules", []string{"threshold", "reserve"}},
+	{"sweep_history", []string{"amount", "fee"}},
+	{"address_value_history", []string{"balance", "roll_value", "deferred"}},
+	{"value_history_hourly", []string{"open", "close", "min", "max", "avg"}},
+	{"value_history_daily", []string{"open", "close", "min", "max", "avg"}},
+	{"ledger_entries", []string{"amount"}},
+}
+
+/*
+migrateAmountColumns converts the amount columns created as REAL columns of MAS by the previous versions of the plugin
+into INTEGER columns of nanoMAS. Tables already created with INTEGER columns are left untouched.
+*/
+func migrateAmountColumns(tx *sql.Tx) error {
+	for _, amountTable := range amountColumns {
+		realColumns, err := realColumns(tx, amountTable.table)
+		if err != nil {
+			return err
+		}
+
+		var columns []string
+		for _, column := range amountTable.columns {
+			if slices.Contains(realColumns, column) {
+				columns = append(columns, column)
+			}
+		}
+		if len(columns) == 0 {
+			continue
+		}
+
+		if err := convertToNanoMAS(tx, amountTable.table, columns); err != nil {
+			return err
+		}
+		logger.Infof("Amount columns %v of table %s converted to nanoMAS", columns, amountTable.table)
+	}
+
+	return nil
+}
+
+// realColumns returns the columns of a table declared as REAL
+func realColumns(tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.Query(`SELECT name FROM pragma_table_info(?) WHERE type = 'REAL'`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns of %s: %w", table, err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close columns of %s rows: %v", table, err)
+		}
+	}()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, fmt.Errorf("failed to scan column of %s: %w", table, err)
+		}
+		columns = append(columns, column)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over columns of %s: %w", table, err)
+	}
+
+	return columns, nil
+}
+
+/*
+convertToNanoMAS replaces REAL columns of MAS by INTEGER columns of nanoMAS holding the same amounts rounded to the nanoMAS.
+The converted columns are moved to the end of the table, the queries name their columns.
+*/
+func convertToNanoMAS(tx *sql.Tx, table string, columns []string) error {
+	for _, column := range columns {
+		converted := column + "_nano"
+		statements := []string{
+			fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s INTEGER NOT NULL DEFAULT 0`, table, converted),
+			fmt.Sprintf(`UPDATE %s SET %s = CAST(ROUND(%s * %d) AS INTEGER)`, table, converted, column, int64(massaAmount.MAS)),
+			fmt.Sprintf(`ALTER TABLE %s DROP COLUMN %s`, table, column),
+			fmt.Sprintf(`ALTER TABLE %s RENAME COLUMN %s TO %s`, table, converted, column),
+		}
+		for _, statement := range statements {
+			if _, err := tx.Exec(statement); err != nil {
+				return fmt.Errorf("failed to convert %s.%s to nanoMAS: %w", table, column, err)
+			}
+		}
+	}
+
+	return nil
+}
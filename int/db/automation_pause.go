@@ -0,0 +1,82 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	logger "github.com/massalabs/station/pkg/logger"
+)
+
+// GlobalPauseAddress is the address of the pause holding the automation of all the staking addresses of a network
+const GlobalPauseAddress = ""
+
+// AutomationPause holds the roll and sweep automation of a staking address, or of all of them if Address is GlobalPauseAddress
+type AutomationPause struct {
+	Address  string    `json:"address"`
+	PausedAt time.Time `json:"paused_at"`
+}
+
+// AddAutomationPause pauses the automation of an address, nothing is done if it is already paused
+func (d *dB) AddAutomationPause(address string, network utils.Network) error {
+	query := `INSERT INTO automation_pauses (address, network, paused_at) VALUES (?, ?, ?) ON CONFLICT (address, network) DO NOTHING`
+
+	_, err := d.db.Exec(query, address, string(network), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to add automation pause of address %q: %w", address, err)
+	}
+
+	return nil
+}
+
+// DeleteAutomationPause resumes the automation of an address
+func (d *dB) DeleteAutomationPause(address string, network utils.Network) error {
+	query := `DELETE FROM automation_pauses WHERE address = ? AND network = ?`
+
+	result, err := d.db.Exec(query, address, string(network))
+	if err != nil {
+		return fmt.Errorf("failed to delete automation pause of address %q: %w", address, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, fmt.Sprintf("automation pause of address %q (%s) not found in database", address, string(network)))
+	}
+
+	return nil
+}
+
+// GetAutomationPauses retrieves the automation pauses of a network, including the global one
+func (d *dB) GetAutomationPauses(network utils.Network) ([]AutomationPause, error) {
+	query := `SELECT address, paused_at FROM automation_pauses WHERE network = ? ORDER BY address`
+
+	rows, err := d.db.Query(query, string(network))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automation pauses: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close automation pauses rows: %v", err)
+		}
+	}()
+
+	var pauses []AutomationPause
+	for rows.Next() {
+		var pause AutomationPause
+		if err := rows.Scan(&pause.Address, &pause.PausedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan automation pauses row: %w", err)
+		}
+		pauses = append(pauses, pause)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over automation pauses rows: %w", err)
+	}
+
+	return pauses, nil
+}
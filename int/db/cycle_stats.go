@@ -0,0 +1,67 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	logger "github.com/massalabs/station/pkg/logger"
+)
+
+// CycleStats holds the block production statistics of an address for a given cycle
+type CycleStats struct {
+	Address     string `json:"address"`
+	Cycle       uint64 `json:"cycle"`
+	OkCount     uint64 `json:"ok_count"`
+	NokCount    uint64 `json:"nok_count"`
+	ActiveRolls uint64 `json:"active_rolls"`
+	IsFinal     bool   `json:"is_final"`
+}
+
+// UpsertCycleStats adds the cycle stats of an address or updates them if the cycle is already registered
+func (d *dB) UpsertCycleStats(stats CycleStats, network utils.Network) error {
+	query := `
+	INSERT INTO cycle_stats (address, network, cycle, ok_count, nok_count, active_rolls, is_final)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (address, network, cycle) DO UPDATE SET
+		ok_count = excluded.ok_count,
+		nok_count = excluded.nok_count,
+		active_rolls = excluded.active_rolls,
+		is_final = excluded.is_final`
+
+	_, err := d.db.Exec(query, stats.Address, string(network), stats.Cycle, stats.OkCount, stats.NokCount, stats.ActiveRolls, stats.IsFinal)
+	if err != nil {
+		return fmt.Errorf("failed to upsert cycle stats of address %s for cycle %d: %w", stats.Address, stats.Cycle, err)
+	}
+
+	return nil
+}
+
+// GetCycleStats retrieves all cycle stats records for a specific address and network, newest cycle first
+func (d *dB) GetCycleStats(address string, network utils.Network) ([]CycleStats, error) {
+	query := `SELECT address, cycle, ok_count, nok_count, active_rolls, is_final FROM cycle_stats WHERE address = ? AND network = ? ORDER BY cycle DESC`
+
+	rows, err := d.db.Query(query, address, string(network))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cycle stats: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close cycle stats rows: %v", err)
+		}
+	}()
+
+	var stats []CycleStats
+	for rows.Next() {
+		var s CycleStats
+		if err := rows.Scan(&s.Address, &s.Cycle, &s.OkCount, &s.NokCount, &s.ActiveRolls, &s.IsFinal); err != nil {
+			return nil, fmt.Errorf("failed to scan cycle stats row: %w", err)
+		}
+		stats = append(stats, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over cycle stats rows: %w", err)
+	}
+
+	return stats, nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
 	"github.com/massalabs/node-manager-plugin/int/utils"
 	logger "github.com/massalabs/station/pkg/logger"
 	_ "github.com/ncruces/go-sqlite3/driver"
@@ -23,16 +24,69 @@ type DB interface {
 	DeleteOldValueHistory(cutoff time.Time) error
 	AddRollOpHistory(address string, op RollOp, amount uint64, opId string, network utils.Network) error
 	GetRollOpHistory(address string, network utils.Network) ([]RollOpHistory, error)
-	DeleteRollOpHistoryByAddress(address string) error
+	DeleteRollOpHistoryByAddress(address string, network utils.Network) error
+	UpsertCycleStats(stats CycleStats, network utils.Network) error
+	GetCycleStats(address string, network utils.Network) ([]CycleStats, error)
+	AddDecommission(address string, network utils.Network) error
+	UpdateDecommissionStep(address string, step DecommissionStep, network utils.Network) error
+	GetDecommission(address string, network utils.Network) (Decommission, error)
+	GetDecommissions(network utils.Network) ([]Decommission, error)
+	DeleteDecommission(address string, network utils.Network) error
+	ArchiveRollOpHistoryByAddress(address string, network utils.Network) error
+	GetArchivedRollOpHistory(address string, network utils.Network) ([]RollOpHistory, error)
+	SetSweepRule(rule SweepRule, network utils.Network) error
+	GetSweepRule(address string, network utils.Network) (SweepRule, error)
+	GetSweepRules(network utils.Network) ([]SweepRule, error)
+	DeleteSweepRule(address string, network utils.Network) error
+	AddSweepHistory(sweep SweepHistory, network utils.Network) error
+	GetSweepHistory(address string, network utils.Network) ([]SweepHistory, error)
+	AddRollSchedule(schedule RollSchedule, network utils.Network) (int64, error)
+	UpdateRollSchedule(schedule RollSchedule, network utils.Network) error
+	SetRollScheduleStatus(id int64, status RollScheduleStatus, errMsg string, network utils.Network) error
+	DeleteRollSchedule(id int64, network utils.Network) error
+	GetRollSchedule(id int64, network utils.Network) (RollSchedule, error)
+	GetRollSchedules(network utils.Network) ([]RollSchedule, error)
+	AddBlockedRollOp(blocked BlockedRollOp, network utils.Network) error
+	GetBlockedRollOps(address string, network utils.Network) ([]BlockedRollOp, error)
+	GetRollsBoughtSince(since time.Time, network utils.Network) (uint64, error)
+	AddAutomationPause(address string, network utils.Network) error
+	DeleteAutomationPause(address string, network utils.Network) error
+	GetAutomationPauses(network utils.Network) ([]AutomationPause, error)
+	AddWatchOnlyAddress(address string, network utils.Network) error
+	DeleteWatchOnlyAddress(address string, network utils.Network) error
+	GetWatchOnlyAddresses(network utils.Network) ([]WatchOnlyAddress, error)
+	PostWatchOnlyHistory(history ValueHistory, network utils.Network) error
+	GetWatchOnlyHistory(since time.Time, network utils.Network) ([]ValueHistory, error)
+	SetAddressLabel(label AddressLabel, network utils.Network) error
+	InitAddressLabel(address string, label string, network utils.Network) error
+	GetAddressLabel(address string, network utils.Network) (AddressLabel, error)
+	GetAddressLabels(network utils.Network) ([]AddressLabel, error)
+	DeleteAddressLabel(address string, network utils.Network) error
+	PostAddressHistory(histories []AddressValueHistory, network utils.Network) error
+	GetAddressHistory(filter AddressHistoryFilter, since time.Time, network utils.Network) ([]ValueBreakdownHistory, error)
+	GetAddressValueHistory(filter AddressHistoryFilter, since, until time.Time, network utils.Network) ([]AddressValueHistory, error)
+	GetValueRollups(series ValueSeries, resolution RollupResolution, since time.Time, network utils.Network) ([]ValueRollup, error)
+	GetAddressValueRollups(filter AddressHistoryFilter, resolution RollupResolution, since time.Time, network utils.Network) ([]AddressValueRollup, error)
+	AddNodeStatusHistory(status string, network utils.Network) error
+	GetNodeStatusHistory(since time.Time, network utils.Network) ([]NodeStatusHistory, error)
+	AddAddressEvent(address string, event AddressEventKind, watchOnly bool, network utils.Network) error
+	GetAddressEvents(since time.Time, network utils.Network) ([]AddressEvent, error)
+	GetRollOpHistorySince(since time.Time, network utils.Network) ([]AddressRollOpHistory, error)
+	GetSweepHistorySince(since time.Time, network utils.Network) ([]SweepHistory, error)
+	AddLedgerEntries(entries []LedgerEntry, network utils.Network) error
+	GetLedgerEntries(address string, since time.Time, network utils.Network) ([]LedgerEntry, error)
+	GetPrice(day time.Time, currency string) (float64, error)
+	SetPrice(day time.Time, currency string, price float64) error
 }
 
 type dB struct {
-	db *sql.DB
+	db   *sql.DB
+	path string
 }
 
 type ValueHistory struct {
-	Timestamp  time.Time `json:"timestamp"`
-	TotalValue float64   `json:"total_value"`
+	Timestamp  time.Time          `json:"timestamp"`
+	TotalValue massaAmount.Amount `json:"total_value"`
 }
 
 type AddressInfo struct {
@@ -55,7 +109,7 @@ const (
 	RollOpSell RollOp = "SELL"
 )
 
-// NewDB creates a new database connection and initializes tables
+// NewDB creates a new database connection and migrates its schema to the latest version
 func NewDB(dbPath string) (DB, error) {
 	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
@@ -66,10 +120,18 @@ func NewDB(dbPath string) (DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	database := &dB{db: db}
+	database := &dB{db: db, path: dbPath}
 
-	if err := database.initTables(); err != nil {
-		return nil, fmt.Errorf("failed to initialize tables: %w", err)
+	if err := database.migrate(migrations); err != nil {
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	if err := database.backfillValueRollups(); err != nil {
+		return nil, fmt.Errorf("failed to build value history rollups: %w", err)
+	}
+
+	if err := database.backfillAddressValueRollups(); err != nil {
+		return nil, fmt.Errorf("failed to build address value history rollups: %w", err)
 	}
 
 	return database, nil
@@ -80,20 +142,23 @@ func (d *dB) Close() error {
 	return d.db.Close()
 }
 
-// initTables creates the required tables if they don't exist
-func (d *dB) initTables() error {
+/*
+createTables creates the tables of the schema as it was when versioned migrations were introduced, if they don't exist,
+so that the databases created before keep their data. Later schema changes are new migrations.
+*/
+func createTables(tx *sql.Tx) error {
 	// Create value_history_mainnet table
 	valueHistoryMainnetTable := `
 	CREATE TABLE IF NOT EXISTS value_history_mainnet (
 		timestamp DATETIME PRIMARY KEY,
-		total_value REAL NOT NULL
+		total_value INTEGER NOT NULL
 	);`
 
 	// Create value_history_buildnet table
 	valueHistoryBuildnetTable := `
 	CREATE TABLE IF NOT EXISTS value_history_buildnet (
 		timestamp DATETIME PRIMARY KEY,
-		total_value REAL NOT NULL
+		total_value INTEGER NOT NULL
 	);`
 
 	// Create rolls_target table
@@ -117,22 +182,324 @@ func (d *dB) initTables() error {
 		PRIMARY KEY (op_id, network)
 	);`
 
-	if _, err := d.db.Exec(valueHistoryMainnetTable); err != nil {
+	// Create cycle_stats table
+	cycleStatsTable := `
+	CREATE TABLE IF NOT EXISTS cycle_stats (
+		address TEXT NOT NULL,
+		network TEXT NOT NULL,
+		cycle INTEGER NOT NULL,
+		ok_count INTEGER NOT NULL,
+		nok_count INTEGER NOT NULL,
+		active_rolls INTEGER NOT NULL,
+		is_final BOOLEAN NOT NULL,
+		PRIMARY KEY (address, network, cycle)
+	);`
+
+	// Create decommissions table
+	decommissionsTable := `
+	CREATE TABLE IF NOT EXISTS decommissions (
+		address TEXT NOT NULL,
+		network TEXT NOT NULL,
+		step TEXT NOT NULL,
+		started_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (address, network)
+	);`
+
+	// Create rolls_op_history_archive table
+	rollsOpHistoryArchiveTable := `
+	CREATE TABLE IF NOT EXISTS rolls_op_history_archive (
+		address TEXT,
+		op TEXT NOT NULL,
+		amount INTEGER NOT NULL,
+		network TEXT NOT NULL,
+		op_id TEXT NOT NULL,
+		timestamp DATETIME NOT NULL,
+		archived_at DATETIME NOT NULL,
+		PRIMARY KEY (op_id, network)
+	);`
+
+	// Create sweep_rules table
+	sweepRulesTable := `
+	CREATE TABLE IF NOT EXISTS sweep_rules (
+		address TEXT NOT NULL,
+		network TEXT NOT NULL,
+		cold_address TEXT NOT NULL,
+		threshold INTEGER NOT NULL,
+		reserve INTEGER NOT NULL,
+		dry_run BOOLEAN NOT NULL,
+		PRIMARY KEY (address, network)
+	);`
+
+	// Create sweep_history table
+	sweepHistoryTable := `
+	CREATE TABLE IF NOT EXISTS sweep_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		address TEXT NOT NULL,
+		network TEXT NOT NULL,
+		cold_address TEXT NOT NULL,
+		amount INTEGER NOT NULL,
+		fee INTEGER NOT NULL,
+		op_id TEXT NOT NULL,
+		dry_run BOOLEAN NOT NULL,
+		timestamp DATETIME NOT NULL
+	);`
+
+	// Create roll_schedules table
+	rollSchedulesTable := `
+	CREATE TABLE IF NOT EXISTS roll_schedules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		address TEXT NOT NULL,
+		network TEXT NOT NULL,
+		action TEXT NOT NULL,
+		amount INTEGER NOT NULL,
+		run_at DATETIME,
+		cycle INTEGER,
+		status TEXT NOT NULL,
+		error TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		executed_at DATETIME
+	);`
+
+	// Create blocked_roll_ops table
+	blockedRollOpsTable := `
+	CREATE TABLE IF NOT EXISTS blocked_roll_ops (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		address TEXT NOT NULL,
+		network TEXT NOT NULL,
+		op TEXT NOT NULL,
+		amount INTEGER NOT NULL,
+		reason TEXT NOT NULL,
+		timestamp DATETIME NOT NULL
+	);`
+
+	// Create automation_pauses table
+	automationPausesTable := `
+	CREATE TABLE IF NOT EXISTS automation_pauses (
+		address TEXT NOT NULL,
+		network TEXT NOT NULL,
+		paused_at DATETIME NOT NULL,
+		PRIMARY KEY (address, network)
+	);`
+
+	// Create watch_only_addresses table
+	watchOnlyAddressesTable := `
+	CREATE TABLE IF NOT EXISTS watch_only_addresses (
+		address TEXT NOT NULL,
+		network TEXT NOT NULL,
+		added_at DATETIME NOT NULL,
+		PRIMARY KEY (address, network)
+	);`
+
+	// Create watch_only_value_history table
+	watchOnlyValueHistoryTable := `
+	CREATE TABLE IF NOT EXISTS watch_only_value_history (
+		timestamp DATETIME NOT NULL,
+		network TEXT NOT NULL,
+		total_value INTEGER NOT NULL,
+		PRIMARY KEY (timestamp, network)
+	);`
+
+	// Create address_labels table
+	addressLabelsTable := `
+	CREATE TABLE IF NOT EXISTS address_labels (
+		address TEXT NOT NULL,
+		network TEXT NOT NULL,
+		label TEXT NOT NULL,
+		tags TEXT NOT NULL,
+		group_name TEXT NOT NULL,
+		PRIMARY KEY (address, network)
+	);`
+
+	// Create address_value_history table
+	addressValueHistoryTable := `
+	CREATE TABLE IF NOT EXISTS address_value_history (
+		timestamp DATETIME NOT NULL,
+		network TEXT NOT NULL,
+		address TEXT NOT NULL,
+		watch_only BOOLEAN NOT NULL,
+		balance INTEGER NOT NULL,
+		roll_value INTEGER NOT NULL,
+		deferred INTEGER NOT NULL,
+		PRIMARY KEY (timestamp, network, address)
+	);`
+
+	// Create address_value_history index, used to sum the values of some addresses
+	addressValueHistoryIndex := `
+	CREATE INDEX IF NOT EXISTS idx_address_value_history_address ON address_value_history (network, address, timestamp);`
+
+	// Create value_history_hourly table, hourly buckets of the total value series
+	valueHistoryHourlyTable := `
+	CREATE TABLE IF NOT EXISTS value_history_hourly (
+		series TEXT NOT NULL,
+		network TEXT NOT NULL,
+		bucket_start DATETIME NOT NULL,
+		open INTEGER NOT NULL,
+		close INTEGER NOT NULL,
+		min INTEGER NOT NULL,
+		max INTEGER NOT NULL,
+		avg INTEGER NOT NULL,
+		count INTEGER NOT NULL,
+		PRIMARY KEY (series, network, bucket_start)
+	);`
+
+	// Create value_history_daily table, daily buckets of the total value series
+	valueHistoryDailyTable := `
+	CREATE TABLE IF NOT EXISTS value_history_daily (
+		series TEXT NOT NULL,
+		network TEXT NOT NULL,
+		bucket_start DATETIME NOT NULL,
+		open INTEGER NOT NULL,
+		close INTEGER NOT NULL,
+		min INTEGER NOT NULL,
+		max INTEGER NOT NULL,
+		avg INTEGER NOT NULL,
+		count INTEGER NOT NULL,
+		PRIMARY KEY (series, network, bucket_start)
+	);`
+
+	// Create node_status_history table
+	nodeStatusHistoryTable := `
+	CREATE TABLE IF NOT EXISTS node_status_history (
+		timestamp DATETIME NOT NULL,
+		status TEXT NOT NULL,
+		network TEXT NOT NULL
+	);`
+
+	// Create address_events table
+	addressEventsTable := `
+	CREATE TABLE IF NOT EXISTS address_events (
+		timestamp DATETIME NOT NULL,
+		network TEXT NOT NULL,
+		address TEXT NOT NULL,
+		event TEXT NOT NULL,
+		watch_only BOOLEAN NOT NULL
+	);`
+
+	// Create ledger_entries table
+	ledgerEntriesTable := `
+	CREATE TABLE IF NOT EXISTS ledger_entries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		timestamp DATETIME NOT NULL,
+		network TEXT NOT NULL,
+		address TEXT NOT NULL,
+		cycle INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		amount INTEGER NOT NULL,
+		rolls INTEGER NOT NULL,
+		op_id TEXT NOT NULL
+	);`
+
+	// Create ledger_entries index, used to retrieve the entries of an address
+	ledgerEntriesIndex := `
+	CREATE INDEX IF NOT EXISTS idx_ledger_entries_address ON ledger_entries (network, address, timestamp);`
+
+	// Create price_cache table, prices of a MAS in fiat currencies by UTC day
+	priceCacheTable := `
+	CREATE TABLE IF NOT EXISTS price_cache (
+		day TEXT NOT NULL,
+		currency TEXT NOT NULL,
+		price REAL NOT NULL,
+		fetched_at DATETIME NOT NULL,
+		PRIMARY KEY (day, currency)
+	);`
+
+	if _, err := tx.Exec(valueHistoryMainnetTable); err != nil {
 		return fmt.Errorf("failed to create value_history_mainnet table: %w", err)
 	}
 
-	if _, err := d.db.Exec(valueHistoryBuildnetTable); err != nil {
+	if _, err := tx.Exec(valueHistoryBuildnetTable); err != nil {
 		return fmt.Errorf("failed to create value_history_buildnet table: %w", err)
 	}
 
-	if _, err := d.db.Exec(rollsTargetTable); err != nil {
+	if _, err := tx.Exec(rollsTargetTable); err != nil {
 		return fmt.Errorf("failed to create rolls_target table: %w", err)
 	}
 
-	if _, err := d.db.Exec(rollsOpHistoryTable); err != nil {
+	if _, err := tx.Exec(rollsOpHistoryTable); err != nil {
 		return fmt.Errorf("failed to create rolls_op_history table: %w", err)
 	}
 
+	if _, err := tx.Exec(cycleStatsTable); err != nil {
+		return fmt.Errorf("failed to create cycle_stats table: %w", err)
+	}
+
+	if _, err := tx.Exec(decommissionsTable); err != nil {
+		return fmt.Errorf("failed to create decommissions table: %w", err)
+	}
+
+	if _, err := tx.Exec(rollsOpHistoryArchiveTable); err != nil {
+		return fmt.Errorf("failed to create rolls_op_history_archive table: %w", err)
+	}
+
+	if _, err := tx.Exec(sweepRulesTable); err != nil {
+		return fmt.Errorf("failed to create sweep_rules table: %w", err)
+	}
+
+	if _, err := tx.Exec(sweepHistoryTable); err != nil {
+		return fmt.Errorf("failed to create sweep_history table: %w", err)
+	}
+
+	if _, err := tx.Exec(rollSchedulesTable); err != nil {
+		return fmt.Errorf("failed to create roll_schedules table: %w", err)
+	}
+
+	if _, err := tx.Exec(blockedRollOpsTable); err != nil {
+		return fmt.Errorf("failed to create blocked_roll_ops table: %w", err)
+	}
+
+	if _, err := tx.Exec(automationPausesTable); err != nil {
+		return fmt.Errorf("failed to create automation_pauses table: %w", err)
+	}
+
+	if _, err := tx.Exec(watchOnlyAddressesTable); err != nil {
+		return fmt.Errorf("failed to create watch_only_addresses table: %w", err)
+	}
+
+	if _, err := tx.Exec(watchOnlyValueHistoryTable); err != nil {
+		return fmt.Errorf("failed to create watch_only_value_history table: %w", err)
+	}
+
+	if _, err := tx.Exec(addressLabelsTable); err != nil {
+		return fmt.Errorf("failed to create address_labels table: %w", err)
+	}
+
+	if _, err := tx.Exec(addressValueHistoryTable); err != nil {
+		return fmt.Errorf("failed to create address_value_history table: %w", err)
+	}
+
+	if _, err := tx.Exec(addressValueHistoryIndex); err != nil {
+		return fmt.Errorf("failed to create address_value_history index: %w", err)
+	}
+
+	if _, err := tx.Exec(valueHistoryHourlyTable); err != nil {
+		return fmt.Errorf("failed to create value_history_hourly table: %w", err)
+	}
+
+	if _, err := tx.Exec(valueHistoryDailyTable); err != nil {
+		return fmt.Errorf("failed to create value_history_daily table: %w", err)
+	}
+
+	if _, err := tx.Exec(nodeStatusHistoryTable); err != nil {
+		return fmt.Errorf("failed to create node_status_history table: %w", err)
+	}
+
+	if _, err := tx.Exec(addressEventsTable); err != nil {
+		return fmt.Errorf("failed to create address_events table: %w", err)
+	}
+
+	if _, err := tx.Exec(ledgerEntriesTable); err != nil {
+		return fmt.Errorf("failed to create ledger_entries table: %w", err)
+	}
+
+	if _, err := tx.Exec(ledgerEntriesIndex); err != nil {
+		return fmt.Errorf("failed to create ledger_entries index: %w", err)
+	}
+
+	if _, err := tx.Exec(priceCacheTable); err != nil {
+		return fmt.Errorf("failed to create price_cache table: %w", err)
+	}
+
 	return nil
 }
 
@@ -238,23 +605,10 @@ func (d *dB) DeleteRollsTarget(address string, network utils.Network) error {
 	return nil
 }
 
-// PostHistory adds a single value history record to the appropriate network table
+// PostHistory adds a value history record of the staking addresses of a specific network
 func (d *dB) PostHistory(history ValueHistory, network utils.Network) error {
-	var tableName string
-	switch network {
-	case utils.NetworkMainnet:
-		tableName = "value_history_mainnet"
-	case utils.NetworkBuildnet:
-		tableName = "value_history_buildnet"
-	default:
-		return fmt.Errorf("unsupported network: %s", string(network))
-	}
-
-	query := fmt.Sprintf(`INSERT INTO %s (timestamp, total_value) VALUES (?, ?)`, tableName)
-
-	_, err := d.db.Exec(query, history.Timestamp, history.TotalValue)
-	if err != nil {
-		return fmt.Errorf("failed to insert %s: %w", tableName, err)
+	if err := d.postValue(ValueSeriesStaking, history, network); err != nil {
+		return fmt.Errorf("failed to insert value history: %w", err)
 	}
 
 	return nil
@@ -262,56 +616,45 @@ func (d *dB) PostHistory(history ValueHistory, network utils.Network) error {
 
 // GetHistory retrieves all value history records after a given timestamp for a specific network, ordered chronologically
 func (d *dB) GetHistory(since time.Time, network utils.Network) ([]ValueHistory, error) {
-	var tableName string
-	switch network {
-	case utils.NetworkMainnet:
-		tableName = "value_history_mainnet"
-	case utils.NetworkBuildnet:
-		tableName = "value_history_buildnet"
-	default:
-		return nil, fmt.Errorf("unsupported network: %s", string(network))
-	}
-
-	query := fmt.Sprintf(`SELECT timestamp, total_value FROM %s WHERE timestamp > ? ORDER BY timestamp ASC`, tableName)
+	return d.getValues(ValueSeriesStaking, since, network)
+}
 
-	rows, err := d.db.Query(query, since)
+// DeleteOldValueHistory deletes the history entries of every network older than a given timestamp
+func (d *dB) DeleteOldValueHistory(cutoff time.Time) error {
+	query := `DELETE FROM value_history WHERE timestamp < ?`
+	_, err := d.db.Exec(query, cutoff)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query %s: %w", tableName, err)
+		return fmt.Errorf("failed to delete old value history from value_history: %w", err)
 	}
-	defer func() {
-		if err := rows.Close(); err != nil {
-			logger.Errorf("Failed to close %s rows: %v", tableName, err)
-		}
-	}()
 
-	var histories []ValueHistory
-	for rows.Next() {
-		var history ValueHistory
-		if err := rows.Scan(&history.Timestamp, &history.TotalValue); err != nil {
-			return nil, fmt.Errorf("failed to scan %s row: %w", tableName, err)
-		}
-		histories = append(histories, history)
+	query = `DELETE FROM address_value_history WHERE timestamp < ?`
+	_, err = d.db.Exec(query, cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to delete old value history from address_value_history: %w", err)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating over %s rows: %w", tableName, err)
+	query = `DELETE FROM address_events WHERE timestamp < ?`
+	_, err = d.db.Exec(query, cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to delete old address events: %w", err)
 	}
 
-	return histories, nil
-}
-
-// DeleteOldValueHistory deletes value history entries older than a given timestamp for a specific network
-func (d *dB) DeleteOldValueHistory(cutoff time.Time) error {
-	query := `DELETE FROM value_history_mainnet WHERE timestamp < ?`
-	_, err := d.db.Exec(query, cutoff)
+	// keep the last status of each network before the cutoff, it is the status of the node on the network at the cutoff
+	query = `DELETE FROM node_status_history WHERE timestamp < ?
+		AND timestamp < (SELECT MAX(timestamp) FROM node_status_history s WHERE s.network = node_status_history.network AND s.timestamp < ?)`
+	_, err = d.db.Exec(query, cutoff, cutoff)
 	if err != nil {
-		return fmt.Errorf("failed to delete old value history from value_history_mainnet: %w", err)
+		return fmt.Errorf("failed to delete old node status history: %w", err)
 	}
 
-	query = `DELETE FROM value_history_buildnet WHERE timestamp < ?`
-	_, err = d.db.Exec(query, cutoff)
-	if err != nil {
-		return fmt.Errorf("failed to delete old value history from value_history_buildnet: %w", err)
+	for _, resolution := range rollupResolutions {
+		for _, table := range []string{resolution.table(), resolution.addressTable()} {
+			query = fmt.Sprintf(`DELETE FROM %s WHERE bucket_start < ?`, table)
+			_, err = d.db.Exec(query, resolution.BucketStart(cutoff))
+			if err != nil {
+				return fmt.Errorf("failed to delete old value history from %s: %w", table, err)
+			}
+		}
 	}
 
 	return nil
@@ -359,20 +702,20 @@ func (d *dB) GetRollOpHistory(address string, network utils.Network) ([]RollOpHi
 	return histories, nil
 }
 
-// DeleteRollOpHistoryByAddress deletes all roll operation history records for a specific address
-func (d *dB) DeleteRollOpHistoryByAddress(address string) error {
-	exists, err := d.existsRollsOp(address)
+// DeleteRollOpHistoryByAddress deletes all roll operation history records for a specific address and network
+func (d *dB) DeleteRollOpHistoryByAddress(address string, network utils.Network) error {
+	exists, err := d.existsRollsOp(address, network)
 	if err != nil {
 		return fmt.Errorf("failed to check if address %s exists in rolls_op_history: %w", address, err)
 	}
 
 	if !exists {
-		return nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, fmt.Sprintf("roll operation history for address %s not found in database", address))
+		return nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, fmt.Sprintf("roll operation history for address %s not found in database for network %s", address, string(network)))
 	}
 
-	query := `DELETE FROM rolls_op_history WHERE address = ?`
+	query := `DELETE FROM rolls_op_history WHERE address = ? AND network = ?`
 
-	_, err = d.db.Exec(query, address)
+	_, err = d.db.Exec(query, address, string(network))
 	if err != nil {
 		return fmt.Errorf("failed to delete roll operation history for address %s: %w", address, err)
 	}
@@ -393,14 +736,14 @@ func (d *dB) existsRollsTarget(address string, network utils.Network) (bool, err
 	return count > 0, nil
 }
 
-// existsRollsOp checks if an address exists in the rolls_op_history table
-func (d *dB) existsRollsOp(address string) (bool, error) {
-	query := `SELECT COUNT(*) FROM rolls_op_history WHERE address = ?`
+// existsRollsOp checks if an address exists in the rolls_op_history table for a specific network
+func (d *dB) existsRollsOp(address string, network utils.Network) (bool, error) {
+	query := `SELECT COUNT(*) FROM rolls_op_history WHERE address = ? AND network = ?`
 
 	var count int
-	err := d.db.QueryRow(query, address).Scan(&count)
+	err := d.db.QueryRow(query, address, string(network)).Scan(&count)
 	if err != nil {
-		return false, fmt.Errorf("failed to check if address %s exists in rolls_op_history: %w", address, err)
+		return false, fmt.Errorf("failed to check if address %s exists in rolls_op_history for network %s: %w", address, string(network), err)
 	}
 
 	return count > 0, nil
@@ -1,10 +1,18 @@
 package db
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
 	"github.com/massalabs/node-manager-plugin/int/utils"
 )
 
@@ -240,3 +248,1640 @@ func TestDeleteOldValueHistory(t *testing.T) {
 		}
 	}
 }
+
+func TestCycleStatsOperations(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "testdb.db")
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+
+	stats := []CycleStats{
+		{Address: "address1", Cycle: 10, OkCount: 5, NokCount: 0, ActiveRolls: 100, IsFinal: true},
+		{Address: "address1", Cycle: 11, OkCount: 2, NokCount: 1, ActiveRolls: 100, IsFinal: false},
+		{Address: "address2", Cycle: 11, OkCount: 3, NokCount: 0, ActiveRolls: 50, IsFinal: false},
+	}
+
+	for _, s := range stats {
+		if err := db.UpsertCycleStats(s, utils.NetworkMainnet); err != nil {
+			t.Fatalf("Failed to upsert cycle stats: %v", err)
+		}
+	}
+
+	// Same cycle on buildnet must not collide with mainnet entries
+	if err := db.UpsertCycleStats(stats[0], utils.NetworkBuildnet); err != nil {
+		t.Fatalf("Failed to upsert buildnet cycle stats: %v", err)
+	}
+
+	// Update the non final cycle of address1
+	if err := db.UpsertCycleStats(CycleStats{Address: "address1", Cycle: 11, OkCount: 4, NokCount: 2, ActiveRolls: 100, IsFinal: true}, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to update cycle stats: %v", err)
+	}
+
+	retrieved, err := db.GetCycleStats("address1", utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get cycle stats: %v", err)
+	}
+
+	if len(retrieved) != 2 {
+		t.Fatalf("Expected 2 cycle stats records for address1 on mainnet, got %d", len(retrieved))
+	}
+
+	// Newest cycle comes first
+	if retrieved[0].Cycle != 11 || retrieved[0].OkCount != 4 || retrieved[0].NokCount != 2 || !retrieved[0].IsFinal {
+		t.Errorf("Expected updated stats for cycle 11, got %+v", retrieved[0])
+	}
+
+	if retrieved[1].Cycle != 10 || retrieved[1].OkCount != 5 {
+		t.Errorf("Expected stats of cycle 10 to be unchanged, got %+v", retrieved[1])
+	}
+
+	retrieved, err = db.GetCycleStats("address1", utils.NetworkBuildnet)
+	if err != nil {
+		t.Fatalf("Failed to get buildnet cycle stats: %v", err)
+	}
+
+	if len(retrieved) != 1 {
+		t.Fatalf("Expected 1 cycle stats record for address1 on buildnet, got %d", len(retrieved))
+	}
+}
+
+func TestDecommissionOperations(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "testdb.db")
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+
+	if err := db.AddDecommission("address1", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add decommission: %v", err)
+	}
+
+	// Adding the same address twice must fail
+	if err := db.AddDecommission("address1", utils.NetworkMainnet); err == nil {
+		t.Errorf("Expected error when adding the same decommission twice")
+	}
+
+	decommission, err := db.GetDecommission("address1", utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get decommission: %v", err)
+	}
+
+	if decommission.Step != DecommissionStepSellingRolls {
+		t.Errorf("Expected step %s, got %s", DecommissionStepSellingRolls, decommission.Step)
+	}
+
+	if err := db.UpdateDecommissionStep("address1", DecommissionStepWaitingDeferredCredits, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to update decommission step: %v", err)
+	}
+
+	err = db.UpdateDecommissionStep("address1", DecommissionStepArchivingHistory, utils.NetworkBuildnet)
+	if !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		t.Errorf("Expected not found error when updating decommission of another network, got %v", err)
+	}
+
+	decommissions, err := db.GetDecommissions(utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get decommissions: %v", err)
+	}
+
+	if len(decommissions) != 1 || decommissions[0].Step != DecommissionStepWaitingDeferredCredits {
+		t.Fatalf("Expected 1 decommission at step %s, got %+v", DecommissionStepWaitingDeferredCredits, decommissions)
+	}
+
+	if err := db.DeleteDecommission("address1", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to delete decommission: %v", err)
+	}
+
+	_, err = db.GetDecommission("address1", utils.NetworkMainnet)
+	if !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		t.Errorf("Expected not found error after deletion, got %v", err)
+	}
+}
+
+func TestArchiveRollOpHistory(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "testdb.db")
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+
+	if err := db.AddRollOpHistory("address1", RollOpBuy, 10, "op1", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add roll op history: %v", err)
+	}
+	if err := db.AddRollOpHistory("address1", RollOpSell, 10, "op2", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add roll op history: %v", err)
+	}
+	if err := db.AddRollOpHistory("address1", RollOpBuy, 5, "op3", utils.NetworkBuildnet); err != nil {
+		t.Fatalf("Failed to add roll op history: %v", err)
+	}
+
+	if err := db.ArchiveRollOpHistoryByAddress("address1", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to archive roll op history: %v", err)
+	}
+
+	history, err := db.GetRollOpHistory("address1", utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get roll op history: %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("Expected no roll op history left on mainnet, got %d", len(history))
+	}
+
+	archived, err := db.GetArchivedRollOpHistory("address1", utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get archived roll op history: %v", err)
+	}
+	if len(archived) != 2 {
+		t.Errorf("Expected 2 archived roll op history records, got %d", len(archived))
+	}
+
+	// buildnet history must be kept
+	history, err = db.GetRollOpHistory("address1", utils.NetworkBuildnet)
+	if err != nil {
+		t.Fatalf("Failed to get roll op history: %v", err)
+	}
+	if len(history) != 1 {
+		t.Errorf("Expected buildnet roll op history to be kept, got %d records", len(history))
+	}
+
+	err = db.ArchiveRollOpHistoryByAddress("address1", utils.NetworkMainnet)
+	if !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		t.Errorf("Expected not found error when archiving empty history, got %v", err)
+	}
+}
+
+func TestDeleteRollOpHistoryByAddress(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "testdb.db")
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+
+	if err := db.AddRollOpHistory("address1", RollOpBuy, 10, "op1", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add roll op history: %v", err)
+	}
+	if err := db.AddRollOpHistory("address1", RollOpBuy, 5, "op2", utils.NetworkBuildnet); err != nil {
+		t.Fatalf("Failed to add roll op history: %v", err)
+	}
+
+	if err := db.DeleteRollOpHistoryByAddress("address1", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to delete roll op history: %v", err)
+	}
+
+	history, err := db.GetRollOpHistory("address1", utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get roll op history: %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("Expected no roll op history left on mainnet, got %d", len(history))
+	}
+
+	// buildnet history must be kept
+	history, err = db.GetRollOpHistory("address1", utils.NetworkBuildnet)
+	if err != nil {
+		t.Fatalf("Failed to get roll op history: %v", err)
+	}
+	if len(history) != 1 {
+		t.Errorf("Expected buildnet roll op history to be kept, got %d records", len(history))
+	}
+
+	err = db.DeleteRollOpHistoryByAddress("address1", utils.NetworkMainnet)
+	if !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		t.Errorf("Expected not found error when deleting empty history, got %v", err)
+	}
+}
+
+func TestSweepOperations(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "testdb.db")
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+
+	rule := SweepRule{Address: "address1", ColdAddress: "cold1", Threshold: 10, Reserve: 5, DryRun: true}
+	if err := db.SetSweepRule(rule, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to set sweep rule: %v", err)
+	}
+
+	// Setting the rule again replaces it
+	rule.Threshold = 20
+	rule.DryRun = false
+	if err := db.SetSweepRule(rule, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to update sweep rule: %v", err)
+	}
+
+	retrieved, err := db.GetSweepRule("address1", utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get sweep rule: %v", err)
+	}
+	if retrieved != rule {
+		t.Errorf("Expected sweep rule %+v, got %+v", rule, retrieved)
+	}
+
+	_, err = db.GetSweepRule("address1", utils.NetworkBuildnet)
+	if !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		t.Errorf("Expected not found error for sweep rule of another network, got %v", err)
+	}
+
+	rules, err := db.GetSweepRules(utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get sweep rules: %v", err)
+	}
+	if len(rules) != 1 {
+		t.Fatalf("Expected 1 sweep rule on mainnet, got %d", len(rules))
+	}
+
+	if err := db.AddSweepHistory(SweepHistory{Address: "address1", ColdAddress: "cold1", Amount: 12, Fee: massaAmount.FromMAS(0.01), DryRun: true}, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add sweep history: %v", err)
+	}
+	if err := db.AddSweepHistory(SweepHistory{Address: "address1", ColdAddress: "cold1", Amount: 15, Fee: massaAmount.FromMAS(0.01), OpId: "op1"}, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add sweep history: %v", err)
+	}
+
+	history, err := db.GetSweepHistory("address1", utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get sweep history: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 sweep history records, got %d", len(history))
+	}
+
+	// Newest sweep comes first
+	if history[0].OpId != "op1" || history[0].Amount != 15 || history[0].DryRun {
+		t.Errorf("Expected the sent sweep first, got %+v", history[0])
+	}
+	if history[1].OpId != "" || !history[1].DryRun {
+		t.Errorf("Expected the dry run sweep second, got %+v", history[1])
+	}
+
+	if err := db.DeleteSweepRule("address1", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to delete sweep rule: %v", err)
+	}
+
+	err = db.DeleteSweepRule("address1", utils.NetworkMainnet)
+	if !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		t.Errorf("Expected not found error when deleting a missing sweep rule, got %v", err)
+	}
+}
+
+func TestRollScheduleOperations(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "testdb.db")
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+
+	runAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	cycle := uint64(42)
+
+	dateID, err := db.AddRollSchedule(RollSchedule{Address: "address1", Action: RollScheduleSell, Amount: 50, RunAt: &runAt}, utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to add roll schedule: %v", err)
+	}
+	cycleID, err := db.AddRollSchedule(RollSchedule{Address: "address1", Action: RollScheduleSetTarget, Amount: 200, Cycle: &cycle}, utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to add roll schedule: %v", err)
+	}
+
+	schedule, err := db.GetRollSchedule(dateID, utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get roll schedule: %v", err)
+	}
+	if schedule.Status != RollSchedulePending || schedule.Action != RollScheduleSell || schedule.Amount != 50 {
+		t.Errorf("Unexpected roll schedule %+v", schedule)
+	}
+	if schedule.RunAt == nil || !schedule.RunAt.Equal(runAt) || schedule.Cycle != nil || schedule.ExecutedAt != nil {
+		t.Errorf("Expected roll schedule to run at %v only, got %+v", runAt, schedule)
+	}
+
+	_, err = db.GetRollSchedule(dateID, utils.NetworkBuildnet)
+	if !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		t.Errorf("Expected not found error for roll schedule of another network, got %v", err)
+	}
+
+	// Move the date schedule to a cycle
+	schedule.RunAt = nil
+	schedule.Cycle = &cycle
+	schedule.Amount = 20
+	if err := db.UpdateRollSchedule(schedule, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to update roll schedule: %v", err)
+	}
+
+	schedules, err := db.GetRollSchedules(utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get roll schedules: %v", err)
+	}
+	if len(schedules) != 2 {
+		t.Fatalf("Expected 2 roll schedules, got %d", len(schedules))
+	}
+	if schedules[0].ID != dateID || schedules[0].RunAt != nil || schedules[0].Cycle == nil || *schedules[0].Cycle != cycle || schedules[0].Amount != 20 {
+		t.Errorf("Expected updated roll schedule first, got %+v", schedules[0])
+	}
+
+	if err := db.SetRollScheduleStatus(cycleID, RollScheduleFailed, "address is being decommissioned", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to set roll schedule status: %v", err)
+	}
+
+	schedule, err = db.GetRollSchedule(cycleID, utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get roll schedule: %v", err)
+	}
+	if schedule.Status != RollScheduleFailed || schedule.Error != "address is being decommissioned" || schedule.ExecutedAt == nil {
+		t.Errorf("Expected failed roll schedule, got %+v", schedule)
+	}
+
+	// A schedule that has run can no longer be edited, run again or cancelled
+	if err := db.UpdateRollSchedule(schedule, utils.NetworkMainnet); !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		t.Errorf("Expected not found error when updating a schedule that has run, got %v", err)
+	}
+	if err := db.SetRollScheduleStatus(cycleID, RollScheduleDone, "", utils.NetworkMainnet); !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		t.Errorf("Expected not found error when running a schedule twice, got %v", err)
+	}
+	if err := db.DeleteRollSchedule(cycleID, utils.NetworkMainnet); !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		t.Errorf("Expected not found error when cancelling a schedule that has run, got %v", err)
+	}
+
+	if err := db.DeleteRollSchedule(dateID, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to delete roll schedule: %v", err)
+	}
+
+	schedules, err = db.GetRollSchedules(utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get roll schedules: %v", err)
+	}
+	if len(schedules) != 1 || schedules[0].ID != cycleID {
+		t.Errorf("Expected only the schedule that has run to remain, got %+v", schedules)
+	}
+}
+
+func TestGuardrailOperations(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "testdb.db")
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+
+	before := time.Now().Add(-time.Second)
+
+	// Rolls bought on both networks, only the buys of the requested network are counted
+	if err := db.AddRollOpHistory("address1", RollOpBuy, 3, "op1", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add roll op history: %v", err)
+	}
+	if err := db.AddRollOpHistory("address2", RollOpBuy, 4, "op2", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add roll op history: %v", err)
+	}
+	if err := db.AddRollOpHistory("address1", RollOpSell, 10, "op3", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add roll op history: %v", err)
+	}
+	if err := db.AddRollOpHistory("address1", RollOpBuy, 20, "op4", utils.NetworkBuildnet); err != nil {
+		t.Fatalf("Failed to add roll op history: %v", err)
+	}
+
+	bought, err := db.GetRollsBoughtSince(before, utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get rolls bought: %v", err)
+	}
+	if bought != 7 {
+		t.Errorf("Expected 7 rolls bought on mainnet, got %d", bought)
+	}
+
+	bought, err = db.GetRollsBoughtSince(time.Now().Add(time.Hour), utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get rolls bought: %v", err)
+	}
+	if bought != 0 {
+		t.Errorf("Expected no roll bought in the future, got %d", bought)
+	}
+
+	if err := db.AddBlockedRollOp(BlockedRollOp{Address: "address1", Op: RollOpBuy, Amount: 5, Reason: "kill switch"}, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add blocked roll op: %v", err)
+	}
+	if err := db.AddBlockedRollOp(BlockedRollOp{Address: "address1", Op: RollOpSell, Amount: 2, Reason: "interval"}, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add blocked roll op: %v", err)
+	}
+
+	blockedOps, err := db.GetBlockedRollOps("address1", utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get blocked roll ops: %v", err)
+	}
+	if len(blockedOps) != 2 {
+		t.Fatalf("Expected 2 blocked roll ops, got %d", len(blockedOps))
+	}
+	if blockedOps[0].Op != RollOpSell || blockedOps[0].Reason != "interval" || blockedOps[1].Op != RollOpBuy || blockedOps[1].Amount != 5 {
+		t.Errorf("Expected newest blocked roll op first, got %+v", blockedOps)
+	}
+
+	blockedOps, err = db.GetBlockedRollOps("address1", utils.NetworkBuildnet)
+	if err != nil {
+		t.Fatalf("Failed to get blocked roll ops: %v", err)
+	}
+	if len(blockedOps) != 0 {
+		t.Errorf("Expected no blocked roll op on buildnet, got %d", len(blockedOps))
+	}
+}
+
+func TestAutomationPauseOperations(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "testdb.db")
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+
+	if err := db.AddAutomationPause(GlobalPauseAddress, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add global automation pause: %v", err)
+	}
+	if err := db.AddAutomationPause("address1", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add automation pause: %v", err)
+	}
+
+	// Pausing twice is not an error
+	if err := db.AddAutomationPause("address1", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add automation pause twice: %v", err)
+	}
+
+	pauses, err := db.GetAutomationPauses(utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get automation pauses: %v", err)
+	}
+	if len(pauses) != 2 || pauses[0].Address != GlobalPauseAddress || pauses[1].Address != "address1" {
+		t.Fatalf("Expected global and address1 pauses, got %+v", pauses)
+	}
+
+	pauses, err = db.GetAutomationPauses(utils.NetworkBuildnet)
+	if err != nil {
+		t.Fatalf("Failed to get automation pauses: %v", err)
+	}
+	if len(pauses) != 0 {
+		t.Errorf("Expected no automation pause on buildnet, got %+v", pauses)
+	}
+
+	if err := db.DeleteAutomationPause(GlobalPauseAddress, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to delete global automation pause: %v", err)
+	}
+
+	err = db.DeleteAutomationPause(GlobalPauseAddress, utils.NetworkMainnet)
+	if !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		t.Errorf("Expected not found error when resuming an automation that is not paused, got %v", err)
+	}
+
+	pauses, err = db.GetAutomationPauses(utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get automation pauses: %v", err)
+	}
+	if len(pauses) != 1 || pauses[0].Address != "address1" {
+		t.Errorf("Expected only address1 pause, got %+v", pauses)
+	}
+}
+
+func TestWatchOnlyAddressOperations(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "testdb.db")
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+
+	if err := db.AddWatchOnlyAddress("address2", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add watch-only address: %v", err)
+	}
+	if err := db.AddWatchOnlyAddress("address1", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add watch-only address: %v", err)
+	}
+	if err := db.AddWatchOnlyAddress("address1", utils.NetworkMainnet); err == nil {
+		t.Errorf("Expected an error when adding the same watch-only address twice")
+	}
+
+	addresses, err := db.GetWatchOnlyAddresses(utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get watch-only addresses: %v", err)
+	}
+	if len(addresses) != 2 || addresses[0].Address != "address1" || addresses[1].Address != "address2" {
+		t.Fatalf("Expected address1 and address2, got %+v", addresses)
+	}
+
+	addresses, err = db.GetWatchOnlyAddresses(utils.NetworkBuildnet)
+	if err != nil {
+		t.Fatalf("Failed to get watch-only addresses: %v", err)
+	}
+	if len(addresses) != 0 {
+		t.Errorf("Expected no watch-only address on buildnet, got %+v", addresses)
+	}
+
+	if err := db.DeleteWatchOnlyAddress("address1", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to delete watch-only address: %v", err)
+	}
+
+	err = db.DeleteWatchOnlyAddress("address1", utils.NetworkMainnet)
+	if !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		t.Errorf("Expected not found error when deleting a missing watch-only address, got %v", err)
+	}
+
+	now := time.Now()
+	for i, value := range []massaAmount.Amount{100, 200} {
+		if err := db.PostWatchOnlyHistory(ValueHistory{Timestamp: now.Add(time.Duration(i-2) * time.Hour), TotalValue: value}, utils.NetworkMainnet); err != nil {
+			t.Fatalf("Failed to post watch-only history: %v", err)
+		}
+	}
+	if err := db.PostWatchOnlyHistory(ValueHistory{Timestamp: now, TotalValue: 300}, utils.NetworkBuildnet); err != nil {
+		t.Fatalf("Failed to post watch-only history: %v", err)
+	}
+
+	histories, err := db.GetWatchOnlyHistory(now.Add(-3*time.Hour), utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get watch-only history: %v", err)
+	}
+	if len(histories) != 2 || histories[0].TotalValue != 100 || histories[1].TotalValue != 200 {
+		t.Fatalf("Expected mainnet watch-only values 100 and 200, got %+v", histories)
+	}
+
+	// the staking addresses series is not affected
+	histories, err = db.GetHistory(now.Add(-3*time.Hour), utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get value history: %v", err)
+	}
+	if len(histories) != 0 {
+		t.Errorf("Expected empty staking addresses value history, got %+v", histories)
+	}
+
+	if err := db.DeleteOldValueHistory(now.Add(-90 * time.Minute)); err != nil {
+		t.Fatalf("Failed to delete old value history: %v", err)
+	}
+
+	histories, err = db.GetWatchOnlyHistory(now.Add(-3*time.Hour), utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get watch-only history: %v", err)
+	}
+	if len(histories) != 1 || histories[0].TotalValue != 200 {
+		t.Errorf("Expected only the newest mainnet watch-only value after pruning, got %+v", histories)
+	}
+}
+
+func TestAddressLabelOperations(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "testdb.db")
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+
+	// the wallet nickname labels a new address
+	if err := db.InitAddressLabel("address1", "wallet1", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to init address label: %v", err)
+	}
+
+	label, err := db.GetAddressLabel("address1", utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get address label: %v", err)
+	}
+	if label.Label != "wallet1" || len(label.Tags) != 0 || label.Group != "" {
+		t.Errorf("Expected label wallet1 without tags and group, got %+v", label)
+	}
+
+	if err := db.SetAddressLabel(AddressLabel{Address: "address1", Label: "main", Tags: []string{"hot", "team"}, Group: "treasury"}, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to set address label: %v", err)
+	}
+
+	// a label set by the user is not replaced by the nickname
+	if err := db.InitAddressLabel("address1", "wallet1", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to init address label: %v", err)
+	}
+
+	label, err = db.GetAddressLabel("address1", utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get address label: %v", err)
+	}
+	if label.Label != "main" || len(label.Tags) != 2 || label.Tags[1] != "team" || label.Group != "treasury" {
+		t.Errorf("Expected the label set by the user, got %+v", label)
+	}
+
+	if err := db.SetAddressLabel(AddressLabel{Address: "address0", Group: "treasury"}, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to set address label: %v", err)
+	}
+
+	labels, err := db.GetAddressLabels(utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get address labels: %v", err)
+	}
+	if len(labels) != 2 || labels[0].Address != "address0" || labels[1].Address != "address1" {
+		t.Fatalf("Expected address0 and address1 labels, got %+v", labels)
+	}
+
+	labels, err = db.GetAddressLabels(utils.NetworkBuildnet)
+	if err != nil {
+		t.Fatalf("Failed to get address labels: %v", err)
+	}
+	if len(labels) != 0 {
+		t.Errorf("Expected no address label on buildnet, got %+v", labels)
+	}
+
+	if err := db.DeleteAddressLabel("address1", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to delete address label: %v", err)
+	}
+
+	_, err = db.GetAddressLabel("address1", utils.NetworkMainnet)
+	if !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		t.Errorf("Expected not found error for a deleted label, got %v", err)
+	}
+
+	err = db.DeleteAddressLabel("address1", utils.NetworkMainnet)
+	if !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		t.Errorf("Expected not found error when deleting a missing label, got %v", err)
+	}
+}
+
+func TestAddressValueHistoryOperations(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "testdb.db")
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+
+	now := time.Now()
+	before := now.Add(-time.Hour)
+	histories := []AddressValueHistory{
+		{Timestamp: before, Address: "address1", Balance: 10, RollValue: 100, Rolls: 1, Deferred: 1},
+		{Timestamp: before, Address: "address2", Balance: 20, RollValue: 200, Rolls: 2, Deferred: 2},
+		{Timestamp: before, Address: "watched", WatchOnly: true, Balance: 1000},
+	}
+	if err := db.PostAddressHistory(histories, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to post address history: %v", err)
+	}
+	histories = []AddressValueHistory{
+		{Timestamp: now, Address: "address1", Balance: 30, RollValue: 100, Deferred: 0},
+		{Timestamp: now, Address: "watched", WatchOnly: true, Balance: 2000},
+	}
+	if err := db.PostAddressHistory(histories, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to post address history: %v", err)
+	}
+	if err := db.PostAddressHistory([]AddressValueHistory{{Timestamp: now, Address: "address1", Balance: 5}}, utils.NetworkBuildnet); err != nil {
+		t.Fatalf("Failed to post address history: %v", err)
+	}
+
+	// a failing insertion is rolled back
+	if err := db.PostAddressHistory([]AddressValueHistory{
+		{Timestamp: now.Add(time.Minute), Address: "address1"},
+		{Timestamp: now, Address: "address1"},
+	}, utils.NetworkMainnet); err == nil {
+		t.Fatalf("Expected an error when posting the value of an address twice at the same time")
+	}
+
+	staking, err := db.GetAddressHistory(AddressHistoryFilter{}, before.Add(-time.Minute), utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get address history: %v", err)
+	}
+	if len(staking) != 2 || staking[0].Balance != 30 || staking[0].RollValue != 300 || staking[0].Deferred != 3 || staking[1].Balance != 30 {
+		t.Fatalf("Expected the staking addresses summed by timestamp, got %+v", staking)
+	}
+
+	watched, err := db.GetAddressHistory(AddressHistoryFilter{WatchOnly: true}, before.Add(-time.Minute), utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get address history: %v", err)
+	}
+	if len(watched) != 2 || watched[0].Balance != 1000 || watched[1].Balance != 2000 {
+		t.Fatalf("Expected the watch-only address values, got %+v", watched)
+	}
+
+	selected, err := db.GetAddressHistory(AddressHistoryFilter{Addresses: []string{"address2", "watched"}}, before.Add(-time.Minute), utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get address history: %v", err)
+	}
+	if len(selected) != 2 || selected[0].Balance != 1020 || selected[0].RollValue != 200 || selected[1].Balance != 2000 {
+		t.Fatalf("Expected address2 and watched summed by timestamp, got %+v", selected)
+	}
+
+	records, err := db.GetAddressValueHistory(AddressHistoryFilter{Addresses: []string{"address1", "address2"}}, before, now.Add(-time.Minute), utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get address value history: %v", err)
+	}
+	if len(records) != 2 || records[0].Address != "address1" || records[0].Balance != 10 || records[1].Address != "address2" || records[1].RollValue != 200 || records[1].Rolls != 2 {
+		t.Fatalf("Expected the values of address1 and address2 before now, ordered by address, got %+v", records)
+	}
+
+	records, err = db.GetAddressValueHistory(AddressHistoryFilter{}, before, now, utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get address value history: %v", err)
+	}
+	if len(records) != 3 || records[0].Address != "address1" || records[1].Address != "address1" || records[1].Balance != 30 || records[2].Address != "address2" {
+		t.Fatalf("Expected the values of the staking addresses ordered by address then chronologically, got %+v", records)
+	}
+
+	if err := db.DeleteOldValueHistory(now.Add(-time.Minute)); err != nil {
+		t.Fatalf("Failed to delete old value history: %v", err)
+	}
+
+	selected, err = db.GetAddressHistory(AddressHistoryFilter{Addresses: []string{"address1"}}, before.Add(-time.Minute), utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get address history: %v", err)
+	}
+	if len(selected) != 1 || selected[0].Balance != 30 {
+		t.Errorf("Expected only the newest address1 value after pruning, got %+v", selected)
+	}
+}
+
+func TestValueRollupOperations(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "testdb.db")
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	values := []ValueHistory{
+		{Timestamp: day.Add(10*time.Hour + 5*time.Minute), TotalValue: 100},
+		{Timestamp: day.Add(10*time.Hour + 20*time.Minute), TotalValue: 140},
+		{Timestamp: day.Add(10*time.Hour + 40*time.Minute), TotalValue: 90},
+		{Timestamp: day.Add(11*time.Hour + 10*time.Minute), TotalValue: 110},
+	}
+	for _, value := range values {
+		if err := db.PostHistory(value, utils.NetworkMainnet); err != nil {
+			t.Fatalf("Failed to post history: %v", err)
+		}
+	}
+	if err := db.PostWatchOnlyHistory(ValueHistory{Timestamp: day.Add(10 * time.Hour), TotalValue: 5}, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to post watch-only history: %v", err)
+	}
+
+	checkRollups := func(step string) {
+		hourly, err := db.GetValueRollups(ValueSeriesStaking, RollupHourly, day, utils.NetworkMainnet)
+		if err != nil {
+			t.Fatalf("%s: failed to get hourly rollups: %v", step, err)
+		}
+		if len(hourly) != 2 {
+			t.Fatalf("%s: expected 2 hourly rollups, got %+v", step, hourly)
+		}
+		expected := ValueRollup{BucketStart: day.Add(10 * time.Hour), Open: 100, Close: 90, Min: 90, Max: 140, Avg: 110, Count: 3}
+		if !hourly[0].BucketStart.Equal(expected.BucketStart) || hourly[0].Open != expected.Open || hourly[0].Close != expected.Close ||
+			hourly[0].Min != expected.Min || hourly[0].Max != expected.Max || hourly[0].Avg != expected.Avg || hourly[0].Count != expected.Count {
+			t.Errorf("%s: expected hourly rollup %+v, got %+v", step, expected, hourly[0])
+		}
+		if !hourly[1].BucketStart.Equal(day.Add(11*time.Hour)) || hourly[1].Count != 1 || hourly[1].Close != 110 {
+			t.Errorf("%s: unexpected second hourly rollup %+v", step, hourly[1])
+		}
+
+		daily, err := db.GetValueRollups(ValueSeriesStaking, RollupDaily, day, utils.NetworkMainnet)
+		if err != nil {
+			t.Fatalf("%s: failed to get daily rollups: %v", step, err)
+		}
+		if len(daily) != 1 || daily[0].Open != 100 || daily[0].Close != 110 || daily[0].Min != 90 || daily[0].Max != 140 || daily[0].Avg != 110 || daily[0].Count != 4 {
+			t.Errorf("%s: unexpected daily rollups %+v", step, daily)
+		}
+
+		watchOnly, err := db.GetValueRollups(ValueSeriesWatchOnly, RollupHourly, day, utils.NetworkMainnet)
+		if err != nil {
+			t.Fatalf("%s: failed to get watch-only rollups: %v", step, err)
+		}
+		if len(watchOnly) != 1 || watchOnly[0].Close != 5 {
+			t.Errorf("%s: unexpected watch-only rollups %+v", step, watchOnly)
+		}
+
+		buildnet, err := db.GetValueRollups(ValueSeriesStaking, RollupHourly, day, utils.NetworkBuildnet)
+		if err != nil {
+			t.Fatalf("%s: failed to get buildnet rollups: %v", step, err)
+		}
+		if len(buildnet) != 0 {
+			t.Errorf("%s: expected no buildnet rollups, got %+v", step, buildnet)
+		}
+	}
+	checkRollups("incremental")
+
+	// Rollups are rebuilt from the raw values when the database is opened without them
+	for _, table := range []string{"value_history_hourly", "value_history_daily"} {
+		if _, err := db.(*dB).db.Exec("DELETE FROM " + table); err != nil {
+			t.Fatalf("Failed to empty %s: %v", table, err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Failed to close db connection: %v", err)
+	}
+	db, err = NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+	checkRollups("backfill")
+
+	if err := db.DeleteOldValueHistory(day.Add(11 * time.Hour)); err != nil {
+		t.Fatalf("Failed to delete old value history: %v", err)
+	}
+	hourly, err := db.GetValueRollups(ValueSeriesStaking, RollupHourly, day, utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get hourly rollups: %v", err)
+	}
+	if len(hourly) != 1 || !hourly[0].BucketStart.Equal(day.Add(11*time.Hour)) {
+		t.Errorf("Expected only the 11h hourly rollup to remain, got %+v", hourly)
+	}
+}
+
+func TestAddressValueRollupOperations(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "testdb.db")
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	batches := [][]AddressValueHistory{
+		{
+			{Timestamp: day.Add(10*time.Hour + 5*time.Minute), Address: "AU1", Balance: 60, RollValue: 30, Deferred: 10},
+			{Timestamp: day.Add(10*time.Hour + 5*time.Minute), Address: "AU2", Balance: 20},
+			{Timestamp: day.Add(10*time.Hour + 5*time.Minute), Address: "AU3", WatchOnly: true, Balance: 7},
+		},
+		{
+			{Timestamp: day.Add(10*time.Hour + 40*time.Minute), Address: "AU1", Balance: 80, RollValue: 30, Deferred: 10},
+			{Timestamp: day.Add(10*time.Hour + 40*time.Minute), Address: "AU2", Balance: 40},
+		},
+		{
+			{Timestamp: day.Add(11*time.Hour + 10*time.Minute), Address: "AU1", Balance: 50, RollValue: 30},
+		},
+	}
+	for _, batch := range batches {
+		if err := db.PostAddressHistory(batch, utils.NetworkMainnet); err != nil {
+			t.Fatalf("Failed to post address history: %v", err)
+		}
+	}
+
+	checkRollups := func(step string) {
+		staking := AddressHistoryFilter{}
+		hourly, err := db.GetAddressValueRollups(staking, RollupHourly, day, utils.NetworkMainnet)
+		if err != nil {
+			t.Fatalf("%s: failed to get hourly rollups: %v", step, err)
+		}
+		if len(hourly) != 2 {
+			t.Fatalf("%s: expected 2 hourly rollups, got %+v", step, hourly)
+		}
+		first := hourly[0]
+		if !first.BucketStart.Equal(day.Add(10*time.Hour)) || first.Open != 120 || first.Close != 160 || first.Min != 120 ||
+			first.Max != 160 || first.Avg != 140 || first.Count != 2 || first.Balance != 120 || first.RollValue != 30 || first.Deferred != 10 {
+			t.Errorf("%s: unexpected first hourly rollup %+v", step, first)
+		}
+		if !hourly[1].BucketStart.Equal(day.Add(11*time.Hour)) || hourly[1].Close != 80 || hourly[1].Count != 1 {
+			t.Errorf("%s: unexpected second hourly rollup %+v", step, hourly[1])
+		}
+
+		daily, err := db.GetAddressValueRollups(AddressHistoryFilter{Addresses: []string{"AU1"}}, RollupDaily, day, utils.NetworkMainnet)
+		if err != nil {
+			t.Fatalf("%s: failed to get daily rollups: %v", step, err)
+		}
+		if len(daily) != 1 || daily[0].Open != 100 || daily[0].Close != 80 || daily[0].Min != 80 || daily[0].Max != 120 ||
+			daily[0].Avg != 100 || daily[0].Count != 3 || daily[0].Balance != 50 || daily[0].RollValue != 30 || daily[0].Deferred != 0 {
+			t.Errorf("%s: unexpected daily rollups of AU1 %+v", step, daily)
+		}
+
+		watchOnly, err := db.GetAddressValueRollups(AddressHistoryFilter{WatchOnly: true}, RollupHourly, day, utils.NetworkMainnet)
+		if err != nil {
+			t.Fatalf("%s: failed to get watch-only rollups: %v", step, err)
+		}
+		if len(watchOnly) != 1 || watchOnly[0].Close != 7 {
+			t.Errorf("%s: unexpected watch-only rollups %+v", step, watchOnly)
+		}
+
+		buildnet, err := db.GetAddressValueRollups(staking, RollupHourly, day, utils.NetworkBuildnet)
+		if err != nil {
+			t.Fatalf("%s: failed to get buildnet rollups: %v", step, err)
+		}
+		if len(buildnet) != 0 {
+			t.Errorf("%s: expected no buildnet rollups, got %+v", step, buildnet)
+		}
+	}
+	checkRollups("incremental")
+
+	// Rollups are rebuilt from the raw values when the database is opened without them
+	for _, table := range []string{"address_value_history_hourly", "address_value_history_daily"} {
+		if _, err := db.(*dB).db.Exec("DELETE FROM " + table); err != nil {
+			t.Fatalf("Failed to empty %s: %v", table, err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Failed to close db connection: %v", err)
+	}
+	db, err = NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+	checkRollups("backfill")
+
+	rollups, err := db.GetAddressValueRollups(AddressHistoryFilter{Addresses: []string{}}, RollupHourly, day, utils.NetworkMainnet)
+	if err != nil || rollups != nil {
+		t.Errorf("Expected no rollups for an empty address list, got %+v, %v", rollups, err)
+	}
+
+	if err := db.DeleteOldValueHistory(day.Add(11 * time.Hour)); err != nil {
+		t.Fatalf("Failed to delete old value history: %v", err)
+	}
+	hourly, err := db.GetAddressValueRollups(AddressHistoryFilter{}, RollupHourly, day, utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get hourly rollups: %v", err)
+	}
+	if len(hourly) != 1 || !hourly[0].BucketStart.Equal(day.Add(11*time.Hour)) {
+		t.Errorf("Expected only the 11h hourly rollup to remain, got %+v", hourly)
+	}
+}
+
+func TestHistoryEventsOperations(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "testdb.db")
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+
+	if err := db.AddNodeStatusHistory("off", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add node status history: %v", err)
+	}
+	if err := db.AddNodeStatusHistory("on", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add node status history: %v", err)
+	}
+	since := time.Now()
+	if err := db.AddNodeStatusHistory("stopping", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add node status history: %v", err)
+	}
+
+	if err := db.AddNodeStatusHistory("on", utils.NetworkBuildnet); err != nil {
+		t.Fatalf("Failed to add node status history: %v", err)
+	}
+
+	// the last status before since comes first, the statuses of other networks are left out
+	statuses, err := db.GetNodeStatusHistory(since, utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get node status history: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].Status != "on" || statuses[1].Status != "stopping" || statuses[1].Network != utils.NetworkMainnet {
+		t.Fatalf("Expected on then stopping statuses, got %+v", statuses)
+	}
+
+	statuses, err = db.GetNodeStatusHistory(since, utils.NetworkBuildnet)
+	if err != nil {
+		t.Fatalf("Failed to get buildnet node status history: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Status != "on" || statuses[0].Network != utils.NetworkBuildnet {
+		t.Fatalf("Expected the buildnet on status, got %+v", statuses)
+	}
+
+	if err := db.AddAddressEvent("address1", AddressEventAdded, false, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add address event: %v", err)
+	}
+	if err := db.AddAddressEvent("watched", AddressEventRemoved, true, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add address event: %v", err)
+	}
+	if err := db.AddAddressEvent("address2", AddressEventAdded, false, utils.NetworkBuildnet); err != nil {
+		t.Fatalf("Failed to add address event: %v", err)
+	}
+
+	events, err := db.GetAddressEvents(since, utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get address events: %v", err)
+	}
+	if len(events) != 2 || events[0].Address != "address1" || events[0].Event != AddressEventAdded || events[0].WatchOnly ||
+		events[1].Address != "watched" || events[1].Event != AddressEventRemoved || !events[1].WatchOnly {
+		t.Fatalf("Unexpected address events %+v", events)
+	}
+
+	if err := db.AddRollOpHistory("address1", RollOpBuy, 3, "op1", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add roll operation history: %v", err)
+	}
+	if err := db.AddRollOpHistory("address2", RollOpSell, 1, "op2", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add roll operation history: %v", err)
+	}
+	if err := db.ArchiveRollOpHistoryByAddress("address2", utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to archive roll operation history: %v", err)
+	}
+
+	rollOps, err := db.GetRollOpHistorySince(since, utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get roll operation history: %v", err)
+	}
+	if len(rollOps) != 2 || rollOps[0].Address != "address1" || rollOps[0].Op != string(RollOpBuy) || rollOps[0].Amount != 3 ||
+		rollOps[1].Address != "address2" || rollOps[1].OpId != "op2" {
+		t.Fatalf("Expected the roll operations of address1 and the archived ones of address2, got %+v", rollOps)
+	}
+
+	// the last status of each network is kept
+	cutoff := time.Now()
+	if err := db.DeleteOldValueHistory(cutoff); err != nil {
+		t.Fatalf("Failed to delete old value history: %v", err)
+	}
+	for network, expected := range map[utils.Network]string{utils.NetworkMainnet: "stopping", utils.NetworkBuildnet: "on"} {
+		statuses, err := db.GetNodeStatusHistory(cutoff, network)
+		if err != nil {
+			t.Fatalf("Failed to get %s node status history: %v", network, err)
+		}
+		if len(statuses) != 1 || statuses[0].Status != expected {
+			t.Fatalf("Expected the %s %s status to be kept, got %+v", network, expected, statuses)
+		}
+	}
+}
+
+func TestLedgerEntriesOperations(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "testdb.db")
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+
+	since := time.Now()
+	if err := db.AddSweepHistory(SweepHistory{Address: "address1", ColdAddress: "cold", Amount: 10, Fee: massaAmount.FromMAS(0.01), OpId: "op1"}, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add sweep history: %v", err)
+	}
+	if err := db.AddSweepHistory(SweepHistory{Address: "address2", ColdAddress: "cold", Amount: 5, Fee: massaAmount.FromMAS(0.01), DryRun: true}, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add sweep history: %v", err)
+	}
+	if err := db.AddSweepHistory(SweepHistory{Address: "address1", ColdAddress: "cold", Amount: 3, Fee: massaAmount.FromMAS(0.01), OpId: "op2"}, utils.NetworkBuildnet); err != nil {
+		t.Fatalf("Failed to add sweep history: %v", err)
+	}
+
+	sweeps, err := db.GetSweepHistorySince(since, utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get sweep history: %v", err)
+	}
+	if len(sweeps) != 2 || sweeps[0].Address != "address1" || sweeps[0].OpId != "op1" || sweeps[1].Address != "address2" || !sweeps[1].DryRun {
+		t.Fatalf("Unexpected sweeps %+v", sweeps)
+	}
+
+	if err := db.AddLedgerEntries(nil, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add no ledger entries: %v", err)
+	}
+
+	now := time.Now()
+	entries := []LedgerEntry{
+		{Timestamp: now, Address: "address1", Kind: LedgerRollBuy, Amount: -200, Rolls: 2, OpId: "op3"},
+		{Timestamp: now, Address: "address1", Kind: LedgerFee, Amount: -massaAmount.FromMAS(0.01), OpId: "op3"},
+		{Timestamp: now, Address: "address2", Kind: LedgerExternalTransfer, Amount: 42},
+	}
+	if err := db.AddLedgerEntries(entries, utils.NetworkMainnet); err != nil {
+		t.Fatalf("Failed to add ledger entries: %v", err)
+	}
+	if err := db.AddLedgerEntries([]LedgerEntry{{Timestamp: now, Address: "address1", Kind: LedgerBlockReward, Amount: 1}}, utils.NetworkBuildnet); err != nil {
+		t.Fatalf("Failed to add ledger entries: %v", err)
+	}
+
+	got, err := db.GetLedgerEntries("", since, utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get ledger entries: %v", err)
+	}
+	if len(got) != len(entries) {
+		t.Fatalf("Expected %d ledger entries, got %d", len(entries), len(got))
+	}
+	for i, entry := range entries {
+		if got[i].Address != entry.Address || got[i].Kind != entry.Kind || got[i].Amount != entry.Amount || got[i].Rolls != entry.Rolls || got[i].OpId != entry.OpId {
+			t.Errorf("Expected ledger entry %+v, got %+v", entry, got[i])
+		}
+	}
+
+	got, err = db.GetLedgerEntries("address2", since, utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get ledger entries: %v", err)
+	}
+	if len(got) != 1 || got[0].Kind != LedgerExternalTransfer {
+		t.Fatalf("Expected the external transfer of address2, got %+v", got)
+	}
+
+	got, err = db.GetLedgerEntries("", now, utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get ledger entries: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("Expected no ledger entry after the last one, got %+v", got)
+	}
+}
+
+func TestPriceCache(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "testdb.db")
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+
+	day := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	if _, err := db.GetPrice(day, "EUR"); !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		t.Fatalf("Expected not found error, got %v", err)
+	}
+
+	if err := db.SetPrice(day, "EUR", 0.02); err != nil {
+		t.Fatalf("Failed to set price: %v", err)
+	}
+	// the price is replaced, and shared by every time of the day
+	if err := db.SetPrice(day.Add(5*time.Hour), "EUR", 0.025); err != nil {
+		t.Fatalf("Failed to set price: %v", err)
+	}
+
+	price, err := db.GetPrice(day.Add(-10*time.Hour+time.Minute), "EUR")
+	if err != nil {
+		t.Fatalf("Failed to get price: %v", err)
+	}
+	if price != 0.025 {
+		t.Fatalf("Expected price 0.025, got %f", price)
+	}
+
+	if _, err := db.GetPrice(day, "USD"); !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		t.Fatalf("Expected not found error for another currency, got %v", err)
+	}
+	if _, err := db.GetPrice(day.AddDate(0, 0, 1), "EUR"); !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		t.Fatalf("Expected not found error for another day, got %v", err)
+	}
+}
+
+func TestMigrateAmountColumns(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "testdb.db")
+
+	// Create a sweep history table with amounts in MAS, as the previous versions of the plugin did
+	legacy, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open legacy database: %v", err)
+	}
+	statements := []string{
+		`CREATE TABLE sweep_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			address TEXT NOT NULL,
+			network TEXT NOT NULL,
+			cold_address TEXT NOT NULL,
+			amount REAL NOT NULL,
+			fee REAL NOT NULL,
+			op_id TEXT NOT NULL,
+			dry_run BOOLEAN NOT NULL,
+			timestamp DATETIME NOT NULL
+		)`,
+		`INSERT INTO sweep_history (address, network, cold_address, amount, fee, op_id, dry_run, timestamp)
+		VALUES ('address1', 'mainnet', 'cold', 1234.567891234, 0.01, 'op1', false, CURRENT_TIMESTAMP)`,
+	}
+	for _, statement := range statements {
+		if _, err := legacy.Exec(statement); err != nil {
+			t.Fatalf("Failed to create legacy table: %v", err)
+		}
+	}
+	if err := legacy.Close(); err != nil {
+		t.Fatalf("Failed to close legacy database: %v", err)
+	}
+
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+
+	history, err := db.GetSweepHistory("address1", utils.NetworkMainnet)
+	if err != nil {
+		t.Fatalf("Failed to get sweep history: %v", err)
+	}
+	if len(history) != 1 || history[0].Amount != 1234_567_891_234 || history[0].Fee != 10_000_000 || history[0].OpId != "op1" {
+		t.Fatalf("Expected the amounts converted to nanoMAS, got %+v", history)
+	}
+
+	tx, err := db.(*dB).db.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil {
+			t.Fatalf("Failed to rollback transaction: %v", err)
+		}
+	}()
+
+	columns, err := realColumns(tx, "sweep_history")
+	if err != nil {
+		t.Fatalf("Failed to get columns: %v", err)
+	}
+	if len(columns) != 0 {
+		t.Fatalf("Expected no REAL column left, got %v", columns)
+	}
+
+	// the conversion leaves INTEGER columns untouched
+	if err := migrateAmountColumns(tx); err != nil {
+		t.Fatalf("Failed to migrate amount columns again: %v", err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "testdb.db")
+
+	for i := 0; i < 2; i++ {
+		db, err := NewDB(dbPath)
+		if err != nil {
+			t.Fatalf("Failed to create database: %v", err)
+		}
+
+		version, err := db.(*dB).schemaVersion()
+		if err != nil {
+			t.Fatalf("Failed to get schema version: %v", err)
+		}
+		if version != len(migrations) {
+			t.Fatalf("Expected schema version %d, got %d", len(migrations), version)
+		}
+
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}
+
+	// a new database has nothing to back up, and reopening it has nothing to migrate
+	backups, err := filepath.Glob(dbPath + ".*.bak")
+	if err != nil {
+		t.Fatalf("Failed to list backups: %v", err)
+	}
+	if len(backups) != 0 {
+		t.Fatalf("Expected no backup, got %v", backups)
+	}
+}
+
+func TestMigrateFailure(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "testdb.db")
+
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+
+	failing := append(slices.Clone(migrations), migration{
+		version:     len(migrations) + 1,
+		description: "fail halfway",
+		up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`CREATE TABLE half_migrated (id INTEGER)`); err != nil {
+				return err
+			}
+			return errors.New("migration failed")
+		},
+	})
+	if err := db.(*dB).migrate(failing); err == nil {
+		t.Fatalf("Expected the migration to fail")
+	}
+
+	// the failed migration is rolled back and not recorded
+	version, err := db.(*dB).schemaVersion()
+	if err != nil {
+		t.Fatalf("Failed to get schema version: %v", err)
+	}
+	if version != len(migrations) {
+		t.Fatalf("Expected schema version %d, got %d", len(migrations), version)
+	}
+	var tableCount int
+	if err := db.(*dB).db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'half_migrated'`).Scan(&tableCount); err != nil {
+		t.Fatalf("Failed to look for the table: %v", err)
+	}
+	if tableCount != 0 {
+		t.Fatalf("Expected the table of the failed migration to be rolled back")
+	}
+
+	// the database was backed up before migrating
+	backups, err := filepath.Glob(fmt.Sprintf("%s.v%d-*.bak", dbPath, len(migrations)))
+	if err != nil {
+		t.Fatalf("Failed to list backups: %v", err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("Expected one backup, got %v", backups)
+	}
+
+	// migrations must follow each other
+	if err := db.(*dB).migrate([]migration{{version: 2, description: "gap", up: createTables}}); err == nil {
+		t.Fatalf("Expected an error for a migration list not starting at version 1")
+	}
+}
+
+/*
+TestMigrateTestData migrates forward the databases of test_data: the committed SQL dumps of older installs
+and the databases generated by `task generate-test-data`.
+*/
+func TestMigrateTestData(t *testing.T) {
+	dumps, err := filepath.Glob(filepath.Join("test_data", "*.sql"))
+	if err != nil {
+		t.Fatalf("Failed to list SQL dumps: %v", err)
+	}
+	databases, err := filepath.Glob(filepath.Join("test_data", "*.db"))
+	if err != nil {
+		t.Fatalf("Failed to list databases: %v", err)
+	}
+	if len(dumps) == 0 {
+		t.Fatalf("Expected SQL dumps in test_data")
+	}
+
+	for _, source := range append(dumps, databases...) {
+		t.Run(filepath.Base(source), func(t *testing.T) {
+			dbPath := filepath.Join(t.TempDir(), "testdb.db")
+			content, err := os.ReadFile(source)
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", source, err)
+			}
+
+			if strings.HasSuffix(source, ".sql") {
+				legacy, err := sql.Open("sqlite3", dbPath)
+				if err != nil {
+					t.Fatalf("Failed to open legacy database: %v", err)
+				}
+				if _, err := legacy.Exec(string(content)); err != nil {
+					t.Fatalf("Failed to load %s: %v", source, err)
+				}
+				if err := legacy.Close(); err != nil {
+					t.Fatalf("Failed to close legacy database: %v", err)
+				}
+			} else if err := os.WriteFile(dbPath, content, 0o600); err != nil {
+				t.Fatalf("Failed to copy %s: %v", source, err)
+			}
+
+			db, err := NewDB(dbPath)
+			if err != nil {
+				t.Fatalf("Failed to migrate database: %v", err)
+			}
+			defer func() {
+				if err := db.Close(); err != nil {
+					t.Fatalf("Failed to close db connection: %v", err)
+				}
+			}()
+
+			version, err := db.(*dB).schemaVersion()
+			if err != nil {
+				t.Fatalf("Failed to get schema version: %v", err)
+			}
+			if version != len(migrations) {
+				t.Fatalf("Expected schema version %d, got %d", len(migrations), version)
+			}
+
+			tx, err := db.(*dB).db.Begin()
+			if err != nil {
+				t.Fatalf("Failed to begin transaction: %v", err)
+			}
+			for _, amountTable := range amountColumns {
+				columns, err := realColumns(tx, amountTable.table)
+				if err != nil {
+					t.Fatalf("Failed to get columns: %v", err)
+				}
+				if len(columns) != 0 {
+					t.Fatalf("Expected no REAL column left in %s, got %v", amountTable.table, columns)
+				}
+			}
+			if err := tx.Rollback(); err != nil {
+				t.Fatalf("Failed to rollback transaction: %v", err)
+			}
+
+			// the backup keeps the database as it was before the migrations
+			backups, err := filepath.Glob(dbPath + ".v0-*.bak")
+			if err != nil {
+				t.Fatalf("Failed to list backups: %v", err)
+			}
+			if len(backups) != 1 {
+				t.Fatalf("Expected one backup, got %v", backups)
+			}
+			backup, err := sql.Open("sqlite3", backups[0])
+			if err != nil {
+				t.Fatalf("Failed to open backup: %v", err)
+			}
+			defer func() {
+				if err := backup.Close(); err != nil {
+					t.Fatalf("Failed to close backup: %v", err)
+				}
+			}()
+
+			// the total values are the ones of the backup converted to nanoMAS
+			var migrated []massaAmount.Amount
+			if err := queryColumn(db.(*dB).db, `SELECT total_value FROM value_history WHERE series = 'STAKING' AND network = 'buildnet' ORDER BY timestamp`, &migrated); err != nil {
+				t.Fatalf("Failed to get value history: %v", err)
+			}
+			var original []float64
+			if err := queryColumn(backup, `SELECT total_value FROM value_history_buildnet ORDER BY timestamp`, &original); err != nil {
+				t.Fatalf("Failed to get backup value history: %v", err)
+			}
+			if len(migrated) == 0 || len(migrated) != len(original) {
+				t.Fatalf("Expected %d total values, got %d", len(original), len(migrated))
+			}
+			for i := range original {
+				if expected := massaAmount.FromMAS(original[i]); migrated[i] != expected {
+					t.Fatalf("Expected total value %d converted to %d nanoMAS, got %d", i, expected, migrated[i])
+				}
+			}
+
+			var backupType string
+			if err := backup.QueryRow(`SELECT type FROM pragma_table_info('value_history_buildnet') WHERE name = 'total_value'`).Scan(&backupType); err != nil {
+				t.Fatalf("Failed to get the backup column type: %v", err)
+			}
+			if backupType != "REAL" {
+				t.Fatalf("Expected the backup to keep the REAL column, got %s", backupType)
+			}
+		})
+	}
+}
+
+func TestMergeValueHistoryTables(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "testdb.db")
+
+	dump, err := os.ReadFile(filepath.Join("test_data", "legacy_amounts_in_mas.sql"))
+	if err != nil {
+		t.Fatalf("Failed to read SQL dump: %v", err)
+	}
+	legacy, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open legacy database: %v", err)
+	}
+	if _, err := legacy.Exec(string(dump)); err != nil {
+		t.Fatalf("Failed to load SQL dump: %v", err)
+	}
+	if err := legacy.Close(); err != nil {
+		t.Fatalf("Failed to close legacy database: %v", err)
+	}
+
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Failed to close db connection: %v", err)
+		}
+	}()
+
+	// the per-network and watch-only tables are merged into value_history
+	var tableCount int
+	if err := db.(*dB).db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'
+		AND name IN ('value_history_mainnet', 'value_history_buildnet', 'watch_only_value_history')`).Scan(&tableCount); err != nil {
+		t.Fatalf("Failed to look for the merged tables: %v", err)
+	}
+	if tableCount != 0 {
+		t.Fatalf("Expected the merged tables to be dropped, %d left", tableCount)
+	}
+
+	timestamp := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		get      func(since time.Time, network utils.Network) ([]ValueHistory, error)
+		network  utils.Network
+		expected []massaAmount.Amount
+	}{
+		{"buildnet staking", db.GetHistory, utils.NetworkBuildnet, []massaAmount.Amount{10000_123_456_789, 10000_200_000_000}},
+		{"mainnet staking", db.GetHistory, utils.NetworkMainnet, []massaAmount.Amount{25000_500_000_000}},
+		{"buildnet watch-only", db.GetWatchOnlyHistory, utils.NetworkBuildnet, []massaAmount.Amount{42_000_000_042}},
+		{"mainnet watch-only", db.GetWatchOnlyHistory, utils.NetworkMainnet, nil},
+	}
+	for _, tt := range tests {
+		histories, err := tt.get(time.Time{}, tt.network)
+		if err != nil {
+			t.Fatalf("Failed to get %s history: %v", tt.name, err)
+		}
+		if len(histories) != len(tt.expected) {
+			t.Fatalf("Expected %d %s values, got %d", len(tt.expected), tt.name, len(histories))
+		}
+		for i, history := range histories {
+			if history.TotalValue != tt.expected[i] {
+				t.Fatalf("Expected %s value %d to be %d, got %d", tt.name, i, tt.expected[i], history.TotalValue)
+			}
+		}
+		if len(histories) > 0 && !histories[0].Timestamp.Equal(timestamp) {
+			t.Fatalf("Expected the first %s value at %v, got %v", tt.name, timestamp, histories[0].Timestamp)
+		}
+	}
+
+	// the rollups of every merged series are built
+	rollups, err := db.GetValueRollups(ValueSeriesWatchOnly, RollupHourly, time.Time{}, utils.NetworkBuildnet)
+	if err != nil {
+		t.Fatalf("Failed to get rollups: %v", err)
+	}
+	if len(rollups) != 1 || rollups[0].Close != 42_000_000_042 {
+		t.Fatalf("Expected the watch-only value rolled up, got %+v", rollups)
+	}
+
+	// the roll count of the address values is derived from their roll value, and their rollups are built
+	records, err := db.GetAddressValueHistory(AddressHistoryFilter{Addresses: []string{"address1"}}, time.Time{}, time.Now(), utils.NetworkBuildnet)
+	if err != nil {
+		t.Fatalf("Failed to get address value history: %v", err)
+	}
+	if len(records) != 1 || records[0].Rolls != 99 {
+		t.Fatalf("Expected the address value to hold 99 rolls, got %+v", records)
+	}
+	addressRollups, err := db.GetAddressValueRollups(AddressHistoryFilter{}, RollupDaily, time.Time{}, utils.NetworkBuildnet)
+	if err != nil {
+		t.Fatalf("Failed to get address rollups: %v", err)
+	}
+	if len(addressRollups) != 1 || addressRollups[0].RollValue != 9900*massaAmount.MAS {
+		t.Fatalf("Expected the address value rolled up, got %+v", addressRollups)
+	}
+
+	// a network unknown to the schema has its own history
+	labnet := utils.Network("labnet")
+	if err := db.PostHistory(ValueHistory{Timestamp: time.Now(), TotalValue: 5 * massaAmount.MAS}, labnet); err != nil {
+		t.Fatalf("Failed to post labnet history: %v", err)
+	}
+	histories, err := db.GetHistory(time.Time{}, labnet)
+	if err != nil {
+		t.Fatalf("Failed to get labnet history: %v", err)
+	}
+	if len(histories) != 1 || histories[0].TotalValue != 5*massaAmount.MAS {
+		t.Fatalf("Expected the labnet value, got %+v", histories)
+	}
+}
+
+// queryColumn scans the single column returned by a query
+func queryColumn[T any](db *sql.DB, query string, values *[]T) error {
+	rows, err := db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var value T
+		if err := rows.Scan(&value); err != nil {
+			return err
+		}
+		*values = append(*values, value)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	logger "github.com/massalabs/station/pkg/logger"
+)
+
+// DecommissionStep is the current step of the decommissioning of a staking address
+type DecommissionStep string
+
+const (
+	DecommissionStepSellingRolls           DecommissionStep = "SELLING_ROLLS"            // rolls are sold, the address keeps staking until they are no longer active
+	DecommissionStepWaitingDeferredCredits DecommissionStep = "WAITING_DEFERRED_CREDITS" // waiting for the deferred credits to be paid out
+	DecommissionStepRemovingKey            DecommissionStep = "REMOVING_KEY"             // the key can be removed from the node
+	DecommissionStepArchivingHistory       DecommissionStep = "ARCHIVING_HISTORY"        // the roll operation history of the address is archived, then the decommission is deleted
+)
+
+// DecommissionSteps lists the decommission steps in the order they are performed
+var DecommissionSteps = []DecommissionStep{
+	DecommissionStepSellingRolls,
+	DecommissionStepWaitingDeferredCredits,
+	DecommissionStepRemovingKey,
+	DecommissionStepArchivingHistory,
+}
+
+// Decommission is the state of the decommissioning of a staking address
+type Decommission struct {
+	Address   string           `json:"address"`
+	Step      DecommissionStep `json:"step"`
+	StartedAt time.Time        `json:"started_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// AddDecommission starts the decommissioning of an address at the first step
+func (d *dB) AddDecommission(address string, network utils.Network) error {
+	query := `INSERT INTO decommissions (address, network, step, started_at, updated_at) VALUES (?, ?, ?, ?, ?)`
+
+	now := time.Now()
+	_, err := d.db.Exec(query, address, string(network), string(DecommissionStepSellingRolls), now, now)
+	if err != nil {
+		return fmt.Errorf("failed to add decommission of address %s: %w", address, err)
+	}
+
+	return nil
+}
+
+// UpdateDecommissionStep moves the decommissioning of an address to the given step
+func (d *dB) UpdateDecommissionStep(address string, step DecommissionStep, network utils.Network) error {
+	query := `UPDATE decommissions SET step = ?, updated_at = ? WHERE address = ? AND network = ?`
+
+	result, err := d.db.Exec(query, string(step), time.Now(), address, string(network))
+	if err != nil {
+		return fmt.Errorf("failed to update decommission step of address %s: %w", address, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, fmt.Sprintf("decommission of address %s (%s) not found in database", address, string(network)))
+	}
+
+	return nil
+}
+
+// GetDecommission retrieves the decommission of an address
+func (d *dB) GetDecommission(address string, network utils.Network) (Decommission, error) {
+	query := `SELECT address, step, started_at, updated_at FROM decommissions WHERE address = ? AND network = ?`
+
+	var decommission Decommission
+	err := d.db.QueryRow(query, address, string(network)).Scan(&decommission.Address, &decommission.Step, &decommission.StartedAt, &decommission.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Decommission{}, nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, fmt.Sprintf("decommission of address %s (%s) not found in database", address, string(network)))
+		}
+		return Decommission{}, fmt.Errorf("failed to query decommission of address %s: %w", address, err)
+	}
+
+	return decommission, nil
+}
+
+// GetDecommissions retrieves all the decommissions of a network, oldest first
+func (d *dB) GetDecommissions(network utils.Network) ([]Decommission, error) {
+	query := `SELECT address, step, started_at, updated_at FROM decommissions WHERE network = ? ORDER BY started_at ASC`
+
+	rows, err := d.db.Query(query, string(network))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query decommissions: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close decommissions rows: %v", err)
+		}
+	}()
+
+	var decommissions []Decommission
+	for rows.Next() {
+		var decommission Decommission
+		if err := rows.Scan(&decommission.Address, &decommission.Step, &decommission.StartedAt, &decommission.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan decommissions row: %w", err)
+		}
+		decommissions = append(decommissions, decommission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over decommissions rows: %w", err)
+	}
+
+	return decommissions, nil
+}
+
+// DeleteDecommission removes the decommission of an address once it is completed
+func (d *dB) DeleteDecommission(address string, network utils.Network) error {
+	query := `DELETE FROM decommissions WHERE address = ? AND network = ?`
+
+	result, err := d.db.Exec(query, address, string(network))
+	if err != nil {
+		return fmt.Errorf("failed to delete decommission of address %s: %w", address, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, fmt.Sprintf("decommission of address %s (%s) not found in database", address, string(network)))
+	}
+
+	return nil
+}
+
+// ArchiveRollOpHistoryByAddress moves the roll operation history of an address to the rolls_op_history_archive table
+func (d *dB) ArchiveRollOpHistoryByAddress(address string, network utils.Network) (err error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logger.Errorf("Failed to rollback roll operation history archiving: %v", rbErr)
+			}
+		}
+	}()
+
+	insertQuery := `
+	INSERT OR REPLACE INTO rolls_op_history_archive (address, op, amount, network, op_id, timestamp, archived_at)
+	SELECT address, op, amount, network, op_id, timestamp, ? FROM rolls_op_history WHERE address = ? AND network = ?`
+
+	result, err := tx.Exec(insertQuery, time.Now(), address, string(network))
+	if err != nil {
+		return fmt.Errorf("failed to copy roll operation history of address %s to archive: %w", address, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		err = nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, fmt.Sprintf("roll operation history for address %s (%s) not found in database", address, string(network)))
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM rolls_op_history WHERE address = ? AND network = ?`, address, string(network)); err != nil {
+		return fmt.Errorf("failed to delete archived roll operation history of address %s: %w", address, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit roll operation history archiving: %w", err)
+	}
+
+	return nil
+}
+
+// GetArchivedRollOpHistory retrieves the archived roll operation history of an address, newest first
+func (d *dB) GetArchivedRollOpHistory(address string, network utils.Network) ([]RollOpHistory, error) {
+	query := `SELECT op, amount, op_id, timestamp FROM rolls_op_history_archive WHERE address = ? AND network = ? ORDER BY timestamp DESC`
+
+	rows, err := d.db.Query(query, address, string(network))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query archived roll operation history: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close archived roll operation history rows: %v", err)
+		}
+	}()
+
+	var histories []RollOpHistory
+	for rows.Next() {
+		var history RollOpHistory
+		if err := rows.Scan(&history.Op, &history.Amount, &history.OpId, &history.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan archived roll operation history row: %w", err)
+		}
+		histories = append(histories, history)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over archived roll operation history rows: %w", err)
+	}
+
+	return histories, nil
+}
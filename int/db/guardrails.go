@@ -0,0 +1,72 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	logger "github.com/massalabs/station/pkg/logger"
+)
+
+// BlockedRollOp is a roll operation of a staking address that has not been sent because of a spending guardrail
+type BlockedRollOp struct {
+	Address   string    `json:"address"`
+	Op        RollOp    `json:"op"`
+	Amount    uint64    `json:"amount"` // rolls the roll engine wanted to buy or sell
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// AddBlockedRollOp records a blocked roll operation, the timestamp is set to now
+func (d *dB) AddBlockedRollOp(blocked BlockedRollOp, network utils.Network) error {
+	query := `INSERT INTO blocked_roll_ops (address, network, op, amount, reason, timestamp) VALUES (?, ?, ?, ?, ?, ?)`
+
+	_, err := d.db.Exec(query, blocked.Address, string(network), string(blocked.Op), blocked.Amount, blocked.Reason, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to insert blocked roll operation of address %s: %w", blocked.Address, err)
+	}
+
+	return nil
+}
+
+// GetBlockedRollOps retrieves the blocked roll operations of an address, newest first
+func (d *dB) GetBlockedRollOps(address string, network utils.Network) ([]BlockedRollOp, error) {
+	query := `SELECT address, op, amount, reason, timestamp FROM blocked_roll_ops WHERE address = ? AND network = ? ORDER BY timestamp DESC, id DESC`
+
+	rows, err := d.db.Query(query, address, string(network))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query blocked roll operations: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close blocked roll operations rows: %v", err)
+		}
+	}()
+
+	var blockedOps []BlockedRollOp
+	for rows.Next() {
+		var blocked BlockedRollOp
+		if err := rows.Scan(&blocked.Address, &blocked.Op, &blocked.Amount, &blocked.Reason, &blocked.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan blocked roll operations row: %w", err)
+		}
+		blockedOps = append(blockedOps, blocked)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over blocked roll operations rows: %w", err)
+	}
+
+	return blockedOps, nil
+}
+
+// GetRollsBoughtSince returns the number of rolls bought by all the addresses of a network since a given time
+func (d *dB) GetRollsBoughtSince(since time.Time, network utils.Network) (uint64, error) {
+	query := `SELECT COALESCE(SUM(amount), 0) FROM rolls_op_history WHERE network = ? AND op = ? AND timestamp >= ?`
+
+	var bought uint64
+	if err := d.db.QueryRow(query, string(network), string(RollOpBuy), since).Scan(&bought); err != nil {
+		return 0, fmt.Errorf("failed to query rolls bought since %s: %w", since.Format(time.RFC3339), err)
+	}
+
+	return bought, nil
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	logger "github.com/massalabs/station/pkg/logger"
+)
+
+// NodeStatusHistory is a status taken by the node, with the network it was running on
+type NodeStatusHistory struct {
+	Timestamp time.Time     `json:"timestamp"`
+	Status    string        `json:"status"`
+	Network   utils.Network `json:"network"`
+}
+
+// AddressEventKind is the kind of change of the monitored addresses recorded in the address events
+type AddressEventKind string
+
+const (
+	AddressEventAdded   AddressEventKind = "ADDED"
+	AddressEventRemoved AddressEventKind = "REMOVED"
+)
+
+// AddressEvent records an address being added to or removed from the staking or watch-only addresses
+type AddressEvent struct {
+	Timestamp time.Time        `json:"timestamp"`
+	Address   string           `json:"address"`
+	Event     AddressEventKind `json:"event"`
+	WatchOnly bool             `json:"watch_only"`
+}
+
+// AddressRollOpHistory is a roll operation history record with the address that performed it
+type AddressRollOpHistory struct {
+	Address string `json:"address"`
+	RollOpHistory
+}
+
+// AddNodeStatusHistory records a status taken by the node running on a specific network
+func (d *dB) AddNodeStatusHistory(status string, network utils.Network) error {
+	query := `INSERT INTO node_status_history (timestamp, status, network) VALUES (?, ?, ?)`
+
+	_, err := d.db.Exec(query, time.Now(), status, string(network))
+	if err != nil {
+		return fmt.Errorf("failed to insert node status history: %w", err)
+	}
+
+	return nil
+}
+
+/*
+GetNodeStatusHistory retrieves the statuses taken by the node on a specific network after a given timestamp, ordered chronologically.
+The last status taken before this timestamp, if any, comes first so that the status of the node at this timestamp is known.
+*/
+func (d *dB) GetNodeStatusHistory(since time.Time, network utils.Network) ([]NodeStatusHistory, error) {
+	query := `SELECT timestamp, status, network FROM (
+			SELECT timestamp, status, network FROM node_status_history WHERE network = ? AND timestamp <= ? ORDER BY timestamp DESC LIMIT 1
+		)
+		UNION ALL
+		SELECT timestamp, status, network FROM node_status_history WHERE network = ? AND timestamp > ?
+		ORDER BY timestamp ASC`
+
+	rows, err := d.db.Query(query, string(network), since, string(network), since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query node status history: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close node status history rows: %v", err)
+		}
+	}()
+
+	var histories []NodeStatusHistory
+	for rows.Next() {
+		var history NodeStatusHistory
+		var network string
+		if err := rows.Scan(&history.Timestamp, &history.Status, &network); err != nil {
+			return nil, fmt.Errorf("failed to scan node status history row: %w", err)
+		}
+		history.Network = utils.Network(network)
+		histories = append(histories, history)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over node status history rows: %w", err)
+	}
+
+	return histories, nil
+}
+
+// AddAddressEvent records an address being added to or removed from the staking or watch-only addresses of a specific network
+func (d *dB) AddAddressEvent(address string, event AddressEventKind, watchOnly bool, network utils.Network) error {
+	query := `INSERT INTO address_events (timestamp, network, address, event, watch_only) VALUES (?, ?, ?, ?, ?)`
+
+	_, err := d.db.Exec(query, time.Now(), string(network), address, string(event), watchOnly)
+	if err != nil {
+		return fmt.Errorf("failed to insert address event: %w", err)
+	}
+
+	return nil
+}
+
+// GetAddressEvents retrieves the address events of a specific network after a given timestamp, ordered chronologically
+func (d *dB) GetAddressEvents(since time.Time, network utils.Network) ([]AddressEvent, error) {
+	query := `SELECT timestamp, address, event, watch_only FROM address_events WHERE network = ? AND timestamp > ? ORDER BY timestamp ASC`
+
+	rows, err := d.db.Query(query, string(network), since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query address events: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close address events rows: %v", err)
+		}
+	}()
+
+	var events []AddressEvent
+	for rows.Next() {
+		var event AddressEvent
+		var kind string
+		if err := rows.Scan(&event.Timestamp, &event.Address, &kind, &event.WatchOnly); err != nil {
+			return nil, fmt.Errorf("failed to scan address events row: %w", err)
+		}
+		event.Event = AddressEventKind(kind)
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over address events rows: %w", err)
+	}
+
+	return events, nil
+}
+
+/*
+GetRollOpHistorySince retrieves the roll operations of every address of a specific network after a given timestamp,
+archived ones included, ordered chronologically
+*/
+func (d *dB) GetRollOpHistorySince(since time.Time, network utils.Network) ([]AddressRollOpHistory, error) {
+	query := `SELECT COALESCE(address, ''), op, amount, op_id, timestamp FROM rolls_op_history WHERE network = ? AND timestamp > ?
+		UNION ALL
+		SELECT COALESCE(address, ''), op, amount, op_id, timestamp FROM rolls_op_history_archive WHERE network = ? AND timestamp > ?
+		ORDER BY timestamp ASC`
+
+	rows, err := d.db.Query(query, string(network), since, string(network), since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query roll operation history: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close roll operation history rows: %v", err)
+		}
+	}()
+
+	var histories []AddressRollOpHistory
+	for rows.Next() {
+		var history AddressRollOpHistory
+		if err := rows.Scan(&history.Address, &history.Op, &history.Amount, &history.OpId, &history.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan roll operation history row: %w", err)
+		}
+		histories = append(histories, history)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over roll operation history rows: %w", err)
+	}
+
+	return histories, nil
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	logger "github.com/massalabs/station/pkg/logger"
+)
+
+// LedgerEntryKind is the cause of a balance change recorded in the ledger
+type LedgerEntryKind string
+
+const (
+	LedgerBlockReward           LedgerEntryKind = "BLOCK_REWARD"
+	LedgerEndorsementReward     LedgerEntryKind = "ENDORSEMENT_REWARD"
+	LedgerRollBuy               LedgerEntryKind = "ROLL_BUY"
+	LedgerRollSell              LedgerEntryKind = "ROLL_SELL"
+	LedgerDeferredCreditRelease LedgerEntryKind = "DEFERRED_CREDIT_RELEASE"
+	LedgerFee                   LedgerEntryKind = "FEE"
+	LedgerSweep                 LedgerEntryKind = "SWEEP"
+	LedgerExternalTransfer      LedgerEntryKind = "EXTERNAL_TRANSFER" // balance change that could not be explained
+)
+
+// LedgerEntry is a classified part of the balance change of an address between two polls
+type LedgerEntry struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Address   string             `json:"address"`
+	Cycle     uint64             `json:"cycle"` // cycle of the node at the time of the poll
+	Kind      LedgerEntryKind    `json:"kind"`
+	Amount    massaAmount.Amount `json:"amount"` // change of the final balance
+	Rolls     int64              `json:"rolls"`  // change of the final roll count
+	OpId      string             `json:"op_id"`  // operation sent by the plugin the entry comes from, if any
+}
+
+// AddLedgerEntries records the ledger entries of a specific network in a single transaction
+func (d *dB) AddLedgerEntries(entries []LedgerEntry, network utils.Network) (err error) {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logger.Errorf("failed to rollback ledger entries transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	query := `INSERT INTO ledger_entries (timestamp, network, address, cycle, kind, amount, rolls, op_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	for _, entry := range entries {
+		if _, err = tx.Exec(query, entry.Timestamp, string(network), entry.Address, entry.Cycle, string(entry.Kind), entry.Amount, entry.Rolls, entry.OpId); err != nil {
+			return fmt.Errorf("failed to insert ledger entry of address %s: %w", entry.Address, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit ledger entries: %w", err)
+	}
+
+	return nil
+}
+
+/*
+GetLedgerEntries retrieves the ledger entries of a specific network after a given timestamp, ordered chronologically.
+If address is empty, the entries of every address are returned.
+*/
+func (d *dB) GetLedgerEntries(address string, since time.Time, network utils.Network) ([]LedgerEntry, error) {
+	query := `SELECT timestamp, address, cycle, kind, amount, rolls, op_id FROM ledger_entries
+		WHERE network = ? AND timestamp > ? AND (? = '' OR address = ?)
+		ORDER BY timestamp ASC, id ASC`
+
+	rows, err := d.db.Query(query, string(network), since, address, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ledger entries: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close ledger entries rows: %v", err)
+		}
+	}()
+
+	var entries []LedgerEntry
+	for rows.Next() {
+		var entry LedgerEntry
+		var kind string
+		if err := rows.Scan(&entry.Timestamp, &entry.Address, &entry.Cycle, &kind, &entry.Amount, &entry.Rolls, &entry.OpId); err != nil {
+			return nil, fmt.Errorf("failed to scan ledger entries row: %w", err)
+		}
+		entry.Kind = LedgerEntryKind(kind)
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over ledger entries rows: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	logger "github.com/massalabs/station/pkg/logger"
+)
+
+// migration is a change of the database schema, applied once in its own transaction
+type migration struct {
+	version     int // versions start at 1 and follow each other
+	description string
+	up          func(tx *sql.Tx) error
+}
+
+/*
+migrations lists the schema changes in the order they are applied. A released migration never changes:
+a schema change is a new migration appended with the next version.
+*/
+var migrations = []migration{
+	{version: 1, description: "create the tables", up: createTables},
+	{version: 2, description: "store the amounts in nanoMAS", up: migrateAmountColumns},
+	{version: 3, description: "merge the value history tables of every network and series", up: mergeValueHistoryTables},
+	{version: 4, description: "roll up the address value history", up: createAddressValueRollups},
+	{version: 5, description: "record the roll count in the address value history", up: addRollsColumn},
+}
+
+/*
+migrate applies the migrations newer than the schema version of the database, each one in its own transaction
+recording its version in the schema_version table. The database is backed up first if it already has tables.
+*/
+func (d *dB) migrate(migrations []migration) error {
+	// Create schema_version table
+	schemaVersionTable := `
+	CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at DATETIME NOT NULL
+	);`
+
+	if _, err := d.db.Exec(schemaVersionTable); err != nil {
+		return fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+
+	version, err := d.schemaVersion()
+	if err != nil {
+		return err
+	}
+
+	var pending []migration
+	for i, migration := range migrations {
+		if migration.version != i+1 {
+			return fmt.Errorf("migration %q has version %d, expected %d", migration.description, migration.version, i+1)
+		}
+		if migration.version > version {
+			pending = append(pending, migration)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err := d.backup(version); err != nil {
+		return err
+	}
+
+	for _, migration := range pending {
+		if err := d.applyMigration(migration); err != nil {
+			return err
+		}
+		logger.Infof("Database migrated to version %d: %s", migration.version, migration.description)
+	}
+
+	return nil
+}
+
+// schemaVersion returns the version of the last migration applied to the database, 0 if none has been
+func (d *dB) schemaVersion() (int, error) {
+	var version int
+	if err := d.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+func (d *dB) applyMigration(migration migration) (err error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logger.Errorf("Failed to rollback migration %d: %v", migration.version, rbErr)
+			}
+		}
+	}()
+
+	if err = migration.up(tx); err != nil {
+		return fmt.Errorf("failed to apply migration %d (%s): %w", migration.version, migration.description, err)
+	}
+
+	if _, err = tx.Exec(`INSERT INTO schema_version (version, description, applied_at) VALUES (?, ?, ?)`,
+		migration.version, migration.description, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", migration.version, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", migration.version, err)
+	}
+
+	return nil
+}
+
+/*
+backup copies the database next to its file before it is migrated from the given version,
+so that it can be restored if a migration goes wrong. A database without tables has nothing to back up.
+*/
+func (d *dB) backup(version int) error {
+	var tableCount int
+	if err := d.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT IN ('schema_version', 'sqlite_sequence')`).Scan(&tableCount); err != nil {
+		return fmt.Errorf("failed to count tables: %w", err)
+	}
+	if tableCount == 0 {
+		return nil
+	}
+
+	backupPath := fmt.Sprintf("%s.v%d-%s.bak", d.path, version, time.Now().UTC().Format("20060102T150405"))
+	if _, err := d.db.Exec(`VACUUM INTO ?`, backupPath); err != nil {
+		return fmt.Errorf("failed to back up database to %s: %w", backupPath, err)
+	}
+	logger.Infof("Database backed up to %s before migrating from version %d", backupPath, version)
+
+	return nil
+}
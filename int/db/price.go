@@ -0,0 +1,46 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+)
+
+// priceDayLayout is the format of the days of the price cache
+const priceDayLayout = "2006-01-02"
+
+// GetPrice retrieves the cached price of a MAS in a currency on the UTC day of the given time
+func (d *dB) GetPrice(day time.Time, currency string) (float64, error) {
+	query := `SELECT price FROM price_cache WHERE day = ? AND currency = ?`
+
+	var price float64
+	err := d.db.QueryRow(query, day.UTC().Format(priceDayLayout), currency).Scan(&price)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, fmt.Sprintf("price in %s of %s not found in database", currency, day.UTC().Format(priceDayLayout)))
+		}
+		return 0, fmt.Errorf("failed to query price: %w", err)
+	}
+
+	return price, nil
+}
+
+// SetPrice caches the price of a MAS in a currency on the UTC day of the given time, replacing the cached one if any
+func (d *dB) SetPrice(day time.Time, currency string, price float64) error {
+	query := `
+	INSERT INTO price_cache (day, currency, price, fetched_at)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT (day, currency) DO UPDATE SET
+		price = excluded.price,
+		fetched_at = excluded.fetched_at`
+
+	_, err := d.db.Exec(query, day.UTC().Format(priceDayLayout), currency, price, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to cache price in %s of %s: %w", currency, day.UTC().Format(priceDayLayout), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	logger "github.com/massalabs/station/pkg/logger"
+)
+
+// RollScheduleAction is the change applied to the target rolls of an address when a schedule runs
+type RollScheduleAction string
+
+const (
+	RollScheduleSetTarget RollScheduleAction = "SET_TARGET" // the target rolls are set to Amount
+	RollScheduleBuy       RollScheduleAction = "BUY"        // the target rolls are raised by Amount
+	RollScheduleSell      RollScheduleAction = "SELL"       // the target rolls are lowered by Amount
+)
+
+// RollScheduleStatus is the state of a scheduled roll target change
+type RollScheduleStatus string
+
+const (
+	RollSchedulePending RollScheduleStatus = "PENDING"
+	RollScheduleDone    RollScheduleStatus = "DONE"
+	RollScheduleFailed  RollScheduleStatus = "FAILED"
+)
+
+// RollOpSetTarget is the roll history entry recorded when a scheduled change has been applied, its amount is the new target
+const RollOpSetTarget RollOp = "TARGET"
+
+/*
+RollSchedule is a change of the target rolls of an address scheduled at a date (RunAt)
+or at the start of a cycle (Cycle). Exactly one of them is set.
+*/
+type RollSchedule struct {
+	ID         int64              `json:"id"`
+	Address    string             `json:"address"`
+	Action     RollScheduleAction `json:"action"`
+	Amount     uint64             `json:"amount"`
+	RunAt      *time.Time         `json:"run_at,omitempty"`
+	Cycle      *uint64            `json:"cycle,omitempty"`
+	Status     RollScheduleStatus `json:"status"`
+	Error      string             `json:"error,omitempty"` // why the schedule failed
+	CreatedAt  time.Time          `json:"created_at"`
+	ExecutedAt *time.Time         `json:"executed_at,omitempty"`
+}
+
+// RollScheduleHistoryOpId is the op id of the roll history entry recorded when a schedule has run
+func RollScheduleHistoryOpId(id int64) string {
+	return fmt.Sprintf("schedule-%d", id)
+}
+
+// AddRollSchedule adds a pending roll schedule and returns its id
+func (d *dB) AddRollSchedule(schedule RollSchedule, network utils.Network) (int64, error) {
+	query := `INSERT INTO roll_schedules (address, network, action, amount, run_at, cycle, status, error, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, '', ?)`
+
+	result, err := d.db.Exec(query, schedule.Address, string(network), string(schedule.Action), schedule.Amount, schedule.RunAt, schedule.Cycle, string(RollSchedulePending), time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to add roll schedule of address %s: %w", schedule.Address, err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get id of roll schedule: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateRollSchedule changes the action, amount and trigger of a pending roll schedule
+func (d *dB) UpdateRollSchedule(schedule RollSchedule, network utils.Network) error {
+	query := `UPDATE roll_schedules SET action = ?, amount = ?, run_at = ?, cycle = ? WHERE id = ? AND network = ? AND status = ?`
+
+	result, err := d.db.Exec(query, string(schedule.Action), schedule.Amount, schedule.RunAt, schedule.Cycle, schedule.ID, string(network), string(RollSchedulePending))
+	if err != nil {
+		return fmt.Errorf("failed to update roll schedule %d: %w", schedule.ID, err)
+	}
+
+	return checkRollScheduleAffected(result, schedule.ID, network)
+}
+
+// SetRollScheduleStatus marks a pending roll schedule as run, errMsg is empty unless it failed
+func (d *dB) SetRollScheduleStatus(id int64, status RollScheduleStatus, errMsg string, network utils.Network) error {
+	query := `UPDATE roll_schedules SET status = ?, error = ?, executed_at = ? WHERE id = ? AND network = ? AND status = ?`
+
+	result, err := d.db.Exec(query, string(status), errMsg, time.Now(), id, string(network), string(RollSchedulePending))
+	if err != nil {
+		return fmt.Errorf("failed to set status of roll schedule %d: %w", id, err)
+	}
+
+	return checkRollScheduleAffected(result, id, network)
+}
+
+// DeleteRollSchedule cancels a pending roll schedule
+func (d *dB) DeleteRollSchedule(id int64, network utils.Network) error {
+	query := `DELETE FROM roll_schedules WHERE id = ? AND network = ? AND status = ?`
+
+	result, err := d.db.Exec(query, id, string(network), string(RollSchedulePending))
+	if err != nil {
+		return fmt.Errorf("failed to delete roll schedule %d: %w", id, err)
+	}
+
+	return checkRollScheduleAffected(result, id, network)
+}
+
+// GetRollSchedule retrieves a roll schedule by id
+func (d *dB) GetRollSchedule(id int64, network utils.Network) (RollSchedule, error) {
+	query := `SELECT id, address, action, amount, run_at, cycle, status, error, created_at, executed_at FROM roll_schedules WHERE id = ? AND network = ?`
+
+	schedule, err := scanRollSchedule(d.db.QueryRow(query, id, string(network)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return RollSchedule{}, nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, fmt.Sprintf("roll schedule %d (%s) not found in database", id, string(network)))
+		}
+		return RollSchedule{}, fmt.Errorf("failed to query roll schedule %d: %w", id, err)
+	}
+
+	return schedule, nil
+}
+
+// GetRollSchedules retrieves all the roll schedules of a network, oldest first
+func (d *dB) GetRollSchedules(network utils.Network) ([]RollSchedule, error) {
+	query := `SELECT id, address, action, amount, run_at, cycle, status, error, created_at, executed_at FROM roll_schedules WHERE network = ? ORDER BY id ASC`
+
+	rows, err := d.db.Query(query, string(network))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query roll schedules: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close roll schedules rows: %v", err)
+		}
+	}()
+
+	var schedules []RollSchedule
+	for rows.Next() {
+		schedule, err := scanRollSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan roll schedules row: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over roll schedules rows: %w", err)
+	}
+
+	return schedules, nil
+}
+
+func scanRollSchedule(row interface{ Scan(dest ...any) error }) (RollSchedule, error) {
+	var schedule RollSchedule
+	var runAt, executedAt sql.NullTime
+	var cycle sql.NullInt64
+
+	err := row.Scan(&schedule.ID, &schedule.Address, &schedule.Action, &schedule.Amount, &runAt, &cycle, &schedule.Status, &schedule.Error, &schedule.CreatedAt, &executedAt)
+	if err != nil {
+		return RollSchedule{}, err
+	}
+
+	if runAt.Valid {
+		schedule.RunAt = &runAt.Time
+	}
+	if cycle.Valid {
+		c := uint64(cycle.Int64)
+		schedule.Cycle = &c
+	}
+	if executedAt.Valid {
+		schedule.ExecutedAt = &executedAt.Time
+	}
+
+	return schedule, nil
+}
+
+func checkRollScheduleAffected(result sql.Result, id int64, network utils.Network) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, fmt.Sprintf("pending roll schedule %d (%s) not found in database", id, string(network)))
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	logger "github.com/massalabs/station/pkg/logger"
+)
+
+/*
+SweepRule moves the MAS piling up on a staking address to a cold address.
+When the final balance of the address goes above Threshold + Reserve, everything above Reserve
+(minus the fees) is sent to ColdAddress. In dry run mode, the sweep is only recorded in the history.
+*/
+type SweepRule struct {
+	Address     string             `json:"address"`
+	ColdAddress string             `json:"cold_address"`
+	Threshold   massaAmount.Amount `json:"threshold"` // minimum amount to sweep
+	Reserve     massaAmount.Amount `json:"reserve"`   // amount kept on the staking address
+	DryRun      bool               `json:"dry_run"`
+}
+
+// SweepHistory is a sweep sent, or simulated in dry run mode, for a staking address
+type SweepHistory struct {
+	Address     string             `json:"address"`
+	ColdAddress string             `json:"cold_address"`
+	Amount      massaAmount.Amount `json:"amount"`
+	Fee         massaAmount.Amount `json:"fee"`
+	OpId        string             `json:"op_id"` // empty for dry runs
+	DryRun      bool               `json:"dry_run"`
+	Timestamp   time.Time          `json:"timestamp"`
+}
+
+// SetSweepRule adds the sweep rule of an address or replaces the existing one
+func (d *dB) SetSweepRule(rule SweepRule, network utils.Network) error {
+	query := `
+	INSERT INTO sweep_rules (address, network, cold_address, threshold, reserve, dry_run)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT (address, network) DO UPDATE SET
+		cold_address = excluded.cold_address,
+		threshold = excluded.threshold,
+		reserve = excluded.reserve,
+		dry_run = excluded.dry_run`
+
+	_, err := d.db.Exec(query, rule.Address, string(network), rule.ColdAddress, rule.Threshold, rule.Reserve, rule.DryRun)
+	if err != nil {
+		return fmt.Errorf("failed to set sweep rule of address %s: %w", rule.Address, err)
+	}
+
+	return nil
+}
+
+// GetSweepRule retrieves the sweep rule of an address
+func (d *dB) GetSweepRule(address string, network utils.Network) (SweepRule, error) {
+	query := `SELECT address, cold_address, threshold, reserve, dry_run FROM sweep_rules WHERE address = ? AND network = ?`
+
+	var rule SweepRule
+	err := d.db.QueryRow(query, address, string(network)).Scan(&rule.Address, &rule.ColdAddress, &rule.Threshold, &rule.Reserve, &rule.DryRun)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return SweepRule{}, nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, fmt.Sprintf("sweep rule of address %s (%s) not found in database", address, string(network)))
+		}
+		return SweepRule{}, fmt.Errorf("failed to query sweep rule of address %s: %w", address, err)
+	}
+
+	return rule, nil
+}
+
+// GetSweepRules retrieves all the sweep rules of a network
+func (d *dB) GetSweepRules(network utils.Network) ([]SweepRule, error) {
+	query := `SELECT address, cold_address, threshold, reserve, dry_run FROM sweep_rules WHERE network = ? ORDER BY address`
+
+	rows, err := d.db.Query(query, string(network))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sweep rules: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close sweep rules rows: %v", err)
+		}
+	}()
+
+	var rules []SweepRule
+	for rows.Next() {
+		var rule SweepRule
+		if err := rows.Scan(&rule.Address, &rule.ColdAddress, &rule.Threshold, &rule.Reserve, &rule.DryRun); err != nil {
+			return nil, fmt.Errorf("failed to scan sweep rules row: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over sweep rules rows: %w", err)
+	}
+
+	return rules, nil
+}
+
+// DeleteSweepRule removes the sweep rule of an address
+func (d *dB) DeleteSweepRule(address string, network utils.Network) error {
+	query := `DELETE FROM sweep_rules WHERE address = ? AND network = ?`
+
+	result, err := d.db.Exec(query, address, string(network))
+	if err != nil {
+		return fmt.Errorf("failed to delete sweep rule of address %s: %w", address, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, fmt.Sprintf("sweep rule of address %s (%s) not found in database", address, string(network)))
+	}
+
+	return nil
+}
+
+// AddSweepHistory records a sweep of an address, the timestamp is set to now
+func (d *dB) AddSweepHistory(sweep SweepHistory, network utils.Network) error {
+	query := `INSERT INTO sweep_history (address, network, cold_address, amount, fee, op_id, dry_run, timestamp) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := d.db.Exec(query, sweep.Address, string(network), sweep.ColdAddress, sweep.Amount, sweep.Fee, sweep.OpId, sweep.DryRun, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to insert sweep history of address %s: %w", sweep.Address, err)
+	}
+
+	return nil
+}
+
+// GetSweepHistory retrieves the sweeps of an address, newest first
+func (d *dB) GetSweepHistory(address string, network utils.Network) ([]SweepHistory, error) {
+	query := `SELECT address, cold_address, amount, fee, op_id, dry_run, timestamp FROM sweep_history WHERE address = ? AND network = ? ORDER BY timestamp DESC, id DESC`
+
+	rows, err := d.db.Query(query, address, string(network))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sweep history: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close sweep history rows: %v", err)
+		}
+	}()
+
+	var histories []SweepHistory
+	for rows.Next() {
+		var history SweepHistory
+		if err := rows.Scan(&history.Address, &history.ColdAddress, &history.Amount, &history.Fee, &history.OpId, &history.DryRun, &history.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan sweep history row: %w", err)
+		}
+		histories = append(histories, history)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over sweep history rows: %w", err)
+	}
+
+	return histories, nil
+}
+
+// GetSweepHistorySince retrieves the sweeps of every address of a specific network after a given timestamp, ordered chronologically
+func (d *dB) GetSweepHistorySince(since time.Time, network utils.Network) ([]SweepHistory, error) {
+	query := `SELECT address, cold_address, amount, fee, op_id, dry_run, timestamp FROM sweep_history WHERE network = ? AND timestamp > ? ORDER BY timestamp ASC, id ASC`
+
+	rows, err := d.db.Query(query, string(network), since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sweep history: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close sweep history rows: %v", err)
+		}
+	}()
+
+	var histories []SweepHistory
+	for rows.Next() {
+		var history SweepHistory
+		if err := rows.Scan(&history.Address, &history.ColdAddress, &history.Amount, &history.Fee, &history.OpId, &history.DryRun, &history.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan sweep history row: %w", err)
+		}
+		histories = append(histories, history)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over sweep history rows: %w", err)
+	}
+
+	return histories, nil
+}
@@ -0,0 +1,230 @@
+-- Database of an install from before the versioned migrations, with the amounts stored in MAS in REAL columns.
+-- TestMigrateTestData migrates it forward to the latest schema version.
+
+CREATE TABLE IF NOT EXISTS value_history_mainnet (
+	timestamp DATETIME PRIMARY KEY,
+	total_value REAL NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS value_history_buildnet (
+	timestamp DATETIME PRIMARY KEY,
+	total_value REAL NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS rolls_target (
+	address TEXT,
+	roll_target INTEGER NOT NULL,
+	network TEXT NOT NULL,
+	PRIMARY KEY (address, network)
+);
+
+CREATE TABLE IF NOT EXISTS rolls_op_history (
+	address TEXT,
+	op TEXT NOT NULL,
+	amount INTEGER NOT NULL,
+	network TEXT NOT NULL,
+	op_id TEXT NOT NULL,
+	timestamp DATETIME NOT NULL,
+	PRIMARY KEY (op_id, network)
+);
+
+CREATE TABLE IF NOT EXISTS cycle_stats (
+	address TEXT NOT NULL,
+	network TEXT NOT NULL,
+	cycle INTEGER NOT NULL,
+	ok_count INTEGER NOT NULL,
+	nok_count INTEGER NOT NULL,
+	active_rolls INTEGER NOT NULL,
+	is_final BOOLEAN NOT NULL,
+	PRIMARY KEY (address, network, cycle)
+);
+
+CREATE TABLE IF NOT EXISTS decommissions (
+	address TEXT NOT NULL,
+	network TEXT NOT NULL,
+	step TEXT NOT NULL,
+	started_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	PRIMARY KEY (address, network)
+);
+
+CREATE TABLE IF NOT EXISTS rolls_op_history_archive (
+	address TEXT,
+	op TEXT NOT NULL,
+	amount INTEGER NOT NULL,
+	network TEXT NOT NULL,
+	op_id TEXT NOT NULL,
+	timestamp DATETIME NOT NULL,
+	archived_at DATETIME NOT NULL,
+	PRIMARY KEY (op_id, network)
+);
+
+CREATE TABLE IF NOT EXISTS sweep_rules (
+	address TEXT NOT NULL,
+	network TEXT NOT NULL,
+	cold_address TEXT NOT NULL,
+	threshold REAL NOT NULL,
+	reserve REAL NOT NULL,
+	dry_run BOOLEAN NOT NULL,
+	PRIMARY KEY (address, network)
+);
+
+CREATE TABLE IF NOT EXISTS sweep_history (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	address TEXT NOT NULL,
+	network TEXT NOT NULL,
+	cold_address TEXT NOT NULL,
+	amount REAL NOT NULL,
+	fee REAL NOT NULL,
+	op_id TEXT NOT NULL,
+	dry_run BOOLEAN NOT NULL,
+	timestamp DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS roll_schedules (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	address TEXT NOT NULL,
+	network TEXT NOT NULL,
+	action TEXT NOT NULL,
+	amount INTEGER NOT NULL,
+	run_at DATETIME,
+	cycle INTEGER,
+	status TEXT NOT NULL,
+	error TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	executed_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS blocked_roll_ops (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	address TEXT NOT NULL,
+	network TEXT NOT NULL,
+	op TEXT NOT NULL,
+	amount INTEGER NOT NULL,
+	reason TEXT NOT NULL,
+	timestamp DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS automation_pauses (
+	address TEXT NOT NULL,
+	network TEXT NOT NULL,
+	paused_at DATETIME NOT NULL,
+	PRIMARY KEY (address, network)
+);
+
+CREATE TABLE IF NOT EXISTS watch_only_addresses (
+	address TEXT NOT NULL,
+	network TEXT NOT NULL,
+	added_at DATETIME NOT NULL,
+	PRIMARY KEY (address, network)
+);
+
+CREATE TABLE IF NOT EXISTS watch_only_value_history (
+	timestamp DATETIME NOT NULL,
+	network TEXT NOT NULL,
+	total_value REAL NOT NULL,
+	PRIMARY KEY (timestamp, network)
+);
+
+CREATE TABLE IF NOT EXISTS address_labels (
+	address TEXT NOT NULL,
+	network TEXT NOT NULL,
+	label TEXT NOT NULL,
+	tags TEXT NOT NULL,
+	group_name TEXT NOT NULL,
+	PRIMARY KEY (address, network)
+);
+
+CREATE TABLE IF NOT EXISTS address_value_history (
+	timestamp DATETIME NOT NULL,
+	network TEXT NOT NULL,
+	address TEXT NOT NULL,
+	watch_only BOOLEAN NOT NULL,
+	balance REAL NOT NULL,
+	roll_value REAL NOT NULL,
+	deferred REAL NOT NULL,
+	PRIMARY KEY (timestamp, network, address)
+);
+
+CREATE INDEX IF NOT EXISTS idx_address_value_history_address ON address_value_history (network, address, timestamp);
+
+CREATE TABLE IF NOT EXISTS value_history_hourly (
+	series TEXT NOT NULL,
+	network TEXT NOT NULL,
+	bucket_start DATETIME NOT NULL,
+	open REAL NOT NULL,
+	close REAL NOT NULL,
+	min REAL NOT NULL,
+	max REAL NOT NULL,
+	avg REAL NOT NULL,
+	count INTEGER NOT NULL,
+	PRIMARY KEY (series, network, bucket_start)
+);
+
+CREATE TABLE IF NOT EXISTS value_history_daily (
+	series TEXT NOT NULL,
+	network TEXT NOT NULL,
+	bucket_start DATETIME NOT NULL,
+	open REAL NOT NULL,
+	close REAL NOT NULL,
+	min REAL NOT NULL,
+	max REAL NOT NULL,
+	avg REAL NOT NULL,
+	count INTEGER NOT NULL,
+	PRIMARY KEY (series, network, bucket_start)
+);
+
+CREATE TABLE IF NOT EXISTS node_status_history (
+	timestamp DATETIME NOT NULL,
+	status TEXT NOT NULL,
+	network TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS address_events (
+	timestamp DATETIME NOT NULL,
+	network TEXT NOT NULL,
+	address TEXT NOT NULL,
+	event TEXT NOT NULL,
+	watch_only BOOLEAN NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp DATETIME NOT NULL,
+	network TEXT NOT NULL,
+	address TEXT NOT NULL,
+	cycle INTEGER NOT NULL,
+	kind TEXT NOT NULL,
+	amount REAL NOT NULL,
+	rolls INTEGER NOT NULL,
+	op_id TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_address ON ledger_entries (network, address, timestamp);
+
+CREATE TABLE IF NOT EXISTS price_cache (
+	day TEXT NOT NULL,
+	currency TEXT NOT NULL,
+	price REAL NOT NULL,
+	fetched_at DATETIME NOT NULL,
+	PRIMARY KEY (day, currency)
+);
+
+INSERT INTO value_history_buildnet (timestamp, total_value) VALUES
+	('2025-01-01T00:00:00Z', 10000.123456789),
+	('2025-01-01T00:03:00Z', 10000.2);
+
+INSERT INTO value_history_mainnet (timestamp, total_value) VALUES
+	('2025-01-01T00:00:00Z', 25000.5);
+
+INSERT INTO watch_only_value_history (timestamp, network, total_value) VALUES
+	('2025-01-01T00:00:00Z', 'buildnet', 42.000000042);
+
+INSERT INTO sweep_rules (address, network, cold_address, threshold, reserve, dry_run) VALUES
+	('address1', 'buildnet', 'cold1', 1500, 0.5, false);
+
+INSERT INTO address_value_history (timestamp, network, address, watch_only, balance, roll_value, deferred) VALUES
+	('2025-01-01T00:00:00Z', 'buildnet', 'address1', false, 99.999999999, 9900, 0.1);
+
+INSERT INTO ledger_entries (timestamp, network, address, cycle, kind, amount, rolls, op_id) VALUES
+	('2025-01-01T00:00:00Z', 'buildnet', 'address1', 42, 'reward', 1.000000001, 0, '');
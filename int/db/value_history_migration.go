@@ -0,0 +1,62 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/massalabs/node-manager-plugin/int/utils"
+)
+
+/*
+mergeValueHistoryTables moves the total values of the per-network value_history_<network> tables and of the
+watch_only_value_history table into the value_history table, where each value belongs to a series and a network,
+so that a network is supported without a schema change.
+*/
+func mergeValueHistoryTables(tx *sql.Tx) error {
+	// Create value_history table
+	valueHistoryTable := `
+	CREATE TABLE value_history (
+		series TEXT NOT NULL,
+		network TEXT NOT NULL,
+		timestamp DATETIME NOT NULL,
+		total_value INTEGER NOT NULL,
+		PRIMARY KEY (series, network, timestamp)
+	);`
+
+	// Index used by the deletion of the old values of every series
+	valueHistoryTimestampIndex := `
+	CREATE INDEX idx_value_history_timestamp ON value_history (timestamp);`
+
+	if _, err := tx.Exec(valueHistoryTable); err != nil {
+		return fmt.Errorf("failed to create value_history table: %w", err)
+	}
+
+	if _, err := tx.Exec(valueHistoryTimestampIndex); err != nil {
+		return fmt.Errorf("failed to create value_history timestamp index: %w", err)
+	}
+
+	for _, network := range []utils.Network{utils.NetworkMainnet, utils.NetworkBuildnet} {
+		table := "value_history_" + string(network)
+		query := fmt.Sprintf(`INSERT INTO value_history (series, network, timestamp, total_value)
+			SELECT ?, ?, timestamp, total_value FROM %s`, table)
+		if _, err := tx.Exec(query, string(ValueSeriesStaking), string(network)); err != nil {
+			return fmt.Errorf("failed to move %s to value_history: %w", table, err)
+		}
+
+		if _, err := tx.Exec(fmt.Sprintf(`DROP TABLE %s`, table)); err != nil {
+			return fmt.Errorf("failed to drop %s table: %w", table, err)
+		}
+	}
+
+	query := `INSERT INTO value_history (series, network, timestamp, total_value)
+		SELECT ?, network, timestamp, total_value FROM watch_only_value_history`
+	if _, err := tx.Exec(query, string(ValueSeriesWatchOnly)); err != nil {
+		return fmt.Errorf("failed to move watch_only_value_history to value_history: %w", err)
+	}
+
+	if _, err := tx.Exec(`DROP TABLE watch_only_value_history`); err != nil {
+		return fmt.Errorf("failed to drop watch_only_value_history table: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,267 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	massaAmount "github.com/massalabs/node-manager-plugin/int/massa-amount"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	logger "github.com/massalabs/station/pkg/logger"
+)
+
+// ValueSeries is a total value history series that is rolled up
+type ValueSeries string
+
+const (
+	ValueSeriesStaking   ValueSeries = "STAKING"    // total value of the staking addresses
+	ValueSeriesWatchOnly ValueSeries = "WATCH_ONLY" // total value of the watch-only addresses
+)
+
+// RollupResolution is the duration of the buckets of a rollup table
+type RollupResolution string
+
+const (
+	RollupHourly RollupResolution = "HOURLY"
+	RollupDaily  RollupResolution = "DAILY"
+)
+
+// rollupResolutions lists the rollup tables maintained for each value series
+var rollupResolutions = []RollupResolution{RollupHourly, RollupDaily}
+
+/*
+ValueRollup summarizes the values of a series recorded during a bucket: the first and last values,
+the lowest and highest ones and their average
+*/
+type ValueRollup struct {
+	BucketStart time.Time          `json:"bucket_start"`
+	Open        massaAmount.Amount `json:"open"`
+	Close       massaAmount.Amount `json:"close"`
+	Min         massaAmount.Amount `json:"min"`
+	Max         massaAmount.Amount `json:"max"`
+	Avg         massaAmount.Amount `json:"avg"`   // rounded to the nanoMAS
+	Count       uint64             `json:"count"` // number of values recorded in the bucket
+}
+
+// Duration returns the duration of the buckets of the resolution
+func (r RollupResolution) Duration() time.Duration {
+	if r == RollupDaily {
+		return 24 * time.Hour
+	}
+	return time.Hour
+}
+
+// BucketStart returns the start of the bucket of the resolution containing t, buckets are aligned on UTC
+func (r RollupResolution) BucketStart(t time.Time) time.Time {
+	return t.UTC().Truncate(r.Duration())
+}
+
+func (r RollupResolution) table() string {
+	if r == RollupDaily {
+		return "value_history_daily"
+	}
+	return "value_history_hourly"
+}
+
+// GetValueRollups retrieves the buckets of a value series starting from a given timestamp, ordered chronologically
+func (d *dB) GetValueRollups(series ValueSeries, resolution RollupResolution, since time.Time, network utils.Network) ([]ValueRollup, error) {
+	query := fmt.Sprintf(`SELECT bucket_start, open, close, min, max, avg, count FROM %s
+		WHERE series = ? AND network = ? AND bucket_start >= ? ORDER BY bucket_start ASC`, resolution.table())
+
+	rows, err := d.db.Query(query, string(series), string(network), since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", resolution.table(), err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close %s rows: %v", resolution.table(), err)
+		}
+	}()
+
+	var rollups []ValueRollup
+	for rows.Next() {
+		var rollup ValueRollup
+		if err := rows.Scan(&rollup.BucketStart, &rollup.Open, &rollup.Close, &rollup.Min, &rollup.Max, &rollup.Avg, &rollup.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan %s row: %w", resolution.table(), err)
+		}
+		rollups = append(rollups, rollup)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over %s rows: %w", resolution.table(), err)
+	}
+
+	return rollups, nil
+}
+
+/*
+postValue records a value of a series in the value_history table and adds it to the buckets of the rollup tables, in a single transaction.
+Values are expected in chronological order: the last value added to a bucket becomes its close value.
+*/
+func (d *dB) postValue(series ValueSeries, history ValueHistory, network utils.Network) (err error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logger.Errorf("Failed to rollback value history insertion: %v", rbErr)
+			}
+		}
+	}()
+
+	query := `INSERT INTO value_history (series, network, timestamp, total_value) VALUES (?, ?, ?, ?)`
+	if _, err = tx.Exec(query, string(series), string(network), history.Timestamp, history.TotalValue); err != nil {
+		return err
+	}
+
+	if err = addToRollups(tx, series, history, network); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit value history: %w", err)
+	}
+
+	return nil
+}
+
+// getValues retrieves the values of a series recorded after a given timestamp for a specific network, ordered chronologically
+func (d *dB) getValues(series ValueSeries, since time.Time, network utils.Network) ([]ValueHistory, error) {
+	query := `SELECT timestamp, total_value FROM value_history WHERE series = ? AND network = ? AND timestamp > ? ORDER BY timestamp ASC`
+
+	rows, err := d.db.Query(query, string(series), string(network), since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query value_history: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close value_history rows: %v", err)
+		}
+	}()
+
+	var histories []ValueHistory
+	for rows.Next() {
+		var history ValueHistory
+		if err := rows.Scan(&history.Timestamp, &history.TotalValue); err != nil {
+			return nil, fmt.Errorf("failed to scan value_history row: %w", err)
+		}
+		histories = append(histories, history)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over value_history rows: %w", err)
+	}
+
+	return histories, nil
+}
+
+// addToRollups adds a value of a series to the buckets containing it in every rollup table
+func addToRollups(tx *sql.Tx, series ValueSeries, history ValueHistory, network utils.Network) error {
+	for _, resolution := range rollupResolutions {
+		query := fmt.Sprintf(`INSERT INTO %s (series, network, bucket_start, open, close, min, max, avg, count) VALUES (?, ?, ?, ?, ?, ?, ?, ?, 1)
+			ON CONFLICT (series, network, bucket_start) DO UPDATE SET
+				close = excluded.close,
+				min = MIN(min, excluded.min),
+				max = MAX(max, excluded.max),
+				avg = CAST(ROUND((avg * 1.0 * count + excluded.avg) / (count + 1)) AS INTEGER),
+				count = count + 1`, resolution.table())
+
+		value := history.TotalValue
+		bucketStart := resolution.BucketStart(history.Timestamp)
+		if _, err := tx.Exec(query, string(series), string(network), bucketStart, value, value, value, value, value); err != nil {
+			return fmt.Errorf("failed to update %s: %w", resolution.table(), err)
+		}
+	}
+
+	return nil
+}
+
+/*
+backfillValueRollups builds the rollups of the series that have raw values but no rollup yet,
+which happens the first time the plugin runs with the rollup tables
+*/
+func (d *dB) backfillValueRollups() error {
+	pending, err := d.seriesWithoutRollups()
+	if err != nil {
+		return err
+	}
+
+	for _, seriesNetwork := range pending {
+		histories, err := d.getValues(seriesNetwork.series, time.Time{}, seriesNetwork.network)
+		if err != nil {
+			return err
+		}
+
+		if err := d.addHistoriesToRollups(seriesNetwork.series, histories, seriesNetwork.network); err != nil {
+			return err
+		}
+
+		logger.Infof("Value history rollups of %s (%s) built from %d values", seriesNetwork.series, string(seriesNetwork.network), len(histories))
+	}
+
+	return nil
+}
+
+type seriesNetwork struct {
+	series  ValueSeries
+	network utils.Network
+}
+
+// seriesWithoutRollups returns the series and networks having values in the value_history table but no hourly rollup
+func (d *dB) seriesWithoutRollups() ([]seriesNetwork, error) {
+	query := fmt.Sprintf(`SELECT DISTINCT series, network FROM value_history v
+		WHERE NOT EXISTS (SELECT 1 FROM %s r WHERE r.series = v.series AND r.network = v.network)`, RollupHourly.table())
+
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query series without rollups: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close series without rollups rows: %v", err)
+		}
+	}()
+
+	var pending []seriesNetwork
+	for rows.Next() {
+		var series, network string
+		if err := rows.Scan(&series, &network); err != nil {
+			return nil, fmt.Errorf("failed to scan series without rollups row: %w", err)
+		}
+		pending = append(pending, seriesNetwork{series: ValueSeries(series), network: utils.Network(network)})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over series without rollups rows: %w", err)
+	}
+
+	return pending, nil
+}
+
+func (d *dB) addHistoriesToRollups(series ValueSeries, histories []ValueHistory, network utils.Network) (err error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logger.Errorf("Failed to rollback value history rollups backfill: %v", rbErr)
+			}
+		}
+	}()
+
+	for _, history := range histories {
+		if err = addToRollups(tx, series, history, network); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit value history rollups backfill: %w", err)
+	}
+
+	return nil
+}
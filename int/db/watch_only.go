@@ -0,0 +1,93 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	"github.com/massalabs/node-manager-plugin/int/utils"
+	logger "github.com/massalabs/station/pkg/logger"
+)
+
+// WatchOnlyAddress is an address monitored by the plugin without being staked by the node, its rolls are never bought or sold
+type WatchOnlyAddress struct {
+	Address string    `json:"address"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// AddWatchOnlyAddress adds a watch-only address for a specific network
+func (d *dB) AddWatchOnlyAddress(address string, network utils.Network) error {
+	query := `INSERT INTO watch_only_addresses (address, network, added_at) VALUES (?, ?, ?)`
+
+	_, err := d.db.Exec(query, address, string(network), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to add watch-only address %q: %w", address, err)
+	}
+
+	return nil
+}
+
+// DeleteWatchOnlyAddress deletes a watch-only address of a specific network
+func (d *dB) DeleteWatchOnlyAddress(address string, network utils.Network) error {
+	query := `DELETE FROM watch_only_addresses WHERE address = ? AND network = ?`
+
+	result, err := d.db.Exec(query, address, string(network))
+	if err != nil {
+		return fmt.Errorf("failed to delete watch-only address %q: %w", address, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, fmt.Sprintf("watch-only address %q (%s) not found in database", address, string(network)))
+	}
+
+	return nil
+}
+
+// GetWatchOnlyAddresses retrieves the watch-only addresses of a specific network, ordered by address
+func (d *dB) GetWatchOnlyAddresses(network utils.Network) ([]WatchOnlyAddress, error) {
+	query := `SELECT address, added_at FROM watch_only_addresses WHERE network = ? ORDER BY address`
+
+	rows, err := d.db.Query(query, string(network))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watch-only addresses: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("Failed to close watch-only addresses rows: %v", err)
+		}
+	}()
+
+	var addresses []WatchOnlyAddress
+	for rows.Next() {
+		var address WatchOnlyAddress
+		if err := rows.Scan(&address.Address, &address.AddedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan watch-only addresses row: %w", err)
+		}
+		addresses = append(addresses, address)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over watch-only addresses rows: %w", err)
+	}
+
+	return addresses, nil
+}
+
+// PostWatchOnlyHistory adds a value history record of the watch-only addresses of a specific network
+func (d *dB) PostWatchOnlyHistory(history ValueHistory, network utils.Network) error {
+	if err := d.postValue(ValueSeriesWatchOnly, history, network); err != nil {
+		return fmt.Errorf("failed to insert watch-only value history: %w", err)
+	}
+
+	return nil
+}
+
+// GetWatchOnlyHistory retrieves the value history records of the watch-only addresses after a given timestamp for a specific network, ordered chronologically
+func (d *dB) GetWatchOnlyHistory(since time.Time, network utils.Network) ([]ValueHistory, error) {
+	return d.getValues(ValueSeriesWatchOnly, since, network)
+}
@@ -16,6 +16,12 @@ const (
 
 	// Staking Manager
 	ErrStakingManagerPendingOperationNotCompleted NodeManagerErrorCode = "STAKING_MANAGER_PENDING_OPERATION_NOT_COMPLETED"
+	ErrStakingManagerRollOperationBlocked         NodeManagerErrorCode = "STAKING_MANAGER_ROLL_OPERATION_BLOCKED"
+	ErrStakingManagerAutomationPaused             NodeManagerErrorCode = "STAKING_MANAGER_AUTOMATION_PAUSED"
+
+	// Price provider
+	ErrPriceNotFound            NodeManagerErrorCode = "PRICE_NOT_FOUND"
+	ErrPriceSourceNotConfigured NodeManagerErrorCode = "PRICE_SOURCE_NOT_CONFIGURED"
 )
 
 // NodeManagerError represents a structured error in the node manager
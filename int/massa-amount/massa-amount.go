@@ -0,0 +1,163 @@
+package massaAmount
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+Amount is an amount of MAS counted in nanoMAS, the smallest unit of the massa protocol, so that sums and
+differences of amounts are exact. It is negative for balance decreases.
+*/
+type Amount int64
+
+const (
+	NanoMAS Amount = 1
+	MAS     Amount = 1_000_000_000
+
+	// decimals is the number of decimals of an amount of MAS written with nanoMAS precision
+	decimals = 9
+)
+
+/*
+Parse reads an amount of MAS written in decimal, as returned by the node api, without rounding:
+it fails if the amount has more than 9 decimals or doesn't fit in an Amount.
+*/
+func Parse(s string) (Amount, error) {
+	text := strings.TrimSpace(s)
+	negative := false
+	if strings.HasPrefix(text, "-") || strings.HasPrefix(text, "+") {
+		negative = text[0] == '-'
+		text = text[1:]
+	}
+
+	integerPart, fractionalPart, _ := strings.Cut(text, ".")
+	if integerPart == "" && fractionalPart == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if len(fractionalPart) > decimals {
+		// trailing zeros don't change the amount
+		if strings.TrimRight(fractionalPart[decimals:], "0") != "" {
+			return 0, fmt.Errorf("invalid amount %q: more than %d decimals", s, decimals)
+		}
+		fractionalPart = fractionalPart[:decimals]
+	}
+	for _, part := range []string{integerPart, fractionalPart} {
+		if strings.IndexFunc(part, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+	}
+
+	nano, err := strconv.ParseInt(integerPart+fractionalPart+strings.Repeat("0", decimals-len(fractionalPart)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", s, err)
+	}
+	if negative {
+		nano = -nano
+	}
+	return Amount(nano), nil
+}
+
+// FromMAS converts a floating point amount of MAS, rounded to the nanoMAS
+func FromMAS(mas float64) Amount {
+	return Amount(math.Round(mas * float64(MAS)))
+}
+
+// MAS returns the amount in MAS as a floating point number, for ratios and charts which don't need the exact amount
+func (a Amount) MAS() float64 {
+	return float64(a) / float64(MAS)
+}
+
+// NanoMAS returns the amount in nanoMAS, the unit of the serialized operations. It fails for negative amounts.
+func (a Amount) NanoMAS() (uint64, error) {
+	if a < 0 {
+		return 0, fmt.Errorf("invalid amount %s MAS: negative", a)
+	}
+	return uint64(a), nil
+}
+
+// Fixed writes the amount in MAS with its 9 decimals
+func (a Amount) Fixed() string {
+	sign := ""
+	nano := uint64(a)
+	if a < 0 {
+		sign = "-"
+		nano = uint64(-a)
+	}
+	return fmt.Sprintf("%s%d.%09d", sign, nano/uint64(MAS), nano%uint64(MAS))
+}
+
+// String writes the amount in MAS without its trailing zeros, as accepted by the massa client
+func (a Amount) String() string {
+	return strings.TrimSuffix(strings.TrimRight(a.Fixed(), "0"), ".")
+}
+
+// MarshalJSON writes the amount as a JSON number of MAS
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON reads an amount of MAS written as a JSON number or string
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	amount, err := Parse(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+// MarshalYAML writes the amount as a string of MAS, so that it is read back without rounding
+func (a Amount) MarshalYAML() (interface{}, error) {
+	return a.String(), nil
+}
+
+// UnmarshalYAML reads an amount of MAS written as a YAML number or string
+func (a *Amount) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var text string
+	if err := unmarshal(&text); err != nil {
+		return err
+	}
+	amount, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+// Value stores the amount in nanoMAS in an INTEGER column
+func (a Amount) Value() (driver.Value, error) {
+	return int64(a), nil
+}
+
+// Scan reads an amount stored in nanoMAS, sums and averages of amounts computed by sqlite may be floating point numbers
+func (a *Amount) Scan(src any) error {
+	switch value := src.(type) {
+	case nil:
+		*a = 0
+	case int64:
+		*a = Amount(value)
+	case float64:
+		*a = Amount(math.Round(value))
+	case []byte:
+		return a.scanText(string(value))
+	case string:
+		return a.scanText(value)
+	default:
+		return fmt.Errorf("cannot scan %T into an amount", src)
+	}
+	return nil
+}
+
+func (a *Amount) scanText(text string) error {
+	nano, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return fmt.Errorf("cannot scan %q into an amount: %w", text, err)
+	}
+	*a = Amount(nano)
+	return nil
+}
@@ -0,0 +1,142 @@
+package massaAmount
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input         string
+		expected      Amount
+		expectedError bool
+	}{
+		{input: "0", expected: 0},
+		{input: "1", expected: MAS},
+		{input: "0.01", expected: 10_000_000},
+		{input: ".5", expected: 500_000_000},
+		{input: "12.", expected: 12 * MAS},
+		{input: "1234.567891234", expected: 1234_567_891_234},
+		{input: "0.000000001", expected: NanoMAS},
+		{input: "0.1000000000", expected: 100_000_000},
+		{input: "-3.25", expected: -3_250_000_000},
+		{input: " 7 ", expected: 7 * MAS},
+		{input: "0.0000000001", expectedError: true},
+		{input: "", expectedError: true},
+		{input: ".", expectedError: true},
+		{input: "1e3", expectedError: true},
+		{input: "1.2.3", expectedError: true},
+		{input: "10000000000", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			amount, err := Parse(tt.input)
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, amount)
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		amount        Amount
+		expected      string
+		expectedFixed string
+	}{
+		{amount: 0, expected: "0", expectedFixed: "0.000000000"},
+		{amount: 10_000_000, expected: "0.01", expectedFixed: "0.010000000"},
+		{amount: 100 * MAS, expected: "100", expectedFixed: "100.000000000"},
+		{amount: 1234_567_891_234, expected: "1234.567891234", expectedFixed: "1234.567891234"},
+		{amount: -NanoMAS, expected: "-0.000000001", expectedFixed: "-0.000000001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.amount.String())
+			assert.Equal(t, tt.expectedFixed, tt.amount.Fixed())
+
+			parsed, err := Parse(tt.amount.String())
+			require.NoError(t, err)
+			assert.Equal(t, tt.amount, parsed)
+		})
+	}
+}
+
+func TestFromMAS(t *testing.T) {
+	assert.Equal(t, 10_000_000*NanoMAS, FromMAS(float64(float32(0.01))))
+	assert.Equal(t, 300_000_000*NanoMAS, FromMAS(0.1+0.2))
+	assert.Equal(t, -MAS, FromMAS(-1))
+	assert.Equal(t, 0.01, (10_000_000 * NanoMAS).MAS())
+}
+
+func TestNanoMAS(t *testing.T) {
+	nano, err := (2 * MAS).NanoMAS()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2_000_000_000), nano)
+
+	_, err = (-MAS).NanoMAS()
+	assert.Error(t, err)
+}
+
+func TestJSON(t *testing.T) {
+	type payload struct {
+		Amount Amount `json:"amount"`
+	}
+
+	content, err := json.Marshal(payload{Amount: 1234_567_891_234})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount": 1234.567891234}`, string(content))
+
+	var decoded payload
+	require.NoError(t, json.Unmarshal([]byte(`{"amount": 0.1}`), &decoded))
+	assert.Equal(t, 100_000_000*NanoMAS, decoded.Amount)
+	require.NoError(t, json.Unmarshal([]byte(`{"amount": "2.5"}`), &decoded))
+	assert.Equal(t, 2_500_000_000*NanoMAS, decoded.Amount)
+	assert.Error(t, json.Unmarshal([]byte(`{"amount": 1e-10}`), &decoded))
+}
+
+func TestYAML(t *testing.T) {
+	type payload struct {
+		Amount Amount `yaml:"amount"`
+	}
+
+	content, err := yaml.Marshal(payload{Amount: 1234_567_891_234})
+	require.NoError(t, err)
+
+	var decoded payload
+	require.NoError(t, yaml.Unmarshal(content, &decoded))
+	assert.Equal(t, Amount(1234_567_891_234), decoded.Amount)
+	require.NoError(t, yaml.Unmarshal([]byte(`amount: 1000`), &decoded))
+	assert.Equal(t, 1000*MAS, decoded.Amount)
+	require.NoError(t, yaml.Unmarshal([]byte(`amount: 0.1`), &decoded))
+	assert.Equal(t, 100_000_000*NanoMAS, decoded.Amount)
+	assert.Error(t, yaml.Unmarshal([]byte(`amount: 1e-10`), &decoded))
+	assert.Error(t, yaml.Unmarshal([]byte(`amount: ten`), &decoded))
+}
+
+func TestScan(t *testing.T) {
+	var amount Amount
+	require.NoError(t, amount.Scan(int64(42)))
+	assert.Equal(t, Amount(42), amount)
+	require.NoError(t, amount.Scan(41.6))
+	assert.Equal(t, Amount(42), amount)
+	require.NoError(t, amount.Scan([]byte("7")))
+	assert.Equal(t, Amount(7), amount)
+	require.NoError(t, amount.Scan(nil))
+	assert.Equal(t, Amount(0), amount)
+	assert.Error(t, amount.Scan(true))
+
+	value, err := Amount(42).Value()
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), value)
+}
@@ -0,0 +1,197 @@
+package massaKeys
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// AccountFileVersion is the version of the account file format of the massa wallet plugin
+	AccountFileVersion = 1
+
+	saltSize        = 16
+	nonceSize       = 12
+	pbkdf2Iteration = 600_000
+	aesKeySize      = 32
+)
+
+/*
+AccountFile is an encrypted account file, in the same format as the ones of the massa wallet plugin.
+The secret key is encrypted with AES-GCM using a key derived from the password with PBKDF2-SHA256.
+*/
+type AccountFile struct {
+	Version      uint8     `yaml:"Version"`
+	Nickname     string    `yaml:"Nickname"`
+	Address      string    `yaml:"Address"`
+	Salt         yamlBytes `yaml:"Salt"`
+	Nonce        yamlBytes `yaml:"Nonce"`
+	CipheredData yamlBytes `yaml:"CipheredData"`
+	PublicKey    yamlBytes `yaml:"PublicKey"`
+}
+
+// yamlBytes is a byte slice written as a list of integers, which can also be read from a base64 string
+type yamlBytes []byte
+
+func (b yamlBytes) MarshalYAML() (interface{}, error) {
+	ints := make([]int, len(b))
+	for i, v := range b {
+		ints[i] = int(v)
+	}
+	return ints, nil
+}
+
+func (b *yamlBytes) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var ints []int
+	if err := unmarshal(&ints); err == nil {
+		data := make([]byte, len(ints))
+		for i, v := range ints {
+			if v < 0 || v > 255 {
+				return fmt.Errorf("invalid byte value %d", v)
+			}
+			data[i] = byte(v)
+		}
+		*b = data
+		return nil
+	}
+
+	var encoded string
+	if err := unmarshal(&encoded); err != nil {
+		return fmt.Errorf("bytes must be a list of integers or a base64 string: %w", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("invalid base64 bytes: %w", err)
+	}
+	*b = data
+	return nil
+}
+
+// ParseAccountFile parses the content of an account file
+func ParseAccountFile(content []byte) (*AccountFile, error) {
+	var accountFile AccountFile
+	if err := yaml.Unmarshal(content, &accountFile); err != nil {
+		return nil, fmt.Errorf("failed to parse account file: %w", err)
+	}
+
+	if accountFile.Version != AccountFileVersion {
+		return nil, fmt.Errorf("unsupported account file version %d", accountFile.Version)
+	}
+
+	if len(accountFile.Salt) != saltSize || len(accountFile.Nonce) != nonceSize || len(accountFile.CipheredData) == 0 {
+		return nil, fmt.Errorf("account file is missing its salt, nonce or ciphered data")
+	}
+
+	return &accountFile, nil
+}
+
+// EncryptAccount creates an account file holding the key pair encrypted with the password
+func EncryptAccount(nickname, password string, keyPair *KeyPair) (*AccountFile, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	aead, err := newAEAD(password, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountFile{
+		Version:      AccountFileVersion,
+		Nickname:     nickname,
+		Address:      keyPair.Address(),
+		Salt:         salt,
+		Nonce:        nonce,
+		CipheredData: aead.Seal(nil, nonce, keyPair.SecretKeyBytes(), nil),
+		PublicKey:    keyPair.PublicKeyBytes(),
+	}, nil
+}
+
+// Decrypt decrypts the secret key of the account with the password and checks it matches the address of the file
+func (a *AccountFile) Decrypt(password string) (*KeyPair, error) {
+	seed, err := a.DecryptSeed(password)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeyPairFromSeed(seed)
+}
+
+/*
+DecryptSeed decrypts the 32 bytes ed25519 seed of the account with the password and checks it matches the address of the file.
+The caller owns the returned slice and should wipe it, for example by moving it to a memguard enclave.
+*/
+func (a *AccountFile) DecryptSeed(password string) ([]byte, error) {
+	aead, err := newAEAD(password, a.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	secretKey, err := aead.Open(nil, a.Nonce, a.CipheredData, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt account %s, the password is probably wrong: %w", a.Nickname, err)
+	}
+
+	// older files store the raw seed, newer ones prefix it with the key version
+	seed := secretKey
+	if len(secretKey) != ed25519.SeedSize {
+		if seed, err = trimVersion(secretKey); err != nil {
+			return nil, fmt.Errorf("invalid secret key in account %s: %w", a.Nickname, err)
+		}
+	}
+
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid secret key in account %s: expected %d bytes, got %d", a.Nickname, ed25519.SeedSize, len(seed))
+	}
+
+	if a.Address != "" {
+		publicKey := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+		if AddressFromPublicKey(publicKey) != a.Address {
+			return nil, fmt.Errorf("the secret key of account %s does not match its address %s", a.Nickname, a.Address)
+		}
+	}
+
+	return seed, nil
+}
+
+// Marshal returns the yaml content of the account file
+func (a *AccountFile) Marshal() ([]byte, error) {
+	content, err := yaml.Marshal(a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal account file: %w", err)
+	}
+	return content, nil
+}
+
+func newAEAD(password string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, password, salt, pbkdf2Iteration, aesKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive encryption key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES-GCM: %w", err)
+	}
+
+	return aead, nil
+}
@@ -0,0 +1,99 @@
+package massaKeys
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixturePassword = "fixture_password"
+
+func readFixture(t *testing.T, name string) []byte {
+	content, err := os.ReadFile(filepath.Join("test_data", name))
+	require.NoError(t, err)
+	return content
+}
+
+func TestAccountFileDecrypt(t *testing.T) {
+	tests := []struct {
+		name          string
+		fixture       string
+		password      string
+		expectedError string
+	}{
+		{name: "wallet plugin file", fixture: "wallet_fixture.yaml", password: fixturePassword},
+		{name: "base64 encoded file with raw seed", fixture: "wallet_fixture_base64.yaml", password: fixturePassword},
+		{name: "wrong password", fixture: "wallet_fixture.yaml", password: "wrong_password", expectedError: "the password is probably wrong"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accountFile, err := ParseAccountFile(readFixture(t, tt.fixture))
+			require.NoError(t, err)
+			assert.Equal(t, fixtureAddress, accountFile.Address)
+
+			keyPair, err := accountFile.Decrypt(tt.password)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, fixtureSecretKey, keyPair.SecretKey())
+			assert.Equal(t, fixtureAddress, keyPair.Address())
+		})
+	}
+}
+
+func TestParseAccountFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{name: "not yaml", content: "\t- [", expectedError: "failed to parse account file"},
+		{name: "unsupported version", content: "Version: 2\nNickname: test", expectedError: "unsupported account file version 2"},
+		{name: "missing ciphered data", content: "Version: 1\nNickname: test\nSalt: qLZL9a92FRvPs/I1/lfpdw==\nNonce: xcMauJ8r/pmPsZqY", expectedError: "missing its salt, nonce or ciphered data"},
+		{name: "invalid byte", content: "Version: 1\nSalt: [256]", expectedError: "invalid byte value 256"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseAccountFile([]byte(tt.content))
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestAccountFileDetectsAddressMismatch(t *testing.T) {
+	accountFile, err := ParseAccountFile(readFixture(t, "wallet_fixture.yaml"))
+	require.NoError(t, err)
+
+	accountFile.Address = "AU12oLjNkH8ywGaeqWuSE1CxdWLhG7hsCW8zZgasax1Csn3tW1mni"
+	_, err = accountFile.Decrypt(fixturePassword)
+	assert.ErrorContains(t, err, "does not match its address")
+}
+
+func TestEncryptAccountRoundTrip(t *testing.T) {
+	keyPair, err := NewKeyPairFromSeed(fixtureSeed)
+	require.NoError(t, err)
+
+	accountFile, err := EncryptAccount("round_trip", "pwd", keyPair)
+	require.NoError(t, err)
+
+	content, err := accountFile.Marshal()
+	require.NoError(t, err)
+
+	parsed, err := ParseAccountFile(content)
+	require.NoError(t, err)
+	assert.Equal(t, "round_trip", parsed.Nickname)
+	assert.Equal(t, fixtureAddress, parsed.Address)
+	assert.Equal(t, keyPair.PublicKeyBytes(), []byte(parsed.PublicKey))
+
+	decrypted, err := parsed.Decrypt("pwd")
+	require.NoError(t, err)
+	assert.Equal(t, keyPair.Seed(), decrypted.Seed())
+}
@@ -0,0 +1,230 @@
+package massaKeys
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"lukechampine.com/blake3"
+)
+
+const (
+	secretKeyPrefix   = "S"
+	publicKeyPrefix   = "P"
+	userAddressPrefix = "AU"
+	scAddressPrefix   = "AS"
+
+	// address categories, serialized as a single byte varint before the versioned hash of an address
+	userAddressCategory byte = 0
+	scAddressCategory   byte = 1
+
+	// keyVersion is the only version of keys and user addresses currently used by Massa, serialized as a single byte varint
+	keyVersion byte = 0
+
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	checksumLength = 4
+
+	// addressHashLength is the length of the blake3 hash of the public key in an address
+	addressHashLength = 32
+)
+
+/*
+KeyPair is a Massa ed25519 key pair.
+Its text representations follow the massa node format: a prefix followed by the base58check
+encoding of the version byte and the key (or the blake3 hash of the public key for the address).
+*/
+type KeyPair struct {
+	privateKey ed25519.PrivateKey
+}
+
+// NewKeyPairFromSeed creates a key pair from a 32 bytes ed25519 seed
+func NewKeyPairFromSeed(seed []byte) (*KeyPair, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid secret key length: expected %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+
+	return &KeyPair{privateKey: ed25519.NewKeyFromSeed(seed)}, nil
+}
+
+// ParseSecretKey creates a key pair from a secret key in the massa text format (S1...)
+func ParseSecretKey(secretKey string) (*KeyPair, error) {
+	secretKey = strings.TrimSpace(secretKey)
+	if !strings.HasPrefix(secretKey, secretKeyPrefix) {
+		return nil, fmt.Errorf("invalid secret key: must start with %s", secretKeyPrefix)
+	}
+
+	decoded, err := decodeBase58Check(strings.TrimPrefix(secretKey, secretKeyPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret key: %w", err)
+	}
+
+	seed, err := trimVersion(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret key: %w", err)
+	}
+
+	return NewKeyPairFromSeed(seed)
+}
+
+// Seed returns the 32 bytes ed25519 seed of the key pair
+func (k *KeyPair) Seed() []byte {
+	return k.privateKey.Seed()
+}
+
+// SecretKey returns the secret key in the massa text format (S1...)
+func (k *KeyPair) SecretKey() string {
+	return secretKeyPrefix + EncodeBase58Check(k.SecretKeyBytes())
+}
+
+// SecretKeyBytes returns the versioned secret key bytes
+func (k *KeyPair) SecretKeyBytes() []byte {
+	return append([]byte{keyVersion}, k.Seed()...)
+}
+
+// PublicKey returns the public key in the massa text format (P1...)
+func (k *KeyPair) PublicKey() string {
+	return PublicKeyText(k.privateKey.Public().(ed25519.PublicKey))
+}
+
+// PublicKeyBytes returns the versioned public key bytes
+func (k *KeyPair) PublicKeyBytes() []byte {
+	return PublicKeyBytes(k.privateKey.Public().(ed25519.PublicKey))
+}
+
+// Address returns the user address (AU...) derived from the public key
+func (k *KeyPair) Address() string {
+	return AddressFromPublicKey(k.privateKey.Public().(ed25519.PublicKey))
+}
+
+// PublicKeyBytes returns the versioned bytes of an ed25519 public key, as hashed and signed by massa
+func PublicKeyBytes(publicKey ed25519.PublicKey) []byte {
+	return append([]byte{keyVersion}, publicKey...)
+}
+
+// PublicKeyText returns an ed25519 public key in the massa text format (P1...)
+func PublicKeyText(publicKey ed25519.PublicKey) string {
+	return publicKeyPrefix + EncodeBase58Check(PublicKeyBytes(publicKey))
+}
+
+// AddressFromPublicKey returns the user address (AU...) of an ed25519 public key
+func AddressFromPublicKey(publicKey ed25519.PublicKey) string {
+	hash := blake3.Sum256(PublicKeyBytes(publicKey))
+	return userAddressPrefix + EncodeBase58Check(append([]byte{keyVersion}, hash[:]...))
+}
+
+// SignatureText returns an ed25519 signature in the massa text format
+func SignatureText(signature []byte) string {
+	return EncodeBase58Check(append([]byte{keyVersion}, signature...))
+}
+
+// AddressBytes returns the binary form of an address (AU... or AS...) as serialized in operations: category, version and hash
+func AddressBytes(address string) ([]byte, error) {
+	var category byte
+	switch {
+	case strings.HasPrefix(address, userAddressPrefix):
+		category = userAddressCategory
+	case strings.HasPrefix(address, scAddressPrefix):
+		category = scAddressCategory
+	default:
+		return nil, fmt.Errorf("invalid address %s: must start with %s or %s", address, userAddressPrefix, scAddressPrefix)
+	}
+
+	decoded, err := decodeBase58Check(address[len(userAddressPrefix):])
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+
+	hash, err := trimVersion(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+
+	if len(hash) != addressHashLength {
+		return nil, fmt.Errorf("invalid address %s: expected a %d bytes hash, got %d", address, addressHashLength, len(hash))
+	}
+
+	return append([]byte{category}, decoded...), nil
+}
+
+// trimVersion checks the version byte of a serialized key and removes it
+func trimVersion(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty key")
+	}
+
+	if data[0] != keyVersion {
+		return nil, fmt.Errorf("unsupported key version %d", data[0])
+	}
+
+	return data[1:], nil
+}
+
+// EncodeBase58Check encodes data in base58 followed by the first 4 bytes of its double sha256 as a checksum
+func EncodeBase58Check(data []byte) string {
+	firstHash := sha256.Sum256(data)
+	secondHash := sha256.Sum256(firstHash[:])
+	payload := append(append([]byte{}, data...), secondHash[:checksumLength]...)
+
+	num := new(big.Int).SetBytes(payload)
+	base := big.NewInt(int64(len(base58Alphabet)))
+	mod := new(big.Int)
+
+	encoded := []byte{}
+	for num.Sign() > 0 {
+		num.DivMod(num, base, mod)
+		encoded = append(encoded, base58Alphabet[mod.Int64()])
+	}
+
+	// each leading zero byte is encoded as the first character of the alphabet
+	for _, b := range payload {
+		if b != 0 {
+			break
+		}
+		encoded = append(encoded, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+
+	return string(encoded)
+}
+
+// decodeBase58Check decodes a base58check string and verifies its checksum
+func decodeBase58Check(encoded string) ([]byte, error) {
+	num := new(big.Int)
+	base := big.NewInt(int64(len(base58Alphabet)))
+	for _, c := range encoded {
+		index := strings.IndexRune(base58Alphabet, c)
+		if index < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", c)
+		}
+		num.Mul(num, base)
+		num.Add(num, big.NewInt(int64(index)))
+	}
+
+	leadingZeros := 0
+	for _, c := range encoded {
+		if c != rune(base58Alphabet[0]) {
+			break
+		}
+		leadingZeros++
+	}
+
+	payload := append(make([]byte, leadingZeros), num.Bytes()...)
+	if len(payload) < checksumLength {
+		return nil, fmt.Errorf("base58check data too short")
+	}
+
+	data := payload[:len(payload)-checksumLength]
+	firstHash := sha256.Sum256(data)
+	secondHash := sha256.Sum256(firstHash[:])
+	if !bytes.Equal(secondHash[:checksumLength], payload[len(payload)-checksumLength:]) {
+		return nil, fmt.Errorf("invalid base58check checksum")
+	}
+
+	return data, nil
+}
@@ -0,0 +1,118 @@
+package massaKeys
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"lukechampine.com/blake3"
+)
+
+// key pair of the test_data account files, generated from a seed made of 32 bytes 0x2a
+const (
+	fixtureSecretKey = "S1Ka3NKcnfs8d67EZYU5mbTCVY7Znnd2YQAYjbBfb4XmeYfTma5"
+	fixturePublicKey = "P1CEJhsumzSCQnC1T1P91MqXVSAB5qfSmyVPKJpn4tYFKfhoqdP"
+	fixtureAddress   = "AU1KBGdEiu8zYtJ79ouX1Jv7HsWMgXRKK6yWgt93joD2HLQ9euFp"
+)
+
+var fixtureSeed = bytes.Repeat([]byte{0x2a}, 32)
+
+func TestNewKeyPairFromSeed(t *testing.T) {
+	keyPair, err := NewKeyPairFromSeed(fixtureSeed)
+	require.NoError(t, err)
+
+	assert.Equal(t, fixtureSecretKey, keyPair.SecretKey())
+	assert.Equal(t, fixturePublicKey, keyPair.PublicKey())
+	assert.Equal(t, fixtureAddress, keyPair.Address())
+	assert.Equal(t, fixtureSeed, keyPair.Seed())
+	assert.Len(t, keyPair.SecretKeyBytes(), 33)
+	assert.Len(t, keyPair.PublicKeyBytes(), 33)
+
+	_, err = NewKeyPairFromSeed(fixtureSeed[:31])
+	assert.ErrorContains(t, err, "invalid secret key length")
+}
+
+func TestParseSecretKey(t *testing.T) {
+	tests := []struct {
+		name            string
+		secretKey       string
+		expectedAddress string
+		expectedError   string
+	}{
+		{name: "valid secret key", secretKey: fixtureSecretKey, expectedAddress: fixtureAddress},
+		{name: "surrounding spaces are ignored", secretKey: "  " + fixtureSecretKey + "\n", expectedAddress: fixtureAddress},
+		{name: "wrong prefix", secretKey: "P" + fixtureSecretKey[1:], expectedError: "must start with S"},
+		{name: "public key", secretKey: fixturePublicKey, expectedError: "must start with S"},
+		{name: "invalid character", secretKey: "S1Ka3NKcnfs8d67EZYU5mbTCVY7Znnd2YQAYjbBfb4XmeYfTma0", expectedError: "invalid base58 character"},
+		{name: "wrong checksum", secretKey: "S1Ka3NKcnfs8d67EZYU5mbTCVY7Znnd2YQAYjbBfb4XmeYfTma6", expectedError: "invalid base58check checksum"},
+		{name: "too short", secretKey: "S1", expectedError: "too short"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyPair, err := ParseSecretKey(tt.secretKey)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				assert.Nil(t, keyPair)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedAddress, keyPair.Address())
+			assert.Equal(t, fixtureSecretKey, keyPair.SecretKey())
+		})
+	}
+}
+
+func TestAddressBytes(t *testing.T) {
+	keyPair, err := NewKeyPairFromSeed(fixtureSeed)
+	require.NoError(t, err)
+	hash := blake3.Sum256(keyPair.PublicKeyBytes())
+
+	tests := []struct {
+		name          string
+		address       string
+		expected      []byte
+		expectedError string
+	}{
+		{name: "user address", address: fixtureAddress, expected: append([]byte{0, 0}, hash[:]...)},
+		{name: "smart contract address", address: "AS" + fixtureAddress[2:], expected: append([]byte{1, 0}, hash[:]...)},
+		{name: "public key", address: fixturePublicKey, expectedError: "must start with AU or AS"},
+		{name: "wrong checksum", address: fixtureAddress[:len(fixtureAddress)-1] + "1", expectedError: "invalid base58check checksum"},
+		{name: "hash too short", address: "AU" + EncodeBase58Check([]byte{0, 1, 2, 3}), expectedError: "expected a 32 bytes hash, got 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addressBytes, err := AddressBytes(tt.address)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, addressBytes)
+		})
+	}
+}
+
+func TestBase58Check(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: []byte{}},
+		{name: "leading zeros", data: []byte{0, 0, 1, 2, 3}},
+		{name: "only zeros", data: []byte{0, 0, 0}},
+		{name: "versioned key", data: append([]byte{0}, fixtureSeed...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := decodeBase58Check(EncodeBase58Check(tt.data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.data, decoded)
+		})
+	}
+}
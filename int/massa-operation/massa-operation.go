@@ -0,0 +1,174 @@
+package massaOperation
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/awnumar/memguard"
+	massaKeys "github.com/massalabs/node-manager-plugin/int/massa-keys"
+	"lukechampine.com/blake3"
+)
+
+const (
+	MainnetChainID  uint64 = 77658377
+	BuildnetChainID uint64 = 77658366
+
+	operationIDPrefix = "O"
+	// operationIDVersion is the version of the operation ids, serialized as a single byte varint
+	operationIDVersion byte = 0
+)
+
+// OperationType is the id of the operation type in the massa binary format
+type OperationType uint32
+
+const (
+	OperationTypeTransaction OperationType = 0
+	OperationTypeRollBuy     OperationType = 1
+	OperationTypeRollSell    OperationType = 2
+)
+
+// Operation is the content of a transaction, roll buy or roll sell operation
+type Operation struct {
+	Fee          uint64 // in nanoMAS
+	ExpirePeriod uint64 // last period in which the operation can be included in a block
+	Type         OperationType
+	RollCount    uint64 // roll buy and roll sell only
+	Recipient    string // transaction only
+	Amount       uint64 // transaction only, in nanoMAS
+}
+
+// NewTransaction creates a transaction of amount nanoMAS to recipient, fee is in nanoMAS
+func NewTransaction(fee, expirePeriod uint64, recipient string, amount uint64) Operation {
+	return Operation{Fee: fee, ExpirePeriod: expirePeriod, Type: OperationTypeTransaction, Recipient: recipient, Amount: amount}
+}
+
+// NewRollBuy creates a roll buy operation, fee is in nanoMAS
+func NewRollBuy(fee, expirePeriod, rollCount uint64) Operation {
+	return Operation{Fee: fee, ExpirePeriod: expirePeriod, Type: OperationTypeRollBuy, RollCount: rollCount}
+}
+
+// NewRollSell creates a roll sell operation, fee is in nanoMAS
+func NewRollSell(fee, expirePeriod, rollCount uint64) Operation {
+	return Operation{Fee: fee, ExpirePeriod: expirePeriod, Type: OperationTypeRollSell, RollCount: rollCount}
+}
+
+/*
+Serialize returns the operation content in the massa binary format: fee, expire period and type as unsigned varints followed by
+the roll count as an unsigned varint for roll operations, or the recipient address bytes and the amount as an unsigned varint for transactions.
+*/
+func (o Operation) Serialize() ([]byte, error) {
+	content := binary.AppendUvarint(nil, o.Fee)
+	content = binary.AppendUvarint(content, o.ExpirePeriod)
+	content = binary.AppendUvarint(content, uint64(o.Type))
+
+	switch o.Type {
+	case OperationTypeRollBuy, OperationTypeRollSell:
+		content = binary.AppendUvarint(content, o.RollCount)
+	case OperationTypeTransaction:
+		recipient, err := massaKeys.AddressBytes(o.Recipient)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction recipient: %w", err)
+		}
+		content = append(content, recipient...)
+		content = binary.AppendUvarint(content, o.Amount)
+	default:
+		return nil, fmt.Errorf("unsupported operation type %d", o.Type)
+	}
+
+	return content, nil
+}
+
+// Signer signs operations with a key whose ed25519 seed is kept in a memguard enclave
+type Signer struct {
+	enclave   *memguard.Enclave
+	publicKey ed25519.PublicKey
+}
+
+// NewSigner creates a signer from the enclave holding the 32 bytes ed25519 seed of the key
+func NewSigner(enclave *memguard.Enclave) (*Signer, error) {
+	privateKey, buffer, err := openPrivateKey(enclave)
+	if err != nil {
+		return nil, err
+	}
+	defer buffer.Destroy()
+	defer memguard.WipeBytes(privateKey)
+
+	return &Signer{
+		enclave:   enclave,
+		publicKey: append(ed25519.PublicKey{}, privateKey.Public().(ed25519.PublicKey)...),
+	}, nil
+}
+
+// PublicKey returns the public key of the signer in the massa text format (P1...)
+func (s *Signer) PublicKey() string {
+	return massaKeys.PublicKeyText(s.publicKey)
+}
+
+// Address returns the address of the signer (AU...)
+func (s *Signer) Address() string {
+	return massaKeys.AddressFromPublicKey(s.publicKey)
+}
+
+func (s *Signer) sign(message []byte) ([]byte, error) {
+	privateKey, buffer, err := openPrivateKey(s.enclave)
+	if err != nil {
+		return nil, err
+	}
+	defer buffer.Destroy()
+	defer memguard.WipeBytes(privateKey)
+
+	return ed25519.Sign(privateKey, message), nil
+}
+
+// openPrivateKey decrypts the seed of the enclave in a locked buffer and expands it, the caller must destroy the buffer and wipe the key
+func openPrivateKey(enclave *memguard.Enclave) (ed25519.PrivateKey, *memguard.LockedBuffer, error) {
+	buffer, err := enclave.Open()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open key enclave: %w", err)
+	}
+
+	if buffer.Size() != ed25519.SeedSize {
+		buffer.Destroy()
+		return nil, nil, fmt.Errorf("invalid key size in enclave: expected %d bytes, got %d", ed25519.SeedSize, buffer.Size())
+	}
+
+	return ed25519.NewKeyFromSeed(buffer.Bytes()), buffer, nil
+}
+
+// SignedOperation is an operation ready to be sent to the node through send_operations
+type SignedOperation struct {
+	SerializedContent []byte
+	CreatorPublicKey  string
+	Signature         string
+	ID                string
+}
+
+/*
+Sign serializes and signs the operation for the chain chainID.
+The signed message is the blake3 hash of the chain id (8 bytes big endian), the versioned public key of the creator
+and the serialized content. This hash is also the operation id.
+*/
+func Sign(operation Operation, signer *Signer, chainID uint64) (SignedOperation, error) {
+	content, err := operation.Serialize()
+	if err != nil {
+		return SignedOperation{}, err
+	}
+
+	signedData := binary.BigEndian.AppendUint64(nil, chainID)
+	signedData = append(signedData, massaKeys.PublicKeyBytes(signer.publicKey)...)
+	signedData = append(signedData, content...)
+	hash := blake3.Sum256(signedData)
+
+	signature, err := signer.sign(hash[:])
+	if err != nil {
+		return SignedOperation{}, fmt.Errorf("failed to sign operation: %w", err)
+	}
+
+	return SignedOperation{
+		SerializedContent: content,
+		CreatorPublicKey:  signer.PublicKey(),
+		Signature:         massaKeys.SignatureText(signature),
+		ID:                operationIDPrefix + massaKeys.EncodeBase58Check(append([]byte{operationIDVersion}, hash[:]...)),
+	}, nil
+}
@@ -0,0 +1,134 @@
+package massaOperation
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/awnumar/memguard"
+	massaKeys "github.com/massalabs/node-manager-plugin/int/massa-keys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"lukechampine.com/blake3"
+)
+
+// key pair generated from a seed made of 32 bytes 0x2a, same as the massa-keys test_data account files
+const (
+	fixturePublicKey = "P1CEJhsumzSCQnC1T1P91MqXVSAB5qfSmyVPKJpn4tYFKfhoqdP"
+	fixtureAddress   = "AU1KBGdEiu8zYtJ79ouX1Jv7HsWMgXRKK6yWgt93joD2HLQ9euFp"
+
+	// blake3 hash of the versioned public key, hex encoded
+	fixtureAddressHash = "2945f2b9fa43abff848c7a193be458eb2a1106725b1d737410500da2b02210a0"
+)
+
+func newFixtureSigner(t *testing.T) *Signer {
+	signer, err := NewSigner(memguard.NewEnclave(bytes.Repeat([]byte{0x2a}, 32)))
+	require.NoError(t, err)
+	return signer
+}
+
+func TestSerialize(t *testing.T) {
+	tests := []struct {
+		name          string
+		operation     Operation
+		expectedHex   string
+		expectedError string
+	}{
+		{name: "roll buy", operation: NewRollBuy(10_000_000, 1000, 5), expectedHex: "80ade204e8070105"},
+		{name: "roll sell", operation: NewRollSell(10_000_000, 1000, 5), expectedHex: "80ade204e8070205"},
+		{name: "zero fee, single roll", operation: NewRollBuy(0, 1, 1), expectedHex: "00010101"},
+		{name: "multi bytes roll count", operation: NewRollSell(1, 300, 128), expectedHex: "01ac02028001"},
+		{
+			name:      "transaction",
+			operation: NewTransaction(10_000_000, 1000, fixtureAddress, 1_500_000_000),
+			// fee, expire period, type 0, user address category and version, blake3 hash of the public key, amount
+			expectedHex: "80ade204e80700" + "0000" + fixtureAddressHash + "80dea0cb05",
+		},
+		{name: "transaction to invalid address", operation: NewTransaction(0, 1, "AU12invalid", 1), expectedError: "invalid transaction recipient"},
+		{name: "unsupported type", operation: Operation{Type: 3}, expectedError: "unsupported operation type 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := tt.operation.Serialize()
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedHex, hex.EncodeToString(content))
+		})
+	}
+}
+
+func TestNewSigner(t *testing.T) {
+	signer := newFixtureSigner(t)
+	assert.Equal(t, fixturePublicKey, signer.PublicKey())
+	assert.Equal(t, fixtureAddress, signer.Address())
+
+	_, err := NewSigner(memguard.NewEnclave(bytes.Repeat([]byte{0x2a}, 31)))
+	assert.ErrorContains(t, err, "invalid key size in enclave")
+}
+
+func TestSign(t *testing.T) {
+	signer := newFixtureSigner(t)
+
+	tests := []struct {
+		name              string
+		operation         Operation
+		chainID           uint64
+		expectedSignature string
+		expectedID        string
+	}{
+		{
+			name:              "roll buy on mainnet",
+			operation:         NewRollBuy(10_000_000, 1000, 5),
+			chainID:           MainnetChainID,
+			expectedSignature: "1M1WrHeAYwS5tzaiwPzhXRcAhgsEoRuwwcY9R5kwMBQy3rf7rZvUVqphWVQmGJBh7TF5RY9YarA7AwkiZekdz2dRGqf8D4",
+			expectedID:        "O1DDbBz7aRudEhuuTGDmjUSS1mA72ctY3DZkwTnE8MhkArMHvyk",
+		},
+		{
+			name:              "roll sell on buildnet",
+			operation:         NewRollSell(10_000_000, 1000, 5),
+			chainID:           BuildnetChainID,
+			expectedSignature: "16GXq1GfXmbtR5Svh8GRz2BFvW2yU3bqyvyCrjNvqY7ob6dRxPzuiT8vHZTLwA6QbkiPJoGgeLYYhUygUEb97v51ev22eD",
+			expectedID:        "O12YDazdMAhir7vV6iLD5tL9AfGvSPDjmY6fiy4YojhmUE2EqMmt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed, err := Sign(tt.operation, signer, tt.chainID)
+			require.NoError(t, err)
+
+			content, err := tt.operation.Serialize()
+			require.NoError(t, err)
+			assert.Equal(t, content, signed.SerializedContent)
+			assert.Equal(t, fixturePublicKey, signed.CreatorPublicKey)
+			assert.Equal(t, tt.expectedSignature, signed.Signature)
+			assert.Equal(t, tt.expectedID, signed.ID)
+
+			// the signature must be verifiable with the public key over the hash of chain id, public key and content
+			keyPair, err := massaKeys.NewKeyPairFromSeed(bytes.Repeat([]byte{0x2a}, 32))
+			require.NoError(t, err)
+			signedData := binary.BigEndian.AppendUint64(nil, tt.chainID)
+			signedData = append(signedData, keyPair.PublicKeyBytes()...)
+			signedData = append(signedData, content...)
+			hash := blake3.Sum256(signedData)
+			signature := ed25519.Sign(ed25519.NewKeyFromSeed(keyPair.Seed()), hash[:])
+			assert.Equal(t, massaKeys.SignatureText(signature), signed.Signature)
+			assert.True(t, ed25519.Verify(ed25519.NewKeyFromSeed(keyPair.Seed()).Public().(ed25519.PublicKey), hash[:], signature))
+		})
+	}
+
+	// the chain id is part of the signed data, so the same operation has a different id on each network
+	mainnet, err := Sign(NewRollBuy(1, 1, 1), signer, MainnetChainID)
+	require.NoError(t, err)
+	buildnet, err := Sign(NewRollBuy(1, 1, 1), signer, BuildnetChainID)
+	require.NoError(t, err)
+	assert.NotEqual(t, mainnet.ID, buildnet.ID)
+	assert.NotEqual(t, mainnet.Signature, buildnet.Signature)
+}
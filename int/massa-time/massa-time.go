@@ -0,0 +1,160 @@
+package massaTime
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/massalabs/station/pkg/node"
+)
+
+type Slot struct {
+	Period uint64 `json:"period"`
+	Thread uint8  `json:"thread"`
+}
+
+/*
+Clock converts Massa slots, periods and cycles into wall-clock times and back.
+Each period lasts t0 and is divided evenly between the threads: the slot (period, thread)
+starts at genesis + period*t0 + thread*t0/threadCount.
+*/
+type Clock struct {
+	genesisTimestamp uint64 // in milliseconds
+	t0               uint64 // in milliseconds
+	threadCount      uint64
+	periodsPerCycle  uint64
+}
+
+// NewClock creates a Clock from the genesis timestamp and t0 (both in milliseconds), the thread count and the number of periods per cycle
+func NewClock(genesisTimestamp, t0, threadCount, periodsPerCycle uint64) (*Clock, error) {
+	if t0 == 0 {
+		return nil, fmt.Errorf("t0 must be greater than 0")
+	}
+
+	if threadCount == 0 || threadCount > 256 {
+		return nil, fmt.Errorf("thread count must be between 1 and 256, got %d", threadCount)
+	}
+
+	// each thread needs at least a millisecond of the period
+	if t0 < threadCount {
+		return nil, fmt.Errorf("t0 (%d ms) must be at least the thread count (%d)", t0, threadCount)
+	}
+
+	if periodsPerCycle == 0 {
+		return nil, fmt.Errorf("periods per cycle must be greater than 0")
+	}
+
+	return &Clock{
+		genesisTimestamp: genesisTimestamp,
+		t0:               t0,
+		threadCount:      threadCount,
+		periodsPerCycle:  periodsPerCycle,
+	}, nil
+}
+
+// NewClockFromNodeConfig creates a Clock from the config returned by the node get_status endpoint
+func NewClockFromNodeConfig(config *node.Config) (*Clock, error) {
+	if config == nil {
+		return nil, fmt.Errorf("node config is nil")
+	}
+
+	if config.GenesisTimestamp == nil || config.T0 == nil || config.ThreadCount == nil || config.PeriodsPerCycle == nil {
+		return nil, fmt.Errorf("node config is incomplete: genesis timestamp, t0, thread count and periods per cycle are required")
+	}
+
+	return NewClock(
+		uint64(*config.GenesisTimestamp),
+		uint64(*config.T0),
+		uint64(*config.ThreadCount),
+		uint64(*config.PeriodsPerCycle),
+	)
+}
+
+// PeriodDuration returns the duration of a period (t0)
+func (c *Clock) PeriodDuration() time.Duration {
+	return time.Duration(c.t0) * time.Millisecond
+}
+
+// SlotDuration returns the time between two consecutive slots
+func (c *Clock) SlotDuration() time.Duration {
+	return time.Duration(c.t0) * time.Millisecond / time.Duration(c.threadCount)
+}
+
+// CycleDuration returns the duration of a cycle
+func (c *Clock) CycleDuration() time.Duration {
+	return time.Duration(c.periodsPerCycle*c.t0) * time.Millisecond
+}
+
+// SlotToTime returns the time at which the slot starts
+func (c *Clock) SlotToTime(slot Slot) time.Time {
+	return time.UnixMilli(int64(c.slotTimestamp(slot)))
+}
+
+// PeriodToTime returns the time at which the first slot of the period starts
+func (c *Clock) PeriodToTime(period uint64) time.Time {
+	return c.SlotToTime(Slot{Period: period})
+}
+
+/*
+TimeToSlot returns the slot being produced at t, which is the latest slot that started at or before t.
+It returns an error if t is before genesis.
+*/
+func (c *Clock) TimeToSlot(t time.Time) (Slot, error) {
+	ms := t.UnixMilli()
+	if ms < 0 || uint64(ms) < c.genesisTimestamp {
+		return Slot{}, fmt.Errorf("time %s is before genesis", t.UTC().Format(time.RFC3339))
+	}
+
+	elapsed := uint64(ms) - c.genesisTimestamp
+	period := elapsed / c.t0
+	thread := (elapsed % c.t0) / (c.t0 / c.threadCount)
+	// when t0 is not divisible by the thread count, the end of the period belongs to the last thread
+	if thread >= c.threadCount {
+		thread = c.threadCount - 1
+	}
+
+	return Slot{Period: period, Thread: uint8(thread)}, nil
+}
+
+// SlotToCycle returns the cycle the slot belongs to
+func (c *Clock) SlotToCycle(slot Slot) uint64 {
+	return c.PeriodToCycle(slot.Period)
+}
+
+// PeriodToCycle returns the cycle the period belongs to
+func (c *Clock) PeriodToCycle(period uint64) uint64 {
+	return period / c.periodsPerCycle
+}
+
+// TimeToCycle returns the cycle being produced at t. It returns an error if t is before genesis.
+func (c *Clock) TimeToCycle(t time.Time) (uint64, error) {
+	slot, err := c.TimeToSlot(t)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.SlotToCycle(slot), nil
+}
+
+// CycleFirstSlot returns the first slot of the cycle
+func (c *Clock) CycleFirstSlot(cycle uint64) Slot {
+	return Slot{Period: cycle * c.periodsPerCycle, Thread: 0}
+}
+
+// CycleLastSlot returns the last slot of the cycle
+func (c *Clock) CycleLastSlot(cycle uint64) Slot {
+	return Slot{Period: (cycle+1)*c.periodsPerCycle - 1, Thread: uint8(c.threadCount - 1)}
+}
+
+// CycleStartTime returns the time at which the first slot of the cycle starts
+func (c *Clock) CycleStartTime(cycle uint64) time.Time {
+	return c.SlotToTime(c.CycleFirstSlot(cycle))
+}
+
+// CycleEndTime returns the time at which the cycle ends, which is the start time of the next cycle
+func (c *Clock) CycleEndTime(cycle uint64) time.Time {
+	return c.CycleStartTime(cycle + 1)
+}
+
+func (c *Clock) slotTimestamp(slot Slot) uint64 {
+	return c.genesisTimestamp + slot.Period*c.t0 + uint64(slot.Thread)*(c.t0/c.threadCount)
+}
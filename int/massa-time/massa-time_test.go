@@ -0,0 +1,221 @@
+package massaTime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/massalabs/station/pkg/node"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mainnet parameters
+const (
+	genesis         = uint64(1704289800000)
+	t0              = uint64(16000)
+	threadCount     = uint64(32)
+	periodsPerCycle = uint64(128)
+)
+
+func newTestClock(t *testing.T) *Clock {
+	clock, err := NewClock(genesis, t0, threadCount, periodsPerCycle)
+	require.NoError(t, err)
+	return clock
+}
+
+func TestNewClock(t *testing.T) {
+	tests := []struct {
+		name            string
+		t0              uint64
+		threadCount     uint64
+		periodsPerCycle uint64
+		expectedError   string
+	}{
+		{name: "valid parameters", t0: t0, threadCount: threadCount, periodsPerCycle: periodsPerCycle},
+		{name: "single thread", t0: t0, threadCount: 1, periodsPerCycle: periodsPerCycle},
+		{name: "zero t0", t0: 0, threadCount: threadCount, periodsPerCycle: periodsPerCycle, expectedError: "t0 must be greater than 0"},
+		{name: "zero thread count", t0: t0, threadCount: 0, periodsPerCycle: periodsPerCycle, expectedError: "thread count must be between 1 and 256"},
+		{name: "too many threads", t0: t0, threadCount: 257, periodsPerCycle: periodsPerCycle, expectedError: "thread count must be between 1 and 256"},
+		{name: "t0 shorter than the thread count", t0: 31, threadCount: 32, periodsPerCycle: periodsPerCycle, expectedError: "must be at least the thread count"},
+		{name: "zero periods per cycle", t0: t0, threadCount: threadCount, periodsPerCycle: 0, expectedError: "periods per cycle must be greater than 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock, err := NewClock(genesis, tt.t0, tt.threadCount, tt.periodsPerCycle)
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Nil(t, clock)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, clock)
+			}
+		})
+	}
+}
+
+func TestNewClockFromNodeConfig(t *testing.T) {
+	uintPtr := func(v uint64) *uint {
+		u := uint(v)
+		return &u
+	}
+
+	t.Run("complete config", func(t *testing.T) {
+		clock, err := NewClockFromNodeConfig(&node.Config{
+			GenesisTimestamp: uintPtr(genesis),
+			T0:               uintPtr(t0),
+			ThreadCount:      uintPtr(threadCount),
+			PeriodsPerCycle:  uintPtr(periodsPerCycle),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, newTestClock(t), clock)
+	})
+
+	t.Run("nil config", func(t *testing.T) {
+		_, err := NewClockFromNodeConfig(nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		_, err := NewClockFromNodeConfig(&node.Config{
+			GenesisTimestamp: uintPtr(genesis),
+			T0:               uintPtr(t0),
+			ThreadCount:      uintPtr(threadCount),
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "node config is incomplete")
+	})
+}
+
+func TestDurations(t *testing.T) {
+	clock := newTestClock(t)
+
+	assert.Equal(t, 16*time.Second, clock.PeriodDuration())
+	assert.Equal(t, 500*time.Millisecond, clock.SlotDuration())
+	assert.Equal(t, 128*16*time.Second, clock.CycleDuration())
+}
+
+func TestSlotToTime(t *testing.T) {
+	clock := newTestClock(t)
+
+	tests := []struct {
+		name     string
+		slot     Slot
+		expected time.Time
+	}{
+		{name: "genesis slot", slot: Slot{Period: 0, Thread: 0}, expected: time.UnixMilli(int64(genesis))},
+		{name: "genesis period last thread", slot: Slot{Period: 0, Thread: 31}, expected: time.UnixMilli(int64(genesis + 31*500))},
+		{name: "period 1", slot: Slot{Period: 1, Thread: 0}, expected: time.UnixMilli(int64(genesis + t0))},
+		{name: "period and thread", slot: Slot{Period: 10, Thread: 5}, expected: time.UnixMilli(int64(genesis + 10*t0 + 5*500))},
+		{name: "far period", slot: Slot{Period: 5_000_000, Thread: 17}, expected: time.UnixMilli(int64(genesis + 5_000_000*t0 + 17*500))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, clock.SlotToTime(tt.slot))
+		})
+	}
+
+	assert.Equal(t, clock.SlotToTime(Slot{Period: 42}), clock.PeriodToTime(42))
+}
+
+func TestTimeToSlot(t *testing.T) {
+	clock := newTestClock(t)
+
+	tests := []struct {
+		name          string
+		time          time.Time
+		expected      Slot
+		expectedError bool
+	}{
+		{name: "genesis", time: time.UnixMilli(int64(genesis)), expected: Slot{Period: 0, Thread: 0}},
+		{name: "middle of first slot", time: time.UnixMilli(int64(genesis + 250)), expected: Slot{Period: 0, Thread: 0}},
+		{name: "start of second slot", time: time.UnixMilli(int64(genesis + 500)), expected: Slot{Period: 0, Thread: 1}},
+		{name: "end of the period", time: time.UnixMilli(int64(genesis + t0 - 1)), expected: Slot{Period: 0, Thread: 31}},
+		{name: "start of next period", time: time.UnixMilli(int64(genesis + t0)), expected: Slot{Period: 1, Thread: 0}},
+		{name: "period and thread", time: time.UnixMilli(int64(genesis + 10*t0 + 5*500 + 100)), expected: Slot{Period: 10, Thread: 5}},
+		{name: "before genesis", time: time.UnixMilli(int64(genesis - 1)), expectedError: true},
+		{name: "before unix epoch", time: time.UnixMilli(-1000), expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slot, err := clock.TimeToSlot(tt.time)
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, slot)
+		})
+	}
+}
+
+func TestTimeToSlotUnevenThreads(t *testing.T) {
+	// 1000ms divided between 3 threads: each slot lasts 333ms and the last one takes the remaining ms
+	clock, err := NewClock(0, 1000, 3, 10)
+	require.NoError(t, err)
+
+	slot, err := clock.TimeToSlot(time.UnixMilli(999))
+	require.NoError(t, err)
+	assert.Equal(t, Slot{Period: 0, Thread: 2}, slot)
+
+	slot, err = clock.TimeToSlot(time.UnixMilli(666))
+	require.NoError(t, err)
+	assert.Equal(t, Slot{Period: 0, Thread: 2}, slot)
+
+	slot, err = clock.TimeToSlot(time.UnixMilli(665))
+	require.NoError(t, err)
+	assert.Equal(t, Slot{Period: 0, Thread: 1}, slot)
+}
+
+func TestSlotTimeRoundTrip(t *testing.T) {
+	clock := newTestClock(t)
+
+	for _, period := range []uint64{0, 1, 127, 128, 1_000_000} {
+		for thread := uint8(0); thread < uint8(threadCount); thread++ {
+			slot := Slot{Period: period, Thread: thread}
+			got, err := clock.TimeToSlot(clock.SlotToTime(slot))
+			require.NoError(t, err)
+			assert.Equal(t, slot, got)
+		}
+	}
+}
+
+func TestCycles(t *testing.T) {
+	clock := newTestClock(t)
+
+	t.Run("slot to cycle", func(t *testing.T) {
+		assert.Equal(t, uint64(0), clock.SlotToCycle(Slot{Period: 0, Thread: 0}))
+		assert.Equal(t, uint64(0), clock.SlotToCycle(Slot{Period: 127, Thread: 31}))
+		assert.Equal(t, uint64(1), clock.SlotToCycle(Slot{Period: 128, Thread: 0}))
+		assert.Equal(t, uint64(7812), clock.PeriodToCycle(1_000_000))
+	})
+
+	t.Run("cycle bounds", func(t *testing.T) {
+		assert.Equal(t, Slot{Period: 256, Thread: 0}, clock.CycleFirstSlot(2))
+		assert.Equal(t, Slot{Period: 383, Thread: 31}, clock.CycleLastSlot(2))
+		assert.Equal(t, uint64(2), clock.SlotToCycle(clock.CycleFirstSlot(2)))
+		assert.Equal(t, uint64(2), clock.SlotToCycle(clock.CycleLastSlot(2)))
+	})
+
+	t.Run("cycle times", func(t *testing.T) {
+		assert.Equal(t, time.UnixMilli(int64(genesis+256*t0)), clock.CycleStartTime(2))
+		assert.Equal(t, time.UnixMilli(int64(genesis+384*t0)), clock.CycleEndTime(2))
+		assert.Equal(t, clock.CycleDuration(), clock.CycleEndTime(2).Sub(clock.CycleStartTime(2)))
+	})
+
+	t.Run("time to cycle", func(t *testing.T) {
+		cycle, err := clock.TimeToCycle(clock.CycleStartTime(5))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(5), cycle)
+
+		cycle, err = clock.TimeToCycle(clock.CycleEndTime(5).Add(-time.Millisecond))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(5), cycle)
+
+		_, err = clock.TimeToCycle(time.UnixMilli(0))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,88 @@
+package nodeAPI
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	massaTime "github.com/massalabs/node-manager-plugin/int/massa-time"
+)
+
+type DrawType string
+
+const (
+	DrawTypeBlock       DrawType = "block"
+	DrawTypeEndorsement DrawType = "endorsement"
+)
+
+type endorsementDrawDtoNode struct {
+	Slot  massaTime.Slot `json:"slot"`
+	Index uint64         `json:"index"`
+}
+
+type addressDrawsDtoNode struct {
+	Address              string                   `json:"address"`
+	NextBlockDraws       []massaTime.Slot         `json:"next_block_draws"`
+	NextEndorsementDraws []endorsementDrawDtoNode `json:"next_endorsement_draws"`
+}
+
+// Draw is a slot at which an address has been selected to produce a block or an endorsement
+type Draw struct {
+	Type  DrawType       `json:"type"`
+	Slot  massaTime.Slot `json:"slot"`
+	Index *uint64        `json:"index,omitempty"` // endorsement index in the block, nil for block draws
+	Time  time.Time      `json:"time"`
+}
+
+// AddressDraws contains the upcoming draws of an address, sorted chronologically
+type AddressDraws struct {
+	Address string `json:"address"`
+	Draws   []Draw `json:"draws"`
+}
+
+// GetNextDraws retrieves the upcoming block and endorsement draws of the addresses and converts their slots into wall-clock times
+func (n *nodeAPI) GetNextDraws(addresses []string) ([]AddressDraws, error) {
+	js, err := n.GetAddresses(addresses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get addresses from node: %w", err)
+	}
+
+	var res []addressDrawsDtoNode
+	if err := json.Unmarshal(js, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal addresses draws: %w", err)
+	}
+
+	status, err := n.GetStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node status: %w", err)
+	}
+
+	clock, err := massaTime.NewClockFromNodeConfig(status.Config)
+	if err != nil {
+		return nil, fmt.Errorf("can't convert slots to time: %w", err)
+	}
+
+	return convertDraws(res, clock.SlotToTime), nil
+}
+
+func convertDraws(res []addressDrawsDtoNode, slotTime func(massaTime.Slot) time.Time) []AddressDraws {
+	addressesDraws := make([]AddressDraws, len(res))
+	for i, addr := range res {
+		draws := make([]Draw, 0, len(addr.NextBlockDraws)+len(addr.NextEndorsementDraws))
+		for _, slot := range addr.NextBlockDraws {
+			draws = append(draws, Draw{Type: DrawTypeBlock, Slot: slot, Time: slotTime(slot)})
+		}
+		for _, endorsement := range addr.NextEndorsementDraws {
+			index := endorsement.Index
+			draws = append(draws, Draw{Type: DrawTypeEndorsement, Slot: endorsement.Slot, Index: &index, Time: slotTime(endorsement.Slot)})
+		}
+
+		sort.SliceStable(draws, func(a, b int) bool {
+			return draws[a].Time.Before(draws[b].Time)
+		})
+
+		addressesDraws[i] = AddressDraws{Address: addr.Address, Draws: draws}
+	}
+	return addressesDraws
+}
@@ -0,0 +1,47 @@
+package nodeAPI
+
+import (
+	"testing"
+	"time"
+
+	massaTime "github.com/massalabs/node-manager-plugin/int/massa-time"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertDraws(t *testing.T) {
+	slotTime := func(slot massaTime.Slot) time.Time {
+		return time.UnixMilli(int64(slot.Period*1000 + uint64(slot.Thread)))
+	}
+
+	res := []addressDrawsDtoNode{
+		{
+			Address:        "AU1",
+			NextBlockDraws: []massaTime.Slot{{Period: 5, Thread: 1}, {Period: 2, Thread: 0}},
+			NextEndorsementDraws: []endorsementDrawDtoNode{
+				{Slot: massaTime.Slot{Period: 3, Thread: 2}, Index: 7},
+			},
+		},
+		{
+			Address: "AU2",
+		},
+	}
+
+	draws := convertDraws(res, slotTime)
+
+	assert.Len(t, draws, 2)
+	assert.Equal(t, "AU1", draws[0].Address)
+	assert.Len(t, draws[0].Draws, 3)
+
+	assert.Equal(t, DrawTypeBlock, draws[0].Draws[0].Type)
+	assert.Equal(t, massaTime.Slot{Period: 2, Thread: 0}, draws[0].Draws[0].Slot)
+	assert.Nil(t, draws[0].Draws[0].Index)
+
+	assert.Equal(t, DrawTypeEndorsement, draws[0].Draws[1].Type)
+	assert.Equal(t, uint64(7), *draws[0].Draws[1].Index)
+	assert.Equal(t, time.UnixMilli(3002), draws[0].Draws[1].Time)
+
+	assert.Equal(t, massaTime.Slot{Period: 5, Thread: 1}, draws[0].Draws[2].Slot)
+
+	assert.Equal(t, "AU2", draws[1].Address)
+	assert.Empty(t, draws[1].Draws)
+}
@@ -15,6 +15,7 @@ type NodeAPI interface {
 	GetAddresses(addresses []string) ([]byte, error)
 	GetOperation(operationID string) (*node.Operation, error)
 	GetStatus() (*node.State, error)
+	GetNextDraws(addresses []string) ([]AddressDraws, error)
 }
 
 type nodeAPI struct {
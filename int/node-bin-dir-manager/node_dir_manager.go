@@ -21,6 +21,7 @@ type NodeDirManager interface {
 	GetClientBin(isMainnet bool) (string, error)
 	GetNodeBin(isMainnet bool) (string, error)
 	HasClientAddresses(isMainnet bool) (bool, error)
+	GetClientWalletDir(isMainnet bool) string
 }
 
 type nodeDirManager struct {
@@ -59,6 +60,12 @@ func (ndm *nodeDirManager) GetNodeBin(isMainnet bool) (string, error) {
 	return binPath, nil
 }
 
+// GetClientWalletDir returns the directory where massa-client stores its wallet files
+func (ndm *nodeDirManager) GetClientWalletDir(isMainnet bool) string {
+	version := config.GlobalPluginInfo.GetNetworkVersion(isMainnet)
+	return filepath.Join(ndm.nodeFolderPath, version, clientBinFolder, walletFolder)
+}
+
 func (ndm *nodeDirManager) HasClientAddresses(isMainnet bool) (bool, error) {
 	version := config.GlobalPluginInfo.GetNetworkVersion(isMainnet)
 
@@ -0,0 +1,44 @@
+package priceProvider
+
+import (
+	"time"
+
+	"github.com/massalabs/node-manager-plugin/int/db"
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+// CachedPriceProvider caches the prices of another provider by day in database, so that each of them is retrieved once
+type CachedPriceProvider struct {
+	provider PriceProvider
+	db       db.DB
+}
+
+// NewCachedPriceProvider creates a provider caching the prices of provider in database
+func NewCachedPriceProvider(provider PriceProvider, database db.DB) *CachedPriceProvider {
+	return &CachedPriceProvider{provider: provider, db: database}
+}
+
+// GetPrice returns the cached price of the day, or retrieves it from the provider and caches it
+func (p *CachedPriceProvider) GetPrice(day time.Time, currency string) (float64, error) {
+	currency = normalizeCurrency(currency)
+
+	price, err := p.db.GetPrice(day, currency)
+	if err == nil {
+		return price, nil
+	}
+	if !nodeManagerError.Is(err, nodeManagerError.ErrDBNotFoundItem) {
+		logger.Warnf("failed to read cached price: %v", err)
+	}
+
+	price, err = p.provider.GetPrice(day, currency)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := p.db.SetPrice(day, currency, price); err != nil {
+		logger.Warnf("failed to cache price: %v", err)
+	}
+
+	return price, nil
+}
@@ -0,0 +1,113 @@
+package priceProvider
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+)
+
+// filePrice is a line of a price file
+type filePrice struct {
+	Date     string  `json:"date"` // YYYY-MM-DD, UTC
+	Currency string  `json:"currency"`
+	Price    float64 `json:"price"`
+}
+
+/*
+FilePriceProvider reads the prices from a local file, loaded once. A file with the .json extension holds an array
+of {"date", "currency", "price"} objects, any other file is read as CSV with a date,currency,price header line.
+*/
+type FilePriceProvider struct {
+	prices map[string]float64 // day + currency -> price
+}
+
+// NewFilePriceProvider loads the price file at path
+func NewFilePriceProvider(path string) (*FilePriceProvider, error) {
+	if path == "" {
+		return nil, fmt.Errorf("price_file must be set to use the file price source")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price file: %w", err)
+	}
+
+	var prices []filePrice
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(content, &prices); err != nil {
+			return nil, fmt.Errorf("failed to parse price file %s: %w", path, err)
+		}
+	} else {
+		prices, err = parseCSVPrices(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse price file %s: %w", path, err)
+		}
+	}
+
+	provider := &FilePriceProvider{prices: make(map[string]float64, len(prices))}
+	for _, price := range prices {
+		day, err := time.Parse(dayLayout, price.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q in price file %s: %w", price.Date, path, err)
+		}
+		provider.prices[priceKey(day, price.Currency)] = price.Price
+	}
+
+	return provider, nil
+}
+
+// parseCSVPrices parses CSV prices whose columns are named by the header line
+func parseCSVPrices(content string) ([]filePrice, error) {
+	records, err := csv.NewReader(strings.NewReader(content)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"date", "currency", "price"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing %s column", name)
+		}
+	}
+
+	prices := make([]filePrice, 0, len(records)-1)
+	for i, record := range records[1:] {
+		price, err := strconv.ParseFloat(strings.TrimSpace(record[columns["price"]]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price on line %d: %w", i+2, err)
+		}
+		prices = append(prices, filePrice{
+			Date:     strings.TrimSpace(record[columns["date"]]),
+			Currency: record[columns["currency"]],
+			Price:    price,
+		})
+	}
+
+	return prices, nil
+}
+
+func priceKey(day time.Time, currency string) string {
+	return day.UTC().Format(dayLayout) + "/" + normalizeCurrency(currency)
+}
+
+// GetPrice returns the price of the day in the file
+func (p *FilePriceProvider) GetPrice(day time.Time, currency string) (float64, error) {
+	price, ok := p.prices[priceKey(day, currency)]
+	if !ok {
+		return 0, nodeManagerError.New(nodeManagerError.ErrPriceNotFound, fmt.Sprintf("no price in %s for %s in the price file", normalizeCurrency(currency), day.UTC().Format(dayLayout)))
+	}
+	return price, nil
+}
@@ -0,0 +1,92 @@
+package priceProvider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+// unavailableDelay is how long the price endpoint is not queried after it could not be reached
+const unavailableDelay = time.Minute
+
+// httpPriceResponse is the body returned by the price endpoint
+type httpPriceResponse struct {
+	Price *float64 `json:"price"`
+}
+
+/*
+HTTPPriceProvider fetches the prices from an HTTP endpoint. The endpoint is called with GET and the date (YYYY-MM-DD, UTC)
+and currency query parameters added to its URL, and answers {"price": <price of a MAS>}, or a 404 status if it has no price.
+Once the endpoint can't be reached, the prices are not queried for unavailableDelay, so that a value history
+spanning many days doesn't wait for the timeout of each of them.
+*/
+type HTTPPriceProvider struct {
+	url    string
+	client *http.Client
+
+	mu               sync.Mutex
+	unavailableUntil time.Time
+}
+
+// NewHTTPPriceProvider creates a provider calling the endpoint at url
+func NewHTTPPriceProvider(url string, timeout time.Duration) *HTTPPriceProvider {
+	return &HTTPPriceProvider{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// GetPrice queries the price of the day to the endpoint
+func (p *HTTPPriceProvider) GetPrice(day time.Time, currency string) (float64, error) {
+	endpoint, err := url.Parse(p.url)
+	if err != nil {
+		return 0, fmt.Errorf("invalid price url %q: %w", p.url, err)
+	}
+	query := endpoint.Query()
+	query.Set("date", day.UTC().Format(dayLayout))
+	query.Set("currency", normalizeCurrency(currency))
+	endpoint.RawQuery = query.Encode()
+
+	p.mu.Lock()
+	unavailableUntil := p.unavailableUntil
+	p.mu.Unlock()
+	if time.Now().Before(unavailableUntil) {
+		return 0, fmt.Errorf("price endpoint unreachable, not queried until %s", unavailableUntil.Format(time.RFC3339))
+	}
+
+	resp, err := p.client.Get(endpoint.String())
+	if err != nil {
+		p.mu.Lock()
+		p.unavailableUntil = time.Now().Add(unavailableDelay)
+		p.mu.Unlock()
+		return 0, fmt.Errorf("failed to fetch price: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Warnf("failed to close price response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, nodeManagerError.New(nodeManagerError.ErrPriceNotFound, fmt.Sprintf("no price in %s for %s from the price endpoint", normalizeCurrency(currency), day.UTC().Format(dayLayout)))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("price endpoint returned status code: %d", resp.StatusCode)
+	}
+
+	var body httpPriceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("failed to decode price response: %w", err)
+	}
+	if body.Price == nil {
+		return 0, fmt.Errorf("price endpoint response has no price")
+	}
+
+	return *body.Price, nil
+}
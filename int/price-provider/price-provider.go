@@ -0,0 +1,104 @@
+package priceProvider
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/massalabs/node-manager-plugin/int/config"
+	"github.com/massalabs/node-manager-plugin/int/db"
+	"github.com/massalabs/station/pkg/logger"
+)
+
+// dayLayout is the format of the days in the price files and in the queries of the price endpoint
+const dayLayout = "2006-01-02"
+
+// PriceProvider gives the price of a MAS in fiat currencies
+type PriceProvider interface {
+	// GetPrice returns the price of a MAS in the currency on the UTC day of the given time
+	GetPrice(day time.Time, currency string) (float64, error)
+}
+
+// NewPriceProvider creates the price provider selected by the config, cached in database. It returns nil if no price source is configured.
+func NewPriceProvider(cfg *config.PluginConfig, database db.DB) (PriceProvider, error) {
+	var provider PriceProvider
+	switch cfg.PriceSource {
+	case config.PriceSourceNone, "":
+		return nil, nil
+	case config.PriceSourceFile:
+		fileProvider, err := NewFilePriceProvider(cfg.PriceFile)
+		if err != nil {
+			return nil, err
+		}
+		provider = fileProvider
+	case config.PriceSourceHTTP:
+		if cfg.PriceURL == "" {
+			return nil, fmt.Errorf("price_url must be set to use the http price source")
+		}
+		provider = NewHTTPPriceProvider(cfg.PriceURL, time.Duration(cfg.ClientTimeout)*time.Second)
+	default:
+		return nil, fmt.Errorf("unknown price source %q", cfg.PriceSource)
+	}
+
+	return NewCachedPriceProvider(provider, database), nil
+}
+
+// normalizeCurrency returns the currency code in upper case, as stored in the price cache
+func normalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+/*
+Converter converts MAS amounts to a fiat currency at the price of the day they are dated,
+the price of each day being retrieved once.
+*/
+type Converter struct {
+	provider PriceProvider
+	currency string
+	prices   map[string]*float64 // day -> price, nil if the price is unknown
+}
+
+// NewConverter creates a converter to the currency using the prices of the provider
+func NewConverter(provider PriceProvider, currency string) *Converter {
+	return &Converter{
+		provider: provider,
+		currency: normalizeCurrency(currency),
+		prices:   make(map[string]*float64),
+	}
+}
+
+// Currency returns the currency the amounts are converted to, empty for a nil converter
+func (c *Converter) Currency() string {
+	if c == nil {
+		return ""
+	}
+	return c.currency
+}
+
+/*
+Convert returns the value in fiat of an amount of MAS at the given time, nil if the price of this day is unknown.
+A nil converter converts nothing, so that it can stand for no requested currency.
+*/
+func (c *Converter) Convert(t time.Time, amount float64) *float64 {
+	if c == nil {
+		return nil
+	}
+
+	day := t.UTC().Format(dayLayout)
+	price, ok := c.prices[day]
+	if !ok {
+		value, err := c.provider.GetPrice(t, c.currency)
+		if err != nil {
+			logger.Debugf("no price in %s for %s: %v", c.currency, day, err)
+		} else {
+			price = &value
+		}
+		c.prices[day] = price
+	}
+
+	if price == nil {
+		return nil
+	}
+	value := amount * *price
+	return &value
+}
@@ -0,0 +1,189 @@
+package priceProvider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/massalabs/node-manager-plugin/int/config"
+	"github.com/massalabs/node-manager-plugin/int/db"
+	nodeManagerError "github.com/massalabs/node-manager-plugin/int/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilePriceProvider(t *testing.T) {
+	for _, path := range []string{"test_data/prices.csv", "test_data/prices.json"} {
+		t.Run(path, func(t *testing.T) {
+			provider, err := NewFilePriceProvider(path)
+			require.NoError(t, err)
+
+			// any time of the day has the price of the day
+			price, err := provider.GetPrice(time.Date(2025, 1, 1, 18, 30, 0, 0, time.UTC), "eur")
+			require.NoError(t, err)
+			assert.Equal(t, 0.02, price)
+
+			price, err = provider.GetPrice(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), "USD")
+			require.NoError(t, err)
+			assert.Equal(t, 0.021, price)
+
+			_, err = provider.GetPrice(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), "USD")
+			assert.True(t, nodeManagerError.Is(err, nodeManagerError.ErrPriceNotFound))
+		})
+	}
+
+	_, err := NewFilePriceProvider("test_data/missing.csv")
+	assert.Error(t, err)
+}
+
+func TestParseCSVPrices(t *testing.T) {
+	// columns are found by name
+	prices, err := parseCSVPrices("price,date,currency\n0.5,2025-01-01,EUR\n")
+	require.NoError(t, err)
+	assert.Equal(t, []filePrice{{Date: "2025-01-01", Currency: "EUR", Price: 0.5}}, prices)
+
+	_, err = parseCSVPrices("date,price\n2025-01-01,0.5\n")
+	assert.Error(t, err)
+
+	_, err = parseCSVPrices("date,currency,price\n2025-01-01,EUR,abc\n")
+	assert.Error(t, err)
+}
+
+func TestHTTPPriceProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("date") + "/" + r.URL.Query().Get("currency") {
+		case "2025-01-01/EUR":
+			assert.Equal(t, "key", r.URL.Query().Get("apiKey"))
+			_, _ = w.Write([]byte(`{"price": 0.02}`))
+		case "2025-01-02/EUR":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	// the query parameters of the configured url are kept
+	provider := NewHTTPPriceProvider(server.URL+"/price?apiKey=key", time.Second)
+
+	price, err := provider.GetPrice(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), "eur")
+	require.NoError(t, err)
+	assert.Equal(t, 0.02, price)
+
+	_, err = provider.GetPrice(time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC), "EUR")
+	assert.Error(t, err)
+	assert.False(t, nodeManagerError.Is(err, nodeManagerError.ErrPriceNotFound))
+
+	_, err = provider.GetPrice(time.Date(2025, 1, 3, 12, 0, 0, 0, time.UTC), "EUR")
+	assert.True(t, nodeManagerError.Is(err, nodeManagerError.ErrPriceNotFound))
+}
+
+func TestHTTPPriceProviderUnreachable(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"price": 0.02}`))
+	}))
+	defer server.Close()
+
+	provider := NewHTTPPriceProvider(server.URL, 50*time.Millisecond)
+
+	_, err := provider.GetPrice(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), "EUR")
+	assert.Error(t, err)
+
+	// the endpoint is not queried again until the delay is over
+	start := time.Now()
+	_, err = provider.GetPrice(time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC), "EUR")
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	provider.unavailableUntil = time.Now()
+	_, err = provider.GetPrice(time.Date(2025, 1, 3, 12, 0, 0, 0, time.UTC), "EUR")
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+// countingPriceProvider counts the prices it is asked
+type countingPriceProvider struct {
+	price float64
+	calls int
+}
+
+func (p *countingPriceProvider) GetPrice(day time.Time, currency string) (float64, error) {
+	p.calls++
+	if p.price == 0 {
+		return 0, nodeManagerError.New(nodeManagerError.ErrPriceNotFound, "no price")
+	}
+	return p.price, nil
+}
+
+func TestCachedPriceProvider(t *testing.T) {
+	day := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	source := &countingPriceProvider{price: 0.02}
+
+	mockDB := db.NewMockDB(t)
+	mockDB.On("GetPrice", day, "EUR").Return(0.0, nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, "no price")).Once()
+	mockDB.On("SetPrice", day, "EUR", 0.02).Return(nil).Once()
+	mockDB.On("GetPrice", day, "EUR").Return(0.02, nil).Once()
+
+	provider := NewCachedPriceProvider(source, mockDB)
+
+	// the first call fetches and caches the price, the second one reads it from the cache
+	for range 2 {
+		price, err := provider.GetPrice(day, "eur")
+		require.NoError(t, err)
+		assert.Equal(t, 0.02, price)
+	}
+	assert.Equal(t, 1, source.calls)
+
+	// unknown prices are not cached
+	source.price = 0
+	mockDB.On("GetPrice", day, "USD").Return(0.0, nodeManagerError.New(nodeManagerError.ErrDBNotFoundItem, "no price")).Once()
+	_, err := provider.GetPrice(day, "USD")
+	assert.True(t, nodeManagerError.Is(err, nodeManagerError.ErrPriceNotFound))
+}
+
+func TestConverter(t *testing.T) {
+	source := &countingPriceProvider{price: 0.5}
+	converter := NewConverter(source, "eur")
+	assert.Equal(t, "EUR", converter.Currency())
+
+	day := time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC)
+	value := converter.Convert(day, 10)
+	require.NotNil(t, value)
+	assert.Equal(t, 5.0, *value)
+
+	// the price of a day is asked once, even if unknown
+	value = converter.Convert(day.Add(time.Hour), 4)
+	require.NotNil(t, value)
+	assert.Equal(t, 2.0, *value)
+	source.price = 0
+	assert.Nil(t, converter.Convert(day.AddDate(0, 0, 1), 4))
+	assert.Nil(t, converter.Convert(day.AddDate(0, 0, 1), 5))
+	assert.Equal(t, 2, source.calls)
+
+	var noConverter *Converter
+	assert.Nil(t, noConverter.Convert(day, 10))
+	assert.Empty(t, noConverter.Currency())
+}
+
+func TestNewPriceProvider(t *testing.T) {
+	mockDB := db.NewMockDB(t)
+
+	provider, err := NewPriceProvider(&config.PluginConfig{PriceSource: config.PriceSourceNone}, mockDB)
+	require.NoError(t, err)
+	assert.Nil(t, provider)
+
+	provider, err = NewPriceProvider(&config.PluginConfig{PriceSource: config.PriceSourceFile, PriceFile: "test_data/prices.csv"}, mockDB)
+	require.NoError(t, err)
+	assert.IsType(t, &CachedPriceProvider{}, provider)
+
+	_, err = NewPriceProvider(&config.PluginConfig{PriceSource: config.PriceSourceHTTP}, mockDB)
+	assert.Error(t, err)
+
+	_, err = NewPriceProvider(&config.PluginConfig{PriceSource: "unknown"}, mockDB)
+	assert.Error(t, err)
+}
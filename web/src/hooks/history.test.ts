@@ -41,6 +41,14 @@ describe('getTotalValue function', () => {
     expect(result).toBe(5050);
   });
 
+  it('should not count watch-only addresses', () => {
+    const result = getTotalValue([
+      ...mockStakingAddresses,
+      { ...mockStakingAddresses[0], address: 'address3', watch_only: true },
+    ]);
+    expect(result).toBe(5050);
+  });
+
   it('should handle empty addresses array', () => {
     const result = getTotalValue([]);
     expect(result).toBe(0);
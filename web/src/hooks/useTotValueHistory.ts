@@ -22,6 +22,8 @@ const ROLL_PRICE = 100.0;
 export function getTotalValue(addresses: StakingAddress[]): number {
   let totalValue = 0;
   for (const addr of addresses) {
+    // watch-only addresses have their own value history series
+    if (addr.watch_only) continue;
     let deferredCredits = 0;
     for (const defCredit of addr.deferred_credits) {
       deferredCredits += defCredit.amount;
@@ -1,8 +1,25 @@
 export type ValueHistoryPoint = {
   timestamp: string;
   value: number | null;
+  // only set when the value history is requested with breakdown=true
+  balance?: number;
+  roll_value?: number;
+  deferred?: number;
+  // only set when the value history is requested with aggregation=minmax or aggregation=ohlc
+  open?: number;
+  min?: number;
+  max?: number;
+  // only set when the value history is requested with a currency and the price of the day is known
+  fiatValue?: number;
 };
 
+export enum ValueHistoryAggregation {
+  LAST = 'last',
+  AVG = 'avg',
+  MINMAX = 'minmax',
+  OHLC = 'ohlc',
+}
+
 export enum SinceFetch {
   H1 = '1H',
   D1 = '1D',
@@ -12,7 +29,131 @@ export enum SinceFetch {
   DEFAULT = '',
 }
 
+export type ValueHistoryDowntime = {
+  start: string;
+  // not set if the node is still down
+  end?: string;
+};
+
+export type ValueHistoryRollOp = {
+  address: string;
+  op: 'BUY' | 'SELL';
+  amount: number;
+  opId: string;
+  timestamp: string;
+};
+
+export type ValueHistoryAddressEvent = {
+  address: string;
+  event: 'ADDED' | 'REMOVED';
+  watch_only: boolean;
+  timestamp: string;
+};
+
+export type ValueHistoryAnnotations = {
+  downtimes?: ValueHistoryDowntime[];
+  rollOps?: ValueHistoryRollOp[];
+  addressEvents?: ValueHistoryAddressEvent[];
+};
+
 export type ValueHistorySamplesResponse = {
   samples: ValueHistoryPoint[];
   emptyDataPointNum: number;
+  annotations?: ValueHistoryAnnotations;
+};
+
+export type CapitalFlow = {
+  timestamp: string;
+  address: string;
+  // positive for a deposit, negative for a withdrawal
+  amount: number;
+  kind: 'DEPOSIT' | 'WITHDRAWAL' | 'SWEEP' | 'ADDRESS_ADDED' | 'ADDRESS_REMOVED';
+};
+
+export type Performance = {
+  address?: string;
+  since: string;
+  until: string;
+  startValue: number;
+  endValue: number;
+  deposits: number;
+  withdrawals: number;
+  rewards: number;
+  timeWeightedReturn: number;
+  moneyWeightedReturn?: number;
+  annualizedYield?: number;
+  averageRolls: number;
+  rewardsPerRoll?: number;
+  flows: CapitalFlow[];
+  // only set when the performance is requested with a currency and the prices are known
+  startValueFiat?: number;
+  endValueFiat?: number;
+  rewardsFiat?: number;
+};
+
+export type PerformanceReport = {
+  total: Performance;
+  addresses: Performance[];
+};
+
+export enum ExportSection {
+  Balances = 'balances',
+  RollOperations = 'roll_operations',
+  Rewards = 'rewards',
+  DeferredCreditReleases = 'deferred_credit_releases',
+}
+
+export type ExportPeriod = 'DAY' | 'WEEK' | 'MONTH';
+
+// layout of the version 2 of the accounting export, the fiat fields are only set when a currency is requested
+export type AccountingExport = {
+  version: number;
+  network: string;
+  since: string;
+  until: string;
+  period: ExportPeriod;
+  consolidated: boolean;
+  currency?: string;
+  balances: {
+    period_start: string;
+    period_end: string;
+    address: string; // ALL in a consolidated export
+    start_balance: number;
+    start_roll_value: number;
+    start_deferred_credits: number;
+    start_total: number;
+    end_balance: number;
+    end_roll_value: number;
+    end_deferred_credits: number;
+    end_total: number;
+    start_total_fiat?: number;
+    end_total_fiat?: number;
+  }[];
+  roll_operations: {
+    timestamp: string;
+    cycle: number;
+    address: string;
+    operation: 'BUY' | 'SELL';
+    rolls: number;
+    balance_change: number;
+    fee: number;
+    op_id: string;
+    balance_change_fiat?: number;
+    fee_fiat?: number;
+  }[];
+  rewards: {
+    cycle: number;
+    address: string; // ALL in a consolidated export
+    block_rewards: number;
+    endorsement_rewards: number;
+    total: number;
+    total_fiat?: number;
+  }[];
+  deferred_credit_releases: {
+    timestamp: string;
+    cycle: number;
+    address: string;
+    amount: number;
+    amount_fiat?: number;
+  }[];
 };
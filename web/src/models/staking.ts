@@ -6,6 +6,21 @@ export interface Slot {
 export interface DeferredCredit {
   slot: Slot;
   amount: number;
+  unlock_time?: string; // estimated release time
+}
+
+export interface PendingOperation {
+  id: string;
+  expire_period?: number;
+  expire_time?: string; // estimated expiry time if the operation is not included
+}
+
+export interface CycleInfo {
+  cycle: number;
+  is_final: boolean;
+  ok_count: number; // produced blocks
+  nok_count: number; // missed blocks
+  active_rolls: number;
 }
 
 export interface StakingAddress {
@@ -17,8 +32,12 @@ export interface StakingAddress {
   candidate_balance: number;
   thread: number;
   deferred_credits: DeferredCredit[];
+  cycle_infos: CycleInfo[];
+  pending_operation?: PendingOperation;
   // if target rolls is negative, it means that the address is auto-compounding: buy as many rolls as possible
   target_rolls: number;
+  // watch-only addresses are monitored but not staked by the node, their rolls are never bought or sold
+  watch_only?: boolean;
 }
 
 export interface StakingAddressesResponse {
@@ -30,6 +49,41 @@ export interface AddStakingAddressBody {
   nickname: string;
 }
 
+export type KeySource = 'WALLET_FILE' | 'SECRET_KEY' | 'GENERATED';
+
+export interface AddStakingKeyBody {
+  source: KeySource;
+  walletFile?: string; // content of the wallet account file, for WALLET_FILE
+  password?: string; // wallet file password, or plugin keystore password for GENERATED
+  secretKey?: string; // for SECRET_KEY
+  nickname?: string; // for GENERATED
+}
+
+export interface ImportStakingAddressesBody {
+  accounts: AddStakingAddressBody[];
+}
+
+export interface ImportResult {
+  nickname: string;
+  address?: string;
+  success: boolean;
+  error?: string; // failure reason, or non blocking error if success is true
+}
+
+export interface ImportStakingAddressesResponse {
+  results: ImportResult[];
+}
+
+export interface WalletAccount {
+  nickname: string;
+  address: string;
+  isStaking: boolean;
+}
+
+export interface WalletAccountsResponse {
+  accounts: WalletAccount[];
+}
+
 export interface UpdateStakingAddressBody {
   address: string;
   target_rolls: number;
@@ -37,10 +91,31 @@ export interface UpdateStakingAddressBody {
 
 export interface RemoveStakingAddressBody {
   address: string;
+  force?: boolean; // remove immediately instead of decommissioning
+}
+
+export type DecommissionStep =
+  | 'SELLING_ROLLS'
+  | 'WAITING_DEFERRED_CREDITS'
+  | 'REMOVING_KEY'
+  | 'ARCHIVING_HISTORY';
+
+export interface Decommission {
+  address: string;
+  step: DecommissionStep;
+  stepIndex: number;
+  stepCount: number;
+  startedAt: string;
+  updatedAt: string;
+  eta?: string;
+}
+
+export interface DecommissionsResponse {
+  decommissions: Decommission[];
 }
 
 export interface RollOpHistory {
-  op: 'BUY' | 'SELL';
+  op: 'BUY' | 'SELL' | 'TARGET'; // TARGET: a roll schedule has run, amount is the new target
   amount: number;
   timestamp: string;
   opId?: string; // Optional for backward compatibility
@@ -49,3 +124,146 @@ export interface RollOpHistory {
 export interface RollOpHistoryResponse {
   operations: RollOpHistory[];
 }
+
+export interface AutomationPauses {
+  global: boolean; // automation of all the addresses is paused
+  addresses: string[]; // addresses paused individually
+}
+
+export interface SetAutomationPauseBody {
+  address?: string; // all the addresses if omitted
+  paused: boolean;
+}
+
+export interface WatchOnlyAddressBody {
+  address: string;
+}
+
+export interface AddressLabel {
+  address: string;
+  label: string; // filled with the wallet nickname when the address is added from the wallet plugin
+  tags: string[];
+  group: string; // empty if the address is in no group
+}
+
+export interface AddressLabelsResponse {
+  labels: AddressLabel[];
+}
+
+export interface DeleteAddressLabelBody {
+  address: string;
+}
+
+export interface AddressGroup {
+  name: string;
+  addresses: string[];
+  final_roll_count: number;
+  candidate_roll_count: number;
+  active_roll_count: number;
+  final_balance: number;
+  candidate_balance: number;
+  deferred_credits: number;
+  total_value: number; // final balances, final rolls value and deferred credits
+}
+
+export interface AddressGroupsResponse {
+  groups: AddressGroup[];
+}
+
+export interface BlockedRollOp {
+  op: 'BUY' | 'SELL';
+  amount: number;
+  reason: string; // guardrail that blocked the operation
+  timestamp: string;
+}
+
+export interface BlockedRollOpsResponse {
+  operations: BlockedRollOp[];
+}
+
+export type RollScheduleAction = 'SET_TARGET' | 'BUY' | 'SELL';
+
+export interface RollSchedule {
+  id: number;
+  address: string;
+  action: RollScheduleAction;
+  amount: number;
+  runAt?: string | null; // exclusive with cycle
+  cycle?: number | null;
+  status: 'PENDING' | 'DONE' | 'FAILED';
+  error?: string;
+  createdAt: string;
+  executedAt?: string | null;
+}
+
+export interface RollSchedulesResponse {
+  schedules: RollSchedule[];
+}
+
+export interface SweepRule {
+  address: string;
+  coldAddress: string;
+  threshold: number; // minimum amount to sweep, in MAS
+  reserve: number; // amount kept on the staking address, in MAS
+  dryRun: boolean;
+}
+
+export interface SweepRulesResponse {
+  rules: SweepRule[];
+}
+
+export interface SweepHistory {
+  coldAddress: string;
+  amount: number;
+  fee: number;
+  opId?: string; // empty for dry runs
+  dryRun: boolean;
+  timestamp: string;
+}
+
+export interface SweepHistoryResponse {
+  sweeps: SweepHistory[];
+}
+
+export enum LedgerEntryKind {
+  BlockReward = 'BLOCK_REWARD',
+  EndorsementReward = 'ENDORSEMENT_REWARD',
+  RollBuy = 'ROLL_BUY',
+  RollSell = 'ROLL_SELL',
+  DeferredCreditRelease = 'DEFERRED_CREDIT_RELEASE',
+  Fee = 'FEE',
+  Sweep = 'SWEEP',
+  ExternalTransfer = 'EXTERNAL_TRANSFER', // balance change that could not be explained
+}
+
+export interface LedgerEntry {
+  timestamp: string;
+  address: string;
+  cycle: number;
+  kind: LedgerEntryKind;
+  amount: number; // change of the final balance, in MAS
+  rolls: number; // change of the final roll count
+  opId?: string;
+  fiatAmount?: number; // amount in the requested currency, if its price is known
+}
+
+export interface LedgerResponse {
+  entries: LedgerEntry[];
+}
+
+export interface Draw {
+  type: 'block' | 'endorsement';
+  period: number;
+  thread: number;
+  index?: number; // only for endorsement draws
+  time: string;
+}
+
+export interface AddressDraws {
+  address: string;
+  draws: Draw[];
+}
+
+export interface NextDrawsResponse {
+  addresses: AddressDraws[];
+}
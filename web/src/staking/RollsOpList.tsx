@@ -60,7 +60,9 @@ const RollsOpList: React.FC<{ address: string }> = (props: {
                   className={`inline-flex items-center justify-center px-2 py-1 rounded-full text-xs font-medium ${
                     operation.op === 'BUY'
                       ? 'bg-green-100 text-green-800'
-                      : 'bg-red-100 text-red-800'
+                      : operation.op === 'SELL'
+                        ? 'bg-red-100 text-red-800'
+                        : 'bg-blue-100 text-blue-800'
                   }`}
                 >
                   {operation.op}